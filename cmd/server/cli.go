@@ -0,0 +1,209 @@
+// Package main 命令行子命令
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"emby-telegram/internal/config"
+	"emby-telegram/internal/dataio"
+	"emby-telegram/internal/invitecode"
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/policy"
+	"emby-telegram/internal/schedule"
+	"emby-telegram/internal/user"
+)
+
+const cliUsage = `usage:
+  emby-bot                                  start the bot
+  emby-bot export <dataset> [-format csv|json] [-o file]
+  emby-bot import <dataset> <file> [-format csv|json] [-dry-run]
+
+export datasets: %s
+import datasets: %s
+`
+
+// runCLI 执行导入导出子命令，返回进程退出码
+// 子命令以离线模式运行，不连接 Emby，也不影响正在运行的 Bot
+func runCLI(cfg *config.Config, args []string) int {
+	// 导出可能写到标准输出，日志改为输出到标准错误
+	if cfg.Log.Output == "" || cfg.Log.Output == "stdout" {
+		if err := logger.Init(cfg.Log.Level, "stderr"); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to initialize logger: %v\n", err)
+			return 1
+		}
+	}
+
+	var run func(ctx context.Context, svc *dataio.Service, args []string) error
+	switch args[0] {
+	case "export":
+		run = runExport
+	case "import":
+		run = runImport
+	case "help", "-h", "-help", "--help":
+		printCLIUsage(os.Stdout)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", args[0])
+		printCLIUsage(os.Stderr)
+		return 2
+	}
+
+	stores, err := openDatabase(cfg, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to initialize database: %v\n", err)
+		return 1
+	}
+	defer stores.Close()
+
+	userService := user.NewService(stores.UserStore, stores.RoleStore, cfg.Telegram.AdminIDs)
+	policyService := policy.NewService(stores.PolicyStore)
+	accountService := newAccountService(cfg, stores, userService, policyService, schedule.NewService(stores.ScheduleStore), nil)
+	inviteCodeService := invitecode.NewService(stores.InviteCodeStore, &inviteCodeUserGetterAdapter{userService: userService})
+	svc := dataio.NewService(userService, accountService, inviteCodeService, policyService)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if err := run(ctx, svc, args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "%s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// runExport 导出数据集到文件或标准输出
+func runExport(ctx context.Context, svc *dataio.Service, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	formatFlag := fs.String("format", "", "file format: csv or json (default: from -o extension, else csv)")
+	output := fs.String("o", "", "output file (default: stdout)")
+
+	dataset, rest, err := parseDatasetArg(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("unexpected argument: %s", rest[0])
+	}
+
+	format, err := cliFormat(*formatFlag, *output)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	count, err := svc.Export(ctx, w, dataset, format)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "exported %d %s records\n", count, dataset)
+	return nil
+}
+
+// runImport 从文件导入数据集，并输出每个问题行
+func runImport(ctx context.Context, svc *dataio.Service, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	formatFlag := fs.String("format", "", "file format: csv or json (default: from file extension)")
+	dryRun := fs.Bool("dry-run", false, "validate only, do not write")
+
+	dataset, rest, err := parseDatasetArg(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return errors.New("expected exactly one input file")
+	}
+	path := rest[0]
+
+	format, err := cliFormat(*formatFlag, path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	report, err := svc.Import(ctx, f, dataset, format, *dryRun)
+	if report != nil {
+		for _, issue := range report.Issues {
+			fmt.Fprintf(os.Stderr, "row %d (%s): %v\n", issue.Row, issue.Key, issue.Err)
+		}
+		if report.DryRun {
+			fmt.Fprintf(os.Stderr, "dry run: %d rows, %d valid, %d with issues\n", report.Total, report.Valid, len(report.Issues))
+		} else {
+			fmt.Fprintf(os.Stderr, "imported %d of %d rows, %d with issues\n", report.Imported, report.Total, len(report.Issues))
+		}
+	}
+	return err
+}
+
+// parseDatasetArg 解析子命令的参数，第一个位置参数为数据集，返回其余的位置参数
+// 选项可以写在位置参数之前、之间或之后
+func parseDatasetArg(fs *flag.FlagSet, args []string) (dataio.Dataset, []string, error) {
+	fs.SetOutput(os.Stderr)
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return "", nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) == 0 {
+		return "", nil, errors.New("missing dataset")
+	}
+
+	dataset, err := dataio.ParseDataset(positional[0])
+	if err != nil {
+		return "", nil, err
+	}
+	return dataset, positional[1:], nil
+}
+
+// cliFormat 确定文件格式，未指定时按文件扩展名判断
+func cliFormat(flagValue, path string) (dataio.Format, error) {
+	if flagValue != "" || path == "" {
+		return dataio.ParseFormat(flagValue)
+	}
+	return dataio.FormatFromFilename(path)
+}
+
+// printCLIUsage 输出子命令用法
+func printCLIUsage(w io.Writer) {
+	fmt.Fprintf(w, cliUsage, datasetNames(dataio.Datasets), datasetNames(dataio.ImportDatasets))
+}
+
+// datasetNames 数据集名称列表
+func datasetNames(datasets []dataio.Dataset) string {
+	names := make([]string, len(datasets))
+	for i, d := range datasets {
+		names[i] = string(d)
+	}
+	return strings.Join(names, ", ")
+}
@@ -3,21 +3,35 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"emby-telegram/internal/account"
+	"emby-telegram/internal/api"
+	"emby-telegram/internal/apitoken"
 	"emby-telegram/internal/bot"
 	"emby-telegram/internal/config"
+	"emby-telegram/internal/conversation"
+	"emby-telegram/internal/dashboard"
 	"emby-telegram/internal/database"
+	"emby-telegram/internal/dataio"
 	"emby-telegram/internal/emby"
+	"emby-telegram/internal/httpserver"
+	"emby-telegram/internal/i18n"
 	"emby-telegram/internal/invitecode"
 	"emby-telegram/internal/logger"
+	"emby-telegram/internal/policy"
+	"emby-telegram/internal/schedule"
 	"emby-telegram/internal/storage"
 	"emby-telegram/internal/user"
+	"emby-telegram/internal/webapp"
 )
 
 // userGetterAdapter adapts user.Service to account.UserGetter interface
@@ -30,10 +44,15 @@ func (a *userGetterAdapter) Get(ctx context.Context, id uint) (account.User, err
 	if err != nil {
 		return account.User{}, err
 	}
+	perms := make(map[string]bool)
+	for _, p := range a.userService.PermissionsOf(ctx, u) {
+		perms[string(p)] = true
+	}
 	return account.User{
 		ID:           u.ID,
 		IsAdmin:      u.IsAdmin(),
 		AccountQuota: u.AccountQuota,
+		Permissions:  perms,
 	}, nil
 }
 
@@ -75,25 +94,20 @@ func main() {
 	}
 	defer logger.Sync()
 
+	// 子命令: 数据导入导出
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(cfg, os.Args[1:]))
+	}
+
 	logger.Info("===== emby telegram bot starting =====")
 	logger.Infof("version: %s", cfg.App.Version)
 	logger.Infof("debug mode: %v", cfg.App.Debug)
 
-	stores, err := storage.NewStores(cfg.Database.Driver, cfg.Database.DSN, cfg.App.Debug)
+	stores, err := openDatabase(cfg, cfg.App.Debug)
 	if err != nil {
 		logger.Fatalf("failed to initialize database: %v", err)
 	}
-	logger.Infof("✓ database connected (driver: %s)", cfg.Database.Driver)
-
-	sqlDB, err := stores.DB.DB()
-	if err != nil {
-		logger.Fatalf("failed to get sql.DB: %v", err)
-	}
-
-	if err := database.RunMigrations(sqlDB, cfg.Database.Driver, "migrations", logger.Logger()); err != nil {
-		logger.Fatalf("failed to run migrations: %v", err)
-	}
-	logger.Info("✓ database migrated")
+	logger.Infof("✓ database connected and migrated (driver: %s)", cfg.Database.Driver)
 
 	// 初始化 Emby Client
 	var embyClient *emby.Client
@@ -104,6 +118,10 @@ func main() {
 			cfg.Emby.Timeout,
 			cfg.Emby.RetryCount,
 			cfg.Emby.EnableSync,
+			cfg.Emby.RateLimit,
+			cfg.Emby.RateBurst,
+			cfg.Emby.MaxConcurrent,
+			cfg.Emby.CacheTTL,
 		)
 
 		// 测试连接
@@ -119,43 +137,130 @@ func main() {
 		logger.Info("✓ emby sync disabled, running in offline mode")
 	}
 
-	userService := user.NewService(stores.UserStore)
+	userService := user.NewService(stores.UserStore, stores.RoleStore, cfg.Telegram.AdminIDs)
+	if promoted, err := userService.BootstrapAdmins(context.Background()); err != nil {
+		logger.Fatalf("failed to bootstrap admins: %v", err)
+	} else if promoted > 0 {
+		logger.Infof("✓ promoted %d configured admin(s)", promoted)
+	}
 
-	userGetter := &userGetterAdapter{userService: userService}
+	policyService := policy.NewService(stores.PolicyStore)
+	scheduleService := schedule.NewService(stores.ScheduleStore)
+	apiTokenService := apitoken.NewService(stores.APITokenStore)
 
-	accountService := account.NewService(
-		stores.AccountStore,
-		userGetter,
-		embyClient,
-		cfg.Account.UsernamePrefix,
-		cfg.Account.DefaultExpireDays,
-		cfg.Account.DefaultMaxDevices,
-		cfg.Account.PasswordLength,
-		cfg.Account.MaxAccountsPerUser,
-		cfg.Account.MaxAccountsPerAdmin,
-		cfg.Emby.EnableSync,
-		cfg.Emby.SyncOnCreate,
-		cfg.Emby.SyncOnDelete,
-	)
+	accountService := newAccountService(cfg, stores, userService, policyService, scheduleService, embyClient)
 
 	inviteCodeUserGetter := &inviteCodeUserGetterAdapter{userService: userService}
 	inviteCodeService := invitecode.NewService(stores.InviteCodeStore, inviteCodeUserGetter)
+	dataService := dataio.NewService(userService, accountService, inviteCodeService, policyService)
+
+	logger.Infof("✓ services initialized (user, account, invitecode, policy, schedule, apitoken, dataio)")
 
-	logger.Infof("✓ services initialized (user, account, invitecode)")
+	// 加载消息目录
+	catalog, err := i18n.Load(cfg.Telegram.DefaultLanguage)
+	if err != nil {
+		logger.Fatalf("failed to load message catalogs: %v", err)
+	}
+	logger.Infof("✓ message catalogs loaded (%s)", strings.Join(catalog.Languages(), ", "))
+
+	// 对话状态存储
+	var stateStore conversation.StateStore = stores.StateStore
+	if cfg.Telegram.Conversation.Store == "memory" {
+		stateStore = conversation.NewMemoryStore()
+	}
+	stateTTLs := make(map[bot.UserState]time.Duration, len(cfg.Telegram.Conversation.StateTTLs))
+	for state, seconds := range cfg.Telegram.Conversation.StateTTLs {
+		stateTTLs[bot.UserState(state)] = time.Duration(seconds) * time.Second
+	}
+	stateMachine := bot.NewStateMachine(stateStore, time.Duration(cfg.Telegram.Conversation.TTL)*time.Second, stateTTLs)
+	logger.Infof("✓ conversation state store: %s", cfg.Telegram.Conversation.Store)
 
 	telegramBot, err := bot.New(
 		cfg.Telegram.Token,
-		cfg.Telegram.AdminIDs,
 		accountService,
 		userService,
 		inviteCodeService,
+		policyService,
+		scheduleService,
+		apiTokenService,
+		dataService,
 		embyClient,
+		catalog,
+		stateMachine,
+		bot.ReceiveConfig{
+			Mode:          cfg.Telegram.Mode,
+			PollTimeout:   cfg.Telegram.Timeout,
+			WebhookListen: cfg.Telegram.Webhook.Listen,
+			WebhookURL:    cfg.Telegram.Webhook.URL,
+			WebhookSecret: cfg.Telegram.Webhook.SecretToken,
+			Workers:       cfg.Telegram.Workers,
+			QueueSize:     cfg.Telegram.QueueSize,
+			RateLimit:     cfg.Telegram.RateLimit,
+			RateBurst:     cfg.Telegram.RateBurst,
+
+			SendRate:           cfg.Telegram.Send.Rate,
+			ChatSendRate:       cfg.Telegram.Send.ChatRate,
+			GroupSendPerMinute: cfg.Telegram.Send.GroupPerMinute,
+			SendRetries:        cfg.Telegram.Send.Retries,
+
+			WebAppShortName: webAppShortName(cfg),
+		},
 	)
 	if err != nil {
 		logger.Fatalf("failed to initialize bot: %v", err)
 	}
 	logger.Info("✓ telegram bot initialized")
 
+	// 内置 HTTP 服务
+	var httpServer *httpserver.Server
+	if cfg.HTTPEnabled() {
+		httpServer = httpserver.New(cfg.HTTP.Listen)
+
+		if cfg.Telegram.WebApp.Enabled {
+			// 配置校验已保证 URL 合法
+			webAppURL, _ := url.Parse(cfg.Telegram.WebApp.URL)
+			webAppServer := webapp.NewServer(
+				accountService,
+				userService,
+				catalog,
+				cfg.Telegram.Token,
+				webAppURL.Path,
+				cfg.Telegram.WebApp.GetInitDataTTL(),
+			)
+			httpServer.Handle(webAppServer.BasePath(), webAppServer.Handler())
+			logger.Infof("✓ web app served at %s", cfg.Telegram.WebApp.URL)
+		}
+
+		if cfg.API.Enabled {
+			apiServer := api.NewServer(accountService, userService, inviteCodeService, apiTokenService, embyClient)
+			httpServer.Handle(api.BasePath, apiServer.Handler())
+			logger.Infof("✓ admin api served at %s", api.BasePath)
+		}
+
+		if cfg.Dashboard.Enabled {
+			// 配置校验已保证 URL 合法
+			dashboardURL, _ := url.Parse(cfg.Dashboard.URL)
+			dashboardServer := dashboard.NewServer(
+				accountService,
+				userService,
+				inviteCodeService,
+				embyClient,
+				catalog,
+				cfg.Telegram.Token,
+				telegramBot.Username(),
+				dashboardURL,
+				cfg.Dashboard.GetSessionTTL(),
+			)
+			httpServer.Handle(dashboardServer.BasePath(), dashboardServer.Handler())
+			logger.Infof("✓ admin dashboard served at %s", cfg.Dashboard.URL)
+		}
+
+		if err := httpServer.Start(); err != nil {
+			logger.Fatalf("failed to start http server: %v", err)
+		}
+		logger.Infof("✓ http server listening on %s", cfg.HTTP.Listen)
+	}
+
 	// 创建上下文
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -184,7 +289,33 @@ func main() {
 	}
 
 	logger.Info("shutting down bot...")
-	telegramBot.Stop()
+
+	// 先停止接收循环和 HTTP 监听，再等待正在处理的更新和请求完成，最后才关闭数据库
+	// HTTP 服务与 Bot 并行关闭并各自使用独立的超时，Bot 排空耗尽时间也不会让 HTTP 请求在数据库关闭后继续运行
+	cancel()
+	var wg sync.WaitGroup
+	if httpServer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			httpCtx, httpCancel := context.WithTimeout(context.Background(), cfg.App.GetShutdownTimeout())
+			defer httpCancel()
+			if err := httpServer.Shutdown(httpCtx); err != nil {
+				logger.Warnf("http server did not shut down cleanly: %v", err)
+			} else {
+				logger.Info("✓ http server stopped")
+			}
+		}()
+	}
+
+	botCtx, botCancel := context.WithTimeout(context.Background(), cfg.App.GetShutdownTimeout())
+	defer botCancel()
+	if err := telegramBot.Stop(botCtx); err != nil {
+		logger.Warnf("bot did not shut down cleanly: %v", err)
+	} else {
+		logger.Info("✓ bot stopped")
+	}
+	wg.Wait()
 
 	if err := stores.Close(); err != nil {
 		logger.Errorf("failed to close database connection: %v", err)
@@ -194,3 +325,51 @@ func main() {
 
 	logger.Info("===== bot stopped =====")
 }
+
+// openDatabase 连接数据库并执行迁移
+func openDatabase(cfg *config.Config, debug bool) (*storage.Stores, error) {
+	stores, err := storage.NewStores(cfg.Database.Driver, cfg.Database.DSN, debug)
+	if err != nil {
+		return nil, fmt.Errorf("connect database: %w", err)
+	}
+
+	sqlDB, err := stores.DB.DB()
+	if err != nil {
+		stores.Close()
+		return nil, fmt.Errorf("get sql.DB: %w", err)
+	}
+
+	if err := database.RunMigrations(sqlDB, cfg.Database.Driver, "migrations", logger.Logger()); err != nil {
+		stores.Close()
+		return nil, fmt.Errorf("run migrations: %w", err)
+	}
+	return stores, nil
+}
+
+// newAccountService 创建账号服务，embyClient 为 nil 时以离线模式运行
+func newAccountService(cfg *config.Config, stores *storage.Stores, userService *user.Service, policyService *policy.Service, scheduleService *schedule.Service, embyClient *emby.Client) *account.Service {
+	return account.NewService(
+		stores.AccountStore,
+		&userGetterAdapter{userService: userService},
+		embyClient,
+		policyService,
+		scheduleService,
+		cfg.Account.UsernamePrefix,
+		cfg.Account.DefaultExpireDays,
+		cfg.Account.DefaultMaxDevices,
+		cfg.Account.PasswordLength,
+		cfg.Account.MaxAccountsPerUser,
+		cfg.Account.MaxAccountsPerAdmin,
+		cfg.Emby.EnableSync,
+		cfg.Emby.SyncOnCreate,
+		cfg.Emby.SyncOnDelete,
+	)
+}
+
+// webAppShortName 返回启用时的 Mini App 短名称
+func webAppShortName(cfg *config.Config) string {
+	if !cfg.Telegram.WebApp.Enabled {
+		return ""
+	}
+	return cfg.Telegram.WebApp.ShortName
+}
@@ -0,0 +1,147 @@
+// Package account 账号操作权限
+package account
+
+import (
+	"context"
+	"fmt"
+
+	"emby-telegram/pkg/validator"
+)
+
+// 账号服务使用的权限名称，与 user 包中的权限定义保持一致
+const (
+	PermissionView     = "account.view"     // 查看所有账号
+	PermissionCreate   = "account.create"   // 为任意用户创建账号
+	PermissionRenew    = "account.renew"    // 续期任意账号
+	PermissionPassword = "account.password" // 修改任意账号密码
+	PermissionSuspend  = "account.suspend"  // 停用/启用账号
+	PermissionDelete   = "account.delete"   // 删除账号
+	PermissionPolicy   = "account.policy"   // 管理账号策略
+	PermissionCustomer = "customer.manage"  // 管理自己创建的客户账号
+)
+
+// customerScoped 账号所有者和创建该账号的代理商可以执行的操作
+var customerScoped = map[string]bool{
+	PermissionView:     true,
+	PermissionRenew:    true,
+	PermissionPassword: true,
+}
+
+// CanManage 检查操作者能否以指定权限操作账号
+// 拥有全局权限的用户可以操作任意账号；查看、续期、改密还允许账号所有者和创建该账号的代理商
+func (s *Service) CanManage(ctx context.Context, operatorID uint, acc *Account, permission string) error {
+	if customerScoped[permission] && acc.UserID == operatorID {
+		return nil
+	}
+
+	operator, err := s.userGetter.Get(ctx, operatorID)
+	if err != nil {
+		return fmt.Errorf("get operator: %w", err)
+	}
+
+	if operator.Can(permission) {
+		return nil
+	}
+	if customerScoped[permission] && operator.Can(PermissionCustomer) && acc.CreatedBy == operatorID {
+		return nil
+	}
+
+	return UnauthorizedError(permission)
+}
+
+// CreateFor 代其他用户创建账号
+// 拥有 account.create 或 customer.manage 权限的用户可用，不占用账号所有者的配额；
+// 创建者记录在 CreatedBy 中，代理商之后只能看到和管理自己创建的账号
+func (s *Service) CreateFor(ctx context.Context, operatorID, ownerID uint, username string) (*Account, string, error) {
+	operator, err := s.userGetter.Get(ctx, operatorID)
+	if err != nil {
+		return nil, "", fmt.Errorf("get operator: %w", err)
+	}
+	if !operator.Can(PermissionCreate) && !operator.Can(PermissionCustomer) {
+		return nil, "", UnauthorizedError(PermissionCreate)
+	}
+
+	if err := s.checkMaintenance(ctx); err != nil {
+		return nil, "", err
+	}
+
+	if _, err := s.userGetter.Get(ctx, ownerID); err != nil {
+		return nil, "", fmt.Errorf("get owner: %w", err)
+	}
+
+	username = validator.SanitizeUsername(username)
+	if err := validator.ValidateUsername(username); err != nil {
+		return nil, "", InvalidFieldError("username", err)
+	}
+
+	if _, err := s.store.GetByUsername(ctx, username); err == nil {
+		return nil, "", AlreadyExistsError(username)
+	}
+
+	return s.create(ctx, username, ownerID, operatorID)
+}
+
+// RenewAs 以操作者身份续期账号，先检查续期权限
+func (s *Service) RenewAs(ctx context.Context, operatorID, id uint, days int) error {
+	if err := s.authorize(ctx, operatorID, id, PermissionRenew); err != nil {
+		return err
+	}
+	return s.Renew(ctx, id, days)
+}
+
+// DeleteAs 以操作者身份删除账号，先检查删除权限
+func (s *Service) DeleteAs(ctx context.Context, operatorID, id uint) error {
+	if err := s.authorize(ctx, operatorID, id, PermissionDelete); err != nil {
+		return err
+	}
+	return s.Delete(ctx, id)
+}
+
+// SuspendAs 以操作者身份停用账号，先检查停用权限
+func (s *Service) SuspendAs(ctx context.Context, operatorID, id uint) error {
+	if err := s.authorize(ctx, operatorID, id, PermissionSuspend); err != nil {
+		return err
+	}
+	return s.Suspend(ctx, id)
+}
+
+// ActivateAs 以操作者身份激活账号，与停用使用同一权限
+func (s *Service) ActivateAs(ctx context.Context, operatorID, id uint) error {
+	if err := s.authorize(ctx, operatorID, id, PermissionSuspend); err != nil {
+		return err
+	}
+	return s.Activate(ctx, id)
+}
+
+// authorize 获取账号并检查操作者能否以指定权限操作
+func (s *Service) authorize(ctx context.Context, operatorID, id uint, permission string) error {
+	acc, err := s.store.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get account: %w", err)
+	}
+	return s.CanManage(ctx, operatorID, acc, permission)
+}
+
+// ListManaged 按查询条件列出操作者可以管理的账号
+// 拥有 account.view 权限时返回全部账号，代理商只返回自己创建的账号
+func (s *Service) ListManaged(ctx context.Context, operatorID uint, q ListQuery) ([]*AccountWithUser, int64, error) {
+	operator, err := s.userGetter.Get(ctx, operatorID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get operator: %w", err)
+	}
+
+	switch {
+	case operator.Can(PermissionView):
+		q.CreatorID = 0
+	case operator.Can(PermissionCustomer):
+		q.CreatorID = operatorID
+	default:
+		return nil, 0, UnauthorizedError(PermissionView)
+	}
+
+	accs, total, err := s.store.ListByQuery(ctx, q)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list managed accounts: %w", err)
+	}
+	return accs, total, nil
+}
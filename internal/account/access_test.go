@@ -0,0 +1,278 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"emby-telegram/internal/user"
+)
+
+// 测试中的用户 ID
+const (
+	adminID    uint = 1
+	resellerID uint = 2 // 只有 customer.manage
+	customerID uint = 3 // 代理商的客户
+	supportID  uint = 4 // 只有 account.view
+	renewerID  uint = 5 // 只有 account.renew
+	otherID    uint = 6 // 由管理员创建账号的普通用户
+)
+
+// 测试中的账号 ID
+const (
+	resellerAccountID uint = 10 // 属于 customerID，由代理商创建
+	otherAccountID    uint = 11 // 属于 otherID，由管理员创建
+)
+
+// fakeUsers 按 ID 返回固定的用户及权限
+type fakeUsers map[uint]User
+
+func (f fakeUsers) Get(_ context.Context, id uint) (User, error) {
+	u, ok := f[id]
+	if !ok {
+		return User{}, errors.New("user not found")
+	}
+	return u, nil
+}
+
+// fakeStore 内存账号存储，只实现权限相关操作用到的方法
+type fakeStore struct {
+	Store
+	accounts map[uint]*Account
+	nextID   uint
+}
+
+func (f *fakeStore) Get(_ context.Context, id uint) (*Account, error) {
+	acc, ok := f.accounts[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *acc
+	return &cp, nil
+}
+
+func (f *fakeStore) GetByUsername(_ context.Context, username string) (*Account, error) {
+	for _, acc := range f.accounts {
+		if acc.Username == username {
+			cp := *acc
+			return &cp, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (f *fakeStore) Create(_ context.Context, acc *Account) error {
+	f.nextID++
+	acc.ID = f.nextID
+	cp := *acc
+	f.accounts[acc.ID] = &cp
+	return nil
+}
+
+func (f *fakeStore) Update(_ context.Context, acc *Account) error {
+	cp := *acc
+	f.accounts[acc.ID] = &cp
+	return nil
+}
+
+func (f *fakeStore) Delete(_ context.Context, id uint) error {
+	delete(f.accounts, id)
+	return nil
+}
+
+// newAccessTestService 创建不连接 Emby 的账号服务，预置各角色用户和两个账号
+func newAccessTestService() (*Service, *fakeStore) {
+	users := fakeUsers{
+		adminID:    {ID: adminID, IsAdmin: true},
+		resellerID: {ID: resellerID, Permissions: map[string]bool{PermissionCustomer: true}},
+		customerID: {ID: customerID, AccountQuota: 1},
+		supportID:  {ID: supportID, Permissions: map[string]bool{PermissionView: true}},
+		renewerID:  {ID: renewerID, Permissions: map[string]bool{PermissionRenew: true}},
+		otherID:    {ID: otherID},
+	}
+	store := &fakeStore{
+		accounts: map[uint]*Account{
+			resellerAccountID: {ID: resellerAccountID, Username: "customer", UserID: customerID, CreatedBy: resellerID, Status: StatusActive},
+			otherAccountID:    {ID: otherAccountID, Username: "other", UserID: otherID, CreatedBy: adminID, Status: StatusActive},
+		},
+		nextID: 100,
+	}
+	svc := NewService(store, users, nil, nil, nil, "", 30, 2, 12, 0, 0, false, false, false)
+	return svc, store
+}
+
+func TestCanManage(t *testing.T) {
+	tests := []struct {
+		name       string
+		operator   uint
+		account    uint
+		permission string
+		wantErr    error
+	}{
+		{name: "owner views own account", operator: customerID, account: resellerAccountID, permission: PermissionView},
+		{name: "owner renews own account", operator: customerID, account: resellerAccountID, permission: PermissionRenew},
+		{name: "owner changes own password", operator: customerID, account: resellerAccountID, permission: PermissionPassword},
+		{name: "owner cannot suspend own account", operator: customerID, account: resellerAccountID, permission: PermissionSuspend, wantErr: ErrUnauthorized},
+		{name: "owner cannot delete own account", operator: customerID, account: resellerAccountID, permission: PermissionDelete, wantErr: ErrUnauthorized},
+		{name: "owner cannot change own policy", operator: customerID, account: resellerAccountID, permission: PermissionPolicy, wantErr: ErrUnauthorized},
+		{name: "owner cannot view other account", operator: customerID, account: otherAccountID, permission: PermissionView, wantErr: ErrUnauthorized},
+
+		{name: "reseller views created account", operator: resellerID, account: resellerAccountID, permission: PermissionView},
+		{name: "reseller renews created account", operator: resellerID, account: resellerAccountID, permission: PermissionRenew},
+		{name: "reseller changes created account password", operator: resellerID, account: resellerAccountID, permission: PermissionPassword},
+		{name: "reseller cannot suspend created account", operator: resellerID, account: resellerAccountID, permission: PermissionSuspend, wantErr: ErrUnauthorized},
+		{name: "reseller cannot delete created account", operator: resellerID, account: resellerAccountID, permission: PermissionDelete, wantErr: ErrUnauthorized},
+		{name: "reseller cannot view other account", operator: resellerID, account: otherAccountID, permission: PermissionView, wantErr: ErrUnauthorized},
+		{name: "reseller cannot renew other account", operator: resellerID, account: otherAccountID, permission: PermissionRenew, wantErr: ErrUnauthorized},
+
+		{name: "view role views any account", operator: supportID, account: otherAccountID, permission: PermissionView},
+		{name: "view role cannot renew", operator: supportID, account: otherAccountID, permission: PermissionRenew, wantErr: ErrUnauthorized},
+		{name: "renew role renews any account", operator: renewerID, account: otherAccountID, permission: PermissionRenew},
+		{name: "renew role cannot delete", operator: renewerID, account: otherAccountID, permission: PermissionDelete, wantErr: ErrUnauthorized},
+		{name: "admin deletes any account", operator: adminID, account: otherAccountID, permission: PermissionDelete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newAccessTestService()
+			err := svc.CanManage(context.Background(), tt.operator, store.accounts[tt.account], tt.permission)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("CanManage() error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CanManage() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator uint
+		owner    uint
+		wantErr  error
+	}{
+		// 代理商和被代建账号的用户都没有配额，代建不占用所有者的配额
+		{name: "reseller bypasses owner quota", operator: resellerID, owner: otherID},
+		{name: "admin creates for user", operator: adminID, owner: otherID},
+		{name: "view role cannot create", operator: supportID, owner: otherID, wantErr: ErrUnauthorized},
+		{name: "plain user cannot create for others", operator: customerID, owner: otherID, wantErr: ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newAccessTestService()
+			acc, _, err := svc.CreateFor(context.Background(), tt.operator, tt.owner, "newacc")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("CreateFor() error = %v, want %v", err, tt.wantErr)
+				}
+				if len(store.accounts) != 2 {
+					t.Fatalf("rejected CreateFor() stored an account")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateFor() error = %v", err)
+			}
+			if acc.UserID != tt.owner || acc.CreatedBy != tt.operator {
+				t.Fatalf("CreateFor() owner/creator = %d/%d, want %d/%d", acc.UserID, acc.CreatedBy, tt.owner, tt.operator)
+			}
+		})
+	}
+}
+
+func TestActAs(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		act      func(s *Service) error
+		account  uint
+		wantErr  error
+		wantGone bool
+	}{
+		{
+			name:    "reseller renews created account",
+			act:     func(s *Service) error { return s.RenewAs(ctx, resellerID, resellerAccountID, 30) },
+			account: resellerAccountID,
+		},
+		{
+			name:    "reseller cannot renew other account",
+			act:     func(s *Service) error { return s.RenewAs(ctx, resellerID, otherAccountID, 30) },
+			account: otherAccountID,
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:    "reseller cannot delete created account",
+			act:     func(s *Service) error { return s.DeleteAs(ctx, resellerID, resellerAccountID) },
+			account: resellerAccountID,
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:    "view role cannot suspend",
+			act:     func(s *Service) error { return s.SuspendAs(ctx, supportID, otherAccountID) },
+			account: otherAccountID,
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:    "owner cannot activate own account",
+			act:     func(s *Service) error { return s.ActivateAs(ctx, customerID, resellerAccountID) },
+			account: resellerAccountID,
+			wantErr: ErrUnauthorized,
+		},
+		{
+			name:     "admin deletes any account",
+			act:      func(s *Service) error { return s.DeleteAs(ctx, adminID, otherAccountID) },
+			account:  otherAccountID,
+			wantGone: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, store := newAccessTestService()
+			before := *store.accounts[tt.account]
+
+			err := tt.act(svc)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			after, ok := store.accounts[tt.account]
+			if ok == tt.wantGone {
+				t.Fatalf("account present = %v, want %v", ok, !tt.wantGone)
+			}
+			// 被拒绝的操作不能修改账号
+			if tt.wantErr != nil && (after.Status != before.Status || after.ExpireAt != before.ExpireAt) {
+				t.Fatalf("rejected operation modified account: %+v", after)
+			}
+		})
+	}
+}
+
+// TestPermissionNames 账号服务检查的权限名必须与角色授予的权限名一致，
+// 否则 Bot 路由按 user 包放行的操作会在账号服务中被拒绝，或者相反
+func TestPermissionNames(t *testing.T) {
+	pairs := map[string]user.Permission{
+		PermissionView:     user.PermAccountView,
+		PermissionCreate:   user.PermAccountCreate,
+		PermissionRenew:    user.PermAccountRenew,
+		PermissionPassword: user.PermAccountPassword,
+		PermissionSuspend:  user.PermAccountSuspend,
+		PermissionDelete:   user.PermAccountDelete,
+		PermissionPolicy:   user.PermAccountPolicy,
+		PermissionCustomer: user.PermCustomerManage,
+	}
+	for name, perm := range pairs {
+		if name != string(perm) {
+			t.Errorf("account permission %q does not match user permission %q", name, perm)
+		}
+		if !user.IsValidPermission(perm) {
+			t.Errorf("user permission %q is not a valid permission", perm)
+		}
+	}
+}
@@ -21,24 +21,29 @@ const (
 
 // Account 账号实体
 type Account struct {
-	ID         uint           `gorm:"primarykey" json:"id"`
-	Username   string         `gorm:"uniqueIndex;size:100;not null" json:"username"`
-	Password   string         `gorm:"size:255;not null" json:"-"` // 不序列化密码
-	Email      string         `gorm:"size:100" json:"email"`
-	UserID     uint           `gorm:"index;not null" json:"user_id"` // 关联的 Telegram 用户
-	Status     Status         `gorm:"size:20;default:active" json:"status"`
-	ExpireAt   *time.Time     `json:"expire_at,omitempty"`
-	MaxDevices int            `gorm:"default:3" json:"max_devices"`
+	ID         uint       `gorm:"primarykey" json:"id"`
+	Username   string     `gorm:"uniqueIndex;size:100;not null" json:"username"`
+	Password   string     `gorm:"size:255;not null" json:"-"` // 不序列化密码
+	Email      string     `gorm:"size:100" json:"email"`
+	UserID     uint       `gorm:"index;not null" json:"user_id"`    // 关联的 Telegram 用户
+	CreatedBy  uint       `gorm:"index;not null" json:"created_by"` // 代为创建账号的用户，0 表示用户自行创建
+	Status     Status     `gorm:"size:20;default:active" json:"status"`
+	ExpireAt   *time.Time `json:"expire_at,omitempty"`
+	MaxDevices int        `gorm:"default:3" json:"max_devices"`
+
+	// 策略模板，0 表示使用默认模板
+	PolicyTemplateID uint            `gorm:"not null" json:"policy_template_id"`
+	PolicyOverrides  PolicyOverrides `gorm:"serializer:json;type:text" json:"policy_overrides"` // 账号级策略覆盖
 
 	// Emby 同步字段
-	EmbyUserID string         `gorm:"size:100;index" json:"emby_user_id,omitempty"` // Emby 用户 ID
-	SyncStatus string         `gorm:"size:20;default:pending" json:"sync_status"`    // synced/pending/failed
-	LastSyncAt *time.Time     `json:"last_sync_at,omitempty"`
-	SyncError  string         `gorm:"type:text" json:"sync_error,omitempty"` // 同步错误信息
+	EmbyUserID string     `gorm:"size:100;index" json:"emby_user_id,omitempty"` // Emby 用户 ID
+	SyncStatus string     `gorm:"size:20;default:pending" json:"sync_status"`   // synced/pending/failed
+	LastSyncAt *time.Time `json:"last_sync_at,omitempty"`
+	SyncError  string     `gorm:"type:text" json:"sync_error,omitempty"` // 同步错误信息
 
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // TableName 指定表名
@@ -49,8 +54,8 @@ func (Account) TableName() string {
 // AccountWithUser 账号及关联用户信息
 type AccountWithUser struct {
 	Account
-	OwnerUsername  string
-	OwnerFirstName string
+	OwnerUsername   string
+	OwnerFirstName  string
 	OwnerTelegramID int64
 }
 
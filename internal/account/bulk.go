@@ -0,0 +1,102 @@
+// Package account 批量操作
+package account
+
+import (
+	"context"
+	"fmt"
+
+	"emby-telegram/pkg/validator"
+)
+
+// BulkAction 批量操作类型
+type BulkAction string
+
+const (
+	// BulkRenew 续期 Days 天
+	BulkRenew BulkAction = "renew"
+	// BulkSuspend 暂停
+	BulkSuspend BulkAction = "suspend"
+	// BulkActivate 激活
+	BulkActivate BulkAction = "activate"
+	// BulkDelete 删除
+	BulkDelete BulkAction = "delete"
+	// BulkSetDevices 设置设备数上限为 Devices
+	BulkSetDevices BulkAction = "devices"
+	// BulkApplyTemplate 应用策略模板 TemplateID
+	BulkApplyTemplate BulkAction = "template"
+)
+
+// BulkActions 全部批量操作，按菜单显示顺序排列
+var BulkActions = []BulkAction{BulkRenew, BulkSuspend, BulkActivate, BulkDelete, BulkSetDevices, BulkApplyTemplate}
+
+// BulkOperation 批量操作及其参数
+type BulkOperation struct {
+	Action     BulkAction
+	Days       int  // BulkRenew 的续期天数
+	Devices    int  // BulkSetDevices 的设备数上限
+	TemplateID uint // BulkApplyTemplate 的策略模板
+}
+
+// Permission 执行操作所需的权限
+// 续期允许代理商操作自己创建的账号，其余操作需要对应的全局权限
+func (op BulkOperation) Permission() string {
+	switch op.Action {
+	case BulkRenew:
+		return PermissionRenew
+	case BulkSuspend, BulkActivate:
+		return PermissionSuspend
+	case BulkDelete:
+		return PermissionDelete
+	default:
+		return PermissionPolicy
+	}
+}
+
+// Validate 校验操作类型与参数
+func (op BulkOperation) Validate() error {
+	switch op.Action {
+	case BulkRenew:
+		if err := validator.ValidateDays(op.Days); err != nil {
+			return InvalidFieldError("days", err)
+		}
+	case BulkSetDevices:
+		if op.Devices < 0 {
+			return ValidationError("max_devices", "must be non-negative")
+		}
+	case BulkApplyTemplate:
+		if op.TemplateID == 0 {
+			return ValidationError("template_id", "is required")
+		}
+	case BulkSuspend, BulkActivate, BulkDelete:
+	default:
+		return ValidationError("action", fmt.Sprintf("unknown bulk action %q", op.Action))
+	}
+	return nil
+}
+
+// ApplyAs 以操作者身份对单个账号执行批量操作，先检查该账号的操作权限
+// 批量任务逐个调用，单个账号失败不影响其他账号
+func (s *Service) ApplyAs(ctx context.Context, operatorID, id uint, op BulkOperation) error {
+	if err := op.Validate(); err != nil {
+		return err
+	}
+
+	if err := s.authorize(ctx, operatorID, id, op.Permission()); err != nil {
+		return err
+	}
+
+	switch op.Action {
+	case BulkRenew:
+		return s.Renew(ctx, id, op.Days)
+	case BulkSuspend:
+		return s.Suspend(ctx, id)
+	case BulkActivate:
+		return s.Activate(ctx, id)
+	case BulkDelete:
+		return s.Delete(ctx, id)
+	case BulkSetDevices:
+		return s.SetMaxDevices(ctx, id, op.Devices)
+	default:
+		return s.ApplyTemplate(ctx, id, op.TemplateID)
+	}
+}
@@ -0,0 +1,163 @@
+// Package account 策略偏差检测
+package account
+
+import (
+	"context"
+	"fmt"
+
+	"emby-telegram/internal/emby"
+	"emby-telegram/internal/logger"
+)
+
+// CheckDrift 比对账号在 Emby 上的实际策略与期望策略(模板 + 覆盖)
+func (s *Service) CheckDrift(ctx context.Context, id uint) (*DriftReport, error) {
+	if !s.enableSync || s.embyClient == nil {
+		return nil, ErrSyncDisabled
+	}
+
+	acc, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get account: %w", err)
+	}
+
+	return s.checkDrift(ctx, acc)
+}
+
+func (s *Service) checkDrift(ctx context.Context, acc *Account) (*DriftReport, error) {
+	if acc.EmbyUserID == "" {
+		return nil, NotSyncedError(acc.Username)
+	}
+
+	// 绕过缓存读取，修正和接受都基于 Emby 上的最新策略
+	actual, err := s.embyClient.GetUserPolicyLive(ctx, acc.EmbyUserID)
+	if err != nil {
+		return nil, fmt.Errorf("get emby policy: %w", err)
+	}
+
+	expected := s.policyFor(ctx, acc)
+
+	return &DriftReport{
+		Account: acc,
+		Actual:  actual,
+		Diffs:   emby.DiffPolicy(expected, actual),
+	}, nil
+}
+
+// ScanDrift 检查所有已同步账号，返回存在偏差的报告
+// 单个账号检查失败时记录日志并跳过
+func (s *Service) ScanDrift(ctx context.Context) ([]*DriftReport, error) {
+	if !s.enableSync || s.embyClient == nil {
+		return nil, ErrSyncDisabled
+	}
+
+	accs, err := s.store.ListAll(ctx, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("list all accounts: %w", err)
+	}
+
+	var reports []*DriftReport
+	for _, acc := range accs {
+		if acc.EmbyUserID == "" {
+			continue
+		}
+
+		report, err := s.checkDrift(ctx, acc)
+		if err != nil {
+			logger.Warnf("failed to check policy drift for %s: %v", acc.Username, err)
+			continue
+		}
+		if report.HasDrift() {
+			reports = append(reports, report)
+		}
+	}
+
+	return reports, nil
+}
+
+// FixDrift 将账号在 Emby 上的策略修正为期望策略，并记录每个被修正的字段
+func (s *Service) FixDrift(ctx context.Context, id uint, operatorID int64) (*DriftReport, error) {
+	report, err := s.CheckDrift(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !report.HasDrift() {
+		return report, nil
+	}
+
+	acc := report.Account
+	if err := s.pushPolicy(ctx, acc); err != nil {
+		return nil, err
+	}
+
+	for _, d := range report.Diffs {
+		s.recordPolicyChange(ctx, acc, d.Field, d.Actual, d.Expected, PolicyActionFix, operatorID)
+	}
+
+	return report, nil
+}
+
+// AcceptDrift 将 Emby 上的实际值接受为账号覆盖
+// 只有 CanAcceptField 支持的字段可以接受，返回已接受的字段和仍然存在偏差(只能修正)的字段
+func (s *Service) AcceptDrift(ctx context.Context, id uint, operatorID int64) ([]emby.PolicyDiff, []emby.PolicyDiff, error) {
+	report, err := s.CheckDrift(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !report.HasDrift() {
+		return nil, nil, nil
+	}
+
+	acc := report.Account
+	actual := report.Actual
+
+	var accepted []emby.PolicyDiff
+	for _, d := range report.Diffs {
+		if acc.AcceptField(d.Field, actual) {
+			accepted = append(accepted, d)
+		}
+	}
+
+	if len(accepted) > 0 {
+		if err := s.store.Update(ctx, acc); err != nil {
+			return nil, nil, fmt.Errorf("update account: %w", err)
+		}
+		for _, d := range accepted {
+			s.recordPolicyChange(ctx, acc, d.Field, d.Expected, d.Actual, PolicyActionAccept, operatorID)
+		}
+	}
+
+	// 重新比对，剩余的即为无法通过覆盖表达的偏差
+	remaining := emby.DiffPolicy(s.policyFor(ctx, acc), actual)
+
+	return accepted, remaining, nil
+}
+
+// ListPolicyChanges 列出账号最近的策略变更记录
+func (s *Service) ListPolicyChanges(ctx context.Context, id uint, limit int) ([]*PolicyChange, error) {
+	changes, err := s.store.ListPolicyChanges(ctx, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list policy changes: %w", err)
+	}
+	return changes, nil
+}
+
+// recordPolicyChange 记录策略变更，失败只记录日志
+func (s *Service) recordPolicyChange(ctx context.Context, acc *Account, field, oldValue, newValue, action string, operatorID int64) {
+	logger.Infof("policy change: account=%s, field=%s, %s -> %s, action=%s, operator=%d",
+		acc.Username, field, oldValue, newValue, action, operatorID)
+
+	change := &PolicyChange{
+		AccountID:  acc.ID,
+		Username:   acc.Username,
+		Field:      field,
+		OldValue:   oldValue,
+		NewValue:   newValue,
+		Action:     action,
+		OperatorID: operatorID,
+	}
+	if err := s.store.CreatePolicyChange(ctx, change); err != nil {
+		logger.Errorf("failed to record policy change for %s: %v", acc.Username, err)
+	}
+}
@@ -31,6 +31,33 @@ var (
 
 	// ErrNotAuthorized 用户未授权创建账号
 	ErrNotAuthorized = errors.New("user not authorized to create accounts")
+
+	// ErrSyncDisabled Emby 同步未启用
+	ErrSyncDisabled = errors.New("emby sync disabled")
+
+	// ErrNotSynced 账号尚未同步到 Emby
+	ErrNotSynced = errors.New("account not synced to emby")
+
+	// ErrPolicyPush 策略推送到 Emby 失败，是否已保存本地设置见各方法说明
+	ErrPolicyPush = errors.New("update emby policy")
+
+	// ErrMaintenance 维护期间暂停创建与续期
+	ErrMaintenance = errors.New("service under maintenance")
+
+	// ErrSameOwner 接收方已是账号所有者
+	ErrSameOwner = errors.New("account already belongs to the user")
+
+	// ErrTransferNotFound 转让记录不存在
+	ErrTransferNotFound = errors.New("transfer not found")
+
+	// ErrTransferPending 账号已有等待接受的转让
+	ErrTransferPending = errors.New("transfer already pending")
+
+	// ErrTransferClosed 转让已被接受、拒绝、取消或已过期
+	ErrTransferClosed = errors.New("transfer no longer pending")
+
+	// ErrOwnerChanged 转移时账号所有者已不是发起方
+	ErrOwnerChanged = errors.New("account owner changed")
 )
 
 // NotFoundError 创建账号不存在错误
@@ -48,6 +75,11 @@ func ValidationError(field, reason string) error {
 	return fmt.Errorf("validation failed for %s: %s: %w", field, reason, ErrInvalidInput)
 }
 
+// InvalidFieldError 包装字段验证失败的原因
+func InvalidFieldError(field string, cause error) error {
+	return fmt.Errorf("validation failed for %s: %w: %w", field, cause, ErrInvalidInput)
+}
+
 // ExpiredError 创建账号过期错误
 func ExpiredError(username string) error {
 	return fmt.Errorf("account %q: %w", username, ErrExpired)
@@ -77,3 +109,13 @@ func NotAuthorizedError() error {
 func QuotaExceededError(current, quota int) error {
 	return fmt.Errorf("account quota exceeded (%d/%d): %w", current, quota, ErrAccountLimitExceeded)
 }
+
+// NotSyncedError 创建账号未同步错误
+func NotSyncedError(username string) error {
+	return fmt.Errorf("account %q: %w", username, ErrNotSynced)
+}
+
+// SameOwnerError 创建接收方已是所有者错误
+func SameOwnerError(username string) error {
+	return fmt.Errorf("account %q: %w", username, ErrSameOwner)
+}
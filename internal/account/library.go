@@ -0,0 +1,51 @@
+// Package account 媒体库访问权限
+package account
+
+import (
+	"context"
+	"fmt"
+)
+
+// LibraryAccess 账号当前生效的媒体库权限
+type LibraryAccess struct {
+	AllFolders bool     // 允许访问全部媒体库
+	FolderIDs  []string // AllFolders 为 false 时允许访问的媒体库 ID
+	Overridden bool     // 是否为账号单独设置(否则沿用模板)
+}
+
+// GetLibraryAccess 获取账号生效的媒体库权限
+func (s *Service) GetLibraryAccess(ctx context.Context, id uint) (*LibraryAccess, error) {
+	acc, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get account: %w", err)
+	}
+
+	return s.libraryAccessFor(ctx, acc), nil
+}
+
+// SetLibraryAccess 为账号单独设置可访问的媒体库并推送到 Emby
+// folderIDs 为空表示全部媒体库
+func (s *Service) SetLibraryAccess(ctx context.Context, id uint, folderIDs []string) error {
+	return s.updateSettings(ctx, id, "library access", func(acc *Account) {
+		acc.PolicyOverrides.SetFolders(folderIDs)
+	})
+}
+
+// ResetLibraryAccess 取消账号的媒体库设置，恢复沿用模板
+func (s *Service) ResetLibraryAccess(ctx context.Context, id uint) error {
+	return s.updateSettings(ctx, id, "library access reset", func(acc *Account) {
+		acc.PolicyOverrides.ClearFolders()
+	})
+}
+
+// libraryAccessFor 根据模板和账号覆盖计算生效的媒体库权限
+func (s *Service) libraryAccessFor(ctx context.Context, acc *Account) *LibraryAccess {
+	policy := s.policyFor(ctx, acc)
+	_, overridden := acc.PolicyOverrides.Folders()
+
+	return &LibraryAccess{
+		AllFolders: policy.EnableAllFolders,
+		FolderIDs:  policy.EnabledFolders,
+		Overridden: overridden,
+	}
+}
@@ -0,0 +1,160 @@
+// Package account 账号策略覆盖与变更记录
+package account
+
+import (
+	"time"
+
+	"emby-telegram/internal/emby"
+)
+
+// PolicyOverrides 账号级策略覆盖
+// 字段为 nil 表示沿用模板取值；设备数由 Account.MaxDevices 决定
+type PolicyOverrides struct {
+	MaxParentalRating  *int     `json:"max_parental_rating,omitempty"`
+	EnableTranscoding  *bool    `json:"enable_transcoding,omitempty"`
+	EnableDownloads    *bool    `json:"enable_downloads,omitempty"`
+	EnableRemoteAccess *bool    `json:"enable_remote_access,omitempty"`
+	BitrateLimit       *int     `json:"bitrate_limit,omitempty"`
+	EnableAllFolders   *bool    `json:"enable_all_folders,omitempty"`
+	EnabledFolders     []string `json:"enabled_folders,omitempty"` // EnableAllFolders 为 false 时生效
+}
+
+// IsEmpty 检查是否没有任何覆盖
+func (o *PolicyOverrides) IsEmpty() bool {
+	return o.MaxParentalRating == nil &&
+		o.EnableTranscoding == nil &&
+		o.EnableDownloads == nil &&
+		o.EnableRemoteAccess == nil &&
+		o.BitrateLimit == nil &&
+		o.EnableAllFolders == nil
+}
+
+// Apply 将覆盖应用到模板生成的策略上
+func (o *PolicyOverrides) Apply(p *emby.UserPolicy) {
+	if o.MaxParentalRating != nil {
+		p.MaxParentalRating = int32(*o.MaxParentalRating)
+	}
+	if o.EnableTranscoding != nil {
+		p.EnableAudioPlaybackTranscoding = *o.EnableTranscoding
+		p.EnableVideoPlaybackTranscoding = *o.EnableTranscoding
+		p.EnablePlaybackRemuxing = *o.EnableTranscoding
+	}
+	if o.EnableDownloads != nil {
+		p.EnableContentDownloading = *o.EnableDownloads
+		p.EnableSubtitleDownloading = *o.EnableDownloads
+	}
+	if o.EnableRemoteAccess != nil {
+		p.EnableRemoteAccess = *o.EnableRemoteAccess
+	}
+	if o.BitrateLimit != nil {
+		p.RemoteClientBitrateLimit = int32(*o.BitrateLimit)
+	}
+	if folders, ok := o.Folders(); ok {
+		p.SetFolders(folders)
+	}
+}
+
+// Folders 返回覆盖的媒体库权限，nil 表示全部媒体库
+// 第二个返回值为 false 表示未覆盖，沿用模板
+func (o *PolicyOverrides) Folders() ([]string, bool) {
+	if o.EnableAllFolders == nil {
+		return nil, false
+	}
+	if *o.EnableAllFolders {
+		return nil, true
+	}
+	return o.EnabledFolders, true
+}
+
+// SetFolders 覆盖媒体库权限，空列表表示全部媒体库
+func (o *PolicyOverrides) SetFolders(folderIDs []string) {
+	all := len(folderIDs) == 0
+	o.EnableAllFolders = &all
+	if all {
+		o.EnabledFolders = nil
+	} else {
+		o.EnabledFolders = append([]string(nil), folderIDs...)
+	}
+}
+
+// ClearFolders 取消媒体库覆盖，恢复沿用模板
+func (o *PolicyOverrides) ClearFolders() {
+	o.EnableAllFolders = nil
+	o.EnabledFolders = nil
+}
+
+// AcceptField 将实际策略中的某个字段接受为覆盖
+// 返回 false 表示该字段不支持覆盖
+func (a *Account) AcceptField(field string, actual *emby.UserPolicy) bool {
+	o := &a.PolicyOverrides
+	switch field {
+	case "MaxParentalRating":
+		v := int(actual.MaxParentalRating)
+		o.MaxParentalRating = &v
+	case "SimultaneousStreamLimit":
+		a.MaxDevices = int(actual.SimultaneousStreamLimit)
+	case "EnableAudioPlaybackTranscoding", "EnableVideoPlaybackTranscoding", "EnablePlaybackRemuxing":
+		v := actual.EnableVideoPlaybackTranscoding
+		o.EnableTranscoding = &v
+	case "EnableContentDownloading", "EnableSubtitleDownloading":
+		v := actual.EnableContentDownloading
+		o.EnableDownloads = &v
+	case "EnableRemoteAccess":
+		v := actual.EnableRemoteAccess
+		o.EnableRemoteAccess = &v
+	case "RemoteClientBitrateLimit":
+		v := int(actual.RemoteClientBitrateLimit)
+		o.BitrateLimit = &v
+	case "EnableAllFolders", "EnabledFolders":
+		if actual.EnableAllFolders {
+			o.SetFolders(nil)
+		} else {
+			o.SetFolders(actual.EnabledFolders)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// CanAcceptField 字段的偏差是否可以接受为覆盖，其余字段只能修正
+func CanAcceptField(field string) bool {
+	var a Account
+	return a.AcceptField(field, &emby.UserPolicy{})
+}
+
+// 策略变更动作
+const (
+	PolicyActionFix    = "fix"    // 将 Emby 上的策略修正为期望值
+	PolicyActionAccept = "accept" // 将 Emby 上的实际值接受为账号覆盖
+)
+
+// PolicyChange 策略变更记录，删除账号后仍然保留
+type PolicyChange struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	AccountID  uint      `gorm:"index;not null" json:"account_id"`
+	Username   string    `gorm:"column:account_username;size:100;not null" json:"account_username"` // 账号删除后仍可识别
+	Field      string    `gorm:"size:100;not null" json:"field"`
+	OldValue   string    `gorm:"type:text" json:"old_value"`
+	NewValue   string    `gorm:"type:text" json:"new_value"`
+	Action     string    `gorm:"size:20;not null" json:"action"`
+	OperatorID int64     `gorm:"not null" json:"operator_id"` // 操作者 Telegram ID
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (PolicyChange) TableName() string {
+	return "policy_changes"
+}
+
+// DriftReport 账号策略偏差报告
+type DriftReport struct {
+	Account *Account
+	Actual  *emby.UserPolicy // 检测时从 Emby 读取的实际策略
+	Diffs   []emby.PolicyDiff
+}
+
+// HasDrift 是否存在偏差
+func (r *DriftReport) HasDrift() bool {
+	return len(r.Diffs) > 0
+}
@@ -0,0 +1,47 @@
+// Package account 列表查询条件
+package account
+
+import "time"
+
+// ExpiringWindow "即将到期"筛选的时间范围
+const ExpiringWindow = 7 * 24 * time.Hour
+
+// Filter 账号列表筛选条件
+type Filter string
+
+const (
+	// FilterAll 不筛选
+	FilterAll Filter = ""
+	// FilterActive 激活且未到期
+	FilterActive Filter = "active"
+	// FilterSuspended 已暂停
+	FilterSuspended Filter = "suspended"
+	// FilterExpired 状态为过期或到期时间已过
+	FilterExpired Filter = "expired"
+	// FilterSyncFailed 同步到 Emby 失败
+	FilterSyncFailed Filter = "sync_failed"
+	// FilterExpiring 激活且在 ExpiringWindow 内到期
+	FilterExpiring Filter = "expiring"
+)
+
+// Sort 账号列表排序方式
+type Sort string
+
+const (
+	// SortCreated 按创建时间倒序
+	SortCreated Sort = ""
+	// SortExpiry 按到期时间升序，永久有效的排在最后
+	SortExpiry Sort = "expiry"
+	// SortUsername 按用户名升序
+	SortUsername Sort = "username"
+)
+
+// ListQuery 账号列表查询条件
+type ListQuery struct {
+	Filter    Filter
+	Sort      Sort
+	Search    string // 匹配账号用户名或所有者 username
+	CreatorID uint   // 大于 0 时只返回该用户代为创建的账号
+	Offset    int
+	Limit     int // 0 表示不分页
+}
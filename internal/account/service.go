@@ -23,6 +23,24 @@ type User struct {
 	ID           uint
 	IsAdmin      bool
 	AccountQuota int
+	Permissions  map[string]bool // 用户角色拥有的权限
+}
+
+// Can 检查用户是否拥有指定权限，管理员拥有全部权限
+func (u User) Can(permission string) bool {
+	return u.IsAdmin || u.Permissions[permission]
+}
+
+// PolicyProvider 策略模板查询接口
+type PolicyProvider interface {
+	// BuildPolicy 根据模板生成完整的 Emby 用户策略，templateID 为 0 时使用默认模板
+	BuildPolicy(ctx context.Context, templateID uint, maxDevices int) (*emby.UserPolicy, error)
+}
+
+// MaintenanceChecker 维护窗口查询接口
+type MaintenanceChecker interface {
+	// InMaintenance 检查当前是否处于维护窗口
+	InMaintenance(ctx context.Context) (bool, error)
 }
 
 // Service 账号业务服务
@@ -30,6 +48,8 @@ type Service struct {
 	store               Store
 	userGetter          UserGetter
 	embyClient          *emby.Client
+	policies            PolicyProvider
+	maintenance         MaintenanceChecker
 	usernamePrefix      string
 	defaultExpire       int
 	defaultDevices      int
@@ -42,11 +62,13 @@ type Service struct {
 }
 
 // NewService 创建账号服务实例
-func NewService(store Store, userGetter UserGetter, embyClient *emby.Client, usernamePrefix string, defaultExpire, defaultDevices, passwordLength, maxAccountsPerUser, maxAccountsPerAdmin int, enableSync, syncOnCreate, syncOnDelete bool) *Service {
+func NewService(store Store, userGetter UserGetter, embyClient *emby.Client, policies PolicyProvider, maintenance MaintenanceChecker, usernamePrefix string, defaultExpire, defaultDevices, passwordLength, maxAccountsPerUser, maxAccountsPerAdmin int, enableSync, syncOnCreate, syncOnDelete bool) *Service {
 	return &Service{
 		store:               store,
 		userGetter:          userGetter,
 		embyClient:          embyClient,
+		policies:            policies,
+		maintenance:         maintenance,
 		usernamePrefix:      usernamePrefix,
 		defaultExpire:       defaultExpire,
 		defaultDevices:      defaultDevices,
@@ -59,6 +81,21 @@ func NewService(store Store, userGetter UserGetter, embyClient *emby.Client, use
 	}
 }
 
+// checkMaintenance 维护期间拒绝创建与续期
+func (s *Service) checkMaintenance(ctx context.Context) error {
+	if s.maintenance == nil {
+		return nil
+	}
+	active, err := s.maintenance.InMaintenance(ctx)
+	if err != nil {
+		return fmt.Errorf("check maintenance: %w", err)
+	}
+	if active {
+		return ErrMaintenance
+	}
+	return nil
+}
+
 // checkQuota 检查用户配额
 func (s *Service) checkQuota(ctx context.Context, userID uint) error {
 	user, err := s.userGetter.Get(ctx, userID)
@@ -138,19 +175,55 @@ func (s *Service) syncToEmby(ctx context.Context, acc *Account, plainPassword st
 	// 等待 Emby 完成用户创建
 	time.Sleep(100 * time.Millisecond)
 
-	// 应用默认用户策略（包括 MaxParentalRating 等）
-	defaultPolicy := emby.CreateDefaultPolicy(acc.MaxDevices)
-	if err := s.embyClient.UpdateUserPolicy(ctx, embyUser.ID, defaultPolicy); err != nil {
-		logger.Warnf("failed to set default policy for %s: %v", acc.Username, err)
+	// 应用账号模板策略（包括 MaxParentalRating 等）
+	policy := s.policyFor(ctx, acc)
+	if err := s.embyClient.UpdateUserPolicy(ctx, embyUser.ID, policy); err != nil {
+		logger.Warnf("failed to set policy for %s: %v", acc.Username, err)
 		// 不返回错误，策略可以后续手动设置
 	} else {
-		logger.Infof("default policy set for %s: max_devices=%d", acc.Username, acc.MaxDevices)
+		logger.Infof("policy set for %s: template=%d, max_devices=%d", acc.Username, acc.PolicyTemplateID, acc.MaxDevices)
 	}
 
 	acc.MarkSynced(embyUser.ID)
 	return nil
 }
 
+// policyFor 生成账号应有的 Emby 策略：模板 + 账号覆盖
+// 未配置模板服务或模板读取失败时回退到 emby.CreateDefaultPolicy
+func (s *Service) policyFor(ctx context.Context, acc *Account) *emby.UserPolicy {
+	var policy *emby.UserPolicy
+	if s.policies != nil {
+		p, err := s.policies.BuildPolicy(ctx, acc.PolicyTemplateID, acc.MaxDevices)
+		if err != nil {
+			logger.Warnf("failed to build policy for %s, using default: %v", acc.Username, err)
+		} else {
+			policy = p
+		}
+	}
+	if policy == nil {
+		policy = emby.CreateDefaultPolicy(acc.MaxDevices)
+	}
+
+	acc.PolicyOverrides.Apply(policy)
+
+	// 已暂停的账号保持禁用
+	policy.IsDisabled = acc.IsSuspended()
+	return policy
+}
+
+// pushPolicy 将账号应有的策略(模板 + 覆盖)推送到 Emby
+// 所有对 Emby 策略的写入都经过这里，避免覆盖本地保存的设置
+func (s *Service) pushPolicy(ctx context.Context, acc *Account) error {
+	if !s.enableSync || s.embyClient == nil || acc.EmbyUserID == "" {
+		return nil
+	}
+
+	if err := s.embyClient.UpdateUserPolicy(ctx, acc.EmbyUserID, s.policyFor(ctx, acc)); err != nil {
+		return fmt.Errorf("%w: %w", ErrPolicyPush, err)
+	}
+	return nil
+}
+
 // deleteFromEmby 从 Emby 删除账号
 func (s *Service) deleteFromEmby(ctx context.Context, acc *Account) error {
 	if !s.enableSync || s.embyClient == nil || acc.EmbyUserID == "" {
@@ -190,7 +263,7 @@ func (s *Service) suspendInEmby(ctx context.Context, acc *Account) error {
 		return nil
 	}
 
-	if err := s.embyClient.DisableUser(ctx, acc.EmbyUserID); err != nil {
+	if err := s.pushPolicy(ctx, acc); err != nil {
 		acc.MarkSyncFailed(fmt.Errorf("suspend failed: %w", err))
 		logger.Errorf("failed to suspend emby user %s: %v", acc.Username, err)
 		return err
@@ -206,7 +279,7 @@ func (s *Service) activateInEmby(ctx context.Context, acc *Account) error {
 		return nil
 	}
 
-	if err := s.embyClient.EnableUser(ctx, acc.EmbyUserID); err != nil {
+	if err := s.pushPolicy(ctx, acc); err != nil {
 		acc.MarkSyncFailed(fmt.Errorf("activate failed: %w", err))
 		logger.Errorf("failed to activate emby user %s: %v", acc.Username, err)
 		return err
@@ -219,12 +292,17 @@ func (s *Service) activateInEmby(ctx context.Context, acc *Account) error {
 // Create 创建账号
 // 自动生成密码，返回明文密码和账号信息
 func (s *Service) Create(ctx context.Context, username string, userID uint) (*Account, string, error) {
+	// 维护期间暂停创建
+	if err := s.checkMaintenance(ctx); err != nil {
+		return nil, "", err
+	}
+
 	// 清理用户名
 	username = validator.SanitizeUsername(username)
 
 	// 验证用户名
 	if err := validator.ValidateUsername(username); err != nil {
-		return nil, "", ValidationError("username", err.Error())
+		return nil, "", InvalidFieldError("username", err)
 	}
 
 	// 检查是否已存在
@@ -242,6 +320,11 @@ func (s *Service) Create(ctx context.Context, username string, userID uint) (*Ac
 		return nil, "", err
 	}
 
+	return s.create(ctx, username, userID, 0)
+}
+
+// create 生成密码、保存账号并同步到 Emby
+func (s *Service) create(ctx context.Context, username string, userID, createdBy uint) (*Account, string, error) {
 	// 生成随机密码
 	plainPassword, err := crypto.GeneratePassword(s.passwordLength)
 	if err != nil {
@@ -262,6 +345,7 @@ func (s *Service) Create(ctx context.Context, username string, userID uint) (*Ac
 		Username:   username,
 		Password:   hashedPassword,
 		UserID:     userID,
+		CreatedBy:  createdBy,
 		Status:     StatusActive,
 		ExpireAt:   &expireAt,
 		MaxDevices: s.defaultDevices,
@@ -292,16 +376,21 @@ func (s *Service) Create(ctx context.Context, username string, userID uint) (*Ac
 
 // CreateWithPassword 创建账号(指定密码)
 func (s *Service) CreateWithPassword(ctx context.Context, username, password string, userID uint) (*Account, error) {
+	// 维护期间暂停创建
+	if err := s.checkMaintenance(ctx); err != nil {
+		return nil, err
+	}
+
 	// 清理用户名
 	username = validator.SanitizeUsername(username)
 
 	// 验证
 	if err := validator.ValidateUsername(username); err != nil {
-		return nil, ValidationError("username", err.Error())
+		return nil, InvalidFieldError("username", err)
 	}
 
 	if err := validator.ValidatePassword(password); err != nil {
-		return nil, ValidationError("password", err.Error())
+		return nil, InvalidFieldError("password", err)
 	}
 
 	// 检查是否已存在
@@ -361,6 +450,31 @@ func (s *Service) CreateWithPassword(ctx context.Context, username, password str
 	return acc, nil
 }
 
+// Import 导入其他实例导出的账号记录
+// 账号原样保存，不检查配额、不同步到 Emby；没有密码时生成随机密码，实际密码以 Emby 为准
+// 未填写设备数时使用默认设备数
+func (s *Service) Import(ctx context.Context, acc *Account) error {
+	if acc.MaxDevices == 0 {
+		acc.MaxDevices = s.defaultDevices
+	}
+	if acc.Password == "" {
+		plainPassword, err := crypto.GeneratePassword(s.passwordLength)
+		if err != nil {
+			return fmt.Errorf("generate password: %w", err)
+		}
+		hashedPassword, err := crypto.HashPassword(plainPassword)
+		if err != nil {
+			return fmt.Errorf("hash password: %w", err)
+		}
+		acc.Password = hashedPassword
+	}
+
+	if err := s.store.Create(ctx, acc); err != nil {
+		return fmt.Errorf("import account: %w", err)
+	}
+	return nil
+}
+
 // Get 获取账号
 func (s *Service) Get(ctx context.Context, id uint) (*Account, error) {
 	acc, err := s.store.Get(ctx, id)
@@ -374,7 +488,19 @@ func (s *Service) Get(ctx context.Context, id uint) (*Account, error) {
 func (s *Service) GetByUsername(ctx context.Context, username string) (*Account, error) {
 	acc, err := s.store.GetByUsername(ctx, username)
 	if err != nil {
-		return nil, NotFoundError(username)
+		if errors.Is(err, ErrNotFound) {
+			return nil, NotFoundError(username)
+		}
+		return nil, fmt.Errorf("get account by username: %w", err)
+	}
+	return acc, nil
+}
+
+// GetByEmbyUserID 根据 Emby 用户 ID 获取账号
+func (s *Service) GetByEmbyUserID(ctx context.Context, embyUserID string) (*Account, error) {
+	acc, err := s.store.GetByEmbyUserID(ctx, embyUserID)
+	if err != nil {
+		return nil, fmt.Errorf("get account by emby_user_id: %w", err)
 	}
 	return acc, nil
 }
@@ -406,6 +532,24 @@ func (s *Service) ListAllWithUser(ctx context.Context, offset, limit int) ([]*Ac
 	return accs, nil
 }
 
+// ListByQuery 按查询条件列出全部账号，同时返回符合条件的总数
+func (s *Service) ListByQuery(ctx context.Context, q ListQuery) ([]*AccountWithUser, int64, error) {
+	accs, total, err := s.store.ListByQuery(ctx, q)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list accounts: %w", err)
+	}
+	return accs, total, nil
+}
+
+// SearchByUsernamePrefix 按用户名前缀搜索账号
+func (s *Service) SearchByUsernamePrefix(ctx context.Context, prefix string, limit int) ([]*AccountWithUser, error) {
+	accs, err := s.store.SearchByUsernamePrefix(ctx, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("search accounts: %w", err)
+	}
+	return accs, nil
+}
+
 // GetWithUser 根据 ID 获取账号及用户信息
 func (s *Service) GetWithUser(ctx context.Context, id uint) (*AccountWithUser, error) {
 	acc, err := s.store.GetWithUser(ctx, id)
@@ -417,9 +561,14 @@ func (s *Service) GetWithUser(ctx context.Context, id uint) (*AccountWithUser, e
 
 // Renew 续期账号
 func (s *Service) Renew(ctx context.Context, id uint, days int) error {
+	// 维护期间暂停续期
+	if err := s.checkMaintenance(ctx); err != nil {
+		return err
+	}
+
 	// 验证天数
 	if err := validator.ValidateDays(days); err != nil {
-		return ValidationError("days", err.Error())
+		return InvalidFieldError("days", err)
 	}
 
 	acc, err := s.store.Get(ctx, id)
@@ -464,7 +613,7 @@ func (s *Service) Delete(ctx context.Context, id uint) error {
 func (s *Service) ChangePassword(ctx context.Context, id uint, newPassword string) error {
 	// 验证密码
 	if err := validator.ValidatePassword(newPassword); err != nil {
-		return ValidationError("password", err.Error())
+		return InvalidFieldError("password", err)
 	}
 
 	acc, err := s.store.Get(ctx, id)
@@ -567,6 +716,78 @@ func (s *Service) Activate(ctx context.Context, id uint) error {
 	return nil
 }
 
+// ApplyTemplate 为账号设置策略模板并推送到 Emby
+// 账号覆盖(评级、设备数、媒体库等)优先于模板；推送成功后才保存模板，
+// 推送失败时本地记录的模板保持不变，漂移检测仍以 Emby 上实际的策略为基准
+func (s *Service) ApplyTemplate(ctx context.Context, id uint, templateID uint) error {
+	acc, err := s.store.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get account: %w", err)
+	}
+
+	acc.PolicyTemplateID = templateID
+
+	if err := s.pushPolicy(ctx, acc); err != nil {
+		logger.Errorf("failed to apply policy template to %s: %v", acc.Username, err)
+		return err
+	}
+
+	if err := s.store.Update(ctx, acc); err != nil {
+		return fmt.Errorf("update account: %w", err)
+	}
+
+	logger.Infof("policy template %d applied to %s", templateID, acc.Username)
+	return nil
+}
+
+// ApplyTemplateBatch 为多个账号应用策略模板
+// 单个账号失败不影响其他账号，返回成功和失败的数量
+func (s *Service) ApplyTemplateBatch(ctx context.Context, ids []uint, templateID uint) (int, int) {
+	updated := 0
+	failed := 0
+
+	for _, id := range ids {
+		if err := s.ApplyTemplate(ctx, id, templateID); err != nil {
+			logger.Warnf("failed to apply policy template %d to account %d: %v", templateID, id, err)
+			failed++
+			continue
+		}
+		updated++
+	}
+
+	return updated, failed
+}
+
+// SetTemplateForLinked 为关联到指定 Emby 用户的账号记录策略模板，不推送策略
+// 用于模板已批量推送到 Emby 之后，只传入推送成功的用户，单个账号保存失败只记录日志
+func (s *Service) SetTemplateForLinked(ctx context.Context, templateID uint, embyUserIDs []string) error {
+	if len(embyUserIDs) == 0 {
+		return nil
+	}
+
+	pushed := make(map[string]bool, len(embyUserIDs))
+	for _, id := range embyUserIDs {
+		pushed[id] = true
+	}
+
+	accs, err := s.store.ListAll(ctx, 0, 0)
+	if err != nil {
+		return fmt.Errorf("list all accounts: %w", err)
+	}
+
+	for _, acc := range accs {
+		if !pushed[acc.EmbyUserID] || acc.PolicyTemplateID == templateID {
+			continue
+		}
+		acc.PolicyTemplateID = templateID
+		if err := s.store.Update(ctx, acc); err != nil {
+			logger.Errorf("failed to record policy template %d on %s: %v", templateID, acc.Username, err)
+		}
+	}
+
+	return nil
+}
+
 // CheckOwnership 检查账号所有权
 func (s *Service) CheckOwnership(ctx context.Context, accountID, userID uint) error {
 	acc, err := s.store.Get(ctx, accountID)
@@ -0,0 +1,37 @@
+// Package account 播放会话
+package account
+
+import (
+	"context"
+	"fmt"
+
+	"emby-telegram/internal/emby"
+)
+
+// Sessions 获取账号在 Emby 上的活动会话，每个会话对应一台设备
+func (s *Service) Sessions(ctx context.Context, id uint) ([]emby.SessionInfo, error) {
+	if !s.enableSync || s.embyClient == nil {
+		return nil, ErrSyncDisabled
+	}
+
+	acc, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get account: %w", err)
+	}
+	if acc.EmbyUserID == "" {
+		return nil, NotSyncedError(acc.Username)
+	}
+
+	sessions, err := s.embyClient.GetSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get emby sessions: %w", err)
+	}
+
+	var result []emby.SessionInfo
+	for _, session := range sessions {
+		if session.UserID == acc.EmbyUserID {
+			result = append(result, session)
+		}
+	}
+	return result, nil
+}
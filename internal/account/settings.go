@@ -0,0 +1,120 @@
+// Package account 账号策略设置
+package account
+
+import (
+	"context"
+	"fmt"
+
+	"emby-telegram/internal/emby"
+	"emby-telegram/internal/logger"
+)
+
+// EffectivePolicy 返回账号当前生效的 Emby 策略(模板 + 覆盖)
+// 只在本地计算，不访问 Emby
+func (s *Service) EffectivePolicy(ctx context.Context, acc *Account) *emby.UserPolicy {
+	return s.policyFor(ctx, acc)
+}
+
+// SetParentalRating 设置账号的家长控制评级
+func (s *Service) SetParentalRating(ctx context.Context, id uint, rating int) error {
+	if rating < 0 {
+		return ValidationError("rating", "must be non-negative")
+	}
+
+	return s.updateSettings(ctx, id, "parental rating", func(acc *Account) {
+		acc.PolicyOverrides.MaxParentalRating = &rating
+	})
+}
+
+// SetMaxDevices 设置账号的最大设备数(同时播放数)
+func (s *Service) SetMaxDevices(ctx context.Context, id uint, devices int) error {
+	if devices < 0 {
+		return ValidationError("max_devices", "must be non-negative")
+	}
+
+	return s.updateSettings(ctx, id, "max devices", func(acc *Account) {
+		acc.MaxDevices = devices
+	})
+}
+
+// SetBitrateLimit 设置账号的远程码率上限(bps)，0 表示不限制
+func (s *Service) SetBitrateLimit(ctx context.Context, id uint, bps int) error {
+	if bps < 0 {
+		return ValidationError("bitrate_limit", "must be non-negative")
+	}
+
+	return s.updateSettings(ctx, id, "bitrate limit", func(acc *Account) {
+		acc.PolicyOverrides.BitrateLimit = &bps
+	})
+}
+
+// SetDownloads 设置账号是否允许下载
+func (s *Service) SetDownloads(ctx context.Context, id uint, enabled bool) error {
+	return s.updateSettings(ctx, id, "downloads", func(acc *Account) {
+		acc.PolicyOverrides.EnableDownloads = &enabled
+	})
+}
+
+// ResetOverrides 清除账号的全部策略覆盖，恢复沿用模板
+// 设备数属于账号本身的属性，不会被清除
+func (s *Service) ResetOverrides(ctx context.Context, id uint) error {
+	return s.updateSettings(ctx, id, "overrides reset", func(acc *Account) {
+		acc.PolicyOverrides = PolicyOverrides{}
+	})
+}
+
+// LinkEmbyUser 将账号关联到已存在的 Emby 用户并推送账号策略
+func (s *Service) LinkEmbyUser(ctx context.Context, id uint, embyUserID string) error {
+	return s.updateSettings(ctx, id, "emby user linked", func(acc *Account) {
+		acc.MarkSynced(embyUserID)
+	})
+}
+
+// PolicyAdjuster 返回批量更新 Emby 策略时使用的调整函数
+// 对关联了本地账号的 Emby 用户应用账号覆盖、设备数和停用状态
+func (s *Service) PolicyAdjuster(ctx context.Context) (func(userID string, p *emby.UserPolicy), error) {
+	accs, err := s.store.ListAll(ctx, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("list all accounts: %w", err)
+	}
+
+	byEmbyID := make(map[string]*Account)
+	for _, acc := range accs {
+		if acc.EmbyUserID != "" {
+			byEmbyID[acc.EmbyUserID] = acc
+		}
+	}
+
+	return func(userID string, p *emby.UserPolicy) {
+		acc, ok := byEmbyID[userID]
+		if !ok {
+			return
+		}
+		p.SimultaneousStreamLimit = int32(acc.MaxDevices)
+		acc.PolicyOverrides.Apply(p)
+		p.IsDisabled = acc.IsSuspended()
+	}, nil
+}
+
+// updateSettings 修改账号设置，保存后将合并后的策略推送到 Emby
+// 推送失败时设置已保存，返回的错误包装 ErrPolicyPush
+func (s *Service) updateSettings(ctx context.Context, id uint, what string, fn func(acc *Account)) error {
+	acc, err := s.store.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get account: %w", err)
+	}
+
+	fn(acc)
+
+	if err := s.store.Update(ctx, acc); err != nil {
+		return fmt.Errorf("update account: %w", err)
+	}
+
+	if err := s.pushPolicy(ctx, acc); err != nil {
+		logger.Errorf("failed to push policy for %s (%s): %v", acc.Username, what, err)
+		return err
+	}
+
+	logger.Infof("account %s updated: %s", acc.Username, what)
+	return nil
+}
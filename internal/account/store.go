@@ -15,6 +15,9 @@ type Store interface {
 	// GetByUsername 根据用户名获取账号
 	GetByUsername(ctx context.Context, username string) (*Account, error)
 
+	// GetByEmbyUserID 根据 Emby 用户 ID 获取账号
+	GetByEmbyUserID(ctx context.Context, embyUserID string) (*Account, error)
+
 	// List 列出指定用户的所有账号
 	List(ctx context.Context, userID uint) ([]*Account, error)
 
@@ -24,10 +27,22 @@ type Store interface {
 	// ListAllWithUser 列出所有账号及关联用户信息(分页)
 	ListAllWithUser(ctx context.Context, offset, limit int) ([]*AccountWithUser, error)
 
+	// ListByCreatorWithUser 列出指定用户代为创建的账号及关联用户信息(分页)
+	ListByCreatorWithUser(ctx context.Context, creatorID uint, offset, limit int) ([]*AccountWithUser, error)
+
+	// ListByQuery 按查询条件列出账号及关联用户信息，同时返回符合条件的总数
+	ListByQuery(ctx context.Context, q ListQuery) ([]*AccountWithUser, int64, error)
+
+	// CountByCreator 统计指定用户代为创建的账号数量
+	CountByCreator(ctx context.Context, creatorID uint) (int64, error)
+
+	// SearchByUsernamePrefix 按用户名前缀搜索账号及关联用户信息
+	SearchByUsernamePrefix(ctx context.Context, prefix string, limit int) ([]*AccountWithUser, error)
+
 	// GetWithUser 根据 ID 获取账号及用户信息
 	GetWithUser(ctx context.Context, id uint) (*AccountWithUser, error)
 
-	// Update 更新账号
+	// Update 更新账号，不修改所有者，所有者只能通过转让变更
 	Update(ctx context.Context, acc *Account) error
 
 	// Delete 删除账号
@@ -41,4 +56,33 @@ type Store interface {
 
 	// CountByStatus 统计指定状态的账号数量
 	CountByStatus(ctx context.Context, status Status) (int64, error)
+
+	// CreatePolicyChange 记录策略变更
+	CreatePolicyChange(ctx context.Context, change *PolicyChange) error
+
+	// ListPolicyChanges 列出账号最近的策略变更记录
+	ListPolicyChanges(ctx context.Context, accountID uint, limit int) ([]*PolicyChange, error)
+
+	// CreateTransfer 创建转让记录
+	CreateTransfer(ctx context.Context, t *Transfer) error
+
+	// GetTransfer 根据 ID 获取转让记录
+	GetTransfer(ctx context.Context, id uint) (*Transfer, error)
+
+	// GetPendingTransfer 获取账号等待接受的转让，没有时返回 ErrTransferNotFound
+	GetPendingTransfer(ctx context.Context, accountID uint) (*Transfer, error)
+
+	// ClaimTransfer 以等待状态为条件更新转让状态，返回是否更新成功
+	ClaimTransfer(ctx context.Context, id uint, status TransferStatus) (bool, error)
+
+	// TransferAccount 在同一事务中转移账号并写入已完成的转让记录
+	// 账号所有者已不是 t.FromUserID 时返回 ErrOwnerChanged
+	TransferAccount(ctx context.Context, t *Transfer) error
+
+	// CompleteTransfer 在同一事务中将等待中的转让标记为完成并转移账号
+	// 转让已不在等待状态时返回 ErrTransferClosed，账号所有者已变化时返回 ErrOwnerChanged
+	CompleteTransfer(ctx context.Context, t *Transfer) error
+
+	// ListTransfers 列出账号最近的转让记录
+	ListTransfers(ctx context.Context, accountID uint, limit int) ([]*Transfer, error)
 }
@@ -0,0 +1,245 @@
+// Package account 账号转让
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"emby-telegram/internal/logger"
+)
+
+// TransferOfferTTL 用户发起的转让等待接受的时间，过期后需要重新发起
+const TransferOfferTTL = 24 * time.Hour
+
+// TransferStatus 转让状态
+type TransferStatus string
+
+const (
+	// TransferPending 等待接收方接受
+	TransferPending TransferStatus = "pending"
+	// TransferCompleted 已转移到接收方名下
+	TransferCompleted TransferStatus = "completed"
+	// TransferDeclined 接收方拒绝
+	TransferDeclined TransferStatus = "declined"
+	// TransferCancelled 发起方取消、已过期，或账号所有者已经变化
+	TransferCancelled TransferStatus = "cancelled"
+)
+
+// Transfer 账号转让记录
+// 管理员直接转移的记录创建时即为已完成，用户发起的转让需要接收方接受
+type Transfer struct {
+	ID         uint           `gorm:"primarykey" json:"id"`
+	AccountID  uint           `gorm:"index;not null" json:"account_id"`
+	FromUserID uint           `gorm:"not null" json:"from_user_id"`
+	ToUserID   uint           `gorm:"index;not null" json:"to_user_id"`
+	Status     TransferStatus `gorm:"size:20;not null" json:"status"`
+	OperatorID int64          `gorm:"not null" json:"operator_id"` // 发起者 Telegram ID
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Transfer) TableName() string {
+	return "account_transfers"
+}
+
+// IsExpired 检查等待中的转让是否已过期
+func (t *Transfer) IsExpired(now time.Time) bool {
+	return t.Status == TransferPending && now.Sub(t.CreatedAt) >= TransferOfferTTL
+}
+
+// Transfer 将账号直接转移给另一个用户
+// 接收方需要有剩余配额且未达到账号数量上限；Emby 用户保持不变，观看记录随账号保留
+func (s *Service) Transfer(ctx context.Context, id, toUserID uint, operatorID int64) (*Transfer, error) {
+	acc, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get account: %w", err)
+	}
+
+	if acc.UserID == toUserID {
+		return nil, SameOwnerError(acc.Username)
+	}
+	if err := s.checkRecipient(ctx, toUserID); err != nil {
+		return nil, err
+	}
+
+	t := &Transfer{
+		AccountID:  acc.ID,
+		FromUserID: acc.UserID,
+		ToUserID:   toUserID,
+		Status:     TransferCompleted,
+		OperatorID: operatorID,
+	}
+	// 账号转移和转让记录在同一事务中写入，不会出现没有记录的转移
+	if err := s.store.TransferAccount(ctx, t); err != nil {
+		return nil, fmt.Errorf("transfer account: %w", err)
+	}
+
+	logger.Infof("account transferred: account=%s, from=%d, to=%d", acc.Username, t.FromUserID, toUserID)
+	return t, nil
+}
+
+// OfferTransfer 账号所有者发起转让，接收方接受后才转移
+// 发起时先检查接收方配额，同一账号同时只能有一个等待接受的转让
+func (s *Service) OfferTransfer(ctx context.Context, id, fromUserID, toUserID uint, operatorID int64) (*Transfer, error) {
+	acc, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get account: %w", err)
+	}
+	if acc.UserID != fromUserID {
+		return nil, UnauthorizedError("offer transfer")
+	}
+	if acc.UserID == toUserID {
+		return nil, SameOwnerError(acc.Username)
+	}
+	if err := s.checkRecipient(ctx, toUserID); err != nil {
+		return nil, err
+	}
+
+	pending, err := s.store.GetPendingTransfer(ctx, acc.ID)
+	switch {
+	case err == nil && pending.IsExpired(time.Now()):
+		s.closeTransfer(ctx, pending, TransferCancelled)
+	case err == nil:
+		return nil, ErrTransferPending
+	case !errors.Is(err, ErrTransferNotFound):
+		return nil, fmt.Errorf("get pending transfer: %w", err)
+	}
+
+	t := &Transfer{
+		AccountID:  acc.ID,
+		FromUserID: fromUserID,
+		ToUserID:   toUserID,
+		Status:     TransferPending,
+		OperatorID: operatorID,
+	}
+	if err := s.store.CreateTransfer(ctx, t); err != nil {
+		return nil, fmt.Errorf("create transfer: %w", err)
+	}
+
+	logger.Infof("transfer offered: account=%s, from=%d, to=%d", acc.Username, fromUserID, toUserID)
+	return t, nil
+}
+
+// AcceptTransfer 接收方接受转让
+// 接收方配额不足时转让保持等待状态，调整配额后可以再次接受
+func (s *Service) AcceptTransfer(ctx context.Context, transferID, userID uint) (*Transfer, error) {
+	t, err := s.pendingTransfer(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if t.ToUserID != userID {
+		return nil, UnauthorizedError("accept transfer")
+	}
+
+	acc, err := s.store.Get(ctx, t.AccountID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			s.closeTransfer(ctx, t, TransferCancelled)
+			return nil, ErrTransferClosed
+		}
+		return nil, fmt.Errorf("get account: %w", err)
+	}
+	// 发起后账号已被转移给其他人
+	if acc.UserID != t.FromUserID {
+		s.closeTransfer(ctx, t, TransferCancelled)
+		return nil, ErrTransferClosed
+	}
+
+	if err := s.checkRecipient(ctx, t.ToUserID); err != nil {
+		return nil, err
+	}
+
+	// 先以等待状态为条件认领转让再转移账号，与同时发生的取消或拒绝只会有一个成功
+	err = s.store.CompleteTransfer(ctx, t)
+	switch {
+	case errors.Is(err, ErrOwnerChanged):
+		s.closeTransfer(ctx, t, TransferCancelled)
+		return nil, ErrTransferClosed
+	case err != nil:
+		return nil, fmt.Errorf("complete transfer: %w", err)
+	}
+
+	t.Status = TransferCompleted
+	logger.Infof("account transferred: account=%s, from=%d, to=%d", acc.Username, t.FromUserID, t.ToUserID)
+	return t, nil
+}
+
+// RejectTransfer 拒绝或取消等待中的转让，接收方为拒绝，发起方为取消
+func (s *Service) RejectTransfer(ctx context.Context, transferID, userID uint) (*Transfer, error) {
+	t, err := s.pendingTransfer(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	var status TransferStatus
+	switch userID {
+	case t.ToUserID:
+		status = TransferDeclined
+	case t.FromUserID:
+		status = TransferCancelled
+	default:
+		return nil, UnauthorizedError("reject transfer")
+	}
+
+	claimed, err := s.store.ClaimTransfer(ctx, t.ID, status)
+	if err != nil {
+		return nil, fmt.Errorf("claim transfer: %w", err)
+	}
+	if !claimed {
+		return nil, ErrTransferClosed
+	}
+	t.Status = status
+	return t, nil
+}
+
+// GetTransfer 获取转让记录
+func (s *Service) GetTransfer(ctx context.Context, id uint) (*Transfer, error) {
+	t, err := s.store.GetTransfer(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get transfer: %w", err)
+	}
+	return t, nil
+}
+
+// ListTransfers 列出账号最近的转让记录
+func (s *Service) ListTransfers(ctx context.Context, id uint, limit int) ([]*Transfer, error) {
+	transfers, err := s.store.ListTransfers(ctx, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+// pendingTransfer 获取等待中的转让，已过期的转让会被关闭
+func (s *Service) pendingTransfer(ctx context.Context, id uint) (*Transfer, error) {
+	t, err := s.store.GetTransfer(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get transfer: %w", err)
+	}
+	if t.Status != TransferPending {
+		return nil, ErrTransferClosed
+	}
+	if t.IsExpired(time.Now()) {
+		s.closeTransfer(ctx, t, TransferCancelled)
+		return nil, ErrTransferClosed
+	}
+	return t, nil
+}
+
+// closeTransfer 关闭等待中的转让，已不在等待状态时不做修改，失败只记录日志
+func (s *Service) closeTransfer(ctx context.Context, t *Transfer, status TransferStatus) {
+	if _, err := s.store.ClaimTransfer(ctx, t.ID, status); err != nil {
+		logger.Errorf("failed to close transfer %d: %v", t.ID, err)
+	}
+}
+
+// checkRecipient 检查接收方的配额和账号数量限制
+func (s *Service) checkRecipient(ctx context.Context, userID uint) error {
+	if err := s.checkQuota(ctx, userID); err != nil {
+		return err
+	}
+	return s.checkAccountLimit(ctx, userID)
+}
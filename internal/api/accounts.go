@@ -0,0 +1,214 @@
+// Package api 账号接口
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/logger"
+)
+
+// accountView 账号及所有者信息
+type accountView struct {
+	*account.Account
+	Owner ownerView `json:"owner"`
+}
+
+// ownerView 账号所有者
+type ownerView struct {
+	TelegramID int64  `json:"telegram_id"`
+	Username   string `json:"username"`
+	FirstName  string `json:"first_name"`
+}
+
+// newAccountView 转换带所有者信息的账号
+func newAccountView(acc *account.AccountWithUser) accountView {
+	return accountView{
+		Account: &acc.Account,
+		Owner: ownerView{
+			TelegramID: acc.OwnerTelegramID,
+			Username:   acc.OwnerUsername,
+			FirstName:  acc.OwnerFirstName,
+		},
+	}
+}
+
+// listAccounts 分页列出所有账号
+func (s *Server) listAccounts(r *http.Request, c *caller) (any, error) {
+	p, err := parsePage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	accs, err := s.accounts.ListAllWithUser(r.Context(), p.Offset, p.Limit)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.accounts.Count(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]accountView, 0, len(accs))
+	for _, acc := range accs {
+		views = append(views, newAccountView(acc))
+	}
+	return newList(views, p, total), nil
+}
+
+// getAccount 获取账号详情
+func (s *Server) getAccount(r *http.Request, c *caller) (any, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	return s.accountView(r, id)
+}
+
+// createAccount 为指定用户创建账号，不占用该用户的配额
+func (s *Server) createAccount(r *http.Request, c *caller) (any, error) {
+	var req struct {
+		TelegramID int64  `json:"telegram_id"`
+		Username   string `json:"username"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+
+	owner, err := s.users.GetByTelegramID(r.Context(), req.TelegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	acc, password, err := s.accounts.CreateFor(r.Context(), c.operator.ID, owner.ID, req.Username)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("api: token %d created account %s for user %d", c.token.ID, acc.Username, owner.TelegramID)
+
+	view, err := s.accountView(r, acc.ID)
+	if err != nil {
+		return nil, err
+	}
+	return created{struct {
+		Account  accountView `json:"account"`
+		Password string      `json:"password"`
+	}{view, password}}, nil
+}
+
+// deleteAccount 删除账号
+func (s *Server) deleteAccount(r *http.Request, c *caller) (any, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.accounts.DeleteAs(r.Context(), c.operator.ID, id); err != nil {
+		return nil, err
+	}
+	logger.Infof("api: token %d deleted account %d", c.token.ID, id)
+	return nil, nil
+}
+
+// renewAccount 续期账号
+func (s *Server) renewAccount(r *http.Request, c *caller) (any, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	var req struct {
+		Days int `json:"days"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+
+	if err := s.accounts.RenewAs(r.Context(), c.operator.ID, id, req.Days); err != nil {
+		return nil, err
+	}
+	logger.Infof("api: token %d renewed account %d by %d days", c.token.ID, id, req.Days)
+	return s.accountView(r, id)
+}
+
+// suspendAccount 停用账号
+func (s *Server) suspendAccount(r *http.Request, c *caller) (any, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.accounts.SuspendAs(r.Context(), c.operator.ID, id); err != nil {
+		return nil, err
+	}
+	logger.Infof("api: token %d suspended account %d", c.token.ID, id)
+	return s.accountView(r, id)
+}
+
+// activateAccount 激活账号
+func (s *Server) activateAccount(r *http.Request, c *caller) (any, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.accounts.ActivateAs(r.Context(), c.operator.ID, id); err != nil {
+		return nil, err
+	}
+	logger.Infof("api: token %d activated account %d", c.token.ID, id)
+	return s.accountView(r, id)
+}
+
+// changePassword 修改账号密码
+func (s *Server) changePassword(r *http.Request, c *caller) (any, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	var req struct {
+		Password string `json:"password"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+
+	if err := s.accounts.ChangePassword(r.Context(), id, req.Password); err != nil {
+		return nil, err
+	}
+	logger.Infof("api: token %d changed password of account %d", c.token.ID, id)
+	return nil, nil
+}
+
+// sessionView Emby 播放会话
+type sessionView struct {
+	ID            string    `json:"id"`
+	UserName      string    `json:"user_name"`
+	DeviceName    string    `json:"device_name"`
+	Client        string    `json:"client"`
+	Version       string    `json:"version"`
+	RemoteAddress string    `json:"remote_address"`
+	LastActivity  time.Time `json:"last_activity"`
+	NowPlaying    string    `json:"now_playing,omitempty"`
+}
+
+// accountSessions 列出账号在 Emby 上的活动会话
+func (s *Server) accountSessions(r *http.Request, c *caller) (any, error) {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.accounts.Sessions(r.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	return newSessionViews(sessions), nil
+}
+
+// accountView 读取账号的最新信息
+func (s *Server) accountView(r *http.Request, id uint) (accountView, error) {
+	acc, err := s.accounts.GetWithUser(r.Context(), id)
+	if err != nil {
+		return accountView{}, err
+	}
+	return newAccountView(acc), nil
+}
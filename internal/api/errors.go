@@ -0,0 +1,143 @@
+// Package api 错误响应
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/apitoken"
+	"emby-telegram/internal/invitecode"
+	"emby-telegram/internal/user"
+	"emby-telegram/pkg/validator"
+)
+
+// apiError 接口层产生的错误
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+var (
+	errUnauthenticated   = &apiError{http.StatusUnauthorized, "unauthenticated", "missing or invalid bearer token"}
+	errTokenOwnerRevoked = &apiError{http.StatusForbidden, "token_owner_revoked", "the admin who created this token no longer has access"}
+	errRouteNotFound     = &apiError{http.StatusNotFound, "not_found", "no such endpoint"}
+	errEmbyUnavailable   = &apiError{http.StatusServiceUnavailable, "emby_unavailable", "emby server is not connected"}
+)
+
+// insufficientScopeError 令牌缺少所需的权限范围
+func insufficientScopeError(scope apitoken.Scope) error {
+	return &apiError{http.StatusForbidden, "insufficient_scope", fmt.Sprintf("token lacks scope %s", scope)}
+}
+
+// invalidParamError 路径或查询参数无效
+func invalidParamError(name string) error {
+	return &apiError{http.StatusBadRequest, "invalid_parameter", fmt.Sprintf("invalid parameter %s", name)}
+}
+
+// invalidBodyError 请求体无法解析
+func invalidBodyError(cause error) error {
+	var maxBytes *http.MaxBytesError
+	if errors.As(cause, &maxBytes) {
+		return &apiError{http.StatusRequestEntityTooLarge, "body_too_large", fmt.Sprintf("request body exceeds %d bytes", maxBytes.Limit)}
+	}
+	return &apiError{http.StatusBadRequest, "invalid_body", fmt.Sprintf("invalid request body: %v", cause)}
+}
+
+// errorMapping 领域错误到 HTTP 状态码和错误代码的映射
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// errorMappings 按顺序匹配，第一个匹配的生效
+var errorMappings = []errorMapping{
+	// 账号
+	{account.ErrNotFound, http.StatusNotFound, "account_not_found"},
+	{account.ErrAlreadyExists, http.StatusConflict, "account_exists"},
+	{account.ErrInvalidInput, http.StatusBadRequest, "invalid_input"},
+	{account.ErrExpired, http.StatusConflict, "account_expired"},
+	{account.ErrSuspended, http.StatusConflict, "account_suspended"},
+	{account.ErrUnauthorized, http.StatusForbidden, "forbidden"},
+	{account.ErrAccountLimitExceeded, http.StatusConflict, "account_limit_exceeded"},
+	{account.ErrNotAuthorized, http.StatusForbidden, "not_authorized"},
+	{account.ErrSyncDisabled, http.StatusServiceUnavailable, "emby_unavailable"},
+	{account.ErrNotSynced, http.StatusConflict, "account_not_synced"},
+	{account.ErrMaintenance, http.StatusServiceUnavailable, "maintenance"},
+
+	// 用户
+	{user.ErrNotFound, http.StatusNotFound, "user_not_found"},
+	{user.ErrAlreadyExists, http.StatusConflict, "user_exists"},
+	{user.ErrBlocked, http.StatusForbidden, "user_blocked"},
+	{user.ErrUnauthorized, http.StatusForbidden, "forbidden"},
+	{user.ErrInvalidRole, http.StatusBadRequest, "invalid_role"},
+	{user.ErrConfigAdmin, http.StatusConflict, "config_admin"},
+
+	// 邀请码
+	{invitecode.ErrNotFound, http.StatusNotFound, "invite_code_not_found"},
+	{invitecode.ErrAlreadyExists, http.StatusConflict, "invite_code_exists"},
+	{invitecode.ErrInvalidCode, http.StatusBadRequest, "invalid_invite_code"},
+	{invitecode.ErrCodeExpired, http.StatusConflict, "invite_code_expired"},
+	{invitecode.ErrCodeExhausted, http.StatusConflict, "invite_code_exhausted"},
+	{invitecode.ErrCodeRevoked, http.StatusConflict, "invite_code_revoked"},
+	{invitecode.ErrInvalidMaxUses, http.StatusBadRequest, "invalid_input"},
+}
+
+// statusOf 返回错误对应的 HTTP 状态码
+func statusOf(err error) int {
+	status, _ := classify(err)
+	return status
+}
+
+// classify 返回错误对应的 HTTP 状态码和错误代码
+func classify(err error) (int, string) {
+	var aerr *apiError
+	if errors.As(err, &aerr) {
+		return aerr.status, aerr.code
+	}
+
+	var verr *validator.Error
+	if errors.As(err, &verr) {
+		return http.StatusBadRequest, "invalid_input"
+	}
+
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return m.status, m.code
+		}
+	}
+	return http.StatusInternalServerError, "internal"
+}
+
+// errorBody 错误响应
+type errorBody struct {
+	Error errorDetail `json:"error"`
+}
+
+// errorDetail 错误代码和说明，说明中不包含内部错误细节
+type errorDetail struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// writeError 按错误类型输出错误响应
+func writeError(w http.ResponseWriter, err error) {
+	status, code := classify(err)
+
+	message := err.Error()
+	if status == http.StatusInternalServerError {
+		message = "internal server error"
+	}
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	}
+
+	writeJSON(w, status, errorBody{Error: errorDetail{Code: code, Message: message}})
+}
@@ -0,0 +1,64 @@
+// Package api 邀请码接口
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"emby-telegram/internal/logger"
+)
+
+// listInviteCodes 分页列出邀请码
+func (s *Server) listInviteCodes(r *http.Request, c *caller) (any, error) {
+	p, err := parsePage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := s.inviteCodes.List(r.Context(), p.Offset, p.Limit)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.inviteCodes.Count(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return newList(codes, p, total), nil
+}
+
+// getInviteCode 获取邀请码及使用记录
+func (s *Server) getInviteCode(r *http.Request, c *caller) (any, error) {
+	return s.inviteCodes.GetWithUsage(r.Context(), strings.ToUpper(r.PathValue("code")))
+}
+
+// createInviteCode 生成邀请码，max_uses 为 -1 表示不限次数，expire_days 为 0 表示永久有效
+func (s *Server) createInviteCode(r *http.Request, c *caller) (any, error) {
+	req := struct {
+		MaxUses     int    `json:"max_uses"`
+		ExpireDays  int    `json:"expire_days"`
+		Description string `json:"description"`
+	}{MaxUses: 1}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if req.ExpireDays < 0 {
+		return nil, invalidParamError("expire_days")
+	}
+
+	code, err := s.inviteCodes.Generate(r.Context(), req.MaxUses, req.ExpireDays, req.Description, c.operator.TelegramID)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("api: token %d created invite code %s", c.token.ID, code.Code)
+	return created{code}, nil
+}
+
+// revokeInviteCode 吊销邀请码
+func (s *Server) revokeInviteCode(r *http.Request, c *caller) (any, error) {
+	code := strings.ToUpper(r.PathValue("code"))
+	if err := s.inviteCodes.Revoke(r.Context(), code); err != nil {
+		return nil, err
+	}
+	logger.Infof("api: token %d revoked invite code %s", c.token.ID, code)
+	return nil, nil
+}
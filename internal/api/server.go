@@ -0,0 +1,268 @@
+// Package api 管理 HTTP JSON API
+// 供运维脚本在 Telegram 之外管理用户、账号和邀请码，使用 Bearer API 令牌认证，
+// 每个令牌只能访问其权限范围内的接口
+package api
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/apitoken"
+	"emby-telegram/internal/emby"
+	"emby-telegram/internal/invitecode"
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/user"
+)
+
+// BasePath API 的路径前缀，版本号变化时新增前缀而不是修改已有接口
+const BasePath = "/api/v1/"
+
+// maxBodySize 请求体大小上限
+const maxBodySize = 64 << 10
+
+//go:embed openapi.yaml
+var openAPIDocument []byte
+
+// Server 管理 API 服务
+type Server struct {
+	accounts    *account.Service
+	users       *user.Service
+	inviteCodes *invitecode.Service
+	tokens      *apitoken.Service
+	embyClient  *emby.Client
+}
+
+// NewServer 创建管理 API 服务，embyClient 为 nil 时会话接口不可用
+func NewServer(accounts *account.Service, users *user.Service, inviteCodes *invitecode.Service, tokens *apitoken.Service, embyClient *emby.Client) *Server {
+	return &Server{
+		accounts:    accounts,
+		users:       users,
+		inviteCodes: inviteCodes,
+		tokens:      tokens,
+		embyClient:  embyClient,
+	}
+}
+
+// Handler 返回挂载在 BasePath 下的路由
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET "+BasePath+"openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+		_, _ = w.Write(openAPIDocument)
+	})
+
+	s.route(mux, "GET", "users", apitoken.ScopeUsersRead, s.listUsers)
+	s.route(mux, "GET", "users/{telegram_id}", apitoken.ScopeUsersRead, s.getUser)
+	s.route(mux, "POST", "users/{telegram_id}/block", apitoken.ScopeUsersWrite, s.blockUser)
+	s.route(mux, "POST", "users/{telegram_id}/unblock", apitoken.ScopeUsersWrite, s.unblockUser)
+	s.route(mux, "PUT", "users/{telegram_id}/quota", apitoken.ScopeUsersWrite, s.setUserQuota)
+
+	s.route(mux, "GET", "accounts", apitoken.ScopeAccountsRead, s.listAccounts)
+	s.route(mux, "POST", "accounts", apitoken.ScopeAccountsWrite, s.createAccount)
+	s.route(mux, "GET", "accounts/{id}", apitoken.ScopeAccountsRead, s.getAccount)
+	s.route(mux, "DELETE", "accounts/{id}", apitoken.ScopeAccountsWrite, s.deleteAccount)
+	s.route(mux, "POST", "accounts/{id}/renew", apitoken.ScopeAccountsWrite, s.renewAccount)
+	s.route(mux, "POST", "accounts/{id}/suspend", apitoken.ScopeAccountsWrite, s.suspendAccount)
+	s.route(mux, "POST", "accounts/{id}/activate", apitoken.ScopeAccountsWrite, s.activateAccount)
+	s.route(mux, "PUT", "accounts/{id}/password", apitoken.ScopeAccountsWrite, s.changePassword)
+	s.route(mux, "GET", "accounts/{id}/sessions", apitoken.ScopeSessionsRead, s.accountSessions)
+
+	s.route(mux, "GET", "invitecodes", apitoken.ScopeInviteCodesRead, s.listInviteCodes)
+	s.route(mux, "POST", "invitecodes", apitoken.ScopeInviteCodesWrite, s.createInviteCode)
+	s.route(mux, "GET", "invitecodes/{code}", apitoken.ScopeInviteCodesRead, s.getInviteCode)
+	s.route(mux, "DELETE", "invitecodes/{code}", apitoken.ScopeInviteCodesWrite, s.revokeInviteCode)
+
+	s.route(mux, "GET", "stats", apitoken.ScopeStatsRead, s.stats)
+	s.route(mux, "GET", "sessions", apitoken.ScopeSessionsRead, s.listSessions)
+
+	// 未匹配的路径统一返回 JSON 错误
+	mux.HandleFunc(BasePath, func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, errRouteNotFound)
+	})
+
+	return mux
+}
+
+// caller 发起请求的令牌和创建令牌的管理员
+type caller struct {
+	token    *apitoken.Token
+	operator *user.User
+}
+
+// handlerFunc 接口处理函数，返回值序列化为 JSON，返回 nil 时响应 204
+type handlerFunc func(r *http.Request, c *caller) (any, error)
+
+// created 新建资源的响应，状态码为 201
+type created struct {
+	value any
+}
+
+// route 注册接口，请求需携带拥有 scope 权限范围的令牌
+func (s *Server) route(mux *http.ServeMux, method, path string, scope apitoken.Scope, fn handlerFunc) {
+	mux.HandleFunc(method+" "+BasePath+path, func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+		c, err := s.authenticate(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if !c.token.HasScope(scope) {
+			writeError(w, insufficientScopeError(scope))
+			return
+		}
+
+		result, err := fn(r, c)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				logRequestError(r, c, err)
+			}
+			writeError(w, err)
+			return
+		}
+
+		switch v := result.(type) {
+		case nil:
+			w.WriteHeader(http.StatusNoContent)
+		case created:
+			writeJSON(w, http.StatusCreated, v.value)
+		default:
+			writeJSON(w, http.StatusOK, v)
+		}
+	})
+}
+
+// authenticate 校验 Bearer 令牌
+// 令牌以创建者的身份执行操作，创建者不再是管理员或被封禁后令牌随之失效
+func (s *Server) authenticate(r *http.Request) (*caller, error) {
+	raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || raw == "" {
+		return nil, errUnauthenticated
+	}
+
+	token, err := s.tokens.Authenticate(r.Context(), strings.TrimSpace(raw))
+	if err != nil {
+		if errors.Is(err, apitoken.ErrInvalidToken) {
+			logger.Warnf("api request rejected from %s: invalid token", r.RemoteAddr)
+			return nil, errUnauthenticated
+		}
+		return nil, err
+	}
+
+	operator, err := s.users.GetByTelegramID(r.Context(), token.CreatedBy)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return nil, errTokenOwnerRevoked
+		}
+		return nil, err
+	}
+	if !operator.IsAdmin() || !operator.CanAccess() {
+		logger.Warnf("api token %d rejected: creator %d is no longer an admin", token.ID, token.CreatedBy)
+		return nil, errTokenOwnerRevoked
+	}
+
+	return &caller{token: token, operator: operator}, nil
+}
+
+// logRequestError 记录处理失败的请求，客户端错误只记录调试日志
+func logRequestError(r *http.Request, c *caller, err error) {
+	if statusOf(err) >= http.StatusInternalServerError {
+		logger.Errorf("api %s %s (token %d) failed: %v", r.Method, r.URL.Path, c.token.ID, err)
+		return
+	}
+	logger.Debugf("api %s %s (token %d): %v", r.Method, r.URL.Path, c.token.ID, err)
+}
+
+// page 分页参数
+type page struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// 分页参数默认值与上限
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// parsePage 读取 offset 和 limit 查询参数
+func parsePage(r *http.Request) (page, error) {
+	p := page{Limit: defaultLimit}
+
+	if v := r.URL.Query().Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return p, invalidParamError("offset")
+		}
+		p.Offset = offset
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxLimit {
+			return p, invalidParamError("limit")
+		}
+		p.Limit = limit
+	}
+	return p, nil
+}
+
+// list 分页列表响应
+type list[T any] struct {
+	Items  []T   `json:"items"`
+	Offset int   `json:"offset"`
+	Limit  int   `json:"limit"`
+	Total  int64 `json:"total"`
+}
+
+// newList 创建分页列表响应，空列表序列化为 []
+func newList[T any](items []T, p page, total int64) *list[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return &list[T]{Items: items, Offset: p.Offset, Limit: p.Limit, Total: total}
+}
+
+// decodeBody 解析 JSON 请求体，拒绝未知字段
+func decodeBody(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return invalidBodyError(err)
+	}
+	return nil
+}
+
+// pathID 读取路径中的数字 ID
+func pathID(r *http.Request, name string) (uint, error) {
+	id, err := strconv.ParseUint(r.PathValue(name), 10, 64)
+	if err != nil || id == 0 {
+		return 0, invalidParamError(name)
+	}
+	return uint(id), nil
+}
+
+// pathTelegramID 读取路径中的 Telegram ID
+func pathTelegramID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("telegram_id"), 10, 64)
+	if err != nil {
+		return 0, invalidParamError("telegram_id")
+	}
+	return id, nil
+}
+
+// writeJSON 输出 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Warnf("failed to write api response: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+// Package api 统计与会话接口
+package api
+
+import (
+	"net/http"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/emby"
+	"emby-telegram/internal/user"
+)
+
+// statsView 用户与账号统计
+type statsView struct {
+	Users struct {
+		Total  int64 `json:"total"`
+		Admins int64 `json:"admins"`
+		Users  int64 `json:"users"`
+	} `json:"users"`
+	Accounts struct {
+		Total     int64 `json:"total"`
+		Active    int64 `json:"active"`
+		Suspended int64 `json:"suspended"`
+		Expired   int64 `json:"expired"`
+	} `json:"accounts"`
+}
+
+// stats 用户与账号统计，与 /stats 命令一致
+func (s *Server) stats(r *http.Request, c *caller) (any, error) {
+	ctx := r.Context()
+	var v statsView
+	var err error
+
+	if v.Users.Total, err = s.users.Count(ctx); err != nil {
+		return nil, err
+	}
+	if v.Users.Admins, err = s.users.CountByRole(ctx, user.RoleAdmin); err != nil {
+		return nil, err
+	}
+	if v.Users.Users, err = s.users.CountByRole(ctx, user.RoleUser); err != nil {
+		return nil, err
+	}
+
+	if v.Accounts.Total, err = s.accounts.Count(ctx); err != nil {
+		return nil, err
+	}
+	if v.Accounts.Active, err = s.accounts.CountByStatus(ctx, account.StatusActive); err != nil {
+		return nil, err
+	}
+	if v.Accounts.Suspended, err = s.accounts.CountByStatus(ctx, account.StatusSuspended); err != nil {
+		return nil, err
+	}
+	if v.Accounts.Expired, err = s.accounts.CountByStatus(ctx, account.StatusExpired); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// listSessions 列出 Emby 上的所有活动会话
+func (s *Server) listSessions(r *http.Request, c *caller) (any, error) {
+	if s.embyClient == nil {
+		return nil, errEmbyUnavailable
+	}
+
+	sessions, err := s.embyClient.GetSessions(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return newSessionViews(sessions), nil
+}
+
+// newSessionViews 转换 Emby 播放会话
+func newSessionViews(sessions []emby.SessionInfo) []sessionView {
+	views := make([]sessionView, 0, len(sessions))
+	for _, session := range sessions {
+		v := sessionView{
+			ID:            session.ID,
+			UserName:      session.UserName,
+			DeviceName:    session.DeviceName,
+			Client:        session.Client,
+			Version:       session.ApplicationVersion,
+			RemoteAddress: session.RemoteEndPoint,
+			LastActivity:  session.LastActivityDate,
+		}
+		if item := session.NowPlayingItem; item != nil {
+			v.NowPlaying = item.Name
+			if item.SeriesName != "" {
+				v.NowPlaying = item.SeriesName + " - " + item.Name
+			}
+		}
+		views = append(views, v)
+	}
+	return views
+}
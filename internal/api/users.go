@@ -0,0 +1,95 @@
+// Package api 用户接口
+package api
+
+import (
+	"net/http"
+
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/user"
+)
+
+// listUsers 分页列出用户
+func (s *Server) listUsers(r *http.Request, c *caller) (any, error) {
+	p, err := parsePage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.users.List(r.Context(), p.Offset, p.Limit)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.users.Count(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	return newList(users, p, total), nil
+}
+
+// getUser 根据 Telegram ID 获取用户
+func (s *Server) getUser(r *http.Request, c *caller) (any, error) {
+	telegramID, err := pathTelegramID(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.userByTelegramID(r, telegramID)
+}
+
+// blockUser 封禁用户
+func (s *Server) blockUser(r *http.Request, c *caller) (any, error) {
+	telegramID, err := pathTelegramID(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.Block(r.Context(), telegramID); err != nil {
+		return nil, err
+	}
+	logger.Infof("api: token %d blocked user %d", c.token.ID, telegramID)
+	return s.userByTelegramID(r, telegramID)
+}
+
+// unblockUser 解封用户
+func (s *Server) unblockUser(r *http.Request, c *caller) (any, error) {
+	telegramID, err := pathTelegramID(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.Unblock(r.Context(), telegramID); err != nil {
+		return nil, err
+	}
+	logger.Infof("api: token %d unblocked user %d", c.token.ID, telegramID)
+	return s.userByTelegramID(r, telegramID)
+}
+
+// setUserQuota 设置用户账号配额
+func (s *Server) setUserQuota(r *http.Request, c *caller) (any, error) {
+	telegramID, err := pathTelegramID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req struct {
+		Quota *int `json:"quota"`
+	}
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if req.Quota == nil || *req.Quota < 0 {
+		return nil, invalidParamError("quota")
+	}
+
+	u, err := s.users.GetByTelegramID(r.Context(), telegramID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.SetQuota(r.Context(), u.ID, *req.Quota); err != nil {
+		return nil, err
+	}
+	logger.Infof("api: token %d set quota of user %d to %d", c.token.ID, telegramID, *req.Quota)
+	return s.userByTelegramID(r, telegramID)
+}
+
+// userByTelegramID 读取用户的最新信息
+func (s *Server) userByTelegramID(r *http.Request, telegramID int64) (*user.User, error) {
+	return s.users.GetByTelegramID(r.Context(), telegramID)
+}
@@ -0,0 +1,83 @@
+// Package apitoken HTTP API 访问令牌
+package apitoken
+
+import (
+	"strings"
+	"time"
+)
+
+// Scope 令牌的权限范围
+type Scope string
+
+// 权限范围定义
+const (
+	ScopeUsersRead        Scope = "users:read"
+	ScopeUsersWrite       Scope = "users:write"
+	ScopeAccountsRead     Scope = "accounts:read"
+	ScopeAccountsWrite    Scope = "accounts:write"
+	ScopeInviteCodesRead  Scope = "invitecodes:read"
+	ScopeInviteCodesWrite Scope = "invitecodes:write"
+	ScopeStatsRead        Scope = "stats:read"
+	ScopeSessionsRead     Scope = "sessions:read"
+)
+
+// AllScopes 全部权限范围
+var AllScopes = []Scope{
+	ScopeUsersRead,
+	ScopeUsersWrite,
+	ScopeAccountsRead,
+	ScopeAccountsWrite,
+	ScopeInviteCodesRead,
+	ScopeInviteCodesWrite,
+	ScopeStatsRead,
+	ScopeSessionsRead,
+}
+
+// IsValid 检查权限范围是否有效
+func (s Scope) IsValid() bool {
+	for _, scope := range AllScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Token API 访问令牌，只保存令牌的哈希值
+type Token struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	Name       string     `gorm:"size:64;not null" json:"name"`
+	Prefix     string     `gorm:"size:16;not null" json:"prefix"`        // 令牌开头几位，用于识别令牌
+	Hash       string     `gorm:"size:64;uniqueIndex;not null" json:"-"` // 令牌的 SHA-256 哈希
+	Scopes     string     `gorm:"size:255;not null" json:"scopes"`       // 逗号分隔的权限范围
+	CreatedBy  int64      `gorm:"not null" json:"created_by"`            // 创建令牌的管理员 Telegram ID
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Token) TableName() string {
+	return "api_tokens"
+}
+
+// ScopeList 返回令牌的权限范围
+func (t *Token) ScopeList() []Scope {
+	var scopes []Scope
+	for _, s := range strings.Split(t.Scopes, ",") {
+		if s != "" {
+			scopes = append(scopes, Scope(s))
+		}
+	}
+	return scopes
+}
+
+// HasScope 检查令牌是否拥有指定权限范围
+func (t *Token) HasScope(scope Scope) bool {
+	for _, s := range t.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+// Package apitoken 领域错误定义
+package apitoken
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 领域错误定义
+var (
+	// ErrNotFound 令牌不存在
+	ErrNotFound = errors.New("api token not found")
+
+	// ErrInvalidToken 令牌无效或已被吊销
+	ErrInvalidToken = errors.New("invalid api token")
+
+	// ErrInvalidScope 无效的权限范围
+	ErrInvalidScope = errors.New("invalid scope")
+
+	// ErrInvalidName 令牌名称为空或过长
+	ErrInvalidName = errors.New("token name must be 1-64 characters")
+)
+
+// InvalidScopeError 包装无效的权限范围
+func InvalidScopeError(scope string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+}
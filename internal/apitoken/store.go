@@ -0,0 +1,25 @@
+// Package apitoken 存储接口定义
+package apitoken
+
+import (
+	"context"
+	"time"
+)
+
+// Store API 令牌存储接口
+type Store interface {
+	// Create 创建令牌
+	Create(ctx context.Context, t *Token) error
+
+	// List 按创建时间列出所有令牌
+	List(ctx context.Context) ([]*Token, error)
+
+	// GetByHash 根据哈希获取令牌，不存在时返回 ErrNotFound
+	GetByHash(ctx context.Context, hash string) (*Token, error)
+
+	// Delete 删除令牌，不存在时返回 ErrNotFound
+	Delete(ctx context.Context, id uint) error
+
+	// TouchLastUsed 更新令牌的最近使用时间
+	TouchLastUsed(ctx context.Context, id uint, at time.Time) error
+}
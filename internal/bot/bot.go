@@ -4,57 +4,101 @@ package bot
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"emby-telegram/internal/account"
+	"emby-telegram/internal/apitoken"
+	"emby-telegram/internal/dataio"
 	"emby-telegram/internal/emby"
+	"emby-telegram/internal/i18n"
 	"emby-telegram/internal/invitecode"
 	"emby-telegram/internal/logger"
+	"emby-telegram/internal/policy"
+	"emby-telegram/internal/schedule"
 	"emby-telegram/internal/user"
+	"emby-telegram/pkg/ratelimit"
 )
 
+// shutdownGracePeriod 关闭超时取消上下文后等待处理函数退出的时间
+const shutdownGracePeriod = 5 * time.Second
+
 // Bot Telegram Bot 实例
 type Bot struct {
 	api               *tgbotapi.BotAPI
 	accountService    *account.Service
 	userService       *user.Service
 	inviteCodeService *invitecode.Service
+	policyService     *policy.Service
+	scheduleService   *schedule.Service
+	apiTokenService   *apitoken.Service
+	dataService       *dataio.Service
 	embyClient        *emby.Client
-	adminIDs          map[int64]bool
-	handlers          map[string]CommandHandler
+	catalog           *i18n.Catalog
+	commands          map[string]*commandSpec
+	callbacks         map[string]*callbackSpec
+	wizards           map[string]*Wizard
+	limiter           *ratelimit.Keyed
 	stateMachine      *StateMachine
+	receiveConfig     ReceiveConfig
+	webhookServer     *http.Server
+	dispatcher        *dispatcher
+	sender            *sender
+	broadcasts        jobRegistry
+	bulkJobs          jobRegistry
+	webAppURL         string // Mini App 直达链接，为空表示未启用
+
+	// 处理函数使用独立于接收循环的上下文，关闭时先排空再取消
+	handlerCtx     context.Context
+	cancelHandlers context.CancelFunc
+	jobs           sync.WaitGroup // 后台任务(如群组消息自动删除)
+	stopping       chan struct{}  // 开始关闭时关闭
+	stopOnce       sync.Once
 }
 
 // CommandHandler 命令处理函数类型
 type CommandHandler func(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error)
 
 // New 创建 Bot 实例
-func New(token string, adminIDs []int64, accountSvc *account.Service, userSvc *user.Service, inviteCodeSvc *invitecode.Service, embyClient *emby.Client) (*Bot, error) {
+func New(token string, accountSvc *account.Service, userSvc *user.Service, inviteCodeSvc *invitecode.Service, policySvc *policy.Service, scheduleSvc *schedule.Service, apiTokenSvc *apitoken.Service, dataSvc *dataio.Service, embyClient *emby.Client, catalog *i18n.Catalog, stateMachine *StateMachine, receiveCfg ReceiveConfig) (*Bot, error) {
 	api, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
 		return nil, fmt.Errorf("create bot api: %w", err)
 	}
 
-	admins := make(map[int64]bool, len(adminIDs))
-	for _, id := range adminIDs {
-		admins[id] = true
-	}
-
 	b := &Bot{
 		api:               api,
 		accountService:    accountSvc,
 		userService:       userSvc,
 		inviteCodeService: inviteCodeSvc,
+		policyService:     policySvc,
+		scheduleService:   scheduleSvc,
+		apiTokenService:   apiTokenSvc,
+		dataService:       dataSvc,
 		embyClient:        embyClient,
-		adminIDs:          admins,
-		handlers:          make(map[string]CommandHandler),
-		stateMachine:      NewStateMachine(),
+		catalog:           catalog,
+		commands:          make(map[string]*commandSpec),
+		callbacks:         make(map[string]*callbackSpec),
+		wizards:           make(map[string]*Wizard),
+		limiter:           ratelimit.NewKeyed(float64(receiveCfg.RateLimit)/60, receiveCfg.RateBurst),
+		stateMachine:      stateMachine,
+		receiveConfig:     receiveCfg,
+	}
+	b.sender = newSender(api, receiveCfg.SendRate, receiveCfg.ChatSendRate, receiveCfg.GroupSendPerMinute, receiveCfg.SendRetries)
+	b.dispatcher = newDispatcher(receiveCfg.Workers, receiveCfg.QueueSize, b.dispatch)
+	b.handlerCtx, b.cancelHandlers = context.WithCancel(context.Background())
+	b.stopping = make(chan struct{})
+	if receiveCfg.WebAppShortName != "" {
+		b.webAppURL = fmt.Sprintf("https://t.me/%s/%s", api.Self.UserName, receiveCfg.WebAppShortName)
 	}
 
 	b.registerHandlers()
+	b.registerCallbacks()
+	b.registerWizards()
 
 	if err := b.setupBotCommands(); err != nil {
 		logger.Warnf("failed to setup bot commands: %v", err)
@@ -64,14 +108,27 @@ func New(token string, adminIDs []int64, accountSvc *account.Service, userSvc *u
 	return b, nil
 }
 
+// Username 返回 Bot 的用户名(不含 @)
+func (b *Bot) Username() string {
+	return b.api.Self.UserName
+}
+
 // Start 启动 Bot
+// 长轮询和 Webhook 两种模式的更新都提交给同一个分发器，按用户顺序处理
 func (b *Bot) Start(ctx context.Context) error {
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
+	updates, err := b.receiveUpdates(ctx)
+	if err != nil {
+		return fmt.Errorf("start receiving updates: %w", err)
+	}
 
-	updates := b.api.GetUpdatesChan(u)
+	// 接收循环退出后关闭队列，工作协程处理完已排队的更新后退出
+	b.dispatcher.start(b.handlerCtx)
+	defer b.dispatcher.close()
 
-	logger.Info("bot listening for messages")
+	b.runJob(b.runScheduler)
+
+	stats := b.dispatcher.stats()
+	logger.Infof("bot listening for messages (workers: %d, queue size: %d)", stats.Workers, stats.QueueSize)
 
 	for {
 		select {
@@ -79,25 +136,77 @@ func (b *Bot) Start(ctx context.Context) error {
 			logger.Info("bot received stop signal")
 			return ctx.Err()
 		case update := <-updates:
-			// 处理回调查询（按钮点击）
-			if update.CallbackQuery != nil {
-				go b.handleCallbackQuery(ctx, update.CallbackQuery)
-				continue
-			}
-
-			// 处理消息
-			if update.Message != nil {
-				go b.handleUpdate(ctx, update.Message)
-			}
+			b.dispatcher.submit(ctx, update)
 		}
 	}
 }
 
-// Stop 停止 Bot
-func (b *Bot) Stop() {
-	b.api.StopReceivingUpdates()
-	b.stateMachine.Stop()
-	logger.Info("bot stopped receiving updates")
+// dispatch 处理单个更新，由分发器的工作协程调用
+func (b *Bot) dispatch(ctx context.Context, update tgbotapi.Update) {
+	// 处理回调查询（按钮点击）
+	if update.CallbackQuery != nil {
+		b.handleCallbackQuery(ctx, update.CallbackQuery)
+		return
+	}
+
+	// 处理内联查询
+	if update.InlineQuery != nil {
+		b.handleInlineQuery(ctx, update.InlineQuery)
+		return
+	}
+
+	// 处理消息
+	if update.Message != nil {
+		b.handleUpdate(ctx, update.Message)
+	}
+}
+
+// Stop 优雅停止 Bot
+// 调用前应先取消传给 Start 的 ctx。停止接收新更新后等待正在处理的更新和后台任务完成，
+// ctx 到期仍未完成时取消处理函数的上下文并返回错误
+func (b *Bot) Stop(ctx context.Context) error {
+	b.stopOnce.Do(func() { close(b.stopping) })
+	defer b.stateMachine.Stop()
+	defer b.cancelHandlers()
+
+	b.stopReceiving()
+	logger.Info("bot stopped receiving updates, draining in-flight handlers")
+
+	drained := make(chan struct{})
+	go func() {
+		b.dispatcher.wait()
+		b.jobs.Wait()
+		b.sender.wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		logger.Info("all in-flight handlers finished")
+		return nil
+	case <-ctx.Done():
+	}
+
+	// 超时：取消处理函数的上下文，再给一个短暂的退出时间
+	b.cancelHandlers()
+	logger.Warn("shutdown deadline exceeded, canceling in-flight handlers")
+
+	select {
+	case <-drained:
+	case <-time.After(shutdownGracePeriod):
+		logger.Warn("some handlers did not exit after cancellation")
+	}
+
+	return fmt.Errorf("shutdown: %w", ctx.Err())
+}
+
+// runJob 在后台运行任务，Stop 会等待任务结束
+func (b *Bot) runJob(fn func()) {
+	b.jobs.Add(1)
+	go func() {
+		defer b.jobs.Done()
+		fn()
+	}()
 }
 
 // handleUpdate 处理消息更新
@@ -105,20 +214,15 @@ func (b *Bot) handleUpdate(ctx context.Context, msg *tgbotapi.Message) {
 	currentUser, err := b.userService.GetOrCreate(ctx, msg.From)
 	if err != nil {
 		logger.Errorf("failed to get or create user: %v", err)
-		if isGroupChat(msg) {
-			b.replyWithAutoDelete(msg.Chat.ID, "系统错误，请稍后再试", msg.MessageID)
-		} else {
-			b.reply(msg.Chat.ID, "系统错误，请稍后再试")
-		}
+		b.respond(msg, b.catalog.T(b.catalog.Match(msg.From.LanguageCode), "common.system_error"))
 		return
 	}
 
+	ctx = b.localize(ctx, currentUser, msg.From)
+	b.clearBotBlocked(ctx, currentUser)
+
 	if !currentUser.CanAccess() {
-		if isGroupChat(msg) {
-			b.replyWithAutoDelete(msg.Chat.ID, "您已被封禁，无法使用此 Bot", msg.MessageID)
-		} else {
-			b.reply(msg.Chat.ID, "您已被封禁，无法使用此 Bot")
-		}
+		b.respond(msg, b.t(ctx, "common.blocked"))
 		return
 	}
 
@@ -136,9 +240,9 @@ func (b *Bot) handleUpdate(ctx context.Context, msg *tgbotapi.Message) {
 	}
 
 	// 检查用户是否处于对话状态
-	state, stateData := b.stateMachine.GetState(currentUser.TelegramID)
+	state, payload := b.stateMachine.GetState(ctx, currentUser.TelegramID)
 	if state != StateIdle {
-		b.handleStateInput(ctx, msg, currentUser, state, stateData)
+		b.handleStateInput(ctx, msg, currentUser, state, payload)
 		return
 	}
 
@@ -147,7 +251,7 @@ func (b *Bot) handleUpdate(ctx context.Context, msg *tgbotapi.Message) {
 		return
 	}
 
-	b.reply(msg.Chat.ID, "请点击下方按钮进行操作，或使用 /start 查看主菜单")
+	b.reply(msg.Chat.ID, b.t(ctx, "common.use_buttons"))
 }
 
 // handleCommand 处理命令
@@ -155,52 +259,45 @@ func (b *Bot) handleCommand(ctx context.Context, msg *tgbotapi.Message, currentU
 	cmd := msg.Command()
 	args := parseArgs(msg.CommandArguments())
 
-	logger.Infof("user executed command: %s, command: %s", currentUser.DisplayName(), cmd)
-
-	handler, ok := b.handlers[cmd]
+	spec, ok := b.commands[cmd]
 	if !ok {
-		if isGroupChat(msg) {
-			b.replyWithAutoDelete(msg.Chat.ID, "未知命令，使用 /help 查看帮助", msg.MessageID)
-		} else {
-			b.reply(msg.Chat.ID, "未知命令，使用 /help 查看帮助")
-		}
-		return
-	}
-
-	if isGroupChat(msg) && !groupAllowedCommands[cmd] {
-		b.replyWithAutoDelete(msg.Chat.ID, "❌ 此命令仅支持私聊使用\n\n请点击 Bot 头像进入私聊", msg.MessageID)
+		b.respond(msg, b.t(ctx, "common.unknown_command"))
 		return
 	}
 
-	reply, err := handler(ctx, msg, args)
+	reply, err := spec.run(withCurrentUser(ctx, currentUser), msg, args)
 	if err != nil {
 		logger.Errorf("command execution failed: %s, error: %v", cmd, err)
-		errMsg := fmt.Sprintf("❌ 错误: %v", err)
-		if isGroupChat(msg) {
-			b.replyWithAutoDelete(msg.Chat.ID, errMsg, msg.MessageID)
-		} else {
-			b.reply(msg.Chat.ID, errMsg)
-		}
+		b.respond(msg, b.t(ctx, "common.error", b.errorText(ctx, err)))
 		return
 	}
 
 	if reply != "" {
-		if isGroupChat(msg) {
-			b.replyWithAutoDelete(msg.Chat.ID, reply, msg.MessageID)
-		} else {
-			b.reply(msg.Chat.ID, reply)
-		}
+		b.respond(msg, reply)
+	}
+}
+
+// respond 回复命令消息，群组中的回复会自动删除
+func (b *Bot) respond(msg *tgbotapi.Message, text string) {
+	if isGroupChat(msg) {
+		b.replyWithAutoDelete(msg.Chat.ID, text, msg.MessageID)
+	} else {
+		b.reply(msg.Chat.ID, text)
 	}
 }
 
 // reply 回复消息
+// 消息进入发送队列后立即返回，发送失败时由队列记录日志
 func (b *Bot) reply(chatID int64, text string) {
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "HTML"
 
-	if _, err := b.api.Send(msg); err != nil {
-		logger.Errorf("failed to send message: %v", err)
-	}
+	b.sender.post(b.handlerCtx, chatID, msg)
+}
+
+// send 通过发送队列发送消息并等待结果
+func (b *Bot) send(ctx context.Context, chatID int64, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return b.sender.send(ctx, chatID, c)
 }
 
 // replyWithAutoDelete 回复消息并在群组中自动删除
@@ -208,46 +305,40 @@ func (b *Bot) replyWithAutoDelete(chatID int64, text string, userMsgID int) {
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "HTML"
 
-	if chatID < 0 {
-		msg.ReplyMarkup = RemoveReplyKeyboard()
-	}
-
-	sentMsg, err := b.api.Send(msg)
-	if err != nil {
-		logger.Errorf("failed to send message: %v", err)
+	if chatID >= 0 {
+		b.sender.post(b.handlerCtx, chatID, msg)
 		return
 	}
 
-	if chatID < 0 {
-		go func() {
-			time.Sleep(30 * time.Second)
+	msg.ReplyMarkup = RemoveReplyKeyboard()
 
-			deleteMsg := tgbotapi.NewDeleteMessage(chatID, sentMsg.MessageID)
-			if _, err := b.api.Request(deleteMsg); err != nil {
-				logger.Debugf("failed to delete bot message: %v", err)
-			}
+	// 在后台等待发送结果，拿到消息 ID 后再安排删除
+	result := b.sender.enqueue(b.handlerCtx, chatID, msg)
+	b.runJob(func() {
+		sent := <-result
+		if sent.Err != nil {
+			logger.Errorf("failed to send message: %v", sent.Err)
+			return
+		}
 
-			if userMsgID != 0 {
-				deleteUserMsg := tgbotapi.NewDeleteMessage(chatID, userMsgID)
-				if _, err := b.api.Request(deleteUserMsg); err != nil {
-					logger.Debugf("failed to delete user message: %v", err)
-				}
-			}
-		}()
-	}
-}
+		// 关闭时立即删除，不再等待
+		select {
+		case <-time.After(30 * time.Second):
+		case <-b.stopping:
+		}
 
-// isAdmin 检查用户是否为管理员
-func (b *Bot) isAdmin(telegramID int64) bool {
-	return b.adminIDs[telegramID]
-}
+		deleteMsg := tgbotapi.NewDeleteMessage(chatID, sent.Message.MessageID)
+		if _, err := b.api.Request(deleteMsg); err != nil {
+			logger.Debugf("failed to delete bot message: %v", err)
+		}
 
-// requireAdmin 检查是否为管理员，如果不是则返回错误
-func (b *Bot) requireAdmin(telegramID int64) error {
-	if !b.isAdmin(telegramID) {
-		return user.UnauthorizedError("此命令需要管理员权限")
-	}
-	return nil
+		if userMsgID != 0 {
+			deleteUserMsg := tgbotapi.NewDeleteMessage(chatID, userMsgID)
+			if _, err := b.api.Request(deleteUserMsg); err != nil {
+				logger.Debugf("failed to delete user message: %v", err)
+			}
+		}
+	})
 }
 
 // isPrivateChat 检查是否为私聊
@@ -293,34 +384,27 @@ func (b *Bot) isCommandForBot(msg *tgbotapi.Message) bool {
 // handleReplyKeyboardButton 处理 Reply Keyboard 按钮点击
 // 返回 true 表示已处理，false 表示不是按钮文本
 func (b *Bot) handleReplyKeyboardButton(ctx context.Context, msg *tgbotapi.Message, currentUser *user.User) bool {
-	var callbackData string
-
-	switch msg.Text {
-	case "📋 我的账号":
-		callbackData = CallbackMyAccounts + ":1"
-	case "➕ 创建账号":
-		callbackData = CallbackCreateAccount
-	case "❓ 帮助":
-		callbackData = CallbackHelp
-	case "🔑 管理员菜单":
-		if !currentUser.IsAdmin() {
-			if isGroupChat(msg) {
-				b.replyWithAutoDelete(msg.Chat.ID, "❌ 您没有管理员权限", msg.MessageID)
-			} else {
-				b.reply(msg.Chat.ID, "❌ 您没有管理员权限")
-			}
-			return true
-		}
-		callbackData = CallbackAdminMenu
-	default:
+	key, ok := b.replyKeyboardKey(msg.Text)
+	if !ok {
 		return false
 	}
 
+	if key == "keyboard.admin" && !b.userService.IsStaff(ctx, currentUser) {
+		b.respond(msg, b.t(ctx, "common.no_admin_permission"))
+		return true
+	}
+
 	if isGroupChat(msg) {
-		b.replyWithAutoDelete(msg.Chat.ID, "请在私聊中使用按钮功能", msg.MessageID)
+		b.replyWithAutoDelete(msg.Chat.ID, b.t(ctx, "common.buttons_private_only"), msg.MessageID)
 		return true
 	}
 
+	b.routeAsCallback(ctx, msg, replyKeyboardRoutes[key])
+	return true
+}
+
+// routeAsCallback 把消息当作按钮点击交给回调处理，响应以新消息发送
+func (b *Bot) routeAsCallback(ctx context.Context, msg *tgbotapi.Message, callbackData string) {
 	query := &tgbotapi.CallbackQuery{
 		ID:   fmt.Sprintf("reply_keyboard_%d", msg.MessageID),
 		From: msg.From,
@@ -333,99 +417,60 @@ func (b *Bot) handleReplyKeyboardButton(ctx context.Context, msg *tgbotapi.Messa
 	}
 
 	b.handleCallbackQuery(ctx, query)
-	return true
 }
 
 // setupBotCommands 设置 Bot 命令菜单（显示在输入框的 / 按钮中）
+// 未指定语言的菜单使用默认语言，其余语言按 Telegram 客户端语言分别设置
 func (b *Bot) setupBotCommands() error {
 	// 群组命令（仅白名单命令）
-	groupCommands := []tgbotapi.BotCommand{
-		{
-			Command:     "start",
-			Description: "开始使用 Bot",
-		},
-		{
-			Command:     "help",
-			Description: "查看帮助信息",
-		},
-		{
-			Command:     "grant",
-			Description: "授权用户创建账号（管理员）",
-		},
-		{
-			Command:     "stats",
-			Description: "查看系统统计（管理员）",
-		},
-		{
-			Command:     "checkemby",
-			Description: "检查 Emby 服务器状态",
-		},
-		{
-			Command:     "playingstats",
-			Description: "查看播放统计（管理员）",
-		},
-	}
+	groupCommands := []string{"start", "help", "grant", "stats", "checkemby", "playingstats"}
 
-	// 私聊命令（全部命令）
-	privateCommands := []tgbotapi.BotCommand{
-		{
-			Command:     "start",
-			Description: "开始使用 Bot",
-		},
-		{
-			Command:     "help",
-			Description: "查看帮助信息",
-		},
-		{
-			Command:     "myaccounts",
-			Description: "查看我的账号列表",
-		},
-		{
-			Command:     "create",
-			Description: "创建新账号",
-		},
-		{
-			Command:     "info",
-			Description: "查看账号详情",
-		},
-		{
-			Command:     "renew",
-			Description: "续期账号",
-		},
-		{
-			Command:     "changepassword",
-			Description: "修改账号密码",
-		},
-		{
-			Command:     "admin",
-			Description: "管理员菜单（仅管理员）",
-		},
-	}
+	// 私聊命令
+	privateCommands := []string{"start", "help", "myaccounts", "create", "info", "renew", "changepassword", "language", "admin"}
 
-	// 为群组设置命令
-	groupScope := tgbotapi.BotCommandScope{
-		Type: "all_group_chats",
-	}
-	groupCfg := tgbotapi.SetMyCommandsConfig{
-		Commands: groupCommands,
-		Scope:    &groupScope,
-	}
-	if _, err := b.api.Request(groupCfg); err != nil {
-		logger.Warnf("failed to set group commands: %v", err)
-	}
+	languages := append([]string{""}, b.catalog.Languages()...)
+	for _, lang := range languages {
+		loc := b.catalog.Localizer(lang)
 
-	// 为私聊设置命令
-	privateScope := tgbotapi.BotCommandScope{
-		Type: "all_private_chats",
-	}
-	privateCfg := tgbotapi.SetMyCommandsConfig{
-		Commands: privateCommands,
-		Scope:    &privateScope,
-	}
-	if _, err := b.api.Request(privateCfg); err != nil {
-		logger.Warnf("failed to set private commands: %v", err)
+		// 为群组设置命令
+		groupScope := tgbotapi.BotCommandScope{
+			Type: "all_group_chats",
+		}
+		groupCfg := tgbotapi.SetMyCommandsConfig{
+			Commands:     botCommands(loc, groupCommands),
+			Scope:        &groupScope,
+			LanguageCode: lang,
+		}
+		if _, err := b.api.Request(groupCfg); err != nil {
+			logger.Warnf("failed to set group commands (language %q): %v", lang, err)
+		}
+
+		// 为私聊设置命令
+		privateScope := tgbotapi.BotCommandScope{
+			Type: "all_private_chats",
+		}
+		privateCfg := tgbotapi.SetMyCommandsConfig{
+			Commands:     botCommands(loc, privateCommands),
+			Scope:        &privateScope,
+			LanguageCode: lang,
+		}
+		if _, err := b.api.Request(privateCfg); err != nil {
+			logger.Warnf("failed to set private commands (language %q): %v", lang, err)
+		}
 	}
 
 	logger.Info("bot commands configured for different chat types")
 	return nil
 }
+
+// botCommands 生成命令菜单，说明文字取自消息目录的 command.<命令名>
+func botCommands(loc *i18n.Localizer, names []string) []tgbotapi.BotCommand {
+	commands := make([]tgbotapi.BotCommand, len(names))
+	for i, name := range names {
+		commands[i] = tgbotapi.BotCommand{
+			Command:     name,
+			Description: loc.T("command." + name),
+		}
+	}
+	return commands
+}
@@ -0,0 +1,337 @@
+// Package bot 群发消息
+package bot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/conversation"
+	"emby-telegram/internal/i18n"
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/user"
+)
+
+// broadcastWorkers 同时发送的消息数
+// 每条消息仍经过发送队列限速，并发数较小可避免群发占满全局配额、拖慢交互回复
+const broadcastWorkers = 4
+
+// broadcastProgressInterval 进度消息的刷新间隔
+const broadcastProgressInterval = 5 * time.Second
+
+// broadcastJob 群发任务
+type broadcastJob struct {
+	id         uint64
+	loc        *i18n.Localizer // 发起人的界面语言，用于进度和报告
+	chatID     int64           // 发起人的聊天，接收进度和报告
+	fromChatID int64           // 被复制消息所在的聊天
+	messageID  int             // 被复制的消息，为 0 时发送 text
+	text       string          // 定时公告的文本
+	recipients []*user.User
+
+	progressID int // 进度消息 ID，0 表示进度消息发送失败
+	started    time.Time
+
+	delivered atomic.Int64
+	blocked   atomic.Int64
+	failed    atomic.Int64
+}
+
+// done 已处理的接收人数
+func (j *broadcastJob) done() int64 {
+	return j.delivered.Load() + j.blocked.Load() + j.failed.Load()
+}
+
+// 停止任务的结果
+var (
+	errJobNotFound = errors.New("job not found")             // 任务不存在或已结束
+	errJobNotOwner = errors.New("job started by other user") // 只有发起人可以停止任务
+)
+
+// jobRegistry 进行中的后台任务(群发、批量操作)，用于响应停止按钮
+// 任务 ID 是递增的，停止时校验发起人，避免其他管理员按 ID 停止别人的任务
+type jobRegistry struct {
+	mu   sync.Mutex
+	seq  uint64
+	jobs map[uint64]registeredJob
+}
+
+// registeredJob 登记的任务
+type registeredJob struct {
+	operatorID int64 // 发起人 Telegram ID
+	cancel     context.CancelFunc
+}
+
+// add 登记任务并返回任务 ID
+func (r *jobRegistry) add(operatorID int64, cancel context.CancelFunc) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.jobs == nil {
+		r.jobs = make(map[uint64]registeredJob)
+	}
+	r.seq++
+	r.jobs[r.seq] = registeredJob{operatorID: operatorID, cancel: cancel}
+	return r.seq
+}
+
+// cancel 由发起人停止任务
+func (r *jobRegistry) cancel(id uint64, operatorID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return errJobNotFound
+	}
+	if job.operatorID != operatorID {
+		return errJobNotOwner
+	}
+	job.cancel()
+	return nil
+}
+
+// remove 任务结束后移除登记
+func (r *jobRegistry) remove(id uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.jobs, id)
+}
+
+// startBroadcast 在后台向接收人复制指定消息
+// 进度发送到发起人的聊天，结束后改为最终报告
+func (b *Bot) startBroadcast(ctx context.Context, chatID, fromChatID int64, messageID int, recipients []*user.User) {
+	b.launchBroadcast(&broadcastJob{
+		loc:        b.loc(ctx),
+		chatID:     chatID,
+		fromChatID: fromChatID,
+		messageID:  messageID,
+		recipients: recipients,
+	})
+}
+
+// startTextBroadcast 在后台向接收人发送纯文本，用于定时公告
+func (b *Bot) startTextBroadcast(loc *i18n.Localizer, chatID int64, text string, recipients []*user.User) {
+	b.launchBroadcast(&broadcastJob{
+		loc:        loc,
+		chatID:     chatID,
+		text:       text,
+		recipients: recipients,
+	})
+}
+
+// launchBroadcast 登记并在后台执行群发任务
+func (b *Bot) launchBroadcast(job *broadcastJob) {
+	jobCtx, cancel := context.WithCancel(b.handlerCtx)
+	job.started = time.Now()
+	job.id = b.broadcasts.add(job.chatID, cancel)
+
+	logger.Infof("broadcast %d started by chat %d: %d recipients", job.id, job.chatID, len(job.recipients))
+
+	b.runJob(func() {
+		defer cancel()
+		defer b.broadcasts.remove(job.id)
+
+		// 关闭 Bot 时停止群发，未发送的接收人计入报告
+		go func() {
+			select {
+			case <-b.stopping:
+				cancel()
+			case <-jobCtx.Done():
+			}
+		}()
+
+		b.runBroadcast(jobCtx, job)
+	})
+}
+
+// runBroadcast 执行群发任务
+func (b *Bot) runBroadcast(ctx context.Context, job *broadcastJob) {
+	progress := tgbotapi.NewMessage(job.chatID, b.broadcastProgressText(job))
+	progress.ParseMode = "HTML"
+	progress.ReplyMarkup = broadcastStopKeyboard(job)
+	if msg, err := b.send(ctx, job.chatID, progress); err != nil {
+		logger.Warnf("broadcast %d: failed to send progress message: %v", job.id, err)
+	} else {
+		job.progressID = msg.MessageID
+	}
+
+	recipients := make(chan *user.User)
+	var workers sync.WaitGroup
+	for i := 0; i < broadcastWorkers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for u := range recipients {
+				b.deliverBroadcast(ctx, job, u)
+			}
+		}()
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		defer close(recipients)
+		for _, u := range job.recipients {
+			select {
+			case recipients <- u:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(broadcastProgressInterval)
+	defer ticker.Stop()
+
+	var reported int64
+	for running := true; running; {
+		select {
+		case <-finished:
+			running = false
+		case <-ticker.C:
+			if done := job.done(); done != reported {
+				reported = done
+				b.updateBroadcastProgress(job, b.broadcastProgressText(job), broadcastStopKeyboard(job))
+			}
+		}
+	}
+	workers.Wait()
+
+	interrupted := ctx.Err() != nil
+	b.updateBroadcastProgress(job, b.broadcastReportText(job, interrupted), nil)
+
+	logger.Infof("broadcast %d finished: delivered %d, blocked %d, failed %d, not sent %d",
+		job.id, job.delivered.Load(), job.blocked.Load(), job.failed.Load(), int64(len(job.recipients))-job.done())
+}
+
+// deliverBroadcast 向单个接收人复制消息并记录结果
+func (b *Bot) deliverBroadcast(ctx context.Context, job *broadcastJob, u *user.User) {
+	var msg tgbotapi.Chattable = tgbotapi.NewCopyMessage(u.TelegramID, job.fromChatID, job.messageID)
+	if job.messageID == 0 {
+		msg = tgbotapi.NewMessage(u.TelegramID, job.text)
+	}
+	_, err := b.send(ctx, u.TelegramID, msg)
+
+	switch {
+	case err == nil:
+		job.delivered.Add(1)
+		if u.HasBlockedBot() {
+			if err := b.userService.ClearBotBlocked(b.handlerCtx, u.TelegramID); err != nil {
+				logger.Warnf("failed to clear bot blocked mark for %d: %v", u.TelegramID, err)
+			}
+		}
+	case ctx.Err() != nil:
+		// 任务被停止，不计入结果
+	case isBotBlockedError(err):
+		job.blocked.Add(1)
+		if err := b.userService.MarkBotBlocked(b.handlerCtx, u.TelegramID); err != nil {
+			logger.Warnf("failed to mark bot blocked for %d: %v", u.TelegramID, err)
+		}
+	default:
+		job.failed.Add(1)
+		logger.Warnf("broadcast %d: failed to deliver to %d: %v", job.id, u.TelegramID, err)
+	}
+}
+
+// isBotBlockedError 检查错误是否表示用户屏蔽了 Bot 或已注销
+func isBotBlockedError(err error) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden
+}
+
+// updateBroadcastProgress 编辑进度消息，进度消息发送失败时只发送最终报告
+func (b *Bot) updateBroadcastProgress(job *broadcastJob, text string, markup *tgbotapi.InlineKeyboardMarkup) {
+	if job.progressID == 0 {
+		if markup == nil {
+			b.reply(job.chatID, text)
+		}
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageText(job.chatID, job.progressID, text)
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = markup
+	if _, err := b.send(b.handlerCtx, job.chatID, edit); err != nil {
+		logger.Warnf("broadcast %d: failed to update progress: %v", job.id, err)
+	}
+}
+
+// broadcastProgressText 进度文案
+func (b *Bot) broadcastProgressText(job *broadcastJob) string {
+	return job.loc.T("broadcast.progress",
+		job.done(),
+		len(job.recipients),
+		job.delivered.Load(),
+		job.blocked.Load(),
+		job.failed.Load(),
+	)
+}
+
+// broadcastReportText 最终报告文案
+func (b *Bot) broadcastReportText(job *broadcastJob, interrupted bool) string {
+	key := "broadcast.report"
+	if interrupted {
+		key = "broadcast.report_stopped"
+	}
+	return job.loc.T(key,
+		len(job.recipients),
+		job.delivered.Load(),
+		job.blocked.Load(),
+		job.failed.Load(),
+		int64(len(job.recipients))-job.done(),
+		time.Since(job.started).Round(time.Second),
+	)
+}
+
+// broadcastStopKeyboard 进度消息上的停止按钮
+func broadcastStopKeyboard(job *broadcastJob) *tgbotapi.InlineKeyboardMarkup {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(job.loc.T("broadcast.stop"), CallbackAdminBroadcastStop+":"+strconv.FormatUint(job.id, 10)),
+		),
+	)
+	return &keyboard
+}
+
+// handleBroadcastStop 停止群发任务，只有发起人可以停止
+func (b *Bot) handleBroadcastStop(ctx context.Context, parts []string, currentUser *user.User) CallbackResponse {
+	id, err := strconv.ParseUint(getCallbackParam(parts, 2), 10, 64)
+	if err != nil {
+		return CallbackResponse{Answer: b.t(ctx, "common.invalid_params"), ShowAlert: true}
+	}
+
+	switch err := b.broadcasts.cancel(id, currentUser.TelegramID); {
+	case errors.Is(err, errJobNotFound):
+		return CallbackResponse{Answer: b.t(ctx, "broadcast.already_finished")}
+	case err != nil:
+		return CallbackResponse{Answer: b.t(ctx, "common.job_not_owner"), ShowAlert: true}
+	}
+	return CallbackResponse{Answer: b.t(ctx, "broadcast.stopping")}
+}
+
+// clearBotBlocked 用户再次与 Bot 互动时清除屏蔽标记
+func (b *Bot) clearBotBlocked(ctx context.Context, u *user.User) {
+	if !u.HasBlockedBot() {
+		return
+	}
+	if err := b.userService.ClearBotBlocked(ctx, u.TelegramID); err != nil {
+		logger.Warnf("failed to clear bot blocked mark for %d: %v", u.TelegramID, err)
+		return
+	}
+	u.BotBlockedAt = nil
+}
+
+// handleBroadcast 处理 /broadcast 命令，启动群发向导
+func (b *Bot) handleBroadcast(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+	resp := b.startWizard(ctx, currentUserFromContext(ctx), WizardBroadcast, conversation.Payload{})
+	if resp.EditText == "" {
+		return resp.Answer, nil
+	}
+	b.sendWithMarkup(ctx, msg.Chat.ID, resp.EditText, resp.EditMarkup)
+	return "", nil
+}
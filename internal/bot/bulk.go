@@ -0,0 +1,721 @@
+// Package bot 批量账号操作
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/conversation"
+	"emby-telegram/internal/i18n"
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/user"
+	"emby-telegram/pkg/validator"
+)
+
+const (
+	// bulkMaxUsernames 一次最多输入的用户名数
+	bulkMaxUsernames = 200
+
+	// bulkPreviewCount 确认时列出的账号数
+	bulkPreviewCount = 10
+
+	// bulkReportItems 报告中最多列出的结果条数，超出时另外发送完整结果文件
+	bulkReportItems = 20
+
+	// bulkProgressInterval 进度消息的刷新间隔
+	bulkProgressInterval = 5 * time.Second
+)
+
+// 批量操作的账号范围
+const (
+	bulkScopeFilter    = "filter"    // 账号列表的筛选结果
+	bulkScopeUsernames = "usernames" // 指定的用户名
+	bulkScopeOwner     = "owner"     // 某个用户的全部账号
+)
+
+// bulkDayOptions 续期天数的快捷选项
+var bulkDayOptions = []int{7, 30, 90, 365}
+
+// bulkDeviceOptions 设备数的快捷选项，0 表示不限制
+var bulkDeviceOptions = []int{1, 2, 3, 5, 0}
+
+// bulkTarget 批量操作的一个账号及其执行结果
+type bulkTarget struct {
+	id       uint // 0 表示账号不存在，不执行操作
+	username string
+	done     bool
+	err      error
+}
+
+// bulkJob 批量操作任务
+// 账号逐个处理，避免同时向 Emby 发出大量请求
+type bulkJob struct {
+	id         uint64
+	loc        *i18n.Localizer // 发起人的界面语言，用于进度和报告
+	chatID     int64           // 发起人的聊天，接收进度和报告
+	operatorID uint
+	op         account.BulkOperation
+	opText     string // 操作说明
+	targets    []*bulkTarget
+
+	progressID int // 进度消息 ID，0 表示进度消息发送失败
+	started    time.Time
+
+	succeeded int
+	failed    int
+}
+
+// processed 已处理的账号数
+func (j *bulkJob) processed() int {
+	return j.succeeded + j.failed
+}
+
+// bulkAccountsWizard 批量账号操作: 选择范围和操作，确认后在后台执行
+// 从账号列表发起时 Payload.ListView 为列表状态，直接使用当前的筛选结果
+func (b *Bot) bulkAccountsWizard() *Wizard {
+	scopeIsNot := func(scope string) func(ctx context.Context, s *WizardSession) bool {
+		return func(ctx context.Context, s *WizardSession) bool {
+			return s.Payload.ListView != "" || s.Value("scope") != scope
+		}
+	}
+	actionIsNot := func(action account.BulkAction) func(ctx context.Context, s *WizardSession) bool {
+		return func(ctx context.Context, s *WizardSession) bool {
+			return account.BulkAction(s.Value("action")) != action
+		}
+	}
+
+	return &Wizard{
+		Name: WizardBulkAccounts,
+		Steps: []WizardStep{
+			{
+				Key: "scope",
+				Skip: func(ctx context.Context, s *WizardSession) bool {
+					return s.Payload.ListView != ""
+				},
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					return b.t(ctx, "bulk.prompt_scope")
+				},
+				Choices: func(ctx context.Context, s *WizardSession) []WizardChoice {
+					scopes := []string{bulkScopeFilter, bulkScopeUsernames, bulkScopeOwner}
+					choices := make([]WizardChoice, len(scopes))
+					for i, scope := range scopes {
+						choices[i] = WizardChoice{Label: b.t(ctx, "bulk.scope_"+scope), Value: scope}
+					}
+					return choices
+				},
+				Validate: func(ctx context.Context, s *WizardSession, value string) error {
+					switch value {
+					case bulkScopeFilter, bulkScopeUsernames, bulkScopeOwner:
+						return nil
+					}
+					return newWizardInputError("broadcast.use_buttons")
+				},
+			},
+			{
+				Key:  "filter",
+				Skip: scopeIsNot(bulkScopeFilter),
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					return b.t(ctx, "bulk.prompt_filter")
+				},
+				Choices: func(ctx context.Context, s *WizardSession) []WizardChoice {
+					choices := make([]WizardChoice, len(accountsListSpec.filters))
+					for i, opt := range accountsListSpec.filters {
+						choices[i] = WizardChoice{Label: b.t(ctx, opt.key), Value: string(opt.code)}
+					}
+					return choices
+				},
+				Validate: func(ctx context.Context, s *WizardSession, value string) error {
+					if len(value) != 1 {
+						return newWizardInputError("broadcast.use_buttons")
+					}
+					if _, ok := findListOption(accountsListSpec.filters, value[0]); !ok {
+						return newWizardInputError("broadcast.use_buttons")
+					}
+					return nil
+				},
+			},
+			{
+				Key:  "usernames",
+				Skip: scopeIsNot(bulkScopeUsernames),
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					return b.t(ctx, "bulk.prompt_usernames", bulkMaxUsernames)
+				},
+				Validate: func(ctx context.Context, s *WizardSession, value string) error {
+					usernames := splitBulkUsernames(value)
+					if len(usernames) == 0 {
+						return newWizardInputError("bulk.usernames_empty")
+					}
+					if len(usernames) > bulkMaxUsernames {
+						return newWizardInputError("bulk.usernames_too_many", bulkMaxUsernames)
+					}
+					return nil
+				},
+			},
+			{
+				Key:  "owner",
+				Skip: scopeIsNot(bulkScopeOwner),
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					return b.t(ctx, "bulk.prompt_owner")
+				},
+				Validate: func(ctx context.Context, s *WizardSession, value string) error {
+					_, err := b.resolveUser(ctx, value)
+					return err
+				},
+			},
+			{
+				Key: "action",
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					return b.t(ctx, "bulk.prompt_action")
+				},
+				Choices: func(ctx context.Context, s *WizardSession) []WizardChoice {
+					var choices []WizardChoice
+					for _, action := range account.BulkActions {
+						if b.canBulk(ctx, s.User, action) {
+							choices = append(choices, WizardChoice{Label: b.t(ctx, "bulk.action_"+string(action)), Value: string(action)})
+						}
+					}
+					return choices
+				},
+				Validate: func(ctx context.Context, s *WizardSession, value string) error {
+					if !b.canBulk(ctx, s.User, account.BulkAction(value)) {
+						return newWizardInputError("broadcast.use_buttons")
+					}
+					return nil
+				},
+			},
+			{
+				Key:  "days",
+				Skip: actionIsNot(account.BulkRenew),
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					return b.t(ctx, "bulk.prompt_days")
+				},
+				Choices: func(ctx context.Context, s *WizardSession) []WizardChoice {
+					choices := make([]WizardChoice, len(bulkDayOptions))
+					for i, days := range bulkDayOptions {
+						choices[i] = WizardChoice{Label: b.t(ctx, "bulk.days_option", days), Value: strconv.Itoa(days)}
+					}
+					return choices
+				},
+				Validate: func(ctx context.Context, s *WizardSession, value string) error {
+					_, err := validator.ParseDays(value)
+					return err
+				},
+			},
+			{
+				Key:  "devices",
+				Skip: actionIsNot(account.BulkSetDevices),
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					return b.t(ctx, "bulk.prompt_devices")
+				},
+				Choices: func(ctx context.Context, s *WizardSession) []WizardChoice {
+					choices := make([]WizardChoice, len(bulkDeviceOptions))
+					for i, devices := range bulkDeviceOptions {
+						choices[i] = WizardChoice{Label: b.devicesText(ctx, devices), Value: strconv.Itoa(devices)}
+					}
+					return choices
+				},
+				Validate: func(ctx context.Context, s *WizardSession, value string) error {
+					devices, err := strconv.Atoi(value)
+					if err != nil || devices < 0 {
+						return newWizardInputError("devices.invalid")
+					}
+					if devices == 0 {
+						return nil
+					}
+					return validator.ValidateMaxDevices(devices)
+				},
+			},
+			{
+				Key:  "template",
+				Skip: actionIsNot(account.BulkApplyTemplate),
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					return b.t(ctx, "bulk.prompt_template")
+				},
+				Choices: func(ctx context.Context, s *WizardSession) []WizardChoice {
+					templates, err := b.policyService.List(ctx)
+					if err != nil {
+						logger.Errorf("failed to list policy templates: %v", err)
+						return nil
+					}
+					choices := make([]WizardChoice, len(templates))
+					for i, tpl := range templates {
+						choices[i] = WizardChoice{Label: tpl.Name, Value: uintToStr(tpl.ID)}
+					}
+					return choices
+				},
+				Validate: func(ctx context.Context, s *WizardSession, value string) error {
+					_, err := b.policyService.Get(ctx, strToUint(value))
+					return err
+				},
+			},
+			{
+				Key: "confirm",
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					return b.bulkConfirmText(ctx, s)
+				},
+				Choices: func(ctx context.Context, s *WizardSession) []WizardChoice {
+					return []WizardChoice{{Label: b.t(ctx, "bulk.run_button"), Value: "run"}}
+				},
+				Validate: func(ctx context.Context, s *WizardSession, value string) error {
+					if value != "run" {
+						return newWizardInputError("broadcast.use_buttons")
+					}
+					return nil
+				},
+			},
+		},
+		Commit: func(ctx context.Context, s *WizardSession) WizardResult {
+			op, opText, err := b.bulkOperation(ctx, s)
+			if err != nil {
+				return WizardResult{Text: b.t(ctx, "bulk.failed", b.errorText(ctx, err))}
+			}
+			targets, _, err := b.bulkTargets(ctx, s)
+			if err != nil {
+				return WizardResult{Text: b.t(ctx, "bulk.list_failed", b.errorText(ctx, err))}
+			}
+			if len(targets) == 0 {
+				return WizardResult{Text: b.t(ctx, "bulk.no_targets")}
+			}
+
+			b.launchBulk(&bulkJob{
+				loc:        b.loc(ctx),
+				chatID:     s.User.TelegramID,
+				operatorID: s.User.ID,
+				op:         op,
+				opText:     opText,
+				targets:    targets,
+			})
+			return WizardResult{Text: b.t(ctx, "bulk.started", len(targets))}
+		},
+		Cancel: func(ctx context.Context, s *WizardSession) CallbackResponse {
+			if s.Payload.ListView != "" {
+				return b.showAllAccountsList(ctx, 1, accountsListSpec.parse(s.Payload.ListView))
+			}
+			return CallbackResponse{
+				Answer:   b.t(ctx, "cancel.answer"),
+				EditText: b.t(ctx, "cancel.text"),
+			}
+		},
+	}
+}
+
+// canBulk 检查用户能否发起指定的批量操作
+// 代理商可以批量续期自己创建的账号，逐个执行时再检查每个账号的权限
+func (b *Bot) canBulk(ctx context.Context, u *user.User, action account.BulkAction) bool {
+	if !slices.Contains(account.BulkActions, action) {
+		return false
+	}
+	op := account.BulkOperation{Action: action}
+	if b.userService.Can(ctx, u, user.Permission(op.Permission())) {
+		return true
+	}
+	return action == account.BulkRenew && b.userService.Can(ctx, u, user.PermCustomerManage)
+}
+
+// splitBulkUsernames 拆分以空白或逗号分隔的用户名，去除重复
+func splitBulkUsernames(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == '，' || unicode.IsSpace(r)
+	})
+
+	seen := make(map[string]bool, len(fields))
+	usernames := make([]string, 0, len(fields))
+	for _, name := range fields {
+		if !seen[name] {
+			seen[name] = true
+			usernames = append(usernames, name)
+		}
+	}
+	return usernames
+}
+
+// resolveUser 根据 Telegram ID 或 @username 查找用户
+func (b *Bot) resolveUser(ctx context.Context, value string) (*user.User, error) {
+	if telegramID, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return b.userService.GetByTelegramID(ctx, telegramID)
+	}
+	return b.userService.GetByUsername(ctx, strings.TrimPrefix(value, "@"))
+}
+
+// bulkOperation 根据向导输入生成批量操作及其说明
+func (b *Bot) bulkOperation(ctx context.Context, s *WizardSession) (account.BulkOperation, string, error) {
+	op := account.BulkOperation{Action: account.BulkAction(s.Value("action"))}
+
+	var text string
+	switch op.Action {
+	case account.BulkRenew:
+		days, err := validator.ParseDays(s.Value("days"))
+		if err != nil {
+			return op, "", err
+		}
+		op.Days = days
+		text = b.t(ctx, "bulk.desc_renew", days)
+	case account.BulkSetDevices:
+		op.Devices, _ = strconv.Atoi(s.Value("devices"))
+		text = b.t(ctx, "bulk.desc_devices", b.devicesText(ctx, op.Devices))
+	case account.BulkApplyTemplate:
+		tpl, err := b.policyService.Get(ctx, strToUint(s.Value("template")))
+		if err != nil {
+			return op, "", err
+		}
+		op.TemplateID = tpl.ID
+		text = b.t(ctx, "bulk.desc_template", html.EscapeString(tpl.Name))
+	default:
+		text = b.t(ctx, "bulk.action_"+string(op.Action))
+	}
+
+	return op, text, op.Validate()
+}
+
+// devicesText 设备数说明，0 表示不限制
+func (b *Bot) devicesText(ctx context.Context, devices int) string {
+	if devices == 0 {
+		return b.t(ctx, "bulk.devices_unlimited")
+	}
+	return b.t(ctx, "bulk.devices_option", devices)
+}
+
+// bulkTargets 根据向导输入查找要操作的账号及范围说明
+// 所有范围都只包含操作者可以查看的账号，预览不会泄露其他账号的用户名；
+// 指定用户名时找不到或无权查看的账号同样按不存在列出，执行时计为失败
+func (b *Bot) bulkTargets(ctx context.Context, s *WizardSession) ([]*bulkTarget, string, error) {
+	scope := s.Value("scope")
+	if s.Payload.ListView != "" {
+		scope = bulkScopeFilter
+	}
+
+	switch scope {
+	case bulkScopeUsernames:
+		usernames := splitBulkUsernames(s.Value("usernames"))
+		targets := make([]*bulkTarget, 0, len(usernames))
+		for _, name := range usernames {
+			target := &bulkTarget{username: name}
+			acc, err := b.accountService.GetByUsername(ctx, name)
+			if err == nil {
+				err = b.accountService.CanManage(ctx, s.User.ID, acc, account.PermissionView)
+			}
+			switch {
+			case err == nil:
+				target.id = acc.ID
+				target.username = acc.Username
+			case errors.Is(err, account.ErrUnauthorized):
+				target.err = account.NotFoundError(name)
+			default:
+				target.err = err
+			}
+			targets = append(targets, target)
+		}
+		return targets, b.t(ctx, "bulk.desc_usernames", len(usernames)), nil
+
+	case bulkScopeOwner:
+		owner, err := b.resolveUser(ctx, s.Value("owner"))
+		if err != nil {
+			return nil, "", err
+		}
+		accounts, err := b.accountService.ListByUser(ctx, owner.ID)
+		if err != nil {
+			return nil, "", err
+		}
+		targets := make([]*bulkTarget, 0, len(accounts))
+		for _, acc := range accounts {
+			err := b.accountService.CanManage(ctx, s.User.ID, acc, account.PermissionView)
+			if errors.Is(err, account.ErrUnauthorized) {
+				continue
+			}
+			if err != nil {
+				return nil, "", err
+			}
+			targets = append(targets, &bulkTarget{id: acc.ID, username: acc.Username})
+		}
+		return targets, b.t(ctx, "bulk.desc_owner", html.EscapeString(owner.DisplayName()), owner.TelegramID), nil
+
+	default:
+		view := accountsListSpec.parse(s.Payload.ListView)
+		if s.Payload.ListView == "" {
+			view = accountsListSpec.parse(s.Value("filter") + string(listDefaultCode))
+		}
+		accounts, _, err := b.accountService.ListManaged(ctx, s.User.ID, account.ListQuery{
+			Filter: account.Filter(accountsListSpec.filterValue(view)),
+			Sort:   account.Sort(accountsListSpec.sortValue(view)),
+			Search: view.search,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		targets := make([]*bulkTarget, len(accounts))
+		for i, acc := range accounts {
+			targets[i] = &bulkTarget{id: acc.ID, username: acc.Username}
+		}
+
+		filter, _ := findListOption(accountsListSpec.filters, view.filter)
+		desc := b.t(ctx, "bulk.desc_filter", b.t(ctx, filter.key))
+		if view.search != "" {
+			desc += b.t(ctx, "bulk.desc_search", html.EscapeString(view.search))
+		}
+		return targets, desc, nil
+	}
+}
+
+// bulkConfirmText 确认步骤的文案: 操作、范围、账号数及部分账号
+func (b *Bot) bulkConfirmText(ctx context.Context, s *WizardSession) string {
+	_, opText, err := b.bulkOperation(ctx, s)
+	if err != nil {
+		return b.t(ctx, "bulk.failed", b.errorText(ctx, err))
+	}
+	targets, scopeText, err := b.bulkTargets(ctx, s)
+	if err != nil {
+		return b.t(ctx, "bulk.list_failed", b.errorText(ctx, err))
+	}
+	if len(targets) == 0 {
+		return b.t(ctx, "bulk.no_targets")
+	}
+
+	var found, missing []string
+	for _, target := range targets {
+		if target.id == 0 {
+			missing = append(missing, html.EscapeString(target.username))
+		} else {
+			found = append(found, html.EscapeString(target.username))
+		}
+	}
+
+	var details string
+	if len(found) > 0 {
+		preview := found
+		if len(preview) > bulkPreviewCount {
+			preview = preview[:bulkPreviewCount]
+		}
+		details += b.t(ctx, "bulk.confirm_accounts", strings.Join(preview, ", "))
+		if more := len(found) - len(preview); more > 0 {
+			details += b.t(ctx, "bulk.confirm_more", more)
+		}
+	}
+	if len(missing) > 0 {
+		details += b.t(ctx, "bulk.confirm_missing", len(missing), strings.Join(missing, ", "))
+	}
+
+	text := b.t(ctx, "bulk.confirm", opText, scopeText, len(targets), details)
+	if account.BulkAction(s.Value("action")) == account.BulkDelete {
+		text += b.t(ctx, "bulk.delete_warning")
+	}
+	return text
+}
+
+// launchBulk 登记并在后台执行批量操作任务
+func (b *Bot) launchBulk(job *bulkJob) {
+	jobCtx, cancel := context.WithCancel(b.handlerCtx)
+	job.started = time.Now()
+	job.id = b.bulkJobs.add(job.chatID, cancel)
+
+	logger.Infof("bulk job %d started by chat %d: %s on %d accounts", job.id, job.chatID, job.op.Action, len(job.targets))
+
+	b.runJob(func() {
+		defer cancel()
+		defer b.bulkJobs.remove(job.id)
+
+		// 关闭 Bot 时停止任务，未处理的账号计入报告
+		go func() {
+			select {
+			case <-b.stopping:
+				cancel()
+			case <-jobCtx.Done():
+			}
+		}()
+
+		b.runBulk(jobCtx, job)
+	})
+}
+
+// runBulk 逐个账号执行批量操作
+// 停止只在账号之间生效，正在处理的账号使用 handlerCtx 完成，避免本地与 Emby 状态不一致
+func (b *Bot) runBulk(ctx context.Context, job *bulkJob) {
+	progress := tgbotapi.NewMessage(job.chatID, b.bulkProgressText(job))
+	progress.ParseMode = "HTML"
+	progress.ReplyMarkup = bulkStopKeyboard(job)
+	if msg, err := b.send(ctx, job.chatID, progress); err != nil {
+		logger.Warnf("bulk job %d: failed to send progress message: %v", job.id, err)
+	} else {
+		job.progressID = msg.MessageID
+	}
+
+	reported := time.Now()
+	for _, target := range job.targets {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if target.id != 0 {
+			target.err = b.accountService.ApplyAs(b.handlerCtx, job.operatorID, target.id, job.op)
+		}
+		target.done = true
+
+		if target.err != nil {
+			job.failed++
+			logger.Warnf("bulk job %d: %s on %s failed: %v", job.id, job.op.Action, target.username, target.err)
+		} else {
+			job.succeeded++
+		}
+
+		if time.Since(reported) >= bulkProgressInterval {
+			reported = time.Now()
+			b.updateBulkProgress(job, b.bulkProgressText(job), bulkStopKeyboard(job))
+		}
+	}
+
+	interrupted := ctx.Err() != nil
+	b.updateBulkProgress(job, b.bulkReportText(job, interrupted), nil)
+	if len(job.targets) > bulkReportItems {
+		b.sendBulkResults(job)
+	}
+
+	logger.Infof("bulk job %d finished: succeeded %d, failed %d, not processed %d",
+		job.id, job.succeeded, job.failed, len(job.targets)-job.processed())
+}
+
+// updateBulkProgress 编辑进度消息，进度消息发送失败时只发送最终报告
+func (b *Bot) updateBulkProgress(job *bulkJob, text string, markup *tgbotapi.InlineKeyboardMarkup) {
+	if job.progressID == 0 {
+		if markup == nil {
+			b.reply(job.chatID, text)
+		}
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageText(job.chatID, job.progressID, text)
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = markup
+	if _, err := b.send(b.handlerCtx, job.chatID, edit); err != nil {
+		logger.Warnf("bulk job %d: failed to update progress: %v", job.id, err)
+	}
+}
+
+// bulkProgressText 进度文案
+func (b *Bot) bulkProgressText(job *bulkJob) string {
+	return job.loc.T("bulk.progress", job.opText, job.processed(), len(job.targets), job.succeeded, job.failed)
+}
+
+// bulkReportText 最终报告文案
+// 账号较少时列出每个账号的结果，否则只列出前 bulkReportItems 个失败的账号
+func (b *Bot) bulkReportText(job *bulkJob, interrupted bool) string {
+	key := "bulk.report"
+	if interrupted {
+		key = "bulk.report_stopped"
+	}
+	text := job.loc.T(key,
+		job.opText,
+		len(job.targets),
+		job.succeeded,
+		job.failed,
+		len(job.targets)-job.processed(),
+		time.Since(job.started).Round(time.Second),
+	)
+
+	ctx := withLocalizer(b.handlerCtx, job.loc)
+	all := len(job.targets) <= bulkReportItems
+	var lines []string
+	hidden := 0
+	for _, target := range job.targets {
+		if !target.done || (!all && target.err == nil) {
+			continue
+		}
+		if len(lines) >= bulkReportItems {
+			hidden++
+			continue
+		}
+		if target.err != nil {
+			lines = append(lines, job.loc.T("bulk.item_failed", html.EscapeString(target.username), html.EscapeString(b.errorText(ctx, target.err))))
+		} else {
+			lines = append(lines, job.loc.T("bulk.item_ok", html.EscapeString(target.username)))
+		}
+	}
+
+	if len(lines) > 0 {
+		header := "bulk.report_items"
+		if !all {
+			header = "bulk.report_failures"
+		}
+		text += job.loc.T(header) + strings.Join(lines, "\n")
+	}
+	if hidden > 0 {
+		text += job.loc.T("bulk.report_more", hidden)
+	}
+	return text
+}
+
+// sendBulkResults 以文件形式发送每个账号的执行结果
+func (b *Bot) sendBulkResults(job *bulkJob) {
+	ctx := withLocalizer(b.handlerCtx, job.loc)
+
+	var sb strings.Builder
+	for _, target := range job.targets {
+		result := job.loc.T("bulk.result_ok")
+		switch {
+		case !target.done:
+			result = job.loc.T("bulk.result_skipped")
+		case target.err != nil:
+			result = b.errorText(ctx, target.err)
+		}
+		fmt.Fprintf(&sb, "%s\t%s\n", target.username, result)
+	}
+
+	doc := tgbotapi.NewDocument(job.chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("bulk-%d-results.txt", job.id),
+		Bytes: []byte(sb.String()),
+	})
+	if _, err := b.send(b.handlerCtx, job.chatID, doc); err != nil {
+		logger.Warnf("bulk job %d: failed to send results file: %v", job.id, err)
+	}
+}
+
+// bulkStopKeyboard 进度消息上的停止按钮
+func bulkStopKeyboard(job *bulkJob) *tgbotapi.InlineKeyboardMarkup {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(job.loc.T("bulk.stop"), CallbackAdminBulkStop+":"+strconv.FormatUint(job.id, 10)),
+		),
+	)
+	return &keyboard
+}
+
+// handleBulkStop 停止批量操作任务，只有发起人可以停止
+func (b *Bot) handleBulkStop(ctx context.Context, parts []string, currentUser *user.User) CallbackResponse {
+	id, err := strconv.ParseUint(getCallbackParam(parts, 2), 10, 64)
+	if err != nil {
+		return CallbackResponse{Answer: b.t(ctx, "common.invalid_params"), ShowAlert: true}
+	}
+
+	switch err := b.bulkJobs.cancel(id, currentUser.TelegramID); {
+	case errors.Is(err, errJobNotFound):
+		return CallbackResponse{Answer: b.t(ctx, "bulk.already_finished")}
+	case err != nil:
+		return CallbackResponse{Answer: b.t(ctx, "common.job_not_owner"), ShowAlert: true}
+	}
+	return CallbackResponse{Answer: b.t(ctx, "bulk.stopping")}
+}
+
+// startBulkFromList 以账号列表当前的筛选和搜索结果发起批量操作
+func (b *Bot) startBulkFromList(ctx context.Context, currentUser *user.User, view listView) CallbackResponse {
+	// 默认状态的编码为空字符串，这里保存完整的状态以区分是否从列表发起
+	raw := string([]byte{view.filter, view.sort}) + view.search
+	return b.startWizard(ctx, currentUser, WizardBulkAccounts, conversation.Payload{ListView: raw})
+}
+
+// handleBulk 处理 /bulk 命令，启动批量操作向导
+func (b *Bot) handleBulk(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+	resp := b.startWizard(ctx, currentUserFromContext(ctx), WizardBulkAccounts, conversation.Payload{})
+	if resp.EditText == "" {
+		return resp.Answer, nil
+	}
+	b.sendWithMarkup(ctx, msg.Chat.ID, resp.EditText, resp.EditMarkup)
+	return "", nil
+}
@@ -9,22 +9,16 @@ import (
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"emby-telegram/internal/account"
+	"emby-telegram/internal/conversation"
+	"emby-telegram/internal/invitecode"
 	"emby-telegram/internal/user"
 	"emby-telegram/pkg/timeutil"
 )
 
 // handleAdminCallback 处理管理员相关回调
 func (b *Bot) handleAdminCallback(ctx context.Context, query *tgbotapi.CallbackQuery, parts []string, currentUser *user.User) CallbackResponse {
-	// 检查管理员权限
-	if !currentUser.IsAdmin() {
-		return CallbackResponse{
-			Answer:    "此功能需要管理员权限",
-			ShowAlert: true,
-		}
-	}
-
 	if len(parts) < 2 {
-		return CallbackResponse{Answer: "无效的操作", ShowAlert: true}
+		return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
 	}
 
 	subAction := parts[1]
@@ -37,23 +31,27 @@ func (b *Bot) handleAdminCallback(ctx context.Context, query *tgbotapi.CallbackQ
 		if len(parts) >= 3 {
 			page = strToInt(parts[2])
 		}
-		return b.showUsersList(ctx, page)
+		return b.showUsersList(ctx, page, usersListSpec.parse(listViewParam(parts, 3)))
+	case "usersearch":
+		return b.startListSearch(ctx, currentUser, usersListSpec, usersListSpec.parse(listViewParam(parts, 2)))
 	case "user":
 		if len(parts) < 3 {
-			return CallbackResponse{Answer: "无效的操作", ShowAlert: true}
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
 		}
 		userID := strToUint(parts[2])
 		page := 1
 		if len(parts) >= 4 {
 			page = strToInt(parts[3])
 		}
-		return b.showUserDetail(ctx, userID, page)
+		return b.showUserDetail(ctx, userID, page, usersListSpec.parse(listViewParam(parts, 4)))
 	case "accounts":
 		page := 1
 		if len(parts) >= 3 {
 			page = strToInt(parts[2])
 		}
-		return b.showAllAccountsList(ctx, page)
+		return b.showAllAccountsList(ctx, page, accountsListSpec.parse(listViewParam(parts, 3)))
+	case "accsearch":
+		return b.startListSearch(ctx, currentUser, accountsListSpec, accountsListSpec.parse(listViewParam(parts, 2)))
 	case "stats":
 		return b.showStats(ctx)
 	case "emby":
@@ -61,38 +59,114 @@ func (b *Bot) handleAdminCallback(ctx context.Context, query *tgbotapi.CallbackQ
 	case "playing":
 		return b.showPlayingStats(ctx)
 	case "updatepolicies":
-		return b.handleUpdatePoliciesCallback(ctx)
+		return b.handleUpdatePoliciesCallback(ctx, parts)
+	case "tpls":
+		return b.showPolicyTemplates(ctx)
+	case "tpl":
+		if len(parts) < 3 {
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+		}
+		return b.showPolicyTemplateDetail(ctx, strToUint(parts[2]))
+	case "tplset":
+		if len(parts) < 4 {
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+		}
+		return b.handlePolicyTemplateSet(ctx, strToUint(parts[2]), parts[3], getCallbackParam(parts, 4))
+	case "tplclone":
+		if len(parts) < 3 {
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+		}
+		return b.startClonePolicyTemplate(ctx, currentUser, strToUint(parts[2]))
+	case "tpldef":
+		if len(parts) < 3 {
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+		}
+		return b.handleSetDefaultPolicyTemplate(ctx, strToUint(parts[2]))
+	case "tpldel":
+		if len(parts) < 3 {
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+		}
+		return b.handleDeletePolicyTemplate(ctx, strToUint(parts[2]), getCallbackParam(parts, 3) == "yes")
+	case "libs":
+		if len(parts) < 3 {
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+		}
+		return b.showAccountLibraries(ctx, strToUint(parts[2]))
+	case "lib":
+		if len(parts) < 4 {
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+		}
+		return b.handleAccountLibraryToggle(ctx, strToUint(parts[2]), parts[3])
+	case "ovr":
+		if len(parts) < 3 {
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+		}
+		return b.handleAccountSettings(ctx, strToUint(parts[2]), getCallbackParam(parts, 3), getCallbackParam(parts, 4))
+	case "drift":
+		return b.showPolicyDriftList(ctx)
+	case "broadcast":
+		return b.startWizard(ctx, currentUser, WizardBroadcast, conversation.Payload{})
+	case "bcstop":
+		return b.handleBroadcastStop(ctx, parts, currentUser)
+	case "accbulk":
+		return b.startBulkFromList(ctx, currentUser, accountsListSpec.parse(listViewParam(parts, 2)))
+	case "bulkstop":
+		return b.handleBulkStop(ctx, parts, currentUser)
+	case "importrun":
+		return b.handleImportRun(ctx, query, currentUser)
+	case "acctransfer":
+		if len(parts) < 3 {
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+		}
+		return b.startWizard(ctx, currentUser, WizardTransferAccount, conversation.Payload{AccountID: strToUint(parts[2])})
+	case "driftacc":
+		if len(parts) < 3 {
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+		}
+		return b.showPolicyDriftDetail(ctx, strToUint(parts[2]))
+	case "driftfix":
+		if len(parts) < 3 {
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+		}
+		return b.handleFixPolicyDrift(ctx, strToUint(parts[2]), currentUser.TelegramID)
+	case "driftaccept":
+		if len(parts) < 3 {
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+		}
+		return b.handleAcceptPolicyDrift(ctx, strToUint(parts[2]), currentUser.TelegramID)
 	case "account":
 		if len(parts) < 3 {
-			return CallbackResponse{Answer: "无效的操作", ShowAlert: true}
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
 		}
 		accountID := strToUint(parts[2])
 		page := 1
 		if len(parts) >= 4 {
 			page = strToInt(parts[3])
 		}
-		return b.showAdminAccountDetail(ctx, accountID, page)
+		return b.showAdminAccountDetail(ctx, accountID, page, accountsListSpec.parse(listViewParam(parts, 4)))
 	case "suspend":
 		if len(parts) < 3 {
-			return CallbackResponse{Answer: "无效的操作", ShowAlert: true}
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
 		}
 		accountID := strToUint(parts[2])
-		return b.handleSuspendAccount(ctx, accountID)
+		return b.handleSuspendAccount(ctx, currentUser, accountID)
 	case "activate":
 		if len(parts) < 3 {
-			return CallbackResponse{Answer: "无效的操作", ShowAlert: true}
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
 		}
 		accountID := strToUint(parts[2])
-		return b.handleActivateAccount(ctx, accountID)
+		return b.handleActivateAccount(ctx, currentUser, accountID)
 	case "invitecodes":
 		page := 1
 		if len(parts) >= 3 {
 			page = strToInt(parts[2])
 		}
-		return b.showInviteCodesList(ctx, page)
+		return b.showInviteCodesList(ctx, page, inviteCodesListSpec.parse(listViewParam(parts, 3)))
+	case "codesearch":
+		return b.startListSearch(ctx, currentUser, inviteCodesListSpec, inviteCodesListSpec.parse(listViewParam(parts, 2)))
 	case "invitecode":
 		if len(parts) < 3 {
-			return CallbackResponse{Answer: "无效的操作", ShowAlert: true}
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
 		}
 		code := parts[2]
 		return b.showInviteCodeDetail(ctx, code)
@@ -100,28 +174,26 @@ func (b *Bot) handleAdminCallback(ctx context.Context, query *tgbotapi.CallbackQ
 		return b.showCreateInviteCodeMenu(ctx)
 	case "quickcreate":
 		if len(parts) < 3 {
-			return CallbackResponse{Answer: "无效的操作", ShowAlert: true}
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
 		}
 		preset := parts[2]
 		return b.handleQuickCreateInviteCode(ctx, preset, currentUser.TelegramID)
 	case "revokecode":
 		if len(parts) < 3 {
-			return CallbackResponse{Answer: "无效的操作", ShowAlert: true}
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
 		}
 		code := parts[2]
 		return b.handleRevokeInviteCode(ctx, code)
 	default:
-		return CallbackResponse{Answer: "未知操作", ShowAlert: true}
+		return CallbackResponse{Answer: b.t(ctx, "common.unknown_action"), ShowAlert: true}
 	}
 }
 
 // showAdminMenu 显示管理员菜单
 func (b *Bot) showAdminMenu(ctx context.Context) CallbackResponse {
-	text := `🔑 <b>管理员菜单</b>
-
-请选择管理功能：`
+	text := b.t(ctx, "admin.menu")
 
-	keyboard := AdminMenuKeyboard()
+	keyboard := AdminMenuKeyboard(b.loc(ctx), b.permissionChecker(ctx))
 
 	return CallbackResponse{
 		EditText:   text,
@@ -130,30 +202,35 @@ func (b *Bot) showAdminMenu(ctx context.Context) CallbackResponse {
 }
 
 // showUsersList 显示用户列表
-func (b *Bot) showUsersList(ctx context.Context, page int) CallbackResponse {
+func (b *Bot) showUsersList(ctx context.Context, page int, view listView) CallbackResponse {
 	limit := 5
 	offset := (page - 1) * limit
 
-	users, err := b.userService.List(ctx, offset, limit)
+	users, totalCount, err := b.userService.ListByQuery(ctx, user.ListQuery{
+		Filter: user.Filter(usersListSpec.filterValue(view)),
+		Sort:   user.Sort(usersListSpec.sortValue(view)),
+		Search: view.search,
+		Offset: offset,
+		Limit:  limit,
+	})
 	if err != nil {
 		return CallbackResponse{
-			Answer:    "获取用户列表失败",
+			Answer:    b.t(ctx, "users.list_failed"),
 			ShowAlert: true,
 		}
 	}
 
-	totalCount, _ := b.userService.Count(ctx)
-
-	if len(users) == 0 {
+	if len(users) == 0 && view.isDefault() {
 		return CallbackResponse{
-			Answer:    "没有找到用户",
+			Answer:    b.t(ctx, "users.empty"),
 			ShowAlert: true,
 		}
 	}
 
-	text := fmt.Sprintf(`👥 <b>用户列表</b>
-
-共 %d 个用户，点击用户查看详情`, totalCount)
+	text := b.t(ctx, "users.menu_title", totalCount) + b.listSummary(ctx, usersListSpec, view)
+	if len(users) == 0 {
+		text += b.t(ctx, "list.no_match")
+	}
 
 	var rows [][]tgbotapi.InlineKeyboardButton
 
@@ -173,7 +250,7 @@ func (b *Bot) showUsersList(ctx context.Context, page int) CallbackResponse {
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
 				buttonText,
-				CallbackAdminUserDetail+":"+fmt.Sprintf("%d:%d", u.ID, page),
+				CallbackAdminUserDetail+":"+fmt.Sprintf("%d:%d", u.ID, page)+view.suffix(),
 			),
 		))
 	}
@@ -181,22 +258,13 @@ func (b *Bot) showUsersList(ctx context.Context, page int) CallbackResponse {
 	totalPages := (int(totalCount) + limit - 1) / limit
 
 	if totalPages > 1 {
-		var pageRow []tgbotapi.InlineKeyboardButton
-		if page > 1 {
-			pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData("⬅️ 上一页", CallbackAdminUsers+":"+fmt.Sprintf("%d", page-1)))
-		}
-		pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData(
-			fmt.Sprintf("%d/%d", page, totalPages),
-			"page:current",
-		))
-		if page < totalPages {
-			pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData("➡️ 下一页", CallbackAdminUsers+":"+fmt.Sprintf("%d", page+1)))
-		}
-		rows = append(rows, pageRow)
+		rows = append(rows, b.listPageRow(ctx, CallbackAdminUsers, page, totalPages, view))
 	}
 
+	rows = append(rows, b.listControlRows(ctx, usersListSpec, view)...)
+
 	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("⬅️ 返回", CallbackAdminMenu),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.back"), CallbackAdminMenu),
 	))
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
@@ -208,11 +276,11 @@ func (b *Bot) showUsersList(ctx context.Context, page int) CallbackResponse {
 }
 
 // showUserDetail 显示用户详情
-func (b *Bot) showUserDetail(ctx context.Context, userID uint, page int) CallbackResponse {
+func (b *Bot) showUserDetail(ctx context.Context, userID uint, page int, view listView) CallbackResponse {
 	u, err := b.userService.Get(ctx, userID)
 	if err != nil {
 		return CallbackResponse{
-			Answer:    "获取用户信息失败",
+			Answer:    b.t(ctx, "users.get_failed"),
 			ShowAlert: true,
 		}
 	}
@@ -222,24 +290,17 @@ func (b *Bot) showUserDetail(ctx context.Context, userID uint, page int) Callbac
 		roleEmoji = "👑"
 	}
 
-	statusText := "正常"
+	statusText := b.t(ctx, "users.status_normal")
 	if u.IsBlocked {
-		statusText = "已封禁"
+		statusText = b.t(ctx, "users.status_blocked")
+	}
+	if u.HasBlockedBot() {
+		statusText += b.t(ctx, "users.bot_blocked", timeutil.FormatDateTime(*u.BotBlockedAt))
 	}
 
 	accountCount, _ := b.accountService.CountByUser(ctx, u.ID)
 
-	text := fmt.Sprintf(`👤 <b>用户详情</b>
-
-%s <b>%s</b>
-
-<b>Telegram ID:</b> <code>%d</code>
-<b>用户名:</b> @%s
-<b>姓名:</b> %s
-<b>角色:</b> %s
-<b>状态:</b> %s
-<b>账号数量:</b> %d 个
-<b>注册时间:</b> %s`,
+	text := b.t(ctx, "users.detail",
 		roleEmoji,
 		u.DisplayName(),
 		u.TelegramID,
@@ -251,7 +312,7 @@ func (b *Bot) showUserDetail(ctx context.Context, userID uint, page int) Callbac
 		timeutil.FormatDateTime(u.CreatedAt),
 	)
 
-	keyboard := BackButton(CallbackAdminUsers + ":" + fmt.Sprintf("%d", page))
+	keyboard := BackButton(b.loc(ctx), listCallback(CallbackAdminUsers, page, view))
 
 	return CallbackResponse{
 		EditText:   text,
@@ -260,30 +321,41 @@ func (b *Bot) showUserDetail(ctx context.Context, userID uint, page int) Callbac
 }
 
 // showAllAccountsList 显示所有账号列表
-func (b *Bot) showAllAccountsList(ctx context.Context, page int) CallbackResponse {
+func (b *Bot) showAllAccountsList(ctx context.Context, page int, view listView) CallbackResponse {
 	limit := 5
 	offset := (page - 1) * limit
 
-	accounts, err := b.accountService.ListAllWithUser(ctx, offset, limit)
+	currentUser := currentUserFromContext(ctx)
+	accounts, totalCount, err := b.accountService.ListManaged(ctx, currentUser.ID, account.ListQuery{
+		Filter: account.Filter(accountsListSpec.filterValue(view)),
+		Sort:   account.Sort(accountsListSpec.sortValue(view)),
+		Search: view.search,
+		Offset: offset,
+		Limit:  limit,
+	})
 	if err != nil {
 		return CallbackResponse{
-			Answer:    "获取账号列表失败",
+			Answer:    b.t(ctx, "accounts.list_failed"),
 			ShowAlert: true,
 		}
 	}
 
-	totalCount, _ := b.accountService.Count(ctx)
-
-	if len(accounts) == 0 {
+	if len(accounts) == 0 && view.isDefault() {
 		return CallbackResponse{
-			Answer:    "没有找到账号",
+			Answer:    b.t(ctx, "admin_accounts.empty"),
 			ShowAlert: true,
 		}
 	}
 
-	text := fmt.Sprintf(`📋 <b>所有账号列表</b>
+	title := b.t(ctx, "admin_accounts.title_all")
+	if !b.userService.Can(ctx, currentUser, user.PermAccountView) {
+		title = b.t(ctx, "admin_accounts.title_managed")
+	}
 
-共 %d 个账号，点击账号查看详情`, totalCount)
+	text := b.t(ctx, "admin_accounts.menu_title", title, totalCount) + b.listSummary(ctx, accountsListSpec, view)
+	if len(accounts) == 0 {
+		text += b.t(ctx, "list.no_match")
+	}
 
 	var rows [][]tgbotapi.InlineKeyboardButton
 
@@ -295,7 +367,7 @@ func (b *Bot) showAllAccountsList(ctx context.Context, page int) CallbackRespons
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
 				buttonText,
-				CallbackAdminAccountDetail+":"+fmt.Sprintf("%d:%d", acc.ID, page),
+				CallbackAdminAccountDetail+":"+fmt.Sprintf("%d:%d", acc.ID, page)+view.suffix(),
 			),
 		))
 	}
@@ -303,22 +375,19 @@ func (b *Bot) showAllAccountsList(ctx context.Context, page int) CallbackRespons
 	totalPages := (int(totalCount) + limit - 1) / limit
 
 	if totalPages > 1 {
-		var pageRow []tgbotapi.InlineKeyboardButton
-		if page > 1 {
-			pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData("⬅️ 上一页", CallbackAdminAccounts+":"+fmt.Sprintf("%d", page-1)))
-		}
-		pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData(
-			fmt.Sprintf("%d/%d", page, totalPages),
-			"page:current",
+		rows = append(rows, b.listPageRow(ctx, CallbackAdminAccounts, page, totalPages, view))
+	}
+
+	rows = append(rows, b.listControlRows(ctx, accountsListSpec, view)...)
+
+	if totalCount > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "bulk.list_button", totalCount), CallbackAdminAccountsBulk+view.suffix()),
 		))
-		if page < totalPages {
-			pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData("➡️ 下一页", CallbackAdminAccounts+":"+fmt.Sprintf("%d", page+1)))
-		}
-		rows = append(rows, pageRow)
 	}
 
 	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("⬅️ 返回", CallbackAdminMenu),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.back"), CallbackAdminMenu),
 	))
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
@@ -347,20 +416,7 @@ func (b *Bot) showStats(ctx context.Context) CallbackResponse {
 		avgAccounts = float64(totalAccounts) / float64(totalUsers)
 	}
 
-	text := fmt.Sprintf(`📊 <b>系统统计</b>
-
-<b>用户统计:</b>
-• 总用户数: %d
-• 管理员: %d 👑
-• 普通用户: %d 👤
-
-<b>账号统计:</b>
-• 总账号数: %d
-• 激活账号: %d ✅
-• 暂停账号: %d ⏸️
-• 过期账号: %d ❌
-
-<b>平均账号数:</b> %.2f 个/用户`,
+	text := b.t(ctx, "stats.menu",
 		totalUsers,
 		adminCount,
 		userCount,
@@ -369,9 +425,10 @@ func (b *Bot) showStats(ctx context.Context) CallbackResponse {
 		suspendedAccounts,
 		expiredAccounts,
 		avgAccounts,
+		b.formatQueueStats(ctx),
 	)
 
-	keyboard := BackButton(CallbackAdminMenu)
+	keyboard := BackButton(b.loc(ctx), CallbackAdminMenu)
 
 	return CallbackResponse{
 		EditText:   text,
@@ -383,23 +440,19 @@ func (b *Bot) showStats(ctx context.Context) CallbackResponse {
 func (b *Bot) showEmbyMenu(ctx context.Context) CallbackResponse {
 	if b.embyClient == nil {
 		return CallbackResponse{
-			Answer:    "Emby 同步未启用",
+			Answer:    b.t(ctx, "error.sync_disabled"),
 			ShowAlert: true,
 		}
 	}
 
-	status := "✅ 已连接"
+	status := b.t(ctx, "emby.connected")
 	if err := b.embyClient.Ping(ctx); err != nil {
-		status = fmt.Sprintf("❌ 连接失败: %v", err)
+		status = b.t(ctx, "emby.connect_failed", b.errorText(ctx, err))
 	}
 
-	text := fmt.Sprintf(`🎬 <b>Emby 管理</b>
-
-<b>连接状态:</b> %s
+	text := b.t(ctx, "emby.menu", status)
 
-请选择操作：`, status)
-
-	keyboard := EmbyMenuKeyboard()
+	keyboard := EmbyMenuKeyboard(b.loc(ctx), b.permissionChecker(ctx))
 
 	return CallbackResponse{
 		EditText:   text,
@@ -411,7 +464,7 @@ func (b *Bot) showEmbyMenu(ctx context.Context) CallbackResponse {
 func (b *Bot) showPlayingStats(ctx context.Context) CallbackResponse {
 	if b.embyClient == nil {
 		return CallbackResponse{
-			Answer:    "Emby 同步未启用",
+			Answer:    b.t(ctx, "error.sync_disabled"),
 			ShowAlert: true,
 		}
 	}
@@ -419,13 +472,13 @@ func (b *Bot) showPlayingStats(ctx context.Context) CallbackResponse {
 	sessions, err := b.embyClient.GetSessions(ctx)
 	if err != nil {
 		return CallbackResponse{
-			Answer:    fmt.Sprintf("获取播放统计失败: %v", err),
+			Answer:    b.t(ctx, "playing.stats_failed", b.errorText(ctx, err)),
 			ShowAlert: true,
 		}
 	}
 
 	var builder strings.Builder
-	builder.WriteString("📊 <b>当前播放统计</b>\n\n")
+	builder.WriteString(b.t(ctx, "playing.stats_title"))
 
 	playingCount := 0
 	for _, session := range sessions {
@@ -434,12 +487,12 @@ func (b *Bot) showPlayingStats(ctx context.Context) CallbackResponse {
 			builder.WriteString(fmt.Sprintf("👤 <b>%s</b>\n", session.UserName))
 			builder.WriteString(fmt.Sprintf("📺 %s\n", session.NowPlayingItem.GetDisplayName()))
 			builder.WriteString(fmt.Sprintf("💻 %s (%s)\n", session.DeviceName, session.Client))
-			builder.WriteString(fmt.Sprintf("⏱ 进度: %.1f%%\n", session.GetProgress()))
+			builder.WriteString(b.t(ctx, "playing.progress", session.GetProgress()))
 
 			if session.TranscodingInfo != nil {
-				playMethod := "直接播放"
+				playMethod := b.t(ctx, "playing.direct")
 				if !session.TranscodingInfo.IsVideoDirect || !session.TranscodingInfo.IsAudioDirect {
-					playMethod = "转码中"
+					playMethod = b.t(ctx, "playing.transcoding_short")
 				}
 				builder.WriteString(fmt.Sprintf("🎬 %s\n", playMethod))
 			}
@@ -448,12 +501,12 @@ func (b *Bot) showPlayingStats(ctx context.Context) CallbackResponse {
 	}
 
 	if playingCount == 0 {
-		builder.WriteString("当前没有用户在播放内容")
+		builder.WriteString(b.t(ctx, "playing.nobody"))
 	} else {
-		builder.WriteString(fmt.Sprintf("共 %d 个用户正在播放", playingCount))
+		builder.WriteString(b.t(ctx, "playing.count", playingCount))
 	}
 
-	keyboard := BackButton(CallbackAdminEmby)
+	keyboard := BackButton(b.loc(ctx), CallbackAdminEmby)
 
 	return CallbackResponse{
 		EditText:   builder.String(),
@@ -461,70 +514,40 @@ func (b *Bot) showPlayingStats(ctx context.Context) CallbackResponse {
 	}
 }
 
-// handleUpdatePoliciesCallback 处理批量更新策略回调
-func (b *Bot) handleUpdatePoliciesCallback(ctx context.Context) CallbackResponse {
-	if b.embyClient == nil {
-		return CallbackResponse{
-			Answer:    "Emby 同步未启用",
-			ShowAlert: true,
-		}
-	}
-
-	updated, failed, err := b.embyClient.BatchUpdateNonAdminPolicies(ctx)
-	if err != nil {
-		return CallbackResponse{
-			Answer:    fmt.Sprintf("批量更新策略失败: %v", err),
-			ShowAlert: true,
-		}
-	}
-
-	text := fmt.Sprintf(`✅ <b>批量更新用户策略完成</b>
-
-• 成功更新: %d 个用户
-• 失败: %d 个用户
-• 已跳过管理员账号`, updated, failed)
-
-	keyboard := BackButton(CallbackAdminEmby)
-
-	return CallbackResponse{
-		EditText:   text,
-		EditMarkup: &keyboard,
-	}
-}
-
 // showAdminAccountDetail 显示管理员账号详情
-func (b *Bot) showAdminAccountDetail(ctx context.Context, accountID uint, page int) CallbackResponse {
+func (b *Bot) showAdminAccountDetail(ctx context.Context, accountID uint, page int, view listView) CallbackResponse {
 	acc, err := b.accountService.GetWithUser(ctx, accountID)
 	if err != nil {
 		return CallbackResponse{
-			Answer:    "获取账号信息失败",
+			Answer:    b.t(ctx, "common.get_account_failed"),
 			ShowAlert: true,
 		}
 	}
 
 	status := getStatusEmoji(string(acc.Status))
-	expireInfo := timeutil.FormatExpireTime(acc.ExpireAt)
+	expireInfo := b.expireText(ctx, acc.ExpireAt)
 	createdAt := timeutil.FormatDateTime(acc.CreatedAt)
 
-	syncStatus := "✅ 已同步"
+	syncStatus := b.t(ctx, "admin_account.synced")
 	if acc.EmbyUserID == "" {
-		syncStatus = "❌ 未同步"
+		syncStatus = b.t(ctx, "account.not_synced")
 	} else if acc.SyncError != "" {
-		syncStatus = fmt.Sprintf("⚠️ 同步失败: %s", acc.SyncError)
+		syncStatus = b.t(ctx, "admin_account.sync_failed", acc.SyncError)
 	}
 
 	ownerInfo := fmt.Sprintf("%s (ID: %d)", acc.GetOwnerDisplayName(), acc.OwnerTelegramID)
 
-	text := fmt.Sprintf(`📝 <b>账号详情</b>
+	libraries := b.t(ctx, "settings.folders_all")
+	if access, err := b.accountService.GetLibraryAccess(ctx, acc.ID); err == nil {
+		if !access.AllFolders {
+			libraries = b.t(ctx, "settings.folders_some", len(access.FolderIDs))
+		}
+		if access.Overridden {
+			libraries += b.t(ctx, "admin_account.libraries_override")
+		}
+	}
 
-<b>用户名:</b> <code>%s</code>
-<b>状态:</b> %s %s
-<b>有效期:</b> %s
-<b>最大设备数:</b> %d
-<b>创建时间:</b> %s
-<b>所属用户:</b> %s
-<b>Emby 同步状态:</b> %s
-<b>Emby 用户ID:</b> <code>%s</code>`,
+	text := b.t(ctx, "admin_account.detail",
 		acc.Username,
 		status,
 		acc.Status,
@@ -534,9 +557,11 @@ func (b *Bot) showAdminAccountDetail(ctx context.Context, accountID uint, page i
 		ownerInfo,
 		syncStatus,
 		acc.EmbyUserID,
+		libraries,
 	)
+	text += b.transferHistoryText(ctx, acc.ID)
 
-	keyboard := AdminAccountActionsKeyboard(acc.ID, string(acc.Status), page)
+	keyboard := AdminAccountActionsKeyboard(b.loc(ctx), acc.ID, string(acc.Status), listCallback(CallbackAdminAccounts, page, view), b.permissionChecker(ctx))
 
 	return CallbackResponse{
 		EditText:   text,
@@ -545,10 +570,10 @@ func (b *Bot) showAdminAccountDetail(ctx context.Context, accountID uint, page i
 }
 
 // handleSuspendAccount 处理停用账号
-func (b *Bot) handleSuspendAccount(ctx context.Context, accountID uint) CallbackResponse {
-	if err := b.accountService.Suspend(ctx, accountID); err != nil {
+func (b *Bot) handleSuspendAccount(ctx context.Context, currentUser *user.User, accountID uint) CallbackResponse {
+	if err := b.accountService.SuspendAs(ctx, currentUser.ID, accountID); err != nil {
 		return CallbackResponse{
-			Answer:    fmt.Sprintf("停用失败: %v", err),
+			Answer:    b.t(ctx, "admin_account.suspend_failed", b.errorText(ctx, err)),
 			ShowAlert: true,
 		}
 	}
@@ -556,23 +581,20 @@ func (b *Bot) handleSuspendAccount(ctx context.Context, accountID uint) Callback
 	acc, _ := b.accountService.Get(ctx, accountID)
 
 	return CallbackResponse{
-		Answer: "账号已停用",
-		EditText: fmt.Sprintf(`✅ <b>账号已停用</b>
-
-账号 <b>%s</b> 已被停用
-如需重新激活，请点击"激活"按钮`, acc.Username),
+		Answer:   b.t(ctx, "admin_account.suspended_short"),
+		EditText: b.t(ctx, "admin_account.suspended", acc.Username),
 		EditMarkup: func() *tgbotapi.InlineKeyboardMarkup {
-			kb := AdminAccountActionsKeyboard(acc.ID, string(acc.Status), 1)
+			kb := AdminAccountActionsKeyboard(b.loc(ctx), acc.ID, string(acc.Status), CallbackAdminAccounts+":1", b.permissionChecker(ctx))
 			return &kb
 		}(),
 	}
 }
 
 // handleActivateAccount 处理激活账号
-func (b *Bot) handleActivateAccount(ctx context.Context, accountID uint) CallbackResponse {
-	if err := b.accountService.Activate(ctx, accountID); err != nil {
+func (b *Bot) handleActivateAccount(ctx context.Context, currentUser *user.User, accountID uint) CallbackResponse {
+	if err := b.accountService.ActivateAs(ctx, currentUser.ID, accountID); err != nil {
 		return CallbackResponse{
-			Answer:    fmt.Sprintf("激活失败: %v", err),
+			Answer:    b.t(ctx, "admin_account.activate_failed", b.errorText(ctx, err)),
 			ShowAlert: true,
 		}
 	}
@@ -580,46 +602,43 @@ func (b *Bot) handleActivateAccount(ctx context.Context, accountID uint) Callbac
 	acc, _ := b.accountService.Get(ctx, accountID)
 
 	return CallbackResponse{
-		Answer: "账号已激活",
-		EditText: fmt.Sprintf(`✅ <b>账号已激活</b>
-
-账号 <b>%s</b> 已被激活
-如需停用，请点击"停用"按钮`, acc.Username),
+		Answer:   b.t(ctx, "admin_account.activated_short"),
+		EditText: b.t(ctx, "admin_account.activated", acc.Username),
 		EditMarkup: func() *tgbotapi.InlineKeyboardMarkup {
-			kb := AdminAccountActionsKeyboard(acc.ID, string(acc.Status), 1)
+			kb := AdminAccountActionsKeyboard(b.loc(ctx), acc.ID, string(acc.Status), CallbackAdminAccounts+":1", b.permissionChecker(ctx))
 			return &kb
 		}(),
 	}
 }
 
 // showInviteCodesList 显示邀请码列表
-func (b *Bot) showInviteCodesList(ctx context.Context, page int) CallbackResponse {
+func (b *Bot) showInviteCodesList(ctx context.Context, page int, view listView) CallbackResponse {
 	limit := 10
 	offset := (page - 1) * limit
 
-	codes, err := b.inviteCodeService.List(ctx, offset, limit)
+	codes, totalCount, err := b.inviteCodeService.ListByQuery(ctx, invitecode.ListQuery{
+		Filter: invitecode.Filter(inviteCodesListSpec.filterValue(view)),
+		Sort:   invitecode.Sort(inviteCodesListSpec.sortValue(view)),
+		Search: view.search,
+		Offset: offset,
+		Limit:  limit,
+	})
 	if err != nil {
 		return CallbackResponse{
-			Answer:    "获取邀请码列表失败",
+			Answer:    b.t(ctx, "codes.list_failed"),
 			ShowAlert: true,
 		}
 	}
 
-	totalCount, _ := b.inviteCodeService.Count(ctx)
-
-	if len(codes) == 0 {
-		text := `🎟️ <b>邀请码管理</b>
-
-暂无邀请码
-
-点击下方按钮创建第一个邀请码`
+	if len(codes) == 0 && view.isDefault() {
+		text := b.t(ctx, "codes.empty")
 
 		keyboard := tgbotapi.NewInlineKeyboardMarkup(
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("➕ 创建邀请码", CallbackAdminCreateInviteCode),
+				tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "codes.create_button"), CallbackAdminCreateInviteCode),
 			),
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("⬅️ 返回", CallbackAdminMenu),
+				tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.back"), CallbackAdminMenu),
 			),
 		)
 
@@ -629,9 +648,10 @@ func (b *Bot) showInviteCodesList(ctx context.Context, page int) CallbackRespons
 		}
 	}
 
-	text := fmt.Sprintf(`🎟️ <b>邀请码列表</b>
-
-共 %d 个邀请码，点击查看详情`, totalCount)
+	text := b.t(ctx, "codes.menu_title", totalCount) + b.listSummary(ctx, inviteCodesListSpec, view)
+	if len(codes) == 0 {
+		text += b.t(ctx, "list.no_match")
+	}
 
 	var rows [][]tgbotapi.InlineKeyboardButton
 
@@ -665,26 +685,17 @@ func (b *Bot) showInviteCodesList(ctx context.Context, page int) CallbackRespons
 	totalPages := (int(totalCount) + limit - 1) / limit
 
 	if totalPages > 1 {
-		var pageRow []tgbotapi.InlineKeyboardButton
-		if page > 1 {
-			pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData("⬅️ 上一页", CallbackAdminInviteCodes+":"+fmt.Sprintf("%d", page-1)))
-		}
-		pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData(
-			fmt.Sprintf("%d/%d", page, totalPages),
-			"page:current",
-		))
-		if page < totalPages {
-			pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData("➡️ 下一页", CallbackAdminInviteCodes+":"+fmt.Sprintf("%d", page+1)))
-		}
-		rows = append(rows, pageRow)
+		rows = append(rows, b.listPageRow(ctx, CallbackAdminInviteCodes, page, totalPages, view))
 	}
 
+	rows = append(rows, b.listControlRows(ctx, inviteCodesListSpec, view)...)
+
 	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("➕ 创建邀请码", CallbackAdminCreateInviteCode),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "codes.create_button"), CallbackAdminCreateInviteCode),
 	))
 
 	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("⬅️ 返回", CallbackAdminMenu),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.back"), CallbackAdminMenu),
 	))
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
@@ -700,46 +711,37 @@ func (b *Bot) showInviteCodeDetail(ctx context.Context, code string) CallbackRes
 	inviteCode, err := b.inviteCodeService.GetWithUsage(ctx, code)
 	if err != nil {
 		return CallbackResponse{
-			Answer:    "获取邀请码详情失败",
+			Answer:    b.t(ctx, "codes.detail_failed"),
 			ShowAlert: true,
 		}
 	}
 
 	statusEmoji := "✅"
-	statusText := "激活"
+	statusText := b.t(ctx, "codes.status_active")
 	if inviteCode.Status == "revoked" {
 		statusEmoji = "🚫"
-		statusText = "已撤销"
+		statusText = b.t(ctx, "code.status_revoked")
 	} else if inviteCode.IsExpired() {
 		statusEmoji = "⏰"
-		statusText = "已过期"
+		statusText = b.t(ctx, "code.status_expired")
 	} else if inviteCode.IsExhausted() {
 		statusEmoji = "💯"
-		statusText = "已用完"
+		statusText = b.t(ctx, "code.status_exhausted")
 	}
 
 	usageText := ""
 	if inviteCode.MaxUses == -1 {
-		usageText = fmt.Sprintf("%d / ∞ (无限)", inviteCode.CurrentUses)
+		usageText = b.t(ctx, "codes.usage_unlimited", inviteCode.CurrentUses)
 	} else {
 		usageText = fmt.Sprintf("%d / %d", inviteCode.CurrentUses, inviteCode.MaxUses)
 	}
 
-	expireText := "永不过期"
+	expireText := b.t(ctx, "codes.never_expires")
 	if inviteCode.ExpireAt != nil {
-		expireText = timeutil.FormatExpireTime(inviteCode.ExpireAt)
+		expireText = b.expireText(ctx, inviteCode.ExpireAt)
 	}
 
-	text := fmt.Sprintf(`🎟️ <b>邀请码详情</b>
-
-%s <b>%s</b>
-
-<b>邀请码:</b> <code>%s</code>
-<b>状态:</b> %s
-<b>使用次数:</b> %s
-<b>有效期:</b> %s
-<b>描述:</b> %s
-<b>创建时间:</b> %s`,
+	text := b.t(ctx, "codes.detail",
 		statusEmoji,
 		inviteCode.Code,
 		inviteCode.Code,
@@ -751,13 +753,13 @@ func (b *Bot) showInviteCodeDetail(ctx context.Context, code string) CallbackRes
 	)
 
 	if len(inviteCode.UsageRecords) > 0 {
-		text += "\n\n<b>使用记录:</b>\n"
+		text += b.t(ctx, "codes.usage_title")
 		for i, record := range inviteCode.UsageRecords {
 			if i >= 5 {
-				text += fmt.Sprintf("\n... 还有 %d 条记录", len(inviteCode.UsageRecords)-5)
+				text += b.t(ctx, "code.usage_more", len(inviteCode.UsageRecords)-5)
 				break
 			}
-			text += fmt.Sprintf("• 用户 ID: %d, 时间: %s\n",
+			text += b.t(ctx, "codes.usage_item",
 				record.UserID,
 				timeutil.FormatDateTime(record.UsedAt),
 			)
@@ -768,12 +770,12 @@ func (b *Bot) showInviteCodeDetail(ctx context.Context, code string) CallbackRes
 
 	if inviteCode.Status == "active" && !inviteCode.IsExpired() && !inviteCode.IsExhausted() {
 		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🚫 撤销邀请码", CallbackAdminRevokeInviteCode+":"+code),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "codes.revoke_button"), CallbackAdminRevokeInviteCode+":"+code),
 		))
 	}
 
 	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("⬅️ 返回列表", CallbackAdminInviteCodes+":1"),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.back_to_list"), CallbackAdminInviteCodes+":1"),
 	))
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
@@ -786,34 +788,23 @@ func (b *Bot) showInviteCodeDetail(ctx context.Context, code string) CallbackRes
 
 // showCreateInviteCodeMenu 显示创建邀请码菜单
 func (b *Bot) showCreateInviteCodeMenu(ctx context.Context) CallbackResponse {
-	text := `➕ <b>创建邀请码</b>
-
-请选择创建方式：
-
-<b>快速预设：</b>
-• <b>单次使用</b> - 1次使用，30天有效
-• <b>标准多次</b> - 10次使用，30天有效
-• <b>长期多次</b> - 50次使用，90天有效
-• <b>无限使用</b> - 无限次，永不过期
-
-<b>自定义：</b>
-• 使用命令自定义参数`
+	text := b.t(ctx, "codes.create_menu")
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("1️⃣ 单次使用", CallbackAdminQuickCreateCode+":single"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "codes.preset_single"), CallbackAdminQuickCreateCode+":single"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔟 标准多次", CallbackAdminQuickCreateCode+":standard"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "codes.preset_standard"), CallbackAdminQuickCreateCode+":standard"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("💯 长期多次", CallbackAdminQuickCreateCode+":longterm"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "codes.preset_longterm"), CallbackAdminQuickCreateCode+":longterm"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("♾️ 无限使用", CallbackAdminQuickCreateCode+":unlimited"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "codes.preset_unlimited"), CallbackAdminQuickCreateCode+":unlimited"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("⬅️ 返回列表", CallbackAdminInviteCodes+":1"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.back_to_list"), CallbackAdminInviteCodes+":1"),
 		),
 	)
 
@@ -833,22 +824,22 @@ func (b *Bot) handleQuickCreateInviteCode(ctx context.Context, preset string, cr
 	case "single":
 		maxUses = 1
 		expireDays = 30
-		description = "单次使用邀请码"
+		description = b.t(ctx, "codes.desc_single")
 	case "standard":
 		maxUses = 10
 		expireDays = 30
-		description = "标准多次使用邀请码"
+		description = b.t(ctx, "codes.desc_standard")
 	case "longterm":
 		maxUses = 50
 		expireDays = 90
-		description = "长期多次使用邀请码"
+		description = b.t(ctx, "codes.desc_longterm")
 	case "unlimited":
 		maxUses = -1
 		expireDays = 0
-		description = "无限使用邀请码"
+		description = b.t(ctx, "codes.desc_unlimited")
 	default:
 		return CallbackResponse{
-			Answer:    "无效的预设选项",
+			Answer:    b.t(ctx, "codes.invalid_preset"),
 			ShowAlert: true,
 		}
 	}
@@ -856,31 +847,24 @@ func (b *Bot) handleQuickCreateInviteCode(ctx context.Context, preset string, cr
 	inviteCode, err := b.inviteCodeService.Generate(ctx, maxUses, expireDays, description, createdBy)
 	if err != nil {
 		return CallbackResponse{
-			Answer:    fmt.Sprintf("创建失败: %v", err),
+			Answer:    b.t(ctx, "codes.create_failed", b.errorText(ctx, err)),
 			ShowAlert: true,
 		}
 	}
 
 	usageText := ""
 	if maxUses == -1 {
-		usageText = "无限次"
+		usageText = b.t(ctx, "code.uses_unlimited")
 	} else {
-		usageText = fmt.Sprintf("%d次", maxUses)
+		usageText = b.t(ctx, "codes.uses_count", maxUses)
 	}
 
-	expireText := "永不过期"
+	expireText := b.t(ctx, "codes.never_expires")
 	if expireDays > 0 {
-		expireText = fmt.Sprintf("%d天后过期", expireDays)
+		expireText = b.t(ctx, "codes.expire_in", expireDays)
 	}
 
-	text := fmt.Sprintf(`✅ <b>邀请码创建成功！</b>
-
-<b>邀请码:</b> <code>%s</code>
-<b>使用次数:</b> %s
-<b>有效期:</b> %s
-<b>描述:</b> %s
-
-📋 点击邀请码可以复制`,
+	text := b.t(ctx, "codes.created",
 		inviteCode.Code,
 		usageText,
 		expireText,
@@ -889,16 +873,16 @@ func (b *Bot) handleQuickCreateInviteCode(ctx context.Context, preset string, cr
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📝 查看详情", CallbackAdminInviteCodeInfo+":"+inviteCode.Code),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "input.view_details"), CallbackAdminInviteCodeInfo+":"+inviteCode.Code),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("➕ 继续创建", CallbackAdminCreateInviteCode),
-			tgbotapi.NewInlineKeyboardButtonData("📋 邀请码列表", CallbackAdminInviteCodes+":1"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "codes.create_more"), CallbackAdminCreateInviteCode),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "codes.list_button"), CallbackAdminInviteCodes+":1"),
 		),
 	)
 
 	return CallbackResponse{
-		Answer:     "邀请码创建成功",
+		Answer:     b.t(ctx, "codes.created_short"),
 		EditText:   text,
 		EditMarkup: &keyboard,
 	}
@@ -908,27 +892,24 @@ func (b *Bot) handleQuickCreateInviteCode(ctx context.Context, preset string, cr
 func (b *Bot) handleRevokeInviteCode(ctx context.Context, code string) CallbackResponse {
 	if err := b.inviteCodeService.Revoke(ctx, code); err != nil {
 		return CallbackResponse{
-			Answer:    fmt.Sprintf("撤销失败: %v", err),
+			Answer:    b.t(ctx, "codes.revoke_failed", b.errorText(ctx, err)),
 			ShowAlert: true,
 		}
 	}
 
-	text := fmt.Sprintf(`✅ <b>邀请码已撤销</b>
-
-邀请码 <code>%s</code> 已被撤销
-该邀请码将无法继续使用`, code)
+	text := b.t(ctx, "codes.revoked", code)
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📝 查看详情", CallbackAdminInviteCodeInfo+":"+code),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "input.view_details"), CallbackAdminInviteCodeInfo+":"+code),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("⬅️ 返回列表", CallbackAdminInviteCodes+":1"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.back_to_list"), CallbackAdminInviteCodes+":1"),
 		),
 	)
 
 	return CallbackResponse{
-		Answer:     "邀请码已撤销",
+		Answer:     b.t(ctx, "codes.revoked_short"),
 		EditText:   text,
 		EditMarkup: &keyboard,
 	}
@@ -3,17 +3,17 @@ package bot
 
 import (
 	"context"
-	"fmt"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
+	"emby-telegram/internal/conversation"
 	"emby-telegram/internal/user"
 )
 
 // handleCreateCallback 处理创建账号回调
 func (b *Bot) handleCreateCallback(ctx context.Context, query *tgbotapi.CallbackQuery, parts []string, currentUser *user.User) CallbackResponse {
 	if len(parts) < 2 {
-		return CallbackResponse{Answer: "无效的操作", ShowAlert: true}
+		return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
 	}
 
 	subAction := parts[1]
@@ -22,42 +22,27 @@ func (b *Bot) handleCreateCallback(ctx context.Context, query *tgbotapi.Callback
 	case "start":
 		return b.startCreateAccount(ctx, currentUser)
 	default:
-		return CallbackResponse{Answer: "未知操作", ShowAlert: true}
+		return CallbackResponse{Answer: b.t(ctx, "common.unknown_action"), ShowAlert: true}
 	}
 }
 
 // startCreateAccount 开始创建账号流程
 func (b *Bot) startCreateAccount(ctx context.Context, currentUser *user.User) CallbackResponse {
-	// 设置状态为等待输入用户名
-	b.stateMachine.SetState(currentUser.TelegramID, StateWaitingUsername, nil)
-
-	text := `➕ <b>创建新账号</b>
-
-请输入新账号的用户名：
-
-<b>用户名要求：</b>
-• 只能包含字母、数字和下划线
-• 长度 3-20 个字符
-• 不能与现有账号重复
-
-输入 /cancel 取消创建`
-
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("❌ 取消", CallbackMainMenu),
-		),
-	)
-
-	return CallbackResponse{
-		EditText:   text,
-		EditMarkup: &keyboard,
+	// 维护期间不进入向导，避免用户填完信息才被拒绝
+	if _, ok := b.activeMaintenance(ctx); ok {
+		keyboard := BackButton(b.loc(ctx), CallbackMainMenu)
+		return CallbackResponse{
+			EditText:   b.maintenanceText(ctx),
+			EditMarkup: &keyboard,
+		}
 	}
+	return b.startWizard(ctx, currentUser, WizardCreateAccount, conversation.Payload{})
 }
 
 // handleConfirmCallback 处理确认操作回调
 func (b *Bot) handleConfirmCallback(ctx context.Context, query *tgbotapi.CallbackQuery, parts []string, currentUser *user.User) CallbackResponse {
 	if len(parts) < 3 {
-		return CallbackResponse{Answer: "无效的操作", ShowAlert: true}
+		return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
 	}
 
 	action := parts[1]
@@ -67,7 +52,7 @@ func (b *Bot) handleConfirmCallback(ctx context.Context, query *tgbotapi.Callbac
 	case "renew":
 		// confirm:renew:accountID:days
 		if len(parts) < 4 {
-			return CallbackResponse{Answer: "参数错误", ShowAlert: true}
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_params"), ShowAlert: true}
 		}
 		accountID := strToUint(param)
 		days := strToInt(parts[3])
@@ -81,14 +66,14 @@ func (b *Bot) handleConfirmCallback(ctx context.Context, query *tgbotapi.Callbac
 	case "rating":
 		// confirm:rating:accountID:ratingValue
 		if len(parts) < 4 {
-			return CallbackResponse{Answer: "参数错误", ShowAlert: true}
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_params"), ShowAlert: true}
 		}
 		accountID := strToUint(param)
 		rating := strToInt(parts[3])
 		return b.executeRatingUpdate(ctx, currentUser, accountID, rating)
 
 	default:
-		return CallbackResponse{Answer: "未知操作", ShowAlert: true}
+		return CallbackResponse{Answer: b.t(ctx, "common.unknown_action"), ShowAlert: true}
 	}
 }
 
@@ -97,15 +82,7 @@ func (b *Bot) executeRenew(ctx context.Context, currentUser *user.User, accountI
 	acc, err := b.accountService.Get(ctx, accountID)
 	if err != nil {
 		return CallbackResponse{
-			Answer:    "获取账号信息失败",
-			ShowAlert: true,
-		}
-	}
-
-	// 检查所有权
-	if err := b.accountService.CheckOwnership(ctx, acc.ID, currentUser.ID); err != nil {
-		return CallbackResponse{
-			Answer:    "您没有权限操作此账号",
+			Answer:    b.t(ctx, "common.get_account_failed"),
 			ShowAlert: true,
 		}
 	}
@@ -113,33 +90,25 @@ func (b *Bot) executeRenew(ctx context.Context, currentUser *user.User, accountI
 	// 续期
 	if err := b.accountService.Renew(ctx, acc.ID, days); err != nil {
 		return CallbackResponse{
-			Answer:    fmt.Sprintf("续期失败: %v", err),
+			Answer:    b.t(ctx, "renew.failed", b.errorText(ctx, err)),
 			ShowAlert: true,
 		}
 	}
 
 	// 显示成功消息并返回账号详情
 	return CallbackResponse{
-		Answer: fmt.Sprintf("✅ 已成功续期 %d 天", days),
+		Answer: b.t(ctx, "renew.done", days),
 		// 刷新账号详情页面
-		EditText: "正在刷新...",
+		EditText: b.t(ctx, "common.refreshing"),
 	}
 }
 
 // executeDelete 执行删除
 func (b *Bot) executeDelete(ctx context.Context, currentUser *user.User, accountID uint) CallbackResponse {
-	// 只有管理员可以删除
-	if !currentUser.IsAdmin() {
-		return CallbackResponse{
-			Answer:    "只有管理员可以删除账号",
-			ShowAlert: true,
-		}
-	}
-
 	acc, err := b.accountService.Get(ctx, accountID)
 	if err != nil {
 		return CallbackResponse{
-			Answer:    "获取账号信息失败",
+			Answer:    b.t(ctx, "common.get_account_failed"),
 			ShowAlert: true,
 		}
 	}
@@ -147,21 +116,19 @@ func (b *Bot) executeDelete(ctx context.Context, currentUser *user.User, account
 	username := acc.Username
 
 	// 删除账号
-	if err := b.accountService.Delete(ctx, accountID); err != nil {
+	if err := b.accountService.DeleteAs(ctx, currentUser.ID, accountID); err != nil {
 		return CallbackResponse{
-			Answer:    fmt.Sprintf("删除失败: %v", err),
+			Answer:    b.t(ctx, "delete.failed", b.errorText(ctx, err)),
 			ShowAlert: true,
 		}
 	}
 
-	text := fmt.Sprintf(`✅ <b>账号已删除</b>
-
-账号 <b>%s</b> 已成功删除。`, username)
+	text := b.t(ctx, "delete.done", username)
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📋 我的账号", CallbackMyAccounts+":1"),
-			tgbotapi.NewInlineKeyboardButtonData("⬅️ 主菜单", CallbackMainMenu),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "keyboard.my_accounts"), CallbackMyAccounts+":1"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.main_menu"), CallbackMainMenu),
 		),
 	)
 
@@ -176,47 +143,21 @@ func (b *Bot) executeRatingUpdate(ctx context.Context, currentUser *user.User, a
 	acc, err := b.accountService.Get(ctx, accountID)
 	if err != nil {
 		return CallbackResponse{
-			Answer:    "获取账号信息失败",
+			Answer:    b.t(ctx, "common.get_account_failed"),
 			ShowAlert: true,
 		}
 	}
 
-	// 检查所有权
-	if err := b.accountService.CheckOwnership(ctx, acc.ID, currentUser.ID); err != nil {
-		return CallbackResponse{
-			Answer:    "您没有权限操作此账号",
-			ShowAlert: true,
-		}
-	}
-
-	// 检查账号是否已同步到 Emby
-	if acc.EmbyUserID == "" {
-		return CallbackResponse{
-			Answer:    "账号尚未同步到 Emby，无法设置评级",
-			ShowAlert: true,
-		}
-	}
-
-	// 更新 Emby 用户策略中的家长控制评级
-	policy, err := b.embyClient.GetUserPolicy(ctx, acc.EmbyUserID)
-	if err != nil {
-		return CallbackResponse{
-			Answer:    fmt.Sprintf("获取用户策略失败: %v", err),
-			ShowAlert: true,
-		}
-	}
-
-	policy.MaxParentalRating = int32(rating)
-
-	if err := b.embyClient.UpdateUserPolicy(ctx, acc.EmbyUserID, policy); err != nil {
+	// 评级保存在本地账号覆盖中，已同步的账号会同时更新 Emby 策略
+	if err := b.accountService.SetParentalRating(ctx, acc.ID, rating); err != nil {
 		return CallbackResponse{
-			Answer:    fmt.Sprintf("更新评级失败: %v", err),
+			Answer:    b.t(ctx, "rating.failed", b.errorText(ctx, err)),
 			ShowAlert: true,
 		}
 	}
 
 	// 返回账号详情页面，显示成功消息
 	response := b.showAccountInfo(ctx, currentUser, accountID)
-	response.Answer = fmt.Sprintf("✅ 评级已设置为 %d", rating)
+	response.Answer = b.t(ctx, "rating.done", rating)
 	return response
 }
@@ -0,0 +1,150 @@
+// Package bot 策略偏差检测回调处理
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/emby"
+	"emby-telegram/internal/i18n"
+	"emby-telegram/pkg/timeutil"
+)
+
+// policyChangeHistoryLimit 偏差详情中展示的变更记录条数
+const policyChangeHistoryLimit = 5
+
+// showPolicyDriftList 检测所有已同步账号并列出存在偏差的账号
+func (b *Bot) showPolicyDriftList(ctx context.Context) CallbackResponse {
+	reports, err := b.accountService.ScanDrift(ctx)
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "drift.scan_failed", b.errorText(ctx, err)),
+			ShowAlert: true,
+		}
+	}
+
+	var text string
+	if len(reports) == 0 {
+		text = b.t(ctx, "drift.none")
+	} else {
+		text = b.t(ctx, "drift.found", len(reports))
+	}
+
+	keyboard := PolicyDriftListKeyboard(b.loc(ctx), reports)
+
+	return CallbackResponse{
+		EditText:   text,
+		EditMarkup: &keyboard,
+	}
+}
+
+// showPolicyDriftDetail 显示单个账号的策略差异和最近变更记录
+func (b *Bot) showPolicyDriftDetail(ctx context.Context, accountID uint) CallbackResponse {
+	report, err := b.accountService.CheckDrift(ctx, accountID)
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "drift.scan_failed", b.errorText(ctx, err)),
+			ShowAlert: true,
+		}
+	}
+
+	// 变更记录仅作参考，读取失败不影响偏差展示
+	changes, _ := b.accountService.ListPolicyChanges(ctx, accountID, policyChangeHistoryLimit)
+
+	keyboard := PolicyDriftActionsKeyboard(b.loc(ctx), accountID, report.HasDrift())
+
+	return CallbackResponse{
+		EditText:   formatDriftReport(b.loc(ctx), report, changes),
+		EditMarkup: &keyboard,
+	}
+}
+
+// handleFixPolicyDrift 将 Emby 上的策略修正为期望值
+func (b *Bot) handleFixPolicyDrift(ctx context.Context, accountID uint, operatorID int64) CallbackResponse {
+	report, err := b.accountService.FixDrift(ctx, accountID, operatorID)
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "drift.fix_failed", b.errorText(ctx, err)),
+			ShowAlert: true,
+		}
+	}
+
+	response := b.showPolicyDriftDetail(ctx, accountID)
+	response.Answer = b.t(ctx, "drift.fixed", len(report.Diffs))
+	return response
+}
+
+// handleAcceptPolicyDrift 将 Emby 上的实际值接受为账号覆盖
+func (b *Bot) handleAcceptPolicyDrift(ctx context.Context, accountID uint, operatorID int64) CallbackResponse {
+	accepted, remaining, err := b.accountService.AcceptDrift(ctx, accountID, operatorID)
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "drift.accept_failed", b.errorText(ctx, err)),
+			ShowAlert: true,
+		}
+	}
+
+	response := b.showPolicyDriftDetail(ctx, accountID)
+	response.Answer = b.t(ctx, "drift.accepted", len(accepted))
+	if len(remaining) > 0 {
+		response.Answer += b.t(ctx, "drift.accept_unsupported", len(remaining))
+		response.ShowAlert = true
+	}
+	return response
+}
+
+// formatDriftReport 格式化策略偏差报告
+func formatDriftReport(loc *i18n.Localizer, report *account.DriftReport, changes []*account.PolicyChange) string {
+	var builder strings.Builder
+
+	builder.WriteString(loc.T("drift.report_title", report.Account.Username, report.Account.EmbyUserID))
+
+	if report.HasDrift() {
+		builder.WriteString(loc.T("drift.diff_title", len(report.Diffs)))
+		builder.WriteString(formatPolicyDiffs(loc, report.Diffs))
+	} else {
+		builder.WriteString(loc.T("drift.in_sync"))
+	}
+
+	if len(changes) > 0 {
+		builder.WriteString(loc.T("drift.recent_changes"))
+		for _, c := range changes {
+			action := loc.T("drift.action_fix")
+			if c.Action == account.PolicyActionAccept {
+				action = loc.T("drift.action_accept")
+			}
+			builder.WriteString(fmt.Sprintf("• %s [%s] %s: %s → %s\n",
+				timeutil.FormatDateTime(c.CreatedAt), action, c.Field, c.OldValue, c.NewValue))
+		}
+	}
+
+	return builder.String()
+}
+
+// formatPolicyDiffs 格式化字段差异列表，不能接受为覆盖的字段标记为仅可修正
+func formatPolicyDiffs(loc *i18n.Localizer, diffs []emby.PolicyDiff) string {
+	var builder strings.Builder
+	for _, d := range diffs {
+		key := "drift.diff_item"
+		if !account.CanAcceptField(d.Field) {
+			key = "drift.diff_item_fix_only"
+		}
+		builder.WriteString(loc.T(key, d.Field, d.Expected, d.Actual))
+	}
+	return builder.String()
+}
+
+// policyDriftErrorMessage 策略偏差检测错误对应的提示
+func (b *Bot) policyDriftErrorMessage(ctx context.Context, err error) string {
+	switch {
+	case errors.Is(err, account.ErrSyncDisabled):
+		return b.t(ctx, "drift.sync_disabled")
+	case errors.Is(err, account.ErrNotSynced):
+		return "❌ " + b.errorText(ctx, err)
+	default:
+		return b.t(ctx, "drift.check_failed", b.errorText(ctx, err))
+	}
+}
@@ -8,82 +8,128 @@ import (
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
+	"emby-telegram/internal/account"
 	"emby-telegram/internal/logger"
+	"emby-telegram/internal/user"
 )
 
 // handleCallbackQuery 处理按钮回调
 func (b *Bot) handleCallbackQuery(ctx context.Context, query *tgbotapi.CallbackQuery) {
 	if query.Message != nil && isGroupChatFromMessage(query.Message) {
-		b.answerCallback(query.ID, "请在私聊中使用按钮功能", true)
+		b.answerCallback(query.ID, b.catalog.T(b.catalog.Match(query.From.LanguageCode), "common.buttons_private_only"), true)
 		return
 	}
 
 	currentUser, err := b.userService.GetOrCreate(ctx, query.From)
 	if err != nil {
 		logger.Errorf("failed to get or create user: %v", err)
-		b.answerCallback(query.ID, "系统错误，请稍后再试", true)
+		b.answerCallback(query.ID, b.catalog.T(b.catalog.Match(query.From.LanguageCode), "common.system_error"), true)
 		return
 	}
 
+	ctx = b.localize(ctx, currentUser, query.From)
+	b.clearBotBlocked(ctx, currentUser)
+
 	if !currentUser.CanAccess() {
-		b.answerCallback(query.ID, "您已被封禁，无法使用此 Bot", true)
+		b.answerCallback(query.ID, b.t(ctx, "common.blocked"), true)
 		return
 	}
 
 	// 解析 callback data
 	parts := strings.Split(query.Data, ":")
 	if len(parts) == 0 {
-		b.answerCallback(query.ID, "无效的操作", true)
+		b.answerCallback(query.ID, b.t(ctx, "common.invalid_action"), true)
 		return
 	}
 
-	action := parts[0]
-
-	logger.Infof("user clicked button: %s, data: %s", currentUser.DisplayName(), query.Data)
-
-	// 路由到对应的处理函数
-	var response CallbackResponse
-	switch action {
-	case "menu":
-		response = b.handleMenuCallback(ctx, query, parts, currentUser)
-	case "accounts":
-		response = b.handleAccountsCallback(ctx, query, parts, currentUser)
-	case "account":
-		response = b.handleAccountCallback(ctx, query, parts, currentUser)
-	case "create":
-		response = b.handleCreateCallback(ctx, query, parts, currentUser)
-	case "admin":
-		response = b.handleAdminCallback(ctx, query, parts, currentUser)
-	case "confirm":
-		response = b.handleConfirmCallback(ctx, query, parts, currentUser)
-	case "cancel":
-		b.stateMachine.ClearState(currentUser.TelegramID)
-		response = CallbackResponse{
-			Answer:   "操作已取消",
-			EditText: "✅ 操作已取消",
+	spec, ok := b.lookupCallback(parts)
+	if !ok {
+		logger.Infof("user clicked button: %s, data: %s", currentUser.DisplayName(), query.Data)
+		b.sendCallbackResponse(ctx, query, CallbackResponse{Answer: b.t(ctx, "common.unknown_action"), ShowAlert: true})
+		return
+	}
+
+	b.sendCallbackResponse(ctx, query, spec.run(withCurrentUser(ctx, currentUser), query, parts, currentUser))
+}
+
+// registerCallbacks 注册按钮回调路由
+// 子操作可用 "第一段:第二段" 单独声明执行条件，由中间件统一校验
+func (b *Bot) registerCallbacks() {
+	b.callback("menu", callbackSpec{handler: b.handleMenuCallback})
+	b.callback("accounts", callbackSpec{handler: b.handleAccountsCallback})
+	b.callback("create", callbackSpec{handler: b.handleCreateCallback})
+	b.callback("cancel", callbackSpec{handler: b.handleCancelCallback})
+	b.callback("back", callbackSpec{handler: b.handleBackCallback})
+	b.callback("lang", callbackSpec{handler: b.handleLanguageCallback})
+	b.callback(CallbackTransfer, callbackSpec{handler: b.handleTransferCallback})
+	b.callback(CallbackWizard, callbackSpec{handler: b.handleWizardCallback})
+
+	// 账号操作: account:<操作>:<账号ID>
+	b.callback("account", callbackSpec{handler: b.handleAccountCallback})
+	b.callback("account:info", callbackSpec{handler: b.handleAccountCallback, accountParam: 2, accountPermission: account.PermissionView})
+	b.callback("account:renew", callbackSpec{handler: b.handleAccountCallback, accountParam: 2, accountPermission: account.PermissionRenew})
+	b.callback("account:pwd", callbackSpec{handler: b.handleAccountCallback, accountParam: 2, accountPermission: account.PermissionPassword})
+	b.callback("account:sync", callbackSpec{handler: b.handleAccountCallback, accountParam: 2, accountPermission: account.PermissionView})
+	b.callback("account:rating", callbackSpec{handler: b.handleAccountCallback, accountParam: 2})
+	b.callback("account:del", callbackSpec{handler: b.handleAccountCallback, permission: user.PermAccountDelete})
+
+	// 确认操作: confirm:<操作>:<账号ID>[:参数]
+	b.callback("confirm", callbackSpec{handler: b.handleConfirmCallback})
+	b.callback("confirm:renew", callbackSpec{handler: b.handleConfirmCallback, accountParam: 2, accountPermission: account.PermissionRenew})
+	b.callback("confirm:rating", callbackSpec{handler: b.handleConfirmCallback, accountParam: 2})
+	b.callback("confirm:delete", callbackSpec{handler: b.handleConfirmCallback, permission: user.PermAccountDelete})
+
+	// 管理菜单: admin:<操作>[:参数]，各子菜单按权限开放
+	b.callback("admin", callbackSpec{handler: b.handleAdminCallback, staffOnly: true})
+	b.callback("admin:emby", callbackSpec{handler: b.handleAdminCallback, staffOnly: true})
+	b.callback("admin:account", callbackSpec{handler: b.handleAdminCallback, staffOnly: true, accountParam: 2, accountPermission: account.PermissionView})
+	// 同一个子操作可以列在多个权限下，拥有其中任一权限即可执行
+	// 批量操作向导按权限筛选可执行的操作，停止任务时再校验发起人
+	adminRoutes := map[user.Permission][]string{
+		user.PermUserManage:      {"users", "user", "usersearch"},
+		user.PermAccountRenew:    {"accbulk", "bulkstop"},
+		user.PermAccountSuspend:  {"suspend", "activate", "accbulk", "bulkstop"},
+		user.PermAccountDelete:   {"accbulk", "bulkstop"},
+		user.PermAccountTransfer: {"acctransfer"},
+		user.PermCustomerManage:  {"accbulk", "bulkstop"},
+		user.PermStatsView:       {"stats"},
+		user.PermSessionView:     {"playing"},
+		user.PermAccountPolicy:   {"updatepolicies", "tpls", "tpl", "tplset", "tplclone", "tpldef", "tpldel", "libs", "lib", "ovr", "drift", "driftacc", "driftfix", "driftaccept", "accbulk", "bulkstop"},
+		user.PermInviteManage:    {"invitecodes", "invitecode", "createcode", "quickcreate", "revokecode", "codesearch"},
+		user.PermBroadcast:       {"broadcast", "bcstop"},
+	}
+	actionPermissions := make(map[string][]user.Permission)
+	for perm, actions := range adminRoutes {
+		for _, action := range actions {
+			actionPermissions[action] = append(actionPermissions[action], perm)
 		}
-	case "back":
-		response = b.handleBackCallback(ctx, query, parts, currentUser)
-	default:
-		response = CallbackResponse{Answer: "未知操作", ShowAlert: true}
 	}
+	for action, perms := range actionPermissions {
+		b.callback("admin:"+action, callbackSpec{handler: b.handleAdminCallback, anyPermission: perms})
+	}
+}
 
-	// 发送响应
-	b.sendCallbackResponse(query, response)
+// handleCancelCallback 取消当前操作
+func (b *Bot) handleCancelCallback(ctx context.Context, query *tgbotapi.CallbackQuery, parts []string, currentUser *user.User) CallbackResponse {
+	b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+	return CallbackResponse{
+		Answer:   b.t(ctx, "cancel.answer"),
+		EditText: b.t(ctx, "cancel.text"),
+	}
 }
 
 // CallbackResponse 回调响应结构
 type CallbackResponse struct {
-	Answer    string // Callback answer 提示文本
-	ShowAlert bool   // 是否显示为弹窗
-	EditText  string // 要编辑的消息文本
+	Answer     string                         // Callback answer 提示文本
+	ShowAlert  bool                           // 是否显示为弹窗
+	EditText   string                         // 要编辑的消息文本
 	EditMarkup *tgbotapi.InlineKeyboardMarkup // 要编辑的按钮
-	NewMessage string // 发送新消息
-	NewMarkup *tgbotapi.InlineKeyboardMarkup // 新消息的按钮
+	NewMessage string                         // 发送新消息
+	NewMarkup  *tgbotapi.InlineKeyboardMarkup // 新消息的按钮
 }
 
 // sendCallbackResponse 发送回调响应
-func (b *Bot) sendCallbackResponse(query *tgbotapi.CallbackQuery, response CallbackResponse) {
+func (b *Bot) sendCallbackResponse(ctx context.Context, query *tgbotapi.CallbackQuery, response CallbackResponse) {
 	if !strings.HasPrefix(query.ID, "reply_keyboard_") {
 		b.answerCallback(query.ID, response.Answer, response.ShowAlert)
 	}
@@ -105,7 +151,7 @@ func (b *Bot) sendCallbackResponse(query *tgbotapi.CallbackQuery, response Callb
 			if response.EditMarkup != nil {
 				msg.ReplyMarkup = response.EditMarkup
 			}
-			if _, err := b.api.Send(msg); err != nil {
+			if _, err := b.send(ctx, query.Message.Chat.ID, msg); err != nil {
 				logger.Errorf("failed to send message: %v", err)
 			}
 		} else {
@@ -119,7 +165,7 @@ func (b *Bot) sendCallbackResponse(query *tgbotapi.CallbackQuery, response Callb
 			if response.EditMarkup != nil {
 				edit.ReplyMarkup = response.EditMarkup
 			}
-			if _, err := b.api.Send(edit); err != nil {
+			if _, err := b.send(ctx, query.Message.Chat.ID, edit); err != nil {
 				// 忽略"消息未修改"错误，这是正常情况
 				if !strings.Contains(err.Error(), "message is not modified") {
 					logger.Errorf("failed to edit message: %v", err)
@@ -135,7 +181,7 @@ func (b *Bot) sendCallbackResponse(query *tgbotapi.CallbackQuery, response Callb
 		if response.NewMarkup != nil {
 			msg.ReplyMarkup = response.NewMarkup
 		}
-		if _, err := b.api.Send(msg); err != nil {
+		if _, err := b.send(ctx, query.Message.Chat.ID, msg); err != nil {
 			logger.Errorf("failed to send message: %v", err)
 		}
 	}
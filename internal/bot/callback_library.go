@@ -0,0 +1,224 @@
+// Package bot 媒体库访问权限回调处理
+package bot
+
+import (
+	"context"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/emby"
+	"emby-telegram/internal/i18n"
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/policy"
+)
+
+// 媒体库选择键盘中的特殊取值
+const (
+	libraryAll   = "all"   // 全部媒体库
+	libraryReset = "reset" // 取消账号单独设置，沿用模板
+)
+
+// showAccountLibraries 显示账号媒体库权限多选键盘
+func (b *Bot) showAccountLibraries(ctx context.Context, accountID uint) CallbackResponse {
+	if b.embyClient == nil {
+		return CallbackResponse{Answer: b.t(ctx, "error.sync_disabled"), ShowAlert: true}
+	}
+
+	acc, err := b.accountService.Get(ctx, accountID)
+	if err != nil {
+		return CallbackResponse{Answer: b.t(ctx, "common.get_account_failed"), ShowAlert: true}
+	}
+
+	access, err := b.accountService.GetLibraryAccess(ctx, accountID)
+	if err != nil {
+		return CallbackResponse{Answer: b.t(ctx, "library.access_failed"), ShowAlert: true}
+	}
+
+	folders, err := b.embyClient.ListMediaFolders(ctx)
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "library.list_failed", b.errorText(ctx, err)),
+			ShowAlert: true,
+		}
+	}
+
+	source := b.t(ctx, "library.source_template")
+	var extraRows [][]tgbotapi.InlineKeyboardButton
+	if access.Overridden {
+		source = b.t(ctx, "library.source_account")
+		extraRows = append(extraRows, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "library.reset_button"), CallbackAdminAccountLibrary+":"+uintToStr(accountID)+":"+libraryReset),
+		})
+	}
+
+	text := b.t(ctx, "library.account_detail", acc.Username, formatLibraryAccess(b.loc(ctx), folders, access.AllFolders, access.FolderIDs), source)
+
+	keyboard := LibraryAccessKeyboard(b.loc(ctx), folders, access.AllFolders, access.FolderIDs,
+		CallbackAdminAccountLibrary+":"+uintToStr(accountID)+":", extraRows,
+		CallbackAdminAccountDetail+":"+uintToStr(accountID))
+
+	return CallbackResponse{
+		EditText:   text,
+		EditMarkup: &keyboard,
+	}
+}
+
+// handleAccountLibraryToggle 切换账号的媒体库访问权限
+func (b *Bot) handleAccountLibraryToggle(ctx context.Context, accountID uint, value string) CallbackResponse {
+	if b.embyClient == nil {
+		return CallbackResponse{Answer: b.t(ctx, "error.sync_disabled"), ShowAlert: true}
+	}
+
+	if value == libraryReset {
+		if err := b.accountService.ResetLibraryAccess(ctx, accountID); err != nil {
+			return CallbackResponse{
+				Answer:    b.t(ctx, "library.update_failed", b.errorText(ctx, err)),
+				ShowAlert: true,
+			}
+		}
+
+		response := b.showAccountLibraries(ctx, accountID)
+		response.Answer = b.t(ctx, "library.reset_done")
+		return response
+	}
+
+	access, err := b.accountService.GetLibraryAccess(ctx, accountID)
+	if err != nil {
+		return CallbackResponse{Answer: b.t(ctx, "library.access_failed"), ShowAlert: true}
+	}
+
+	folders, err := b.embyClient.ListMediaFolders(ctx)
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "library.list_failed", b.errorText(ctx, err)),
+			ShowAlert: true,
+		}
+	}
+
+	selected, ok := toggleLibrary(folders, access.AllFolders, access.FolderIDs, value)
+	if !ok {
+		return CallbackResponse{Answer: b.t(ctx, "library.keep_one"), ShowAlert: true}
+	}
+
+	if err := b.accountService.SetLibraryAccess(ctx, accountID, selected); err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "library.update_failed", b.errorText(ctx, err)),
+			ShowAlert: true,
+		}
+	}
+
+	response := b.showAccountLibraries(ctx, accountID)
+	response.Answer = b.t(ctx, "library.updated")
+	return response
+}
+
+// handleTemplateLibrarySet 显示或修改模板的媒体库设置
+func (b *Bot) handleTemplateLibrarySet(ctx context.Context, tpl *policy.Template, value string) CallbackResponse {
+	if b.embyClient == nil {
+		return CallbackResponse{Answer: b.t(ctx, "error.sync_disabled"), ShowAlert: true}
+	}
+
+	folders, err := b.embyClient.ListMediaFolders(ctx)
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "library.list_failed", b.errorText(ctx, err)),
+			ShowAlert: true,
+		}
+	}
+
+	// 未指定任何媒体库时与全部媒体库等价
+	allFolders := tpl.EnableAllFolders || len(tpl.EnabledFolders) == 0
+
+	answer := ""
+	if value != "" {
+		selected, ok := toggleLibrary(folders, allFolders, tpl.EnabledFolders, value)
+		if !ok {
+			return CallbackResponse{Answer: b.t(ctx, "library.keep_one"), ShowAlert: true}
+		}
+
+		allFolders = len(selected) == 0
+		tpl.EnableAllFolders = allFolders
+		tpl.EnabledFolders = selected
+		if err := b.policyService.Update(ctx, tpl); err != nil {
+			return CallbackResponse{
+				Answer:    b.t(ctx, "policy.update_failed", b.errorText(ctx, err)),
+				ShowAlert: true,
+			}
+		}
+
+		logger.Infof("policy template updated: %s, field: lib", tpl.Name)
+		answer = b.t(ctx, "policy.updated")
+	}
+
+	text := b.t(ctx, "library.template_detail", tpl.Name, formatLibraryAccess(b.loc(ctx), folders, allFolders, tpl.EnabledFolders))
+
+	keyboard := LibraryAccessKeyboard(b.loc(ctx), folders, allFolders, tpl.EnabledFolders,
+		CallbackAdminPolicyTemplateSet+":"+uintToStr(tpl.ID)+":lib:", nil,
+		CallbackAdminPolicyTemplate+":"+uintToStr(tpl.ID))
+
+	return CallbackResponse{
+		Answer:     answer,
+		EditText:   text,
+		EditMarkup: &keyboard,
+	}
+}
+
+// toggleLibrary 在当前媒体库选择上切换一项
+// 返回新的选择，nil 表示全部媒体库；取消最后一个媒体库时返回 false
+func toggleLibrary(folders []*emby.VirtualFolder, allFolders bool, selected []string, value string) ([]string, bool) {
+	if value == libraryAll {
+		return nil, true
+	}
+
+	// 全部媒体库状态下先展开为具体列表
+	current := make(map[string]bool)
+	if allFolders {
+		for _, f := range folders {
+			current[f.ItemID] = true
+		}
+	} else {
+		for _, id := range selected {
+			current[id] = true
+		}
+	}
+	current[value] = !current[value]
+
+	var result []string
+	for _, f := range folders {
+		if current[f.ItemID] {
+			result = append(result, f.ItemID)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, false
+	}
+	if len(result) == len(folders) {
+		return nil, true
+	}
+	return result, true
+}
+
+// formatLibraryAccess 格式化媒体库权限为媒体库名称列表
+func formatLibraryAccess(loc *i18n.Localizer, folders []*emby.VirtualFolder, allFolders bool, selected []string) string {
+	if allFolders {
+		return loc.T("library.all")
+	}
+
+	names := make(map[string]string, len(folders))
+	for _, f := range folders {
+		names[f.ItemID] = f.Name
+	}
+
+	var items []string
+	for _, id := range selected {
+		if name, ok := names[id]; ok {
+			items = append(items, name)
+		} else {
+			items = append(items, loc.T("library.deleted_suffix", id))
+		}
+	}
+
+	return strings.Join(items, loc.T("library.separator"))
+}
@@ -0,0 +1,371 @@
+// Package bot 策略模板管理回调处理
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/conversation"
+	"emby-telegram/internal/i18n"
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/policy"
+	"emby-telegram/internal/user"
+)
+
+// 批量应用策略的账号范围
+const (
+	policyScopeActive = "active" // 激活中的本地账号
+	policyScopeAll    = "all"    // 全部本地账号
+	policyScopeEmby   = "emby"   // Emby 上全部非管理员用户，关联的本地账号同时记录模板
+)
+
+// showPolicyTemplates 显示策略模板列表
+func (b *Bot) showPolicyTemplates(ctx context.Context) CallbackResponse {
+	tpls, err := b.policyService.List(ctx)
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "policy.list_failed", b.errorText(ctx, err)),
+			ShowAlert: true,
+		}
+	}
+
+	text := b.t(ctx, "policy.list", len(tpls))
+
+	keyboard := PolicyTemplateListKeyboard(b.loc(ctx), tpls, CallbackAdminPolicyTemplate, CallbackAdminEmby)
+
+	return CallbackResponse{
+		EditText:   text,
+		EditMarkup: &keyboard,
+	}
+}
+
+// showPolicyTemplateDetail 显示策略模板详情
+func (b *Bot) showPolicyTemplateDetail(ctx context.Context, templateID uint) CallbackResponse {
+	tpl, err := b.policyService.Get(ctx, templateID)
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "policy.get_failed"),
+			ShowAlert: true,
+		}
+	}
+
+	keyboard := PolicyTemplateDetailKeyboard(b.loc(ctx), tpl)
+
+	return CallbackResponse{
+		EditText:   formatPolicyTemplate(b.loc(ctx), tpl),
+		EditMarkup: &keyboard,
+	}
+}
+
+// handlePolicyTemplateSet 修改模板字段
+// 布尔字段直接切换；码率、评级和媒体库未带取值时显示选择键盘
+// 只保存模板，不推送到已有账号，需要通过「应用到账号」推送
+func (b *Bot) handlePolicyTemplateSet(ctx context.Context, templateID uint, field, value string) CallbackResponse {
+	tpl, err := b.policyService.Get(ctx, templateID)
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "policy.get_failed"),
+			ShowAlert: true,
+		}
+	}
+
+	switch field {
+	case "tc":
+		tpl.EnableTranscoding = !tpl.EnableTranscoding
+	case "dl":
+		tpl.EnableDownloads = !tpl.EnableDownloads
+	case "ra":
+		tpl.EnableRemoteAccess = !tpl.EnableRemoteAccess
+	case "lib":
+		return b.handleTemplateLibrarySet(ctx, tpl, value)
+	case "br":
+		if value == "" {
+			keyboard := TemplateBitrateKeyboard(b.loc(ctx), tpl.ID)
+			return CallbackResponse{
+				EditText:   b.t(ctx, "policy.bitrate_prompt", tpl.Name, formatBitrate(b.loc(ctx), tpl.BitrateLimit)),
+				EditMarkup: &keyboard,
+			}
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return CallbackResponse{Answer: b.t(ctx, "policy.invalid_value", value), ShowAlert: true}
+		}
+		tpl.BitrateLimit = n
+	case "rt":
+		if value == "" {
+			keyboard := TemplateRatingKeyboard(b.loc(ctx), tpl.ID)
+			return CallbackResponse{
+				EditText:   b.t(ctx, "policy.rating_prompt", tpl.Name, tpl.MaxParentalRating),
+				EditMarkup: &keyboard,
+			}
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return CallbackResponse{Answer: b.t(ctx, "policy.invalid_value", value), ShowAlert: true}
+		}
+		tpl.MaxParentalRating = n
+	default:
+		return CallbackResponse{Answer: b.t(ctx, "settings.unknown_field"), ShowAlert: true}
+	}
+
+	if err := b.policyService.Update(ctx, tpl); err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "policy.update_failed", b.errorText(ctx, err)),
+			ShowAlert: true,
+		}
+	}
+
+	logger.Infof("policy template updated: %s, field: %s", tpl.Name, field)
+
+	response := b.showPolicyTemplateDetail(ctx, tpl.ID)
+	response.Answer = b.t(ctx, "policy.updated")
+	return response
+}
+
+// startClonePolicyTemplate 开始复制模板，等待输入新模板名称
+func (b *Bot) startClonePolicyTemplate(ctx context.Context, currentUser *user.User, templateID uint) CallbackResponse {
+	tpl, err := b.policyService.Get(ctx, templateID)
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "policy.get_failed"),
+			ShowAlert: true,
+		}
+	}
+
+	if err := b.stateMachine.SetState(ctx, currentUser.TelegramID, StateWaitingTemplateName, conversation.Payload{TemplateID: tpl.ID}); err != nil {
+		logger.Errorf("failed to save conversation state: %v", err)
+		return CallbackResponse{Answer: b.t(ctx, "common.system_error"), ShowAlert: true}
+	}
+
+	text := b.t(ctx, "policy.clone_prompt", tpl.Name)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.cancel"), CallbackCancel),
+		),
+	)
+
+	return CallbackResponse{
+		EditText:   text,
+		EditMarkup: &keyboard,
+	}
+}
+
+// handleSetDefaultPolicyTemplate 设置默认模板
+func (b *Bot) handleSetDefaultPolicyTemplate(ctx context.Context, templateID uint) CallbackResponse {
+	if err := b.policyService.SetDefault(ctx, templateID); err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "policy.set_default_failed", b.errorText(ctx, err)),
+			ShowAlert: true,
+		}
+	}
+
+	response := b.showPolicyTemplateDetail(ctx, templateID)
+	response.Answer = b.t(ctx, "policy.set_default_done")
+	return response
+}
+
+// handleDeletePolicyTemplate 删除模板（需二次确认）
+func (b *Bot) handleDeletePolicyTemplate(ctx context.Context, templateID uint, confirmed bool) CallbackResponse {
+	tpl, err := b.policyService.Get(ctx, templateID)
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "policy.get_failed"),
+			ShowAlert: true,
+		}
+	}
+
+	if !confirmed {
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "policy.confirm_delete_button"), CallbackAdminPolicyTemplateDelete+":"+uintToStr(tpl.ID)+":yes"),
+				tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.cancel"), CallbackAdminPolicyTemplate+":"+uintToStr(tpl.ID)),
+			),
+		)
+		return CallbackResponse{
+			EditText:   b.t(ctx, "policy.confirm_delete", tpl.Name),
+			EditMarkup: &keyboard,
+		}
+	}
+
+	if err := b.policyService.Delete(ctx, tpl.ID); err != nil {
+		if errors.Is(err, policy.ErrDeleteDefault) {
+			return CallbackResponse{Answer: b.t(ctx, "policy.delete_default"), ShowAlert: true}
+		}
+		return CallbackResponse{
+			Answer:    b.t(ctx, "policy.delete_failed", b.errorText(ctx, err)),
+			ShowAlert: true,
+		}
+	}
+
+	response := b.showPolicyTemplates(ctx)
+	response.Answer = b.t(ctx, "policy.deleted")
+	return response
+}
+
+// handleUpdatePoliciesCallback 处理批量更新策略回调
+// admin:updatepolicies -> 选择模板
+// admin:updatepolicies:templateID -> 选择账号范围
+// admin:updatepolicies:templateID:scope -> 执行
+func (b *Bot) handleUpdatePoliciesCallback(ctx context.Context, parts []string) CallbackResponse {
+	if b.embyClient == nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "error.sync_disabled"),
+			ShowAlert: true,
+		}
+	}
+
+	if len(parts) < 3 {
+		tpls, err := b.policyService.List(ctx)
+		if err != nil {
+			return CallbackResponse{
+				Answer:    b.t(ctx, "policy.list_failed", b.errorText(ctx, err)),
+				ShowAlert: true,
+			}
+		}
+
+		keyboard := PolicyTemplateListKeyboard(b.loc(ctx), tpls, CallbackAdminUpdatePolicies, CallbackAdminEmby)
+		return CallbackResponse{
+			EditText:   b.t(ctx, "policy.apply_choose_template"),
+			EditMarkup: &keyboard,
+		}
+	}
+
+	tpl, err := b.policyService.Get(ctx, strToUint(parts[2]))
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "policy.get_failed"),
+			ShowAlert: true,
+		}
+	}
+
+	if len(parts) < 4 {
+		keyboard := PolicyScopeKeyboard(b.loc(ctx), tpl.ID)
+		return CallbackResponse{
+			EditText:   b.t(ctx, "policy.apply_choose_scope", tpl.Name),
+			EditMarkup: &keyboard,
+		}
+	}
+
+	updated, failed, err := b.applyPolicyTemplate(ctx, tpl, parts[3], nil)
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "policy.apply_failed", b.errorText(ctx, err)),
+			ShowAlert: true,
+		}
+	}
+
+	keyboard := BackButton(b.loc(ctx), CallbackAdminEmby)
+
+	return CallbackResponse{
+		EditText:   formatPolicyApplyResult(b.loc(ctx), tpl, parts[3], updated, failed),
+		EditMarkup: &keyboard,
+	}
+}
+
+// applyPolicyTemplate 将模板应用到指定范围的账号
+// usernames 非空时只处理这些账号，忽略 scope
+func (b *Bot) applyPolicyTemplate(ctx context.Context, tpl *policy.Template, scope string, usernames []string) (int, int, error) {
+	if len(usernames) > 0 {
+		var ids []uint
+		notFound := 0
+		for _, name := range usernames {
+			acc, err := b.accountService.GetByUsername(ctx, name)
+			if err != nil {
+				logger.Warnf("apply policy template: account not found: %s", name)
+				notFound++
+				continue
+			}
+			ids = append(ids, acc.ID)
+		}
+		updated, failed := b.accountService.ApplyTemplateBatch(ctx, ids, tpl.ID)
+		return updated, failed + notFound, nil
+	}
+
+	switch scope {
+	case policyScopeEmby:
+		// 保留本地账号的设置(评级、设备数、媒体库等)，避免被模板覆盖
+		adjust, err := b.accountService.PolicyAdjuster(ctx)
+		if err != nil {
+			return 0, 0, err
+		}
+		updated, failed, err := b.embyClient.BatchUpdateNonAdminPolicies(ctx, tpl.Build(0), adjust)
+		if err != nil {
+			return 0, 0, err
+		}
+		// 只记录到推送成功的本地账号，之后推送账号策略时不会回退到旧模板，漂移检测也以实际推送的模板为准
+		if err := b.accountService.SetTemplateForLinked(ctx, tpl.ID, updated); err != nil {
+			logger.Errorf("failed to record policy template %s on linked accounts: %v", tpl.Name, err)
+		}
+		return len(updated), failed, nil
+	case policyScopeAll, policyScopeActive:
+		accs, err := b.accountService.ListAll(ctx, 0, 0)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		var ids []uint
+		for _, acc := range accs {
+			if scope == policyScopeActive && acc.Status != account.StatusActive {
+				continue
+			}
+			ids = append(ids, acc.ID)
+		}
+
+		updated, failed := b.accountService.ApplyTemplateBatch(ctx, ids, tpl.ID)
+		return updated, failed, nil
+	default:
+		return 0, 0, fmt.Errorf("unknown account scope: %s", scope)
+	}
+}
+
+// formatPolicyTemplate 格式化模板详情
+func formatPolicyTemplate(loc *i18n.Localizer, tpl *policy.Template) string {
+	folders := loc.T("settings.folders_all")
+	if !tpl.EnableAllFolders && len(tpl.EnabledFolders) > 0 {
+		folders = loc.T("settings.folders_some", len(tpl.EnabledFolders))
+	}
+
+	defaultMark := ""
+	if tpl.IsDefault {
+		defaultMark = loc.T("policy.default_mark")
+	}
+
+	return loc.T("policy.detail",
+		tpl.Name,
+		defaultMark,
+		tpl.Description,
+		boolEmoji(tpl.EnableTranscoding),
+		boolEmoji(tpl.EnableDownloads),
+		boolEmoji(tpl.EnableRemoteAccess),
+		formatBitrate(loc, tpl.BitrateLimit),
+		tpl.MaxParentalRating,
+		folders,
+	)
+}
+
+// formatPolicyApplyResult 格式化批量应用结果
+func formatPolicyApplyResult(loc *i18n.Localizer, tpl *policy.Template, scope string, updated, failed int) string {
+	scopeKey := "policy.scope_name_selected"
+	switch scope {
+	case policyScopeActive, policyScopeAll, policyScopeEmby:
+		scopeKey = "policy.scope_name_" + scope
+	}
+
+	return loc.T("policy.apply_result", tpl.Name, loc.T(scopeKey), updated, failed)
+}
+
+// formatBitrate 格式化码率（bps）
+func formatBitrate(loc *i18n.Localizer, bps int) string {
+	if bps <= 0 {
+		return loc.T("policy.bitrate_unlimited")
+	}
+	mbps := float64(bps) / 1000000
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.1f", mbps), "0"), ".") + " Mbps"
+}
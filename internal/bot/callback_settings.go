@@ -0,0 +1,108 @@
+// Package bot 账号播放设置回调处理
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/emby"
+	"emby-telegram/internal/i18n"
+)
+
+// handleAccountSettings 显示或修改账号播放设置
+// 下载直接切换；码率未带取值时显示选择键盘；reset 清除全部账号覆盖
+func (b *Bot) handleAccountSettings(ctx context.Context, accountID uint, field, value string) CallbackResponse {
+	acc, err := b.accountService.Get(ctx, accountID)
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "common.get_account_failed"),
+			ShowAlert: true,
+		}
+	}
+
+	effective := b.accountService.EffectivePolicy(ctx, acc)
+
+	switch field {
+	case "":
+		return b.showAccountSettings(ctx, acc, "")
+	case "dl":
+		err = b.accountService.SetDownloads(ctx, acc.ID, !effective.EnableContentDownloading)
+	case "br":
+		if value == "" {
+			keyboard := AccountBitrateKeyboard(b.loc(ctx), acc.ID)
+			return CallbackResponse{
+				EditText:   b.t(ctx, "settings.bitrate_prompt", acc.Username, formatBitrate(b.loc(ctx), int(effective.RemoteClientBitrateLimit))),
+				EditMarkup: &keyboard,
+			}
+		}
+		err = b.accountService.SetBitrateLimit(ctx, acc.ID, strToInt(value))
+	case "reset":
+		err = b.accountService.ResetOverrides(ctx, acc.ID)
+	default:
+		return CallbackResponse{Answer: b.t(ctx, "settings.unknown_field"), ShowAlert: true}
+	}
+
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "settings.update_failed", b.errorText(ctx, err)),
+			ShowAlert: true,
+		}
+	}
+
+	acc, err = b.accountService.Get(ctx, accountID)
+	if err != nil {
+		return CallbackResponse{
+			Answer:    b.t(ctx, "common.get_account_failed"),
+			ShowAlert: true,
+		}
+	}
+
+	return b.showAccountSettings(ctx, acc, b.t(ctx, "settings.updated"))
+}
+
+// showAccountSettings 显示账号生效的播放设置
+func (b *Bot) showAccountSettings(ctx context.Context, acc *account.Account, answer string) CallbackResponse {
+	effective := b.accountService.EffectivePolicy(ctx, acc)
+
+	text := b.t(ctx, "settings.detail",
+		acc.Username,
+		acc.MaxDevices,
+		formatEffectiveSettings(b.loc(ctx), effective, &acc.PolicyOverrides),
+	)
+
+	keyboard := AccountSettingsKeyboard(b.loc(ctx), acc.ID, effective, !acc.PolicyOverrides.IsEmpty())
+
+	return CallbackResponse{
+		Answer:     answer,
+		EditText:   text,
+		EditMarkup: &keyboard,
+	}
+}
+
+// formatEffectiveSettings 格式化账号生效的策略设置，账号覆盖的字段带 ✏️ 标记
+func formatEffectiveSettings(loc *i18n.Localizer, p *emby.UserPolicy, o *account.PolicyOverrides) string {
+	mark := func(overridden bool) string {
+		if overridden {
+			return " ✏️"
+		}
+		return ""
+	}
+
+	folders := loc.T("settings.folders_all")
+	if !p.EnableAllFolders {
+		folders = loc.T("settings.folders_some", len(p.EnabledFolders))
+	}
+	_, foldersOverridden := o.Folders()
+
+	var builder strings.Builder
+	builder.WriteString(loc.T("settings.title"))
+	builder.WriteString(loc.T("settings.rating", p.MaxParentalRating, mark(o.MaxParentalRating != nil)))
+	builder.WriteString(loc.T("settings.bitrate", formatBitrate(loc, int(p.RemoteClientBitrateLimit)), mark(o.BitrateLimit != nil)))
+	builder.WriteString(loc.T("settings.downloads", boolEmoji(p.EnableContentDownloading), mark(o.EnableDownloads != nil)))
+	builder.WriteString(loc.T("settings.transcoding", boolEmoji(p.EnableVideoPlaybackTranscoding), mark(o.EnableTranscoding != nil)))
+	builder.WriteString(loc.T("settings.remote", boolEmoji(p.EnableRemoteAccess), mark(o.EnableRemoteAccess != nil)))
+	builder.WriteString(loc.T("settings.folders", folders, mark(foldersOverridden)))
+
+	return builder.String()
+}
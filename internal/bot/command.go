@@ -3,63 +3,76 @@ package bot
 
 import (
 	"strings"
-)
+	"unicode"
 
-// groupAllowedCommands 群组中允许执行的命令白名单
-var groupAllowedCommands = map[string]bool{
-	"grant":        true,
-	"checkemby":    true,
-	"stats":        true,
-	"playingstats": true,
-	"help":         true,
-	"start":        true,
-	"generatecode": true,
-	"listcodes":    true,
-}
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/user"
+)
 
 // registerHandlers 注册所有命令处理器
+// 执行条件(权限、私聊、群组、账号归属)在元数据中声明，由中间件统一校验
 func (b *Bot) registerHandlers() {
 	// 基础命令
-	b.handlers["start"] = b.handleStart
-	b.handlers["help"] = b.handleHelp
+	b.command("start", commandSpec{handler: b.handleStart, groupAllowed: true})
+	b.command("help", commandSpec{handler: b.handleHelp, groupAllowed: true})
 
 	// 用户命令
-	b.handlers["myaccounts"] = b.handleMyAccounts
-	b.handlers["create"] = b.handleCreateAccount
-	b.handlers["info"] = b.handleAccountInfo
-	b.handlers["renew"] = b.handleRenewAccount
-	b.handlers["changepassword"] = b.handleChangePassword
-	b.handlers["quota"] = b.handleQuota
+	b.command("myaccounts", commandSpec{handler: b.handleMyAccounts, privateOnly: true})
+	b.command("create", commandSpec{handler: b.handleCreateAccount, privateOnly: true, privateHint: passwordPrivateHint})
+	b.command("info", commandSpec{handler: b.handleAccountInfo, privateOnly: true, accountArg: 1, accountPermission: account.PermissionView})
+	b.command("renew", commandSpec{handler: b.handleRenewAccount, privateOnly: true, accountArg: 1, accountPermission: account.PermissionRenew})
+	b.command("changepassword", commandSpec{handler: b.handleChangePassword, privateOnly: true, privateHint: passwordPrivateHint, accountArg: 1, accountPermission: account.PermissionPassword})
+	b.command("quota", commandSpec{handler: b.handleQuota, privateOnly: true})
+	b.command("language", commandSpec{handler: b.handleLanguage})
+
+	// 管理命令
+	b.command("admin", commandSpec{handler: b.handleAdmin, staffOnly: true})
+	b.command("grant", commandSpec{handler: b.handleGrant, permission: user.PermUserManage, groupOnly: true})
+	b.command("users", commandSpec{handler: b.handleListUsers, permission: user.PermUserManage})
+	b.command("accounts", commandSpec{handler: b.handleListAccounts, staffOnly: true})
+	b.command("createfor", commandSpec{handler: b.handleCreateFor, staffOnly: true, privateOnly: true, privateHint: passwordPrivateHint})
+	b.command("deleteaccount", commandSpec{handler: b.handleDeleteAccount, permission: user.PermAccountDelete})
+	b.command("suspend", commandSpec{handler: b.handleSuspendAccountCmd, permission: user.PermAccountSuspend})
+	b.command("activate", commandSpec{handler: b.handleActivateAccountCmd, permission: user.PermAccountSuspend})
+	b.command("bulk", commandSpec{handler: b.handleBulk, staffOnly: true, privateOnly: true})
+	b.command("blockuser", commandSpec{handler: b.handleBlockUser, permission: user.PermUserManage})
+	b.command("unblockuser", commandSpec{handler: b.handleUnblockUser, permission: user.PermUserManage})
+	b.command("stats", commandSpec{handler: b.handleStats, permission: user.PermStatsView, groupAllowed: true})
+	b.command("playingstats", commandSpec{handler: b.handlePlayingStats, permission: user.PermSessionView, groupAllowed: true})
+	b.command("updatepolicies", commandSpec{handler: b.handleUpdatePolicies, permission: user.PermAccountPolicy})
+	b.command("broadcast", commandSpec{handler: b.handleBroadcast, permission: user.PermBroadcast, privateOnly: true})
+	b.command("schedule", commandSpec{handler: b.handleSchedule, permission: user.PermScheduleManage, groupAllowed: true})
+	b.command("unschedule", commandSpec{handler: b.handleUnschedule, permission: user.PermScheduleManage, groupAllowed: true})
+	b.command("maintenance", commandSpec{handler: b.handleMaintenance, permission: user.PermScheduleManage, groupAllowed: true})
 
-	// 管理员命令
-	b.handlers["admin"] = b.handleAdmin
-	b.handlers["grant"] = b.handleGrant
-	b.handlers["users"] = b.handleListUsers
-	b.handlers["accounts"] = b.handleListAccounts
-	b.handlers["deleteaccount"] = b.handleDeleteAccount
-	b.handlers["suspend"] = b.handleSuspendAccountCmd
-	b.handlers["activate"] = b.handleActivateAccountCmd
-	b.handlers["setrole"] = b.handleSetRole
-	b.handlers["blockuser"] = b.handleBlockUser
-	b.handlers["unblockuser"] = b.handleUnblockUser
-	b.handlers["stats"] = b.handleStats
-	b.handlers["playingstats"] = b.handlePlayingStats
-	b.handlers["updatepolicies"] = b.handleUpdatePolicies
+	// 角色管理命令(超级管理员)
+	b.command("setrole", commandSpec{handler: b.handleSetRole, adminOnly: true})
+	b.command("roles", commandSpec{handler: b.handleListRoles, adminOnly: true})
+	b.command("addrole", commandSpec{handler: b.handleAddRole, adminOnly: true})
+	b.command("delrole", commandSpec{handler: b.handleDeleteRole, adminOnly: true})
+	b.command("roleperm", commandSpec{handler: b.handleRolePermission, adminOnly: true})
+	b.command("apitoken", commandSpec{handler: b.handleAPIToken, adminOnly: true, privateOnly: true})
+	b.command("export", commandSpec{handler: b.handleExport, adminOnly: true, privateOnly: true})
+	b.command("import", commandSpec{handler: b.handleImport, adminOnly: true, privateOnly: true})
 
 	// Emby 管理命令
-	b.handlers["checkemby"] = b.handleCheckEmby
-	b.handlers["syncstatus"] = b.handleSyncStatus
-	b.handlers["syncaccount"] = b.handleSyncAccount
-	b.handlers["embyusers"] = b.handleListEmbyUsers
-	b.handlers["setdevicelimit"] = b.handleSetDeviceLimit
+	b.command("checkemby", commandSpec{handler: b.handleCheckEmby, permission: user.PermEmbyManage, groupAllowed: true})
+	b.command("syncstatus", commandSpec{handler: b.handleSyncStatus, accountArg: 1, accountPermission: account.PermissionView})
+	b.command("syncaccount", commandSpec{handler: b.handleSyncAccount, permission: user.PermEmbyManage})
+	b.command("embyusers", commandSpec{handler: b.handleListEmbyUsers, permission: user.PermEmbyManage})
+	b.command("setdevicelimit", commandSpec{handler: b.handleSetDeviceLimit, permission: user.PermAccountPolicy})
+	b.command("policydrift", commandSpec{handler: b.handlePolicyDrift, permission: user.PermAccountPolicy})
 
 	// 邀请码管理命令
-	b.handlers["generatecode"] = b.handleGenerateCode
-	b.handlers["listcodes"] = b.handleListCodes
-	b.handlers["codeinfo"] = b.handleCodeInfo
-	b.handlers["revokecode"] = b.handleRevokeCode
+	b.command("generatecode", commandSpec{handler: b.handleGenerateCode, permission: user.PermInviteManage, groupAllowed: true})
+	b.command("listcodes", commandSpec{handler: b.handleListCodes, permission: user.PermInviteManage, groupAllowed: true})
+	b.command("codeinfo", commandSpec{handler: b.handleCodeInfo, permission: user.PermInviteManage})
+	b.command("revokecode", commandSpec{handler: b.handleRevokeCode, permission: user.PermInviteManage})
 }
 
+// passwordPrivateHint 涉及密码的命令在非私聊中的提示
+const passwordPrivateHint = "common.private_password_hint"
+
 // parseArgs 解析命令参数
 func parseArgs(argsString string) []string {
 	if argsString == "" {
@@ -70,6 +83,24 @@ func parseArgs(argsString string) []string {
 	return parts
 }
 
+// splitArgs 取出前 n 个参数，其余部分原样返回(保留换行)
+// 参数不足 n 个时返回已有的参数和空字符串
+func splitArgs(argsString string, n int) ([]string, string) {
+	args := make([]string, 0, n)
+	rest := strings.TrimSpace(argsString)
+	for len(args) < n && rest != "" {
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			args = append(args, rest)
+			rest = ""
+			break
+		}
+		args = append(args, rest[:end])
+		rest = strings.TrimSpace(rest[end:])
+	}
+	return args, rest
+}
+
 // getArg 安全获取参数
 func getArg(args []string, index int) string {
 	if index < len(args) {
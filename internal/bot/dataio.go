@@ -0,0 +1,299 @@
+// Package bot 数据导入导出
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/conversation"
+	"emby-telegram/internal/dataio"
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/user"
+)
+
+const (
+	// maxImportFileSize 导入文件的最大字节数
+	maxImportFileSize = 10 << 20
+	// maxExportFileSize Bot 发送文件的大小上限，更大的数据请使用命令行导出
+	maxExportFileSize = 50 << 20
+	// importReportIssues 导入报告消息中最多列出的问题行，更多时附带完整文件
+	importReportIssues = 20
+)
+
+// handleExport 处理 /export 命令，以文件形式发送数据集
+func (b *Bot) handleExport(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+	if !hasArg(args, 1) {
+		return b.t(ctx, "dataio.export_usage", datasetList(dataio.Datasets)), nil
+	}
+	dataset, err := dataio.ParseDataset(getArg(args, 0))
+	if err != nil {
+		return b.t(ctx, "dataio.export_usage", datasetList(dataio.Datasets)), nil
+	}
+	format, err := dataio.ParseFormat(getArg(args, 1))
+	if err != nil {
+		return b.t(ctx, "dataio.export_usage", datasetList(dataio.Datasets)), nil
+	}
+
+	var buf bytes.Buffer
+	count, err := b.dataService.Export(ctx, &buf, dataset, format)
+	if err != nil {
+		return "", fmt.Errorf("export data: %w", err)
+	}
+	if buf.Len() > maxExportFileSize {
+		return b.t(ctx, "dataio.export_too_large"), nil
+	}
+
+	logger.Infof("user %d exported %d %s records as %s", msg.From.ID, count, dataset, format)
+
+	doc := tgbotapi.NewDocument(msg.Chat.ID, tgbotapi.FileBytes{
+		Name:  dataio.Filename(dataset, format),
+		Bytes: buf.Bytes(),
+	})
+	doc.Caption = b.t(ctx, "dataio.export_caption", dataset, count)
+	if _, err := b.send(ctx, msg.Chat.ID, doc); err != nil {
+		return "", fmt.Errorf("send export file: %w", err)
+	}
+	return "", nil
+}
+
+// handleImport 处理 /import 命令，等待管理员上传文件
+func (b *Bot) handleImport(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+	if !hasArg(args, 1) {
+		return b.t(ctx, "dataio.import_usage", datasetList(dataio.ImportDatasets)), nil
+	}
+	dataset, err := dataio.ParseDataset(getArg(args, 0))
+	if err != nil || !slices.Contains(dataio.ImportDatasets, dataset) {
+		return b.t(ctx, "dataio.import_usage", datasetList(dataio.ImportDatasets)), nil
+	}
+
+	payload := conversation.Payload{Values: map[string]string{"dataset": string(dataset)}}
+	if err := b.stateMachine.SetState(ctx, msg.From.ID, StateWaitingImportFile, payload); err != nil {
+		return "", fmt.Errorf("set conversation state: %w", err)
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.cancel"), CallbackCancel),
+		),
+	)
+	b.sendWithMarkup(ctx, msg.Chat.ID, b.t(ctx, "dataio.import_prompt", dataset, maxImportFileSize>>20), &keyboard)
+	return "", nil
+}
+
+// handleImportFileInput 处理上传的导入文件，先试运行并展示报告，确认后才写入
+// 等待确认时再次上传文件会以新文件重新试运行
+func (b *Bot) handleImportFileInput(ctx context.Context, msg *tgbotapi.Message, currentUser *user.User, payload conversation.Payload) {
+	if !isAdminUser(currentUser) {
+		b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+		b.reply(msg.Chat.ID, b.t(ctx, "common.admin_required"))
+		return
+	}
+
+	dataset, err := dataio.ParseDataset(payload.Values["dataset"])
+	if err != nil || !slices.Contains(dataio.ImportDatasets, dataset) {
+		b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+		b.reply(msg.Chat.ID, b.t(ctx, "common.session_expired"))
+		return
+	}
+
+	if msg.Document == nil {
+		b.reply(msg.Chat.ID, b.t(ctx, "dataio.file_required"))
+		return
+	}
+	format, err := dataio.FormatFromFilename(msg.Document.FileName)
+	if err != nil {
+		b.reply(msg.Chat.ID, b.t(ctx, "dataio.unknown_format"))
+		return
+	}
+	if msg.Document.FileSize > maxImportFileSize {
+		b.reply(msg.Chat.ID, b.t(ctx, "dataio.file_too_large", maxImportFileSize>>20))
+		return
+	}
+
+	data, err := b.downloadFile(ctx, msg.Document.FileID, maxImportFileSize)
+	if err != nil {
+		logger.Errorf("failed to download import file: %v", err)
+		b.reply(msg.Chat.ID, b.t(ctx, "common.error", b.errorText(ctx, err)))
+		return
+	}
+
+	report, err := b.dataService.Import(ctx, bytes.NewReader(data), dataset, format, true)
+	if err != nil {
+		b.reply(msg.Chat.ID, b.t(ctx, "dataio.import_failed", b.errorText(ctx, err)))
+		return
+	}
+
+	if report.Valid == 0 {
+		b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+		b.sendImportReport(ctx, msg.Chat.ID, report, nil)
+		return
+	}
+
+	payload.Values["file_id"] = msg.Document.FileID
+	payload.Values["format"] = string(format)
+	if err := b.stateMachine.SetState(ctx, currentUser.TelegramID, StateWaitingImportConfirm, payload); err != nil {
+		logger.Errorf("failed to set state: %v", err)
+		b.reply(msg.Chat.ID, b.t(ctx, "common.system_error"))
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "dataio.confirm_button", report.Valid), CallbackAdminImportRun),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.cancel"), CallbackCancel),
+		),
+	)
+	b.sendImportReport(ctx, msg.Chat.ID, report, &keyboard)
+}
+
+// handleImportRun 确认导入，在后台重新读取文件并写入
+// 试运行之后数据可能已经变化，因此写入时重新校验每一行
+func (b *Bot) handleImportRun(ctx context.Context, query *tgbotapi.CallbackQuery, currentUser *user.User) CallbackResponse {
+	if !isAdminUser(currentUser) {
+		return CallbackResponse{Answer: b.t(ctx, "common.admin_required"), ShowAlert: true}
+	}
+
+	state, payload := b.stateMachine.GetState(ctx, currentUser.TelegramID)
+	if state != StateWaitingImportConfirm {
+		return CallbackResponse{Answer: b.t(ctx, "common.session_expired"), ShowAlert: true}
+	}
+	b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+
+	dataset, err := dataio.ParseDataset(payload.Values["dataset"])
+	if err != nil || !slices.Contains(dataio.ImportDatasets, dataset) {
+		return CallbackResponse{Answer: b.t(ctx, "common.session_expired"), ShowAlert: true}
+	}
+	format, err := dataio.ParseFormat(payload.Values["format"])
+	if err != nil {
+		return CallbackResponse{Answer: b.t(ctx, "common.session_expired"), ShowAlert: true}
+	}
+	fileID := payload.Values["file_id"]
+	chatID := query.Message.Chat.ID
+	loc := b.loc(ctx)
+
+	b.runJob(func() {
+		jobCtx := withLocalizer(b.handlerCtx, loc)
+
+		data, err := b.downloadFile(jobCtx, fileID, maxImportFileSize)
+		if err != nil {
+			logger.Errorf("failed to download import file: %v", err)
+			b.reply(chatID, loc.T("dataio.import_failed", b.errorText(jobCtx, err)))
+			return
+		}
+
+		report, err := b.dataService.Import(jobCtx, bytes.NewReader(data), dataset, format, false)
+		if err != nil {
+			logger.Errorf("import of %s by user %d failed: %v", dataset, currentUser.TelegramID, err)
+			b.reply(chatID, loc.T("dataio.import_failed", b.errorText(jobCtx, err)))
+			// 中途失败时已写入的行仍然有效，同时发送报告
+			if report == nil || report.Imported == 0 {
+				return
+			}
+		}
+		logger.Infof("user %d imported %d/%d %s records", currentUser.TelegramID, report.Imported, report.Total, dataset)
+		b.sendImportReport(jobCtx, chatID, report, nil)
+	})
+
+	return CallbackResponse{
+		Answer:   b.t(ctx, "dataio.import_started"),
+		EditText: b.t(ctx, "dataio.import_running", dataset),
+	}
+}
+
+// sendImportReport 发送导入报告，问题行较多时附带完整的问题列表文件
+func (b *Bot) sendImportReport(ctx context.Context, chatID int64, report *dataio.ImportReport, markup *tgbotapi.InlineKeyboardMarkup) {
+	b.sendWithMarkup(ctx, chatID, b.importReportText(ctx, report), markup)
+
+	if len(report.Issues) <= importReportIssues {
+		return
+	}
+	var sb strings.Builder
+	for _, issue := range report.Issues {
+		fmt.Fprintf(&sb, "%d\t%s\t%s\n", issue.Row, issue.Key, b.errorText(ctx, issue.Err))
+	}
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("%s-import-issues.txt", report.Dataset),
+		Bytes: []byte(sb.String()),
+	})
+	if _, err := b.send(ctx, chatID, doc); err != nil {
+		logger.Warnf("failed to send import issues file: %v", err)
+	}
+}
+
+// importReportText 导入报告文本
+func (b *Bot) importReportText(ctx context.Context, report *dataio.ImportReport) string {
+	var sb strings.Builder
+	if report.DryRun {
+		sb.WriteString(b.t(ctx, "dataio.dry_run_title", report.Dataset, report.Total, report.Valid, len(report.Issues)))
+	} else {
+		sb.WriteString(b.t(ctx, "dataio.import_title", report.Dataset, report.Total, report.Imported, len(report.Issues)))
+	}
+
+	if len(report.Issues) > 0 {
+		sb.WriteString(b.t(ctx, "dataio.issues_header"))
+		for i, issue := range report.Issues {
+			if i == importReportIssues {
+				sb.WriteString(b.t(ctx, "dataio.issues_more", len(report.Issues)-importReportIssues))
+				break
+			}
+			key := issue.Key
+			if key == "" {
+				key = "-"
+			}
+			sb.WriteString(b.t(ctx, "dataio.issue_item", issue.Row, html.EscapeString(key), html.EscapeString(b.errorText(ctx, issue.Err))))
+		}
+	}
+
+	if report.DryRun && report.Valid > 0 {
+		sb.WriteString(b.t(ctx, "dataio.confirm_hint"))
+	}
+	return sb.String()
+}
+
+// downloadFile 下载用户上传的文件，超过 limit 字节时返回错误
+func (b *Bot) downloadFile(ctx context.Context, fileID string, limit int64) ([]byte, error) {
+	fileURL, err := b.api.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, fmt.Errorf("get file url: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// 错误信息中的 URL 含有 Bot 令牌，不能原样返回
+		return nil, fmt.Errorf("download file: request failed")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download file: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("download file: larger than %d bytes", limit)
+	}
+	return data, nil
+}
+
+// datasetList 数据集名称列表，用于用法提示
+func datasetList(datasets []dataio.Dataset) string {
+	names := make([]string, len(datasets))
+	for i, d := range datasets {
+		names[i] = "<code>" + string(d) + "</code>"
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,183 @@
+// Package bot 更新分发器
+package bot
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/logger"
+)
+
+// 分发器默认参数
+const (
+	defaultWorkers   = 8
+	defaultQueueSize = 64
+)
+
+// backpressureWarnInterval 队列已满告警的最小间隔
+const backpressureWarnInterval = 10 * time.Second
+
+// DispatcherStats 分发器运行指标
+type DispatcherStats struct {
+	Workers    int           // 工作协程数
+	QueueSize  int           // 每个工作协程的队列容量
+	Queued     int           // 当前排队中的更新数
+	Dispatched int64         // 已提交的更新总数
+	Processed  int64         // 已处理完成的更新总数
+	Panics     int64         // 处理过程中恢复的 panic 次数
+	Blocked    int64         // 因队列已满而等待的提交次数
+	BlockedFor time.Duration // 提交累计等待时间
+}
+
+// dispatcher 固定大小的工作池
+// 按用户 ID 将更新哈希到固定的工作协程，保证同一用户的更新按顺序处理
+type dispatcher struct {
+	queues []chan tgbotapi.Update
+	handle func(ctx context.Context, update tgbotapi.Update)
+	wg     sync.WaitGroup
+
+	dispatched atomic.Int64
+	processed  atomic.Int64
+	panics     atomic.Int64
+	blocked    atomic.Int64
+	blockedNs  atomic.Int64
+	lastWarn   atomic.Int64
+}
+
+// newDispatcher 创建分发器，workers 或 queueSize 不大于 0 时使用默认值
+func newDispatcher(workers, queueSize int, handle func(ctx context.Context, update tgbotapi.Update)) *dispatcher {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	d := &dispatcher{
+		queues: make([]chan tgbotapi.Update, workers),
+		handle: handle,
+	}
+	for i := range d.queues {
+		d.queues[i] = make(chan tgbotapi.Update, queueSize)
+	}
+	return d
+}
+
+// start 启动所有工作协程
+// ctx 传给每个处理函数；工作协程在队列关闭且排空后退出
+func (d *dispatcher) start(ctx context.Context) {
+	for _, q := range d.queues {
+		d.wg.Add(1)
+		go d.work(ctx, q)
+	}
+}
+
+// close 关闭所有队列，不再接受新的更新
+// 只能在最后一次 submit 返回后调用
+func (d *dispatcher) close() {
+	for _, q := range d.queues {
+		close(q)
+	}
+}
+
+// wait 等待所有工作协程处理完剩余更新并退出
+func (d *dispatcher) wait() {
+	d.wg.Wait()
+}
+
+// submit 提交更新，队列已满时阻塞直到有空位或 ctx 取消
+// ctx 为接收更新的上下文，与处理函数的上下文相互独立
+func (d *dispatcher) submit(ctx context.Context, update tgbotapi.Update) {
+	q := d.queues[d.slot(update)]
+	d.dispatched.Add(1)
+
+	select {
+	case q <- update:
+		return
+	default:
+	}
+
+	// 队列已满，阻塞等待以向上游施加背压
+	d.blocked.Add(1)
+	d.warnBackpressure()
+	started := time.Now()
+	defer func() { d.blockedNs.Add(int64(time.Since(started))) }()
+
+	select {
+	case q <- update:
+	case <-ctx.Done():
+		logger.Warnf("update %d dropped: dispatcher stopping", update.UpdateID)
+	}
+}
+
+// slot 计算更新所属的工作协程
+// 同一用户的更新总是落在同一个工作协程上，没有发送者的更新按聊天 ID 分配
+func (d *dispatcher) slot(update tgbotapi.Update) int {
+	var key int64
+	if from := update.SentFrom(); from != nil {
+		key = from.ID
+	} else if chat := update.FromChat(); chat != nil {
+		key = chat.ID
+	}
+
+	if key < 0 {
+		key = -key
+	}
+	return int(key % int64(len(d.queues)))
+}
+
+// work 顺序处理单个队列中的更新，直到队列关闭
+func (d *dispatcher) work(ctx context.Context, q <-chan tgbotapi.Update) {
+	defer d.wg.Done()
+
+	for update := range q {
+		d.process(ctx, update)
+	}
+}
+
+// process 处理单个更新，panic 被恢复并记录，不影响工作协程
+func (d *dispatcher) process(ctx context.Context, update tgbotapi.Update) {
+	defer d.processed.Add(1)
+	defer func() {
+		if r := recover(); r != nil {
+			d.panics.Add(1)
+			logger.Errorf("panic while handling update %d: %v\n%s", update.UpdateID, r, debug.Stack())
+		}
+	}()
+
+	d.handle(ctx, update)
+}
+
+// warnBackpressure 队列已满时告警，限制告警频率
+func (d *dispatcher) warnBackpressure() {
+	now := time.Now().UnixNano()
+	last := d.lastWarn.Load()
+	if now-last < int64(backpressureWarnInterval) || !d.lastWarn.CompareAndSwap(last, now) {
+		return
+	}
+	logger.Warnf("dispatcher queue full, applying backpressure (blocked submissions: %d)", d.blocked.Load())
+}
+
+// stats 返回当前运行指标
+func (d *dispatcher) stats() DispatcherStats {
+	queued := 0
+	for _, q := range d.queues {
+		queued += len(q)
+	}
+
+	return DispatcherStats{
+		Workers:    len(d.queues),
+		QueueSize:  cap(d.queues[0]),
+		Queued:     queued,
+		Dispatched: d.dispatched.Load(),
+		Processed:  d.processed.Load(),
+		Panics:     d.panics.Load(),
+		Blocked:    d.blocked.Load(),
+		BlockedFor: time.Duration(d.blockedNs.Load()),
+	}
+}
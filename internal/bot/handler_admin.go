@@ -3,13 +3,16 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"emby-telegram/internal/account"
+	"emby-telegram/internal/i18n"
 	"emby-telegram/internal/logger"
 	"emby-telegram/internal/user"
 	"emby-telegram/pkg/timeutil"
@@ -17,60 +20,11 @@ import (
 
 // handleAdmin 处理 /admin 命令
 func (b *Bot) handleAdmin(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "❌ 此命令需要管理员权限", nil
-	}
-
-	return `🔑 <b>管理员命令</b>
-
-<b>用户管理:</b>
-/users [页码] - 列出所有用户
-/setrole &lt;telegram_id&gt; &lt;admin|user&gt; - 设置用户角色
-/blockuser &lt;telegram_id&gt; - 封禁用户
-/unblockuser &lt;telegram_id&gt; - 解封用户
-/grant &lt;telegram_id&gt; &lt;配额&gt; - 授予用户账号配额
-
-<b>账号管理:</b>
-/accounts [页码] - 列出所有账号
-/deleteaccount &lt;用户名&gt; - 删除账号
-/suspend &lt;用户名&gt; - 暂停账号
-/activate &lt;用户名&gt; - 激活账号
-
-<b>邀请码管理:</b>
-/generatecode [次数] [天数] [描述] - 生成邀请码
-/listcodes [页码] - 列出所有邀请码
-/codeinfo &lt;邀请码&gt; - 查看邀请码详情
-/revokecode &lt;邀请码&gt; - 撤销邀请码
-
-<b>Emby 管理:</b>
-/checkemby - 检查 Emby 服务器连接状态
-/syncaccount &lt;用户名&gt; &lt;密码&gt; - 手动同步账号到 Emby
-/embyusers - 列出 Emby 服务器上的所有用户
-/setdevicelimit &lt;用户名&gt; &lt;设备数&gt; - 设置账号设备限制
-/updatepolicies - 批量更新所有非管理员用户策略
-
-<b>统计信息:</b>
-/stats - 查看系统统计
-/playingstats - 查看 Emby 播放状态
-
-<b>使用示例:</b>
-<code>/users 1</code> - 查看第1页用户
-<code>/grant 123456 1</code> - 授予用户1个账号配额
-<code>/generatecode 10 30 "推广活动"</code> - 生成10次/30天邀请码
-<code>/listcodes 1</code> - 查看第1页邀请码
-<code>/codeinfo ABC12345</code> - 查看邀请码详情
-<code>/revokecode ABC12345</code> - 撤销邀请码
-<code>/deleteaccount emby_john</code> - 删除账号
-<code>/checkemby</code> - 检查 Emby 连接
-`, nil
+	return b.t(ctx, "admin.help"), nil
 }
 
 // handleListUsers 处理 /users 命令
 func (b *Bot) handleListUsers(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "❌ 此命令需要管理员权限", nil
-	}
-
 	page := 1
 	if hasArg(args, 1) {
 		if p, err := strconv.Atoi(getArg(args, 0)); err == nil && p > 0 {
@@ -83,17 +37,17 @@ func (b *Bot) handleListUsers(ctx context.Context, msg *tgbotapi.Message, args [
 
 	users, err := b.userService.List(ctx, offset, limit)
 	if err != nil {
-		return "", fmt.Errorf("获取用户列表失败: %w", err)
+		return "", fmt.Errorf("list users: %w", err)
 	}
 
 	totalCount, _ := b.userService.Count(ctx)
 
 	if len(users) == 0 {
-		return "没有找到用户", nil
+		return b.t(ctx, "users.empty"), nil
 	}
 
 	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("👥 <b>用户列表</b> (第 %d 页，共 %d 个用户)\n\n", page, totalCount))
+	builder.WriteString(b.t(ctx, "users.title", page, totalCount))
 
 	for i, u := range users {
 		roleEmoji := "👤"
@@ -107,15 +61,15 @@ func (b *Bot) handleListUsers(ctx context.Context, msg *tgbotapi.Message, args [
 		}
 
 		builder.WriteString(fmt.Sprintf("%d. %s %s %s\n", offset+i+1, roleEmoji, u.DisplayName(), statusEmoji))
-		builder.WriteString(fmt.Sprintf("   ID: <code>%d</code> | 角色: %s\n", u.TelegramID, u.Role))
-		builder.WriteString(fmt.Sprintf("   注册时间: %s\n\n", timeutil.FormatDate(u.CreatedAt)))
+		builder.WriteString(b.t(ctx, "users.item_id", u.TelegramID, u.Role))
+		builder.WriteString(b.t(ctx, "users.item_joined", timeutil.FormatDate(u.CreatedAt)))
 	}
 
 	totalPages := (int(totalCount) + limit - 1) / limit
 	if totalPages > 1 {
-		builder.WriteString(fmt.Sprintf("📄 第 %d/%d 页\n", page, totalPages))
+		builder.WriteString(b.t(ctx, "common.page", page, totalPages))
 		if page < totalPages {
-			builder.WriteString(fmt.Sprintf("使用 <code>/users %d</code> 查看下一页\n", page+1))
+			builder.WriteString(b.t(ctx, "users.next_page", page+1))
 		}
 	}
 
@@ -124,10 +78,6 @@ func (b *Bot) handleListUsers(ctx context.Context, msg *tgbotapi.Message, args [
 
 // handleListAccounts 处理 /accounts 命令
 func (b *Bot) handleListAccounts(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "❌ 此命令需要管理员权限", nil
-	}
-
 	page := 1
 	if hasArg(args, 1) {
 		if p, err := strconv.Atoi(getArg(args, 0)); err == nil && p > 0 {
@@ -138,34 +88,41 @@ func (b *Bot) handleListAccounts(ctx context.Context, msg *tgbotapi.Message, arg
 	limit := 10
 	offset := (page - 1) * limit
 
-	accounts, err := b.accountService.ListAll(ctx, offset, limit)
+	currentUser := currentUserFromContext(ctx)
+	accounts, totalCount, err := b.accountService.ListManaged(ctx, currentUser.ID, account.ListQuery{Offset: offset, Limit: limit})
 	if err != nil {
-		return "", fmt.Errorf("获取账号列表失败: %w", err)
+		if errors.Is(err, account.ErrUnauthorized) {
+			return b.t(ctx, "admin_accounts.forbidden"), nil
+		}
+		return "", fmt.Errorf("list accounts: %w", err)
 	}
 
-	totalCount, _ := b.accountService.Count(ctx)
-
 	if len(accounts) == 0 {
-		return "没有找到账号", nil
+		return b.t(ctx, "admin_accounts.empty"), nil
+	}
+
+	title := b.t(ctx, "admin_accounts.title_all")
+	if !b.userService.Can(ctx, currentUser, user.PermAccountView) {
+		title = b.t(ctx, "admin_accounts.title_managed")
 	}
 
 	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("📋 <b>所有账号列表</b> (第 %d 页，共 %d 个账号)\n\n", page, totalCount))
+	builder.WriteString(b.t(ctx, "admin_accounts.title", title, page, totalCount))
 
 	for i, acc := range accounts {
 		status := getStatusEmoji(string(acc.Status))
-		expireInfo := timeutil.FormatExpireTime(acc.ExpireAt)
+		expireInfo := b.expireText(ctx, acc.ExpireAt)
 
 		builder.WriteString(fmt.Sprintf("%d. <b>%s</b> %s\n", offset+i+1, acc.Username, status))
-		builder.WriteString(fmt.Sprintf("   用户ID: %d | 状态: %s\n", acc.UserID, acc.Status))
-		builder.WriteString(fmt.Sprintf("   到期: %s\n\n", expireInfo))
+		builder.WriteString(b.t(ctx, "admin_accounts.item_owner", acc.GetOwnerDisplayName(), acc.Status))
+		builder.WriteString(b.t(ctx, "admin_accounts.item_expire", expireInfo))
 	}
 
 	totalPages := (int(totalCount) + limit - 1) / limit
 	if totalPages > 1 {
-		builder.WriteString(fmt.Sprintf("📄 第 %d/%d 页\n", page, totalPages))
+		builder.WriteString(b.t(ctx, "common.page", page, totalPages))
 		if page < totalPages {
-			builder.WriteString(fmt.Sprintf("使用 <code>/accounts %d</code> 查看下一页\n", page+1))
+			builder.WriteString(b.t(ctx, "admin_accounts.next_page", page+1))
 		}
 	}
 
@@ -174,84 +131,68 @@ func (b *Bot) handleListAccounts(ctx context.Context, msg *tgbotapi.Message, arg
 
 // handleDeleteAccount 处理 /deleteaccount 命令
 func (b *Bot) handleDeleteAccount(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "❌ 此命令需要管理员权限", nil
-	}
-
 	if !hasArg(args, 1) {
-		return "❌ 请提供用户名\n\n使用方法: <code>/deleteaccount &lt;用户名&gt;</code>", nil
+		return b.t(ctx, "admin_accounts.delete_usage"), nil
 	}
 
 	username := getArg(args, 0)
 
 	acc, err := b.accountService.GetByUsername(ctx, username)
 	if err != nil {
-		return "", fmt.Errorf("获取账号信息失败: %w", err)
+		return "", fmt.Errorf("get account: %w", err)
 	}
 
-	if err := b.accountService.Delete(ctx, acc.ID); err != nil {
-		return "", fmt.Errorf("删除账号失败: %w", err)
+	if err := b.accountService.DeleteAs(ctx, currentUserFromContext(ctx).ID, acc.ID); err != nil {
+		return "", fmt.Errorf("delete account: %w", err)
 	}
 
-	return fmt.Sprintf("✅ 账号 <b>%s</b> 已删除", acc.Username), nil
+	return b.t(ctx, "admin_accounts.deleted", acc.Username), nil
 }
 
 // handleSuspendAccountCmd 处理 /suspend 命令
 func (b *Bot) handleSuspendAccountCmd(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "❌ 此命令需要管理员权限", nil
-	}
-
 	if !hasArg(args, 1) {
-		return "❌ 请提供用户名\n\n使用方法: <code>/suspend &lt;用户名&gt;</code>", nil
+		return b.t(ctx, "admin_accounts.suspend_usage"), nil
 	}
 
 	username := getArg(args, 0)
 
 	acc, err := b.accountService.GetByUsername(ctx, username)
 	if err != nil {
-		return "", fmt.Errorf("获取账号信息失败: %w", err)
+		return "", fmt.Errorf("get account: %w", err)
 	}
 
-	if err := b.accountService.Suspend(ctx, acc.ID); err != nil {
-		return "", fmt.Errorf("暂停账号失败: %w", err)
+	if err := b.accountService.SuspendAs(ctx, currentUserFromContext(ctx).ID, acc.ID); err != nil {
+		return "", fmt.Errorf("suspend account: %w", err)
 	}
 
-	return fmt.Sprintf("⏸️ 账号 <b>%s</b> 已暂停", acc.Username), nil
+	return b.t(ctx, "admin_accounts.suspended", acc.Username), nil
 }
 
 // handleActivateAccountCmd 处理 /activate 命令
 func (b *Bot) handleActivateAccountCmd(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "❌ 此命令需要管理员权限", nil
-	}
-
 	if !hasArg(args, 1) {
-		return "❌ 请提供用户名\n\n使用方法: <code>/activate &lt;用户名&gt;</code>", nil
+		return b.t(ctx, "admin_accounts.activate_usage"), nil
 	}
 
 	username := getArg(args, 0)
 
 	acc, err := b.accountService.GetByUsername(ctx, username)
 	if err != nil {
-		return "", fmt.Errorf("获取账号信息失败: %w", err)
+		return "", fmt.Errorf("get account: %w", err)
 	}
 
-	if err := b.accountService.Activate(ctx, acc.ID); err != nil {
-		return "", fmt.Errorf("激活账号失败: %w", err)
+	if err := b.accountService.ActivateAs(ctx, currentUserFromContext(ctx).ID, acc.ID); err != nil {
+		return "", fmt.Errorf("activate account: %w", err)
 	}
 
-	return fmt.Sprintf("✅ 账号 <b>%s</b> 已激活", acc.Username), nil
+	return b.t(ctx, "admin_accounts.activated", acc.Username), nil
 }
 
 // handleSetRole 处理 /setrole 命令
 func (b *Bot) handleSetRole(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "❌ 此命令需要管理员权限", nil
-	}
-
 	if !hasArg(args, 2) {
-		return "❌ 参数不足\n\n使用方法: <code>/setrole &lt;telegram_id&gt; &lt;admin|user&gt;</code>\n例如: <code>/setrole 123456 admin</code>", nil
+		return b.t(ctx, "setrole.usage"), nil
 	}
 
 	telegramIDStr := getArg(args, 0)
@@ -259,77 +200,112 @@ func (b *Bot) handleSetRole(ctx context.Context, msg *tgbotapi.Message, args []s
 
 	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
 	if err != nil {
-		return "❌ Telegram ID 必须是有效的数字", nil
-	}
-
-	if role != "admin" && role != "user" {
-		return "❌ 角色必须是 admin 或 user", nil
+		return b.t(ctx, "common.invalid_telegram_id"), nil
 	}
 
 	if err := b.userService.SetRole(ctx, telegramID, role); err != nil {
-		return "", fmt.Errorf("设置角色失败: %w", err)
+		if errors.Is(err, user.ErrInvalidRole) {
+			return b.t(ctx, "setrole.invalid_role", role), nil
+		}
+		if errors.Is(err, user.ErrConfigAdmin) {
+			return b.t(ctx, "setrole.config_admin"), nil
+		}
+		return "", fmt.Errorf("set role: %w", err)
 	}
 
 	roleEmoji := "👤"
-	if role == "admin" {
+	if role == string(user.RoleAdmin) {
 		roleEmoji = "👑"
+	} else if role != string(user.RoleUser) {
+		roleEmoji = "🛡"
 	}
 
-	return fmt.Sprintf("%s 已将用户 %d 设置为 <b>%s</b>", roleEmoji, telegramID, role), nil
+	return b.t(ctx, "setrole.done", roleEmoji, telegramID, role), nil
 }
 
-// handleBlockUser 处理 /blockuser 命令
-func (b *Bot) handleBlockUser(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "❌ 此命令需要管理员权限", nil
+// handleCreateFor 处理 /createfor 命令
+// 为其他用户代开账号，不占用该用户的配额
+func (b *Bot) handleCreateFor(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+	if !hasArg(args, 2) {
+		return b.t(ctx, "createfor.usage"), nil
+	}
+
+	telegramID, err := strconv.ParseInt(getArg(args, 0), 10, 64)
+	if err != nil {
+		return b.t(ctx, "common.invalid_telegram_id"), nil
+	}
+
+	owner, err := b.userService.GetByTelegramID(ctx, telegramID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return b.t(ctx, "createfor.user_not_found"), nil
+		}
+		return "", fmt.Errorf("get user: %w", err)
+	}
+
+	currentUser := currentUserFromContext(ctx)
+	acc, plainPassword, err := b.accountService.CreateFor(ctx, currentUser.ID, owner.ID, getArg(args, 1))
+	if err != nil {
+		if errors.Is(err, account.ErrUnauthorized) {
+			return b.t(ctx, "createfor.forbidden"), nil
+		}
+		if errors.Is(err, account.ErrAlreadyExists) || errors.Is(err, account.ErrInvalidInput) {
+			return "❌ " + b.errorText(ctx, err), nil
+		}
+		return "", fmt.Errorf("create account: %w", err)
 	}
 
+	logger.Infof("account %s created by user %d for user %d", acc.Username, currentUser.TelegramID, owner.TelegramID)
+
+	return b.t(ctx, "createfor.done",
+		owner.DisplayName(),
+		acc.Username,
+		plainPassword,
+		b.expireText(ctx, acc.ExpireAt),
+		acc.MaxDevices,
+	), nil
+}
+
+// handleBlockUser 处理 /blockuser 命令
+func (b *Bot) handleBlockUser(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
 	if !hasArg(args, 1) {
-		return "❌ 请提供 Telegram ID\n\n使用方法: <code>/blockuser &lt;telegram_id&gt;</code>", nil
+		return b.t(ctx, "block.usage"), nil
 	}
 
 	telegramIDStr := getArg(args, 0)
 	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
 	if err != nil {
-		return "❌ Telegram ID 必须是有效的数字", nil
+		return b.t(ctx, "common.invalid_telegram_id"), nil
 	}
 
 	if err := b.userService.Block(ctx, telegramID); err != nil {
-		return "", fmt.Errorf("封禁用户失败: %w", err)
+		return "", fmt.Errorf("block user: %w", err)
 	}
 
-	return fmt.Sprintf("🚫 已封禁用户 %d", telegramID), nil
+	return b.t(ctx, "block.done", telegramID), nil
 }
 
 // handleUnblockUser 处理 /unblockuser 命令
 func (b *Bot) handleUnblockUser(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "❌ 此命令需要管理员权限", nil
-	}
-
 	if !hasArg(args, 1) {
-		return "❌ 请提供 Telegram ID\n\n使用方法: <code>/unblockuser &lt;telegram_id&gt;</code>", nil
+		return b.t(ctx, "unblock.usage"), nil
 	}
 
 	telegramIDStr := getArg(args, 0)
 	telegramID, err := strconv.ParseInt(telegramIDStr, 10, 64)
 	if err != nil {
-		return "❌ Telegram ID 必须是有效的数字", nil
+		return b.t(ctx, "common.invalid_telegram_id"), nil
 	}
 
 	if err := b.userService.Unblock(ctx, telegramID); err != nil {
-		return "", fmt.Errorf("解封用户失败: %w", err)
+		return "", fmt.Errorf("unblock user: %w", err)
 	}
 
-	return fmt.Sprintf("✅ 已解封用户 %d", telegramID), nil
+	return b.t(ctx, "unblock.done", telegramID), nil
 }
 
 // handleStats 处理 /stats 命令
 func (b *Bot) handleStats(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "❌ 此命令需要管理员权限", nil
-	}
-
 	// 统计用户
 	totalUsers, _ := b.userService.Count(ctx)
 	adminCount, _ := b.userService.CountByRole(ctx, user.RoleAdmin)
@@ -341,21 +317,7 @@ func (b *Bot) handleStats(ctx context.Context, msg *tgbotapi.Message, args []str
 	suspendedAccounts, _ := b.accountService.CountByStatus(ctx, account.StatusSuspended)
 	expiredAccounts, _ := b.accountService.CountByStatus(ctx, account.StatusExpired)
 
-	return fmt.Sprintf(`📊 <b>系统统计</b>
-
-<b>用户统计:</b>
-• 总用户数: %d
-• 管理员: %d
-• 普通用户: %d
-
-<b>账号统计:</b>
-• 总账号数: %d
-• 激活账号: %d ✅
-• 暂停账号: %d ⏸️
-• 过期账号: %d ❌
-
-<b>平均账号数:</b> %.2f 个/用户
-`,
+	return b.t(ctx, "stats.text",
 		totalUsers,
 		adminCount,
 		userCount,
@@ -364,26 +326,54 @@ func (b *Bot) handleStats(ctx context.Context, msg *tgbotapi.Message, args []str
 		suspendedAccounts,
 		expiredAccounts,
 		float64(totalAccounts)/float64(totalUsers),
+		b.formatQueueStats(ctx),
 	), nil
 }
 
-func (b *Bot) handlePlayingStats(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "❌ 此命令需要管理员权限", nil
-	}
+// formatQueueStats 格式化更新处理队列与发送队列指标
+func (b *Bot) formatQueueStats(ctx context.Context) string {
+	loc := b.loc(ctx)
+	return formatDispatcherStats(loc, b.dispatcher.stats()) + "\n\n" + formatSenderStats(loc, b.sender.stats())
+}
 
+// formatDispatcherStats 格式化更新处理队列指标
+func formatDispatcherStats(loc *i18n.Localizer, s DispatcherStats) string {
+	return loc.T("stats.dispatcher",
+		s.Workers,
+		s.QueueSize,
+		s.Queued,
+		s.Dispatched,
+		s.Processed,
+		s.Blocked,
+		s.BlockedFor.Round(time.Millisecond),
+		s.Panics,
+	)
+}
+
+// formatSenderStats 格式化发送队列指标
+func formatSenderStats(loc *i18n.Localizer, s SenderStats) string {
+	return loc.T("stats.sender",
+		s.Pending,
+		s.Sent,
+		s.Failed,
+		s.Retried,
+		s.Throttled,
+	)
+}
+
+func (b *Bot) handlePlayingStats(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
 	if b.embyClient == nil {
-		return "❌ Emby 同步未启用", nil
+		return "❌ " + b.t(ctx, "error.sync_disabled"), nil
 	}
 
 	sessions, err := b.embyClient.GetSessions(ctx)
 	if err != nil {
 		logger.Errorf("failed to get emby sessions: %v", err)
-		return "", fmt.Errorf("获取播放状态失败: %w", err)
+		return "", fmt.Errorf("get playing sessions: %w", err)
 	}
 
 	if len(sessions) == 0 {
-		return "📺 当前没有活跃的播放会话", nil
+		return b.t(ctx, "playing.none"), nil
 	}
 
 	var playingSessions []string
@@ -402,19 +392,19 @@ func (b *Bot) handlePlayingStats(ctx context.Context, msg *tgbotapi.Message, arg
 				session.PlayState.PlayMethod)
 
 			if session.TranscodingInfo != nil && (!session.TranscodingInfo.IsVideoDirect || !session.TranscodingInfo.IsAudioDirect) {
-				info += fmt.Sprintf(" | 转码中 (%.1f%%)", session.TranscodingInfo.CompletionPercentage)
+				info += b.t(ctx, "playing.transcoding", session.TranscodingInfo.CompletionPercentage)
 			}
 
 			playingSessions = append(playingSessions, info)
 		} else if session.NowPlayingItem != nil {
-			idleSessions = append(idleSessions, fmt.Sprintf("👤 <b>%s</b> - 已暂停", session.UserName))
+			idleSessions = append(idleSessions, b.t(ctx, "playing.paused_item", session.UserName))
 		}
 	}
 
-	result := "📺 <b>Emby 播放状态</b>\n\n"
+	result := b.t(ctx, "playing.title")
 
 	if len(playingSessions) > 0 {
-		result += fmt.Sprintf("<b>正在播放 (%d):</b>\n", len(playingSessions))
+		result += b.t(ctx, "playing.playing_title", len(playingSessions))
 		for i, info := range playingSessions {
 			if i > 0 {
 				result += "\n"
@@ -427,37 +417,58 @@ func (b *Bot) handlePlayingStats(ctx context.Context, msg *tgbotapi.Message, arg
 		if len(playingSessions) > 0 {
 			result += "\n"
 		}
-		result += fmt.Sprintf("<b>已暂停 (%d):</b>\n", len(idleSessions))
+		result += b.t(ctx, "playing.paused_title", len(idleSessions))
 		for _, info := range idleSessions {
 			result += info + "\n"
 		}
 	}
 
-	result += fmt.Sprintf("\n📊 总会话数: %d", len(sessions))
+	result += b.t(ctx, "playing.total", len(sessions))
 
 	return result, nil
 }
 
+// handleUpdatePolicies 处理 /updatepolicies 命令
+// 用法: /updatepolicies <模板名> <active|all|emby|用户名...>
 func (b *Bot) handleUpdatePolicies(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "❌ 此命令需要管理员权限", nil
+	if b.embyClient == nil {
+		return "❌ " + b.t(ctx, "error.sync_disabled"), nil
 	}
 
-	if b.embyClient == nil {
-		return "❌ Emby 同步未启用", nil
+	if len(args) < 2 {
+		tpls, err := b.policyService.List(ctx)
+		if err != nil {
+			return "", fmt.Errorf("list policy templates: %w", err)
+		}
+
+		var names []string
+		for _, tpl := range tpls {
+			name := "<code>" + tpl.Name + "</code>"
+			if tpl.IsDefault {
+				name += " ⭐"
+			}
+			names = append(names, name)
+		}
+
+		return b.t(ctx, "updatepolicies.usage", strings.Join(names, ", ")), nil
 	}
 
-	updated, failed, err := b.embyClient.BatchUpdateNonAdminPolicies(ctx)
+	tpl, err := b.policyService.GetByName(ctx, args[0])
 	if err != nil {
-		logger.Errorf("failed to batch update policies: %v", err)
-		return "", fmt.Errorf("批量更新策略失败: %w", err)
+		return "", err
 	}
 
-	return fmt.Sprintf(`✅ <b>批量更新用户策略完成</b>
+	scope := args[1]
+	var usernames []string
+	if scope != policyScopeActive && scope != policyScopeAll && scope != policyScopeEmby {
+		usernames = args[1:]
+	}
 
-• 成功更新: %d 个用户
-• 失败: %d 个用户
-• 已跳过管理员账号
+	updated, failed, err := b.applyPolicyTemplate(ctx, tpl, scope, usernames)
+	if err != nil {
+		logger.Errorf("failed to batch update policies: %v", err)
+		return "", fmt.Errorf("update policies: %w", err)
+	}
 
-所有非管理员用户已应用默认安全策略。`, updated, failed), nil
+	return formatPolicyApplyResult(b.loc(ctx), tpl, scope, updated, failed), nil
 }
@@ -0,0 +1,96 @@
+// Package bot 管理 API 令牌
+package bot
+
+import (
+	"context"
+	"html"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/apitoken"
+	"emby-telegram/pkg/timeutil"
+)
+
+// handleAPIToken 处理 /apitoken 命令
+// 令牌明文只在创建时显示一次，因此命令仅限私聊
+func (b *Bot) handleAPIToken(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+	if len(args) == 0 {
+		return b.apiTokenListText(ctx)
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "create":
+		if !hasArg(args, 3) {
+			return b.t(ctx, "apitoken.usage", apiScopeList()), nil
+		}
+		scopes, err := apitoken.ParseScopes(getArg(args, 2))
+		if err != nil {
+			return "", err
+		}
+
+		t, raw, err := b.apiTokenService.Create(ctx, getArg(args, 1), scopes, msg.From.ID)
+		if err != nil {
+			return "", err
+		}
+		return b.t(ctx, "apitoken.created", t.ID, html.EscapeString(t.Name), t.Scopes, raw), nil
+
+	case "revoke":
+		if !hasArg(args, 2) {
+			return b.t(ctx, "apitoken.usage", apiScopeList()), nil
+		}
+		id, err := strconv.ParseUint(strings.TrimPrefix(getArg(args, 1), "#"), 10, 64)
+		if err != nil {
+			return b.t(ctx, "apitoken.invalid_id"), nil
+		}
+
+		if err := b.apiTokenService.Revoke(ctx, uint(id)); err != nil {
+			return "", err
+		}
+		return b.t(ctx, "apitoken.revoked", id), nil
+
+	default:
+		return b.t(ctx, "apitoken.usage", apiScopeList()), nil
+	}
+}
+
+// apiTokenListText 令牌列表和用法
+func (b *Bot) apiTokenListText(ctx context.Context) (string, error) {
+	list, err := b.apiTokenService.List(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(list) == 0 {
+		return b.t(ctx, "apitoken.empty") + "\n\n" + b.t(ctx, "apitoken.usage", apiScopeList()), nil
+	}
+
+	var builder strings.Builder
+	builder.WriteString(b.t(ctx, "apitoken.list_title", len(list)))
+	for _, t := range list {
+		lastUsed := b.t(ctx, "apitoken.never_used")
+		if t.LastUsedAt != nil {
+			lastUsed = timeutil.FormatMinute(*t.LastUsedAt)
+		}
+		builder.WriteString(b.t(ctx, "apitoken.item",
+			t.ID,
+			html.EscapeString(t.Name),
+			t.Prefix,
+			t.Scopes,
+			t.CreatedBy,
+			timeutil.FormatMinute(t.CreatedAt),
+			lastUsed,
+		))
+	}
+	builder.WriteString("\n" + b.t(ctx, "apitoken.usage", apiScopeList()))
+	return builder.String(), nil
+}
+
+// apiScopeList 以逗号分隔的全部权限范围
+func apiScopeList() string {
+	names := make([]string, 0, len(apitoken.AllScopes))
+	for _, s := range apitoken.AllScopes {
+		names = append(names, string(s))
+	}
+	return strings.Join(names, ", ")
+}
@@ -3,6 +3,7 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -14,48 +15,31 @@ import (
 
 // handleCheckEmby 检查 Emby 服务器连接状态
 func (b *Bot) handleCheckEmby(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "", err
-	}
-
 	if b.embyClient == nil {
-		return "❌ Emby 同步已禁用或未配置", nil
+		return b.t(ctx, "emby.disabled"), nil
 	}
 
 	// 测试连接
 	if err := b.embyClient.Ping(ctx); err != nil {
-		return fmt.Sprintf("❌ Emby 服务器连接失败\n错误: %v", err), nil
+		return b.t(ctx, "emby.ping_failed", b.errorText(ctx, err)), nil
 	}
 
-	return "✅ Emby 服务器连接正常", nil
+	return b.t(ctx, "emby.ping_ok"), nil
 }
 
 // handleSyncStatus 查看账号同步状态
 func (b *Bot) handleSyncStatus(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
 	if len(args) < 1 {
-		return "", account.ValidationError("username", "用户名不能为空")
+		return b.t(ctx, "sync.status_usage"), nil
 	}
 
-	username := args[0]
-
-	// 获取账号信息
-	acc, err := b.accountService.GetByUsername(ctx, username)
-	if err != nil {
-		return "", err
-	}
-
-	// 检查所有权
-	userID := uint(msg.From.ID)
-	if !b.isAdmin(msg.From.ID) {
-		if err := b.accountService.CheckOwnership(ctx, acc.ID, userID); err != nil {
-			return "", err
-		}
-	}
+	// 账号已由中间件完成归属校验，管理员可查看任意账号
+	acc := accountFromContext(ctx)
 
 	// 构建状态消息
 	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("<b>账号同步状态</b>\n\n"))
-	builder.WriteString(fmt.Sprintf("用户名: <code>%s</code>\n", acc.Username))
+	builder.WriteString(b.t(ctx, "sync.status_title"))
+	builder.WriteString(b.t(ctx, "sync.username", acc.Username))
 
 	// 同步状态
 	statusEmoji := map[string]string{
@@ -67,23 +51,23 @@ func (b *Bot) handleSyncStatus(ctx context.Context, msg *tgbotapi.Message, args
 	if !ok {
 		emoji = "❓"
 	}
-	builder.WriteString(fmt.Sprintf("同步状态: %s %s\n", emoji, acc.SyncStatus))
+	builder.WriteString(b.t(ctx, "sync.state", emoji, acc.SyncStatus))
 
 	// Emby User ID
 	if acc.EmbyUserID != "" {
-		builder.WriteString(fmt.Sprintf("Emby ID: <code>%s</code>\n", acc.EmbyUserID))
+		builder.WriteString(b.t(ctx, "sync.emby_id", acc.EmbyUserID))
 	} else {
-		builder.WriteString("Emby ID: 未同步\n")
+		builder.WriteString(b.t(ctx, "sync.emby_id_none"))
 	}
 
 	// 最后同步时间
 	if acc.LastSyncAt != nil {
-		builder.WriteString(fmt.Sprintf("最后同步: %s\n", acc.LastSyncAt.Format("2006-01-02 15:04:05")))
+		builder.WriteString(b.t(ctx, "sync.last_sync", acc.LastSyncAt.Format("2006-01-02 15:04:05")))
 	}
 
 	// 同步错误
 	if acc.SyncError != "" {
-		builder.WriteString(fmt.Sprintf("\n<b>同步错误</b>:\n<pre>%s</pre>", acc.SyncError))
+		builder.WriteString(b.t(ctx, "sync.error", acc.SyncError))
 	}
 
 	return builder.String(), nil
@@ -91,16 +75,12 @@ func (b *Bot) handleSyncStatus(ctx context.Context, msg *tgbotapi.Message, args
 
 // handleSyncAccount 手动同步账号到 Emby
 func (b *Bot) handleSyncAccount(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "", err
-	}
-
 	if b.embyClient == nil {
-		return "❌ Emby 同步已禁用或未配置", nil
+		return b.t(ctx, "emby.disabled"), nil
 	}
 
 	if len(args) < 2 {
-		return "", account.ValidationError("args", "用法: /syncaccount &lt;用户名&gt; &lt;密码&gt;")
+		return b.t(ctx, "sync.account_usage"), nil
 	}
 
 	username := args[0]
@@ -114,62 +94,58 @@ func (b *Bot) handleSyncAccount(ctx context.Context, msg *tgbotapi.Message, args
 
 	// 如果已同步，提示
 	if acc.IsSynced() {
-		return fmt.Sprintf("⚠️ 账号 <code>%s</code> 已同步到 Emby (ID: %s)\n是否要重新同步？", acc.Username, acc.EmbyUserID), nil
+		return b.t(ctx, "sync.already_synced", acc.Username, acc.EmbyUserID), nil
 	}
 
 	// 尝试创建 Emby 用户
 	embyUser, err := b.embyClient.CreateUser(ctx, acc.Username, password)
 	if err != nil {
 		logger.Errorf("手动同步账号失败: %v", err)
-		return fmt.Sprintf("❌ 同步失败: %v", err), nil
+		return b.t(ctx, "sync.failed", b.errorText(ctx, err)), nil
 	}
 
-	// 更新设备限制
-	if acc.MaxDevices > 0 {
-		if err := b.embyClient.SetMaxActiveSessions(ctx, embyUser.ID, acc.MaxDevices); err != nil {
-			logger.Warnf("设置设备限制失败: %v", err)
+	// 保存同步状态并推送账号策略(模板 + 覆盖)
+	if err := b.accountService.LinkEmbyUser(ctx, acc.ID, embyUser.ID); err != nil {
+		if !errors.Is(err, account.ErrPolicyPush) {
+			logger.Errorf("保存账号 %s 的 Emby 关联失败: %v", acc.Username, err)
+			return b.t(ctx, "sync.link_failed", embyUser.ID, b.errorText(ctx, err)), nil
 		}
+		logger.Warnf("设置账号策略失败: %v", err)
+		return b.t(ctx, "sync.done_policy_failed", acc.Username, embyUser.ID, b.errorText(ctx, err)), nil
 	}
 
-	// 更新账号同步状态
-	acc.MarkSynced(embyUser.ID)
-	// 这里账号状态已更新，记录日志
 	logger.Infof("账号 %s 已同步到 Emby (ID: %s)", acc.Username, embyUser.ID)
 
-	return fmt.Sprintf("✅ 账号已成功同步到 Emby\n用户名: <code>%s</code>\nEmby ID: <code>%s</code>", acc.Username, embyUser.ID), nil
+	return b.t(ctx, "sync.done", acc.Username, embyUser.ID), nil
 }
 
 // handleListEmbyUsers 列出 Emby 服务器上的所有用户
 func (b *Bot) handleListEmbyUsers(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "", err
-	}
-
 	if b.embyClient == nil {
-		return "❌ Emby 同步已禁用或未配置", nil
+		return b.t(ctx, "emby.disabled"), nil
 	}
 
 	// 获取 Emby 用户列表
 	users, err := b.embyClient.ListUsers(ctx)
 	if err != nil {
-		return fmt.Sprintf("❌ 获取 Emby 用户列表失败: %v", err), nil
+		return b.t(ctx, "emby.users_failed", b.errorText(ctx, err)), nil
 	}
 
 	if len(users) == 0 {
-		return "📋 Emby 服务器上没有用户", nil
+		return b.t(ctx, "emby.users_empty"), nil
 	}
 
 	var builder strings.Builder
-	builder.WriteString(fmt.Sprintf("<b>Emby 用户列表</b> (共 %d 个)\n\n", len(users)))
+	builder.WriteString(b.t(ctx, "emby.users_title", len(users)))
 
 	for i, user := range users {
-		status := "✅ 启用"
+		status := b.t(ctx, "emby.user_enabled")
 		if user.Policy.IsDisabled {
-			status = "❌ 禁用"
+			status = b.t(ctx, "emby.user_disabled")
 		}
 
 		builder.WriteString(fmt.Sprintf("%d. <code>%s</code>\n", i+1, user.Name))
-		builder.WriteString(fmt.Sprintf("   状态: %s\n", status))
+		builder.WriteString(b.t(ctx, "emby.user_status", status))
 		builder.WriteString(fmt.Sprintf("   ID: <code>%s</code>\n", user.ID))
 
 		if i < len(users)-1 {
@@ -182,16 +158,12 @@ func (b *Bot) handleListEmbyUsers(ctx context.Context, msg *tgbotapi.Message, ar
 
 // handleSetDeviceLimit 手动设置账号设备限制
 func (b *Bot) handleSetDeviceLimit(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "", err
-	}
-
 	if b.embyClient == nil {
-		return "❌ Emby 同步已禁用或未配置", nil
+		return b.t(ctx, "emby.disabled"), nil
 	}
 
 	if len(args) < 2 {
-		return "❌ 参数不足\n\n使用方法: <code>/setdevicelimit &lt;用户名&gt; &lt;设备数&gt;</code>\n例如: <code>/setdevicelimit john 3</code>", nil
+		return b.t(ctx, "devices.usage"), nil
 	}
 
 	username := args[0]
@@ -200,32 +172,70 @@ func (b *Bot) handleSetDeviceLimit(ctx context.Context, msg *tgbotapi.Message, a
 	// 解析设备数
 	var limit int
 	if _, err := fmt.Sscanf(limitStr, "%d", &limit); err != nil || limit < 0 {
-		return "❌ 设备数必须是非负整数", nil
+		return b.t(ctx, "devices.invalid"), nil
 	}
 
 	// 获取账号信息
 	acc, err := b.accountService.GetByUsername(ctx, username)
 	if err != nil {
-		return fmt.Sprintf("❌ 获取账号信息失败: %v", err), nil
+		return b.t(ctx, "common.get_account_failed_reason", b.errorText(ctx, err)), nil
 	}
 
 	// 检查是否已同步
 	if acc.EmbyUserID == "" {
-		return fmt.Sprintf("❌ 账号 <code>%s</code> 尚未同步到 Emby", acc.Username), nil
+		return b.t(ctx, "devices.not_synced", acc.Username), nil
 	}
 
-	// 设置设备限制
-	if err := b.embyClient.SetMaxActiveSessions(ctx, acc.EmbyUserID, limit); err != nil {
+	// 设置设备限制(保存到账号并推送合并后的策略)
+	if err := b.accountService.SetMaxDevices(ctx, acc.ID, limit); err != nil {
 		logger.Errorf("设置设备限制失败: %v", err)
-		return fmt.Sprintf("❌ 设置设备限制失败: %v", err), nil
+		return b.t(ctx, "devices.failed", b.errorText(ctx, err)), nil
 	}
 
 	logger.Infof("成功为账号 %s 设置设备限制: %d", acc.Username, limit)
 
-	return fmt.Sprintf(`✅ <b>设备限制设置成功！</b>
+	return b.t(ctx, "devices.done", acc.Username, acc.EmbyUserID, limit), nil
+}
+
+// handlePolicyDrift 检测账号 Emby 策略与期望策略的偏差
+// 用法: /policydrift [用户名]
+func (b *Bot) handlePolicyDrift(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+	if b.embyClient == nil {
+		return b.t(ctx, "emby.disabled"), nil
+	}
+
+	if len(args) > 0 {
+		acc, err := b.accountService.GetByUsername(ctx, args[0])
+		if err != nil {
+			return b.t(ctx, "common.get_account_failed_reason", b.errorText(ctx, err)), nil
+		}
+
+		report, err := b.accountService.CheckDrift(ctx, acc.ID)
+		if err != nil {
+			return b.policyDriftErrorMessage(ctx, err), nil
+		}
+
+		changes, _ := b.accountService.ListPolicyChanges(ctx, acc.ID, policyChangeHistoryLimit)
+		return formatDriftReport(b.loc(ctx), report, changes), nil
+	}
+
+	reports, err := b.accountService.ScanDrift(ctx)
+	if err != nil {
+		return b.policyDriftErrorMessage(ctx, err), nil
+	}
+
+	if len(reports) == 0 {
+		return b.t(ctx, "drift.all_in_sync"), nil
+	}
 
-账号: <code>%s</code>
-Emby ID: <code>%s</code>
-最大设备数: <b>%d</b>
-`, acc.Username, acc.EmbyUserID, limit), nil
+	var builder strings.Builder
+	builder.WriteString(b.t(ctx, "drift.summary_title", len(reports)))
+	for _, r := range reports {
+		builder.WriteString(fmt.Sprintf("<b>%s</b>\n", r.Account.Username))
+		builder.WriteString(formatPolicyDiffs(b.loc(ctx), r.Diffs))
+		builder.WriteString("\n")
+	}
+	builder.WriteString(b.t(ctx, "drift.summary_footer"))
+
+	return builder.String(), nil
 }
@@ -15,45 +15,35 @@ import (
 
 // handleGrant 处理 /grant 命令（管理员在群组中授权用户）
 func (b *Bot) handleGrant(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if !isGroupChat(msg) {
-		return "此命令请在管理群组中使用", nil
-	}
-
-	if err := b.requireAdmin(msg.From.ID); err != nil {
-		return "❌ 此命令需要管理员权限", nil
-	}
-
 	targetUser, quota, err := b.parseGrantArgs(ctx, msg, args)
 	if err != nil {
-		return "❌ " + err.Error(), nil
+		return "❌ " + b.errorText(ctx, err), nil
 	}
 
 	if err := b.userService.SetQuota(ctx, targetUser.ID, quota); err != nil {
-		return "", fmt.Errorf("设置配额失败: %w", err)
+		return "", fmt.Errorf("set quota: %w", err)
 	}
 
 	count, _ := b.accountService.CountByUser(ctx, targetUser.ID)
 
 	var text string
 	if quota == 0 {
-		text = fmt.Sprintf("⚠️ 已收回 %s 的创建权限", targetUser.DisplayName())
+		text = b.t(ctx, "grant.revoked", targetUser.DisplayName())
 		if count > 0 {
-			text += fmt.Sprintf("\n当前账号: %d (保留)", count)
+			text += b.t(ctx, "grant.current_kept", count)
 		}
-		text += "\n\n用户将无法创建新账号"
+		text += b.t(ctx, "grant.revoked_footer")
 	} else {
 		if count == 0 {
-			text = fmt.Sprintf("✅ 已授权 %s 创建账号\n配额: %d 个",
-				targetUser.DisplayName(), quota)
+			text = b.t(ctx, "grant.granted", targetUser.DisplayName(), quota)
 		} else {
-			text = fmt.Sprintf("✅ 已将 %s 的账号配额调整为 %d",
-				targetUser.DisplayName(), quota)
-			text += fmt.Sprintf("\n当前账号: %d", count)
+			text = b.t(ctx, "grant.adjusted", targetUser.DisplayName(), quota)
+			text += b.t(ctx, "grant.current", count)
 
 			if int(count) > quota {
-				text += fmt.Sprintf("\n\n⚠️ 用户需要删除 %d 个账号才能继续使用", int(count)-quota)
+				text += b.t(ctx, "grant.over_quota", int(count)-quota)
 			} else if int(count) < quota {
-				text += fmt.Sprintf("\n可继续创建: %d 个", quota-int(count))
+				text += b.t(ctx, "grant.remaining", quota-int(count))
 			}
 		}
 	}
@@ -70,7 +60,7 @@ func (b *Bot) parseGrantArgs(ctx context.Context, msg *tgbotapi.Message, args []
 		var err error
 		targetUser, err = b.userService.GetByTelegramID(ctx, msg.ReplyToMessage.From.ID)
 		if err != nil {
-			return nil, 0, fmt.Errorf("获取用户信息失败: %w", err)
+			return nil, 0, fmt.Errorf("get user: %w", err)
 		}
 
 		if hasArg(args, 1) {
@@ -92,23 +82,23 @@ func (b *Bot) parseGrantArgs(ctx context.Context, msg *tgbotapi.Message, args []
 			var err error
 			targetUser, err = b.userService.GetByUsername(ctx, username)
 			if err != nil {
-				return nil, 0, fmt.Errorf("用户 %s 不存在", userArg)
+				return nil, 0, errors.New(b.t(ctx, "grant.user_not_found", userArg))
 			}
 		} else if telegramID, err := strconv.ParseInt(userArg, 10, 64); err == nil {
 			targetUser, err = b.userService.GetByTelegramID(ctx, telegramID)
 			if err != nil {
-				return nil, 0, fmt.Errorf("用户 ID %d 不存在", telegramID)
+				return nil, 0, errors.New(b.t(ctx, "grant.user_id_not_found", telegramID))
 			}
 		} else {
-			return nil, 0, errors.New("无效的用户标识\n\n支持的格式:\n• [回复消息] /grant [配额]\n• /grant @username [配额]\n• /grant <telegram_id> [配额]\n\n配额默认为 1")
+			return nil, 0, errors.New(b.t(ctx, "grant.invalid_user"))
 		}
 	} else {
-		return nil, 0, errors.New("参数不足\n\n使用方法:\n• [回复目标消息] /grant [配额]\n• /grant @username [配额]\n• /grant <telegram_id> [配额]\n\n配额默认为 1")
+		return nil, 0, errors.New(b.t(ctx, "grant.usage"))
 	}
 
 	quota, err := strconv.Atoi(quotaStr)
 	if err != nil || quota < 0 {
-		return nil, 0, errors.New("配额必须是非负整数")
+		return nil, 0, errors.New(b.t(ctx, "grant.invalid_quota"))
 	}
 
 	return targetUser, quota, nil
@@ -116,10 +106,6 @@ func (b *Bot) parseGrantArgs(ctx context.Context, msg *tgbotapi.Message, args []
 
 // handleQuota 处理 /quota 命令（用户查询自己的配额）
 func (b *Bot) handleQuota(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
-	if !isPrivateChat(msg) {
-		return "请在私聊中使用此命令", nil
-	}
-
 	user, err := b.userService.GetByTelegramID(ctx, msg.From.ID)
 	if err != nil {
 		return "", err
@@ -130,25 +116,14 @@ func (b *Bot) handleQuota(ctx context.Context, msg *tgbotapi.Message, args []str
 	count := len(accounts)
 
 	if quota == 0 {
-		return fmt.Sprintf(`📊 <b>账号授权状态:</b> ❌ 未授权
-
-已创建账号: %d
-
-💡 如需创建账号，请在管理群组联系管理员申请`, count), nil
+		return b.t(ctx, "quota.unauthorized", count), nil
 	}
 
 	remaining := quota - count
-	status := "✅ 已授权"
+	status := b.t(ctx, "quota.authorized")
 	if count >= quota {
-		status = "⚠️ 已满额"
+		status = b.t(ctx, "quota.full")
 	}
 
-	return fmt.Sprintf(`📊 <b>账号授权状态:</b> %s
-
-<b>账号配额:</b> %d
-<b>已创建:</b> %d
-<b>剩余:</b> %d
-
-💡 配额不足？请在管理群组联系管理员`,
-		status, quota, count, remaining), nil
+	return b.t(ctx, "quota.status", status, quota, count, remaining), nil
 }
@@ -0,0 +1,144 @@
+// Package bot 角色管理命令处理器
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/user"
+)
+
+// handleListRoles 处理 /roles 命令
+func (b *Bot) handleListRoles(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+	roles, err := b.userService.ListRoles(ctx)
+	if err != nil {
+		return "", fmt.Errorf("list roles: %w", err)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(b.t(ctx, "role.list_title"))
+
+	for _, role := range roles {
+		builder.WriteString(fmt.Sprintf("<b>%s</b>", role.Name))
+		if role.Builtin {
+			builder.WriteString(b.t(ctx, "role.builtin"))
+		}
+		// 内置角色的说明使用语言包，自定义角色显示创建时填写的说明
+		description := role.Description
+		if role.Builtin {
+			description = b.t(ctx, "role.desc_"+string(role.Name))
+		}
+		if description != "" {
+			builder.WriteString(" - " + description)
+		}
+		builder.WriteString("\n")
+
+		switch {
+		case role.Name == user.RoleAdmin:
+			builder.WriteString(b.t(ctx, "role.perms_all"))
+		case len(role.Permissions) == 0:
+			builder.WriteString(b.t(ctx, "role.perms_none"))
+		default:
+			perms := make([]string, len(role.Permissions))
+			for i, p := range role.Permissions {
+				perms[i] = string(p)
+			}
+			builder.WriteString(b.t(ctx, "role.perms", strings.Join(perms, ", ")))
+		}
+	}
+
+	builder.WriteString(b.t(ctx, "role.available_perms"))
+	for _, perm := range user.AllPermissions {
+		builder.WriteString(fmt.Sprintf("<code>%s</code> - %s\n", perm, b.t(ctx, "perm."+string(perm))))
+	}
+
+	return builder.String(), nil
+}
+
+// handleAddRole 处理 /addrole 命令
+func (b *Bot) handleAddRole(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+	if !hasArg(args, 1) {
+		return b.t(ctx, "role.add_usage"), nil
+	}
+
+	name := getArg(args, 0)
+	description := strings.Join(args[1:], " ")
+
+	role, err := b.userService.CreateRole(ctx, name, description)
+	if err != nil {
+		if errors.Is(err, user.ErrInvalidRole) {
+			return b.t(ctx, "role.invalid_name"), nil
+		}
+		if errors.Is(err, user.ErrRoleExists) {
+			return b.t(ctx, "role.exists", name), nil
+		}
+		return "", fmt.Errorf("create role: %w", err)
+	}
+
+	return b.t(ctx, "role.created", role.Name, role.Name), nil
+}
+
+// handleDeleteRole 处理 /delrole 命令
+func (b *Bot) handleDeleteRole(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+	if !hasArg(args, 1) {
+		return b.t(ctx, "role.delete_usage"), nil
+	}
+
+	name := user.Role(getArg(args, 0))
+	if err := b.userService.DeleteRole(ctx, name); err != nil {
+		switch {
+		case errors.Is(err, user.ErrRoleNotFound):
+			return b.t(ctx, "role.not_found", name), nil
+		case errors.Is(err, user.ErrBuiltinRole):
+			return b.t(ctx, "role.builtin_delete"), nil
+		case errors.Is(err, user.ErrRoleInUse):
+			return b.t(ctx, "role.in_use"), nil
+		}
+		return "", fmt.Errorf("delete role: %w", err)
+	}
+
+	return b.t(ctx, "role.deleted", name), nil
+}
+
+// handleRolePermission 处理 /roleperm 命令
+func (b *Bot) handleRolePermission(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+	if !hasArg(args, 3) {
+		return b.t(ctx, "role.perm_usage"), nil
+	}
+
+	name := user.Role(getArg(args, 0))
+	perm := user.Permission(getArg(args, 1))
+
+	var granted bool
+	switch strings.ToLower(getArg(args, 2)) {
+	case "on":
+		granted = true
+	case "off":
+		granted = false
+	default:
+		return b.t(ctx, "role.perm_onoff"), nil
+	}
+
+	role, err := b.userService.SetRolePermission(ctx, name, perm, granted)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrInvalidPermission):
+			return b.t(ctx, "role.perm_invalid", perm), nil
+		case errors.Is(err, user.ErrImmutableRole):
+			return b.t(ctx, "role.admin_immutable"), nil
+		case errors.Is(err, user.ErrRoleNotFound):
+			return b.t(ctx, "role.not_found", name), nil
+		}
+		return "", fmt.Errorf("update role permissions: %w", err)
+	}
+
+	key := "role.perm_granted"
+	if !granted {
+		key = "role.perm_revoked"
+	}
+	return b.t(ctx, key, role.Name, perm, len(role.Permissions)), nil
+}
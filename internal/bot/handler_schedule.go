@@ -0,0 +1,325 @@
+// Package bot 定时公告与维护窗口
+package bot
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/schedule"
+	"emby-telegram/internal/user"
+	"emby-telegram/pkg/timeutil"
+)
+
+// schedulerInterval 检查到期公告的间隔
+const schedulerInterval = 30 * time.Second
+
+// announcementGracePeriod 公告到期后超过该时间仍未发送(如 Bot 停机期间)则跳过本次
+const announcementGracePeriod = time.Hour
+
+// announcementPreviewLength 公告列表中显示的文本长度
+const announcementPreviewLength = 60
+
+// runScheduler 定时发送到期的公告，Bot 关闭时退出
+func (b *Bot) runScheduler() {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		b.runDueAnnouncements(b.handlerCtx)
+
+		select {
+		case <-b.stopping:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDueAnnouncements 发送所有到期的公告
+// 发送前先认领公告，已被其他实例认领的公告跳过，进程重启或多实例运行时公告不会重复发送
+func (b *Bot) runDueAnnouncements(ctx context.Context) {
+	now := time.Now()
+	due, err := b.scheduleService.Due(ctx, now)
+	if err != nil {
+		logger.Errorf("failed to load due announcements: %v", err)
+		return
+	}
+
+	for _, a := range due {
+		missed := now.Sub(a.NextRunAt) > announcementGracePeriod
+		runAt := a.NextRunAt
+
+		claimed, err := b.scheduleService.Advance(ctx, a, now)
+		if err != nil {
+			logger.Errorf("failed to advance announcement %d: %v", a.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		if missed {
+			logger.Warnf("announcement %d missed its run at %s, skipped", a.ID, timeutil.FormatMinute(runAt))
+			continue
+		}
+
+		b.deliverAnnouncement(ctx, a)
+	}
+}
+
+// deliverAnnouncement 发送公告：群组公告直接发送，面向所有用户的公告按群发执行并向创建者报告
+func (b *Bot) deliverAnnouncement(ctx context.Context, a *schedule.Announcement) {
+	if !a.IsBroadcast() {
+		b.sender.post(b.handlerCtx, a.ChatID, tgbotapi.NewMessage(a.ChatID, a.Text))
+		logger.Infof("announcement %d queued for chat %d", a.ID, a.ChatID)
+		return
+	}
+
+	recipients, err := b.userService.ListByAudience(ctx, user.Audience{Kind: user.AudienceAll})
+	if err != nil {
+		logger.Errorf("announcement %d: failed to list recipients: %v", a.ID, err)
+		return
+	}
+
+	loc := b.catalog.Localizer(b.catalog.DefaultLanguage())
+	if creator, err := b.userService.GetByTelegramID(ctx, a.CreatedBy); err == nil {
+		loc = b.catalog.Localizer(b.userLanguage(creator, nil))
+	}
+
+	logger.Infof("announcement %d started as broadcast", a.ID)
+	b.startTextBroadcast(loc, a.CreatedBy, a.Text, recipients)
+}
+
+// handleSchedule 处理 /schedule 命令
+// 在群组中创建的公告发送到该群组，在私聊中创建的公告发送给所有用户
+func (b *Bot) handleSchedule(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+	if len(args) == 0 {
+		return b.announcementListText(ctx, msg)
+	}
+
+	var target int64
+	if isGroupChat(msg) {
+		target = msg.Chat.ID
+	}
+
+	head, rest := splitArgs(msg.CommandArguments(), 1)
+	switch strings.ToLower(head[0]) {
+	case "at":
+		when, text := splitArgs(rest, 2)
+		if len(when) < 2 || text == "" {
+			return b.t(ctx, "schedule.usage"), nil
+		}
+		at, err := timeutil.ParseLocalMinute(when[0] + " " + when[1])
+		if err != nil {
+			return b.t(ctx, "schedule.invalid_time"), nil
+		}
+
+		a, err := b.scheduleService.ScheduleOnce(ctx, target, text, at, msg.From.ID)
+		if err != nil {
+			return "", err
+		}
+		return b.t(ctx, "schedule.created_once", a.ID, b.announcementTarget(ctx, a), timeutil.FormatMinute(a.NextRunAt)), nil
+
+	case "cron":
+		fields, text := splitArgs(rest, 5)
+		if len(fields) < 5 || text == "" {
+			return b.t(ctx, "schedule.usage"), nil
+		}
+
+		a, err := b.scheduleService.ScheduleCron(ctx, target, text, strings.Join(fields, " "), msg.From.ID)
+		if err != nil {
+			return "", err
+		}
+		return b.t(ctx, "schedule.created_cron", a.ID, b.announcementTarget(ctx, a), a.Cron, timeutil.FormatMinute(a.NextRunAt)), nil
+
+	default:
+		return b.t(ctx, "schedule.usage"), nil
+	}
+}
+
+// handleUnschedule 处理 /unschedule 命令
+func (b *Bot) handleUnschedule(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+	if !hasArg(args, 1) {
+		return b.t(ctx, "schedule.unschedule_usage"), nil
+	}
+
+	id, err := strconv.ParseUint(strings.TrimPrefix(getArg(args, 0), "#"), 10, 64)
+	if err != nil {
+		return b.t(ctx, "schedule.invalid_id"), nil
+	}
+
+	if err := b.scheduleService.DeleteAnnouncement(ctx, uint(id)); err != nil {
+		return "", err
+	}
+	return b.t(ctx, "schedule.deleted", id), nil
+}
+
+// announcementListText 公告列表和用法，群组中只列出发往该群组的公告
+func (b *Bot) announcementListText(ctx context.Context, msg *tgbotapi.Message) (string, error) {
+	list, err := b.scheduleService.ListAnnouncements(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	count := 0
+	for _, a := range list {
+		if isGroupChat(msg) && a.ChatID != msg.Chat.ID {
+			continue
+		}
+		count++
+
+		when := b.t(ctx, "schedule.item_once", timeutil.FormatMinute(a.NextRunAt))
+		if a.IsRecurring() {
+			when = b.t(ctx, "schedule.item_cron", a.Cron, timeutil.FormatMinute(a.NextRunAt))
+		}
+		builder.WriteString(b.t(ctx, "schedule.item",
+			a.ID,
+			b.announcementTarget(ctx, a),
+			when,
+			html.EscapeString(truncateRunes(a.Text, announcementPreviewLength)),
+		))
+	}
+
+	if count == 0 {
+		return b.t(ctx, "schedule.empty") + "\n\n" + b.t(ctx, "schedule.usage"), nil
+	}
+	return b.t(ctx, "schedule.list_title", count) + builder.String() + "\n" + b.t(ctx, "schedule.usage"), nil
+}
+
+// announcementTarget 公告发送对象的描述
+func (b *Bot) announcementTarget(ctx context.Context, a *schedule.Announcement) string {
+	if a.IsBroadcast() {
+		return b.t(ctx, "schedule.target_all")
+	}
+	return b.t(ctx, "schedule.target_chat", a.ChatID)
+}
+
+// handleMaintenance 处理 /maintenance 命令
+func (b *Bot) handleMaintenance(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+	if len(args) == 0 {
+		return b.maintenanceListText(ctx)
+	}
+
+	if strings.EqualFold(args[0], "cancel") {
+		if !hasArg(args, 2) {
+			return b.t(ctx, "maintenance.usage"), nil
+		}
+		id, err := strconv.ParseUint(strings.TrimPrefix(getArg(args, 1), "#"), 10, 64)
+		if err != nil {
+			return b.t(ctx, "schedule.invalid_id"), nil
+		}
+		if err := b.scheduleService.CancelWindow(ctx, uint(id)); err != nil {
+			return "", err
+		}
+		return b.t(ctx, "maintenance.cancelled", id), nil
+	}
+
+	fields, reason := splitArgs(msg.CommandArguments(), 4)
+	if len(fields) < 4 {
+		return b.t(ctx, "maintenance.usage"), nil
+	}
+	startsAt, err := timeutil.ParseLocalMinute(fields[0] + " " + fields[1])
+	if err != nil {
+		return b.t(ctx, "schedule.invalid_time"), nil
+	}
+	endsAt, err := timeutil.ParseLocalMinute(fields[2] + " " + fields[3])
+	if err != nil {
+		return b.t(ctx, "schedule.invalid_time"), nil
+	}
+
+	w, err := b.scheduleService.AddWindow(ctx, startsAt, endsAt, reason, msg.From.ID)
+	if err != nil {
+		return "", err
+	}
+	return b.t(ctx, "maintenance.created",
+		w.ID,
+		timeutil.FormatMinute(w.StartsAt),
+		timeutil.FormatMinute(w.EndsAt),
+		b.maintenanceReason(ctx, w),
+	), nil
+}
+
+// maintenanceListText 进行中和未开始的维护窗口及用法
+func (b *Bot) maintenanceListText(ctx context.Context) (string, error) {
+	list, err := b.scheduleService.ListWindows(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(list) == 0 {
+		return b.t(ctx, "maintenance.empty") + "\n\n" + b.t(ctx, "maintenance.usage"), nil
+	}
+
+	now := time.Now()
+	var builder strings.Builder
+	builder.WriteString(b.t(ctx, "maintenance.list_title", len(list)))
+	for _, w := range list {
+		status := b.t(ctx, "maintenance.status_upcoming")
+		if w.IsActive(now) {
+			status = b.t(ctx, "maintenance.status_active")
+		}
+		builder.WriteString(b.t(ctx, "maintenance.item",
+			w.ID,
+			status,
+			timeutil.FormatMinute(w.StartsAt),
+			timeutil.FormatMinute(w.EndsAt),
+			b.maintenanceReason(ctx, w),
+		))
+	}
+	builder.WriteString("\n" + b.t(ctx, "maintenance.usage"))
+	return builder.String(), nil
+}
+
+// maintenanceReason 维护原因，未填写时显示占位符
+func (b *Bot) maintenanceReason(ctx context.Context, w *schedule.MaintenanceWindow) string {
+	if w.Reason == "" {
+		return b.t(ctx, "maintenance.no_reason")
+	}
+	return html.EscapeString(w.Reason)
+}
+
+// activeMaintenance 获取当前的维护窗口
+func (b *Bot) activeMaintenance(ctx context.Context) (*schedule.MaintenanceWindow, bool) {
+	w, err := b.scheduleService.ActiveWindow(ctx)
+	if err != nil {
+		if !errors.Is(err, schedule.ErrNotFound) {
+			logger.Warnf("failed to check maintenance window: %v", err)
+		}
+		return nil, false
+	}
+	return w, true
+}
+
+// maintenanceText 维护期间创建、续期账号时的提示
+func (b *Bot) maintenanceText(ctx context.Context) string {
+	w, ok := b.activeMaintenance(ctx)
+	if !ok {
+		return b.t(ctx, "maintenance.notice_generic")
+	}
+	return b.t(ctx, "maintenance.notice", timeutil.FormatMinute(w.EndsAt), b.maintenanceReason(ctx, w))
+}
+
+// maintenanceAlert 维护提示的纯文本短版本，用于按钮弹窗和错误描述
+func (b *Bot) maintenanceAlert(ctx context.Context) string {
+	w, ok := b.activeMaintenance(ctx)
+	if !ok {
+		return b.t(ctx, "maintenance.alert_generic")
+	}
+	return b.t(ctx, "maintenance.alert", timeutil.FormatMinute(w.EndsAt))
+}
+
+// truncateRunes 按字符截断文本
+func truncateRunes(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
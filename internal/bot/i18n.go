@@ -0,0 +1,259 @@
+// Package bot 界面语言
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/apitoken"
+	"emby-telegram/internal/dataio"
+	"emby-telegram/internal/i18n"
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/policy"
+	"emby-telegram/internal/schedule"
+	"emby-telegram/internal/user"
+	"emby-telegram/pkg/timeutil"
+	"emby-telegram/pkg/validator"
+)
+
+// languageAuto /language 命令中表示跟随 Telegram 客户端语言的参数
+const languageAuto = "auto"
+
+// withLocalizer 将当前用户的翻译器放入上下文
+func withLocalizer(ctx context.Context, loc *i18n.Localizer) context.Context {
+	return context.WithValue(ctx, localizerKey, loc)
+}
+
+// loc 获取上下文中的翻译器，没有时使用默认语言
+func (b *Bot) loc(ctx context.Context) *i18n.Localizer {
+	if loc, ok := ctx.Value(localizerKey).(*i18n.Localizer); ok {
+		return loc
+	}
+	return b.catalog.Localizer(b.catalog.DefaultLanguage())
+}
+
+// t 按当前用户的语言翻译消息
+func (b *Bot) t(ctx context.Context, key string, args ...any) string {
+	return b.loc(ctx).T(key, args...)
+}
+
+// userLanguage 确定用户的界面语言
+// 用户通过 /language 设置的语言优先，否则按 Telegram 客户端语言匹配
+func (b *Bot) userLanguage(u *user.User, from *tgbotapi.User) string {
+	if u != nil && u.Language != "" && b.catalog.Supports(u.Language) {
+		return u.Language
+	}
+	if from != nil {
+		return b.catalog.Match(from.LanguageCode)
+	}
+	return b.catalog.DefaultLanguage()
+}
+
+// localize 为用户的请求准备带翻译器的上下文
+func (b *Bot) localize(ctx context.Context, u *user.User, from *tgbotapi.User) context.Context {
+	return withLocalizer(ctx, b.catalog.Localizer(b.userLanguage(u, from)))
+}
+
+// errorText 将错误转换为用户可读的描述
+// 已知的业务错误按当前语言翻译，其余错误只记录日志，向用户显示通用的系统错误
+func (b *Bot) errorText(ctx context.Context, err error) string {
+	loc := b.loc(ctx)
+
+	var verr *validator.Error
+	var werr *wizardInputError
+	var ferr *dataio.FieldError
+	var cerr *dataio.ConflictError
+	switch {
+	case errors.As(err, &verr):
+		return loc.T(verr.Key, verr.Args...)
+	case errors.As(err, &werr):
+		return loc.T(werr.Key, werr.Args...)
+	case errors.Is(err, account.ErrNotFound):
+		return loc.T("error.account_not_found")
+	case errors.Is(err, account.ErrAlreadyExists):
+		return loc.T("error.account_exists")
+	case errors.Is(err, account.ErrUnauthorized):
+		return loc.T("error.unauthorized")
+	case errors.Is(err, account.ErrAccountLimitExceeded):
+		return loc.T("error.limit_exceeded")
+	case errors.Is(err, account.ErrNotAuthorized):
+		return loc.T("error.not_authorized")
+	case errors.Is(err, account.ErrNotSynced):
+		return loc.T("error.not_synced")
+	case errors.Is(err, account.ErrSyncDisabled):
+		return loc.T("error.sync_disabled")
+	case errors.Is(err, account.ErrMaintenance):
+		return b.maintenanceAlert(ctx)
+	case errors.Is(err, account.ErrSameOwner):
+		return loc.T("error.transfer_same_owner")
+	case errors.Is(err, account.ErrTransferNotFound):
+		return loc.T("error.transfer_not_found")
+	case errors.Is(err, account.ErrTransferPending):
+		return loc.T("error.transfer_pending")
+	case errors.Is(err, account.ErrTransferClosed):
+		return loc.T("error.transfer_closed")
+	case errors.Is(err, policy.ErrAlreadyExists):
+		return loc.T("error.template_exists")
+	case errors.Is(err, policy.ErrNotFound):
+		return loc.T("error.template_not_found")
+	case errors.Is(err, user.ErrNotFound):
+		return loc.T("error.user_not_found")
+	case errors.Is(err, user.ErrRoleNotFound):
+		return loc.T("error.role_not_found")
+	case errors.Is(err, schedule.ErrNotFound):
+		return loc.T("error.schedule_not_found")
+	case errors.Is(err, schedule.ErrEmptyText):
+		return loc.T("error.schedule_empty_text")
+	case errors.Is(err, schedule.ErrTimeInPast):
+		return loc.T("error.schedule_time_in_past")
+	case errors.Is(err, schedule.ErrInvalidCron):
+		return loc.T("error.schedule_invalid_cron")
+	case errors.Is(err, apitoken.ErrNotFound):
+		return loc.T("error.apitoken_not_found")
+	case errors.Is(err, apitoken.ErrInvalidScope):
+		return loc.T("error.apitoken_invalid_scope", apiScopeList())
+	case errors.Is(err, apitoken.ErrInvalidName):
+		return loc.T("error.apitoken_invalid_name")
+	case errors.Is(err, schedule.ErrInvalidWindow):
+		return loc.T("error.maintenance_invalid_window")
+	case errors.As(err, &ferr):
+		return loc.T("error.import_invalid_field", ferr.Field, ferr.Value)
+	case errors.As(err, &cerr) && cerr.InFile:
+		return loc.T("error.import_duplicate", cerr.Field, cerr.Value)
+	case errors.As(err, &cerr):
+		return loc.T("error.import_exists", cerr.Field, cerr.Value)
+	case errors.Is(err, dataio.ErrTooManyRows):
+		return loc.T("error.import_too_many_rows", dataio.MaxImportRows)
+	case errors.Is(err, dataio.ErrUnknownFormat):
+		return loc.T("dataio.unknown_format")
+	}
+	logger.Errorf("unexpected error: %v", err)
+	return loc.T("common.system_error")
+}
+
+// expireText 格式化到期时间
+func (b *Bot) expireText(ctx context.Context, t *time.Time) string {
+	if t == nil {
+		return b.t(ctx, "time.permanent")
+	}
+	if timeutil.IsExpired(t) {
+		return b.t(ctx, "time.expired")
+	}
+	return b.t(ctx, "time.expire_remaining", timeutil.FormatDate(*t), timeutil.DaysUntil(*t))
+}
+
+// replyKeyboardRoutes 回复键盘按钮 ID 与对应的回调数据
+// 按钮文本随语言变化，路由时先将文本还原为按钮 ID
+var replyKeyboardRoutes = map[string]string{
+	"keyboard.my_accounts": CallbackMyAccounts + ":1",
+	"keyboard.create":      CallbackCreateAccount,
+	"keyboard.help":        CallbackHelp,
+	"keyboard.admin":       CallbackAdminMenu,
+}
+
+// replyKeyboardKey 将回复键盘按钮文本还原为按钮 ID，任何语言的按钮文本都能识别
+func (b *Bot) replyKeyboardKey(text string) (string, bool) {
+	keys := make([]string, 0, len(replyKeyboardRoutes))
+	for key := range replyKeyboardRoutes {
+		keys = append(keys, key)
+	}
+	return b.catalog.KeyOf(text, keys...)
+}
+
+// handleLanguage 处理 /language 命令
+// 无参数时显示语言选择按钮；参数为语言代码或 auto 时直接设置
+func (b *Bot) handleLanguage(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+	currentUser := currentUserFromContext(ctx)
+
+	if !hasArg(args, 1) {
+		text := b.languageMenuText(ctx, currentUser)
+		reply := tgbotapi.NewMessage(msg.Chat.ID, text)
+		reply.ParseMode = "HTML"
+		reply.ReplyMarkup = LanguageKeyboard(b.loc(ctx), b.catalog)
+		if _, err := b.send(ctx, msg.Chat.ID, reply); err != nil {
+			return "", fmt.Errorf("send language menu: %w", err)
+		}
+		return "", nil
+	}
+
+	lang := strings.ToLower(getArg(args, 0))
+	if lang != languageAuto && !b.catalog.Supports(lang) {
+		return b.t(ctx, "language.unsupported", lang, strings.Join(b.catalog.Languages(), ", ")), nil
+	}
+
+	ctx, err := b.setLanguage(ctx, currentUser, msg.From, lang)
+	if err != nil {
+		return "", err
+	}
+
+	b.sendLanguageChanged(ctx, msg.Chat.ID, currentUser)
+	return "", nil
+}
+
+// handleLanguageCallback 处理语言选择按钮: lang:<语言代码|auto>
+func (b *Bot) handleLanguageCallback(ctx context.Context, query *tgbotapi.CallbackQuery, parts []string, currentUser *user.User) CallbackResponse {
+	if len(parts) < 2 {
+		return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+	}
+
+	lang := parts[1]
+	if lang != languageAuto && !b.catalog.Supports(lang) {
+		return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+	}
+
+	ctx, err := b.setLanguage(ctx, currentUser, query.From, lang)
+	if err != nil {
+		return CallbackResponse{Answer: b.t(ctx, "language.save_failed"), ShowAlert: true}
+	}
+
+	if query.Message != nil {
+		b.sendLanguageChanged(ctx, query.Message.Chat.ID, currentUser)
+	}
+
+	return CallbackResponse{
+		Answer:   b.t(ctx, "language.changed_short"),
+		EditText: b.languageMenuText(ctx, currentUser),
+	}
+}
+
+// setLanguage 保存用户的语言设置，返回切换到新语言的上下文
+func (b *Bot) setLanguage(ctx context.Context, currentUser *user.User, from *tgbotapi.User, lang string) (context.Context, error) {
+	if lang == languageAuto {
+		lang = ""
+	}
+
+	if err := b.userService.SetLanguage(ctx, currentUser.ID, lang); err != nil {
+		return ctx, fmt.Errorf("save language: %w", err)
+	}
+	currentUser.Language = lang
+
+	return b.localize(ctx, currentUser, from), nil
+}
+
+// sendLanguageChanged 以新语言发送确认消息，并刷新私聊中的回复键盘
+func (b *Bot) sendLanguageChanged(ctx context.Context, chatID int64, currentUser *user.User) {
+	msg := tgbotapi.NewMessage(chatID, b.t(ctx, "language.changed", b.t(ctx, "language.name")))
+	msg.ParseMode = "HTML"
+	if chatID > 0 {
+		msg.ReplyMarkup = MainReplyKeyboard(b.loc(ctx), b.userService.IsStaff(ctx, currentUser))
+	}
+
+	if _, err := b.send(ctx, chatID, msg); err != nil {
+		b.reply(chatID, msg.Text)
+	}
+}
+
+// languageMenuText 语言设置说明
+func (b *Bot) languageMenuText(ctx context.Context, currentUser *user.User) string {
+	current := b.t(ctx, "language.name")
+	if currentUser.Language == "" {
+		current = b.t(ctx, "language.auto_current", current)
+	}
+	return b.t(ctx, "language.menu", current)
+}
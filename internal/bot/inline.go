@@ -0,0 +1,124 @@
+// Package bot 管理员内联搜索
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/logger"
+	"emby-telegram/pkg/timeutil"
+)
+
+const (
+	// inlineResultLimit 账号和用户各自返回的最大结果数，两者之和不超过 Telegram 的 50 条上限
+	inlineResultLimit = 10
+
+	// 深链接 /start 参数前缀，后接账号或用户 ID
+	deepLinkAccountPrefix = "acc_"
+	deepLinkUserPrefix    = "usr_"
+)
+
+// handleInlineQuery 处理内联查询 (@bot 关键字)
+// 仅管理员可以搜索，其他用户收到空结果。账号按用户名前缀匹配，
+// 用户按 Telegram ID、username 或姓名匹配
+func (b *Bot) handleInlineQuery(ctx context.Context, query *tgbotapi.InlineQuery) {
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		IsPersonal:    true,
+		Results:       []interface{}{},
+	}
+	defer func() {
+		if _, err := b.api.Request(answer); err != nil {
+			logger.Errorf("failed to answer inline query: %v", err)
+		}
+	}()
+
+	currentUser, err := b.userService.GetByTelegramID(ctx, query.From.ID)
+	if err != nil || !currentUser.IsAdmin() || !currentUser.CanAccess() {
+		return
+	}
+	ctx = b.localize(ctx, currentUser, query.From)
+
+	text := strings.TrimSpace(query.Query)
+	if text == "" {
+		return
+	}
+
+	accounts, err := b.accountService.SearchByUsernamePrefix(ctx, text, inlineResultLimit)
+	if err != nil {
+		logger.Errorf("inline search accounts failed: %v", err)
+	}
+	for _, acc := range accounts {
+		status := getStatusEmoji(string(acc.Status))
+		owner := fmt.Sprintf("%s (ID: %d)", acc.GetOwnerDisplayName(), acc.OwnerTelegramID)
+		expire := b.expireText(ctx, acc.ExpireAt)
+
+		result := tgbotapi.NewInlineQueryResultArticleHTML(
+			fmt.Sprintf("acc:%d", acc.ID),
+			status+" "+acc.Username,
+			b.t(ctx, "inline.account_text", html.EscapeString(acc.Username), status, acc.Status, html.EscapeString(owner), expire),
+		)
+		result.Description = b.t(ctx, "inline.account_description", owner, expire)
+		result.ReplyMarkup = b.deepLinkMarkup(ctx, "inline.open_account", deepLinkAccountPrefix, acc.ID)
+		answer.Results = append(answer.Results, result)
+	}
+
+	users, err := b.userService.Search(ctx, text, inlineResultLimit)
+	if err != nil {
+		logger.Errorf("inline search users failed: %v", err)
+	}
+	for _, u := range users {
+		roleEmoji := "👤"
+		if u.IsAdmin() {
+			roleEmoji = "👑"
+		}
+
+		result := tgbotapi.NewInlineQueryResultArticleHTML(
+			fmt.Sprintf("usr:%d", u.ID),
+			roleEmoji+" "+u.DisplayName(),
+			b.t(ctx, "inline.user_text", roleEmoji, html.EscapeString(u.DisplayName()), u.TelegramID, u.Role, timeutil.FormatDateTime(u.CreatedAt)),
+		)
+		result.Description = b.t(ctx, "inline.user_description", u.TelegramID, u.Role)
+		result.ReplyMarkup = b.deepLinkMarkup(ctx, "inline.open_user", deepLinkUserPrefix, u.ID)
+		answer.Results = append(answer.Results, result)
+	}
+}
+
+// deepLinkMarkup 生成打开 Bot 私聊并跳转到详情视图的按钮
+func (b *Bot) deepLinkMarkup(ctx context.Context, labelKey, prefix string, id uint) *tgbotapi.InlineKeyboardMarkup {
+	link := fmt.Sprintf("https://t.me/%s?start=%s%d", b.Username(), prefix, id)
+	markup := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonURL(b.t(ctx, labelKey), link)),
+	)
+	return &markup
+}
+
+// deepLinkRoute 将 /start 深链接参数转换为管理员详情视图的回调数据
+// 权限由回调中间件检查，非管理员打开链接时按普通按钮点击处理
+func deepLinkRoute(payload string) (string, bool) {
+	routes := []struct {
+		prefix   string
+		callback string
+	}{
+		{deepLinkAccountPrefix, CallbackAdminAccountDetail},
+		{deepLinkUserPrefix, CallbackAdminUserDetail},
+	}
+
+	for _, route := range routes {
+		idStr, ok := strings.CutPrefix(payload, route.prefix)
+		if !ok {
+			continue
+		}
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil || id == 0 {
+			return "", false
+		}
+		return fmt.Sprintf("%s:%d", route.callback, id), true
+	}
+	return "", false
+}
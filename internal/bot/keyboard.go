@@ -5,6 +5,12 @@ import (
 	"fmt"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/emby"
+	"emby-telegram/internal/i18n"
+	"emby-telegram/internal/policy"
+	"emby-telegram/internal/user"
 )
 
 // Callback Data 格式常量
@@ -14,59 +20,92 @@ const (
 	CallbackHelp     = "menu:help"
 
 	// 账号列表
-	CallbackMyAccounts = "accounts:list"     // accounts:list:page
-	CallbackAccountInfo = "account:info"     // account:info:accountID
-	CallbackAccountRenew = "account:renew"   // account:renew:accountID
-	CallbackAccountPassword = "account:pwd"    // account:pwd:accountID
-	CallbackAccountDelete = "account:del"      // account:del:accountID
-	CallbackAccountSync = "account:sync"       // account:sync:accountID
-	CallbackAccountRating = "account:rating"   // account:rating:accountID
+	CallbackMyAccounts      = "accounts:list"    // accounts:list:page
+	CallbackAccountInfo     = "account:info"     // account:info:accountID
+	CallbackAccountRenew    = "account:renew"    // account:renew:accountID
+	CallbackAccountPassword = "account:pwd"      // account:pwd:accountID
+	CallbackAccountDelete   = "account:del"      // account:del:accountID
+	CallbackAccountSync     = "account:sync"     // account:sync:accountID
+	CallbackAccountRating   = "account:rating"   // account:rating:accountID
+	CallbackAccountTransfer = "account:transfer" // account:transfer:accountID
 
 	// 创建账号
 	CallbackCreateAccount = "create:start"
 
 	// 管理员菜单
-	CallbackAdminMenu = "admin:menu"
-	CallbackAdminUsers = "admin:users"       // admin:users:page
-	CallbackAdminUserDetail = "admin:user"   // admin:user:userID:page
-	CallbackAdminAccounts = "admin:accounts" // admin:accounts:page
-	CallbackAdminAccountDetail = "admin:account" // admin:account:accountID
-	CallbackAdminAccountSuspend = "admin:suspend" // admin:suspend:accountID
-	CallbackAdminAccountActivate = "admin:activate" // admin:activate:accountID
-	CallbackAdminStats = "admin:stats"
-	CallbackAdminEmby = "admin:emby"
-	CallbackAdminPlayingStats = "admin:playing"
-	CallbackAdminUpdatePolicies = "admin:updatepolicies"
-	CallbackAdminInviteCodes = "admin:invitecodes" // admin:invitecodes:page
-	CallbackAdminInviteCodeInfo = "admin:invitecode" // admin:invitecode:code
-	CallbackAdminCreateInviteCode = "admin:createcode" // admin:createcode
-	CallbackAdminRevokeInviteCode = "admin:revokecode" // admin:revokecode:code
-	CallbackAdminQuickCreateCode = "admin:quickcreate" // admin:quickcreate:preset
+	CallbackAdminMenu                  = "admin:menu"
+	CallbackAdminUsers                 = "admin:users"      // admin:users:page[:view]
+	CallbackAdminUsersSearch           = "admin:usersearch" // admin:usersearch[:view]
+	CallbackAdminUserDetail            = "admin:user"       // admin:user:userID:page[:view]
+	CallbackAdminAccounts              = "admin:accounts"   // admin:accounts:page[:view]
+	CallbackAdminAccountsSearch        = "admin:accsearch"  // admin:accsearch[:view]
+	CallbackAdminAccountDetail         = "admin:account"    // admin:account:accountID[:page[:view]]
+	CallbackAdminAccountSuspend        = "admin:suspend"    // admin:suspend:accountID
+	CallbackAdminAccountActivate       = "admin:activate"   // admin:activate:accountID
+	CallbackAdminStats                 = "admin:stats"
+	CallbackAdminEmby                  = "admin:emby"
+	CallbackAdminPlayingStats          = "admin:playing"
+	CallbackAdminUpdatePolicies        = "admin:updatepolicies"
+	CallbackAdminInviteCodes           = "admin:invitecodes" // admin:invitecodes:page[:view]
+	CallbackAdminInviteCodesSearch     = "admin:codesearch"  // admin:codesearch[:view]
+	CallbackAdminInviteCodeInfo        = "admin:invitecode"  // admin:invitecode:code
+	CallbackAdminCreateInviteCode      = "admin:createcode"  // admin:createcode
+	CallbackAdminRevokeInviteCode      = "admin:revokecode"  // admin:revokecode:code
+	CallbackAdminQuickCreateCode       = "admin:quickcreate" // admin:quickcreate:preset
+	CallbackAdminPolicyTemplates       = "admin:tpls"        // admin:tpls
+	CallbackAdminPolicyTemplate        = "admin:tpl"         // admin:tpl:templateID
+	CallbackAdminPolicyTemplateSet     = "admin:tplset"      // admin:tplset:templateID:field[:value]
+	CallbackAdminPolicyTemplateClone   = "admin:tplclone"    // admin:tplclone:templateID
+	CallbackAdminPolicyTemplateDefault = "admin:tpldef"      // admin:tpldef:templateID
+	CallbackAdminPolicyTemplateDelete  = "admin:tpldel"      // admin:tpldel:templateID[:yes]
+	CallbackAdminAccountLibraries      = "admin:libs"        // admin:libs:accountID
+	CallbackAdminAccountSettings       = "admin:ovr"         // admin:ovr:accountID[:field[:value]]
+	CallbackAdminAccountLibrary        = "admin:lib"         // admin:lib:accountID:folderID|all|reset
+	CallbackAdminPolicyDrift           = "admin:drift"       // admin:drift
+	CallbackAdminPolicyDriftAccount    = "admin:driftacc"    // admin:driftacc:accountID
+	CallbackAdminPolicyDriftFix        = "admin:driftfix"    // admin:driftfix:accountID
+	CallbackAdminPolicyDriftAccept     = "admin:driftaccept" // admin:driftaccept:accountID
+	CallbackAdminBroadcast             = "admin:broadcast"   // admin:broadcast
+	CallbackAdminBroadcastStop         = "admin:bcstop"      // admin:bcstop:jobID
+	CallbackAdminAccountsBulk          = "admin:accbulk"     // admin:accbulk[:列表状态]
+	CallbackAdminBulkStop              = "admin:bulkstop"    // admin:bulkstop:jobID
+	CallbackAdminImportRun             = "admin:importrun"   // 确认导入，文件信息保存在会话状态中
+	CallbackAdminAccountTransfer       = "admin:acctransfer" // admin:acctransfer:accountID
 
 	// 通用操作
-	CallbackConfirm = "confirm" // confirm:action:param
-	CallbackCancel  = "cancel"
-	CallbackBack    = "back" // back:to:menu
+	CallbackConfirm  = "confirm" // confirm:action:param
+	CallbackCancel   = "cancel"
+	CallbackBack     = "back"     // back:to:menu
+	CallbackLanguage = "lang"     // lang:<语言代码|auto>
+	CallbackTransfer = "transfer" // transfer:accept|decline|cancel:transferID
 )
 
 // MainMenuKeyboard 主菜单键盘
-func MainMenuKeyboard(isAdmin bool) tgbotapi.InlineKeyboardMarkup {
+// isStaff 为 true 时显示管理菜单入口，webAppURL 不为空时显示 Mini App 入口
+func MainMenuKeyboard(loc *i18n.Localizer, isStaff bool, webAppURL string) tgbotapi.InlineKeyboardMarkup {
 	rows := [][]tgbotapi.InlineKeyboardButton{
 		{
-			tgbotapi.NewInlineKeyboardButtonData("📋 我的账号", CallbackMyAccounts+":1"),
-		},
-		{
-			tgbotapi.NewInlineKeyboardButtonData("➕ 创建新账号", CallbackCreateAccount),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("keyboard.my_accounts"), CallbackMyAccounts+":1"),
 		},
 		{
-			tgbotapi.NewInlineKeyboardButtonData("❓ 帮助", CallbackHelp),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("menu.create_account"), CallbackCreateAccount),
 		},
 	}
 
-	// 管理员额外按钮
-	if isAdmin {
+	if webAppURL != "" {
 		rows = append(rows, []tgbotapi.InlineKeyboardButton{
-			tgbotapi.NewInlineKeyboardButtonData("🔑 管理员菜单", CallbackAdminMenu),
+			tgbotapi.NewInlineKeyboardButtonURL(loc.T("menu.webapp"), webAppURL),
+		})
+	}
+
+	rows = append(rows, []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData(loc.T("keyboard.help"), CallbackHelp),
+	})
+
+	// 管理人员额外按钮
+	if isStaff {
+		rows = append(rows, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("keyboard.admin"), CallbackAdminMenu),
 		})
 	}
 
@@ -74,86 +113,128 @@ func MainMenuKeyboard(isAdmin bool) tgbotapi.InlineKeyboardMarkup {
 }
 
 // AdminMenuKeyboard 管理员菜单键盘
-func AdminMenuKeyboard() tgbotapi.InlineKeyboardMarkup {
-	return tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("👥 用户管理", CallbackAdminUsers+":1"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📋 账号管理", CallbackAdminAccounts+":1"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🎟️ 邀请码管理", CallbackAdminInviteCodes+":1"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🎬 Emby 管理", CallbackAdminEmby),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📊 系统统计", CallbackAdminStats),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("⬅️ 返回主菜单", CallbackMainMenu),
-		),
-	)
+// 只显示当前用户有权限使用的功能
+func AdminMenuKeyboard(loc *i18n.Localizer, can func(user.Permission) bool) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	if can(user.PermUserManage) {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("admin_menu.users"), CallbackAdminUsers+":1"),
+		))
+	}
+	if can(user.PermAccountView) || can(user.PermCustomerManage) {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("admin_menu.accounts"), CallbackAdminAccounts+":1"),
+		))
+	}
+	if can(user.PermInviteManage) {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("admin_menu.invite_codes"), CallbackAdminInviteCodes+":1"),
+		))
+	}
+	if can(user.PermEmbyManage) || can(user.PermSessionView) || can(user.PermAccountPolicy) {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("admin_menu.emby"), CallbackAdminEmby),
+		))
+	}
+	if can(user.PermStatsView) {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("admin_menu.stats"), CallbackAdminStats),
+		))
+	}
+	if can(user.PermBroadcast) {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("admin_menu.broadcast"), CallbackAdminBroadcast),
+		))
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(loc.T("common.back_to_main"), CallbackMainMenu),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
 // EmbyMenuKeyboard Emby 管理子菜单键盘
-func EmbyMenuKeyboard() tgbotapi.InlineKeyboardMarkup {
-	return tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📊 播放统计", CallbackAdminPlayingStats),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔄 批量更新策略", CallbackAdminUpdatePolicies),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("⬅️ 返回管理员菜单", CallbackAdminMenu),
-		),
-	)
+// 只显示当前用户有权限使用的功能
+func EmbyMenuKeyboard(loc *i18n.Localizer, can func(user.Permission) bool) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	if can(user.PermSessionView) {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("emby_menu.playing"), CallbackAdminPlayingStats),
+		))
+	}
+	if can(user.PermAccountPolicy) {
+		rows = append(rows,
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(loc.T("emby_menu.templates"), CallbackAdminPolicyTemplates),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(loc.T("emby_menu.update_policies"), CallbackAdminUpdatePolicies),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(loc.T("emby_menu.drift"), CallbackAdminPolicyDrift),
+			),
+		)
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(loc.T("emby_menu.back_admin"), CallbackAdminMenu),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
 // AccountActionsKeyboard 单个账号操作键盘
-func AccountActionsKeyboard(accountID uint, isAdmin bool) tgbotapi.InlineKeyboardMarkup {
+func AccountActionsKeyboard(loc *i18n.Localizer, accountID uint, canTransfer, canDelete bool) tgbotapi.InlineKeyboardMarkup {
 	rows := [][]tgbotapi.InlineKeyboardButton{
 		{
-			tgbotapi.NewInlineKeyboardButtonData("🔄 续期", CallbackAccountRenew+":"+uintToStr(accountID)),
-			tgbotapi.NewInlineKeyboardButtonData("🔑 改密", CallbackAccountPassword+":"+uintToStr(accountID)),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("account_actions.renew"), CallbackAccountRenew+":"+uintToStr(accountID)),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("account_actions.password"), CallbackAccountPassword+":"+uintToStr(accountID)),
 		},
 		{
-			tgbotapi.NewInlineKeyboardButtonData("🔞 设置评级", CallbackAccountRating+":"+uintToStr(accountID)),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("account_actions.rating"), CallbackAccountRating+":"+uintToStr(accountID)),
 		},
 		{
-			tgbotapi.NewInlineKeyboardButtonData("🔄 同步状态", CallbackAccountSync+":"+uintToStr(accountID)),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("account_actions.sync"), CallbackAccountSync+":"+uintToStr(accountID)),
 		},
 	}
 
-	// 管理员可以删除账号
-	if isAdmin {
+	// 账号所有者可以将账号转让给其他用户
+	if canTransfer {
 		rows = append(rows, []tgbotapi.InlineKeyboardButton{
-			tgbotapi.NewInlineKeyboardButtonData("❌ 删除账号", CallbackAccountDelete+":"+uintToStr(accountID)),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("account_actions.transfer"), CallbackAccountTransfer+":"+uintToStr(accountID)),
+		})
+	}
+
+	// 拥有删除权限的用户可以删除账号
+	if canDelete {
+		rows = append(rows, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("account_actions.delete"), CallbackAccountDelete+":"+uintToStr(accountID)),
 		})
 	}
 
 	rows = append(rows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("⬅️ 返回列表", CallbackMyAccounts+":1"),
+		tgbotapi.NewInlineKeyboardButtonData(loc.T("common.back_to_list"), CallbackMyAccounts+":1"),
 	})
 
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
 // RenewDaysKeyboard 续期天数选择键盘
-func RenewDaysKeyboard(accountID uint) tgbotapi.InlineKeyboardMarkup {
+func RenewDaysKeyboard(loc *i18n.Localizer, accountID uint) tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("7天", CallbackConfirm+":renew:"+uintToStr(accountID)+":7"),
-			tgbotapi.NewInlineKeyboardButtonData("30天", CallbackConfirm+":renew:"+uintToStr(accountID)+":30"),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("renew.days_option", 7), CallbackConfirm+":renew:"+uintToStr(accountID)+":7"),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("renew.days_option", 30), CallbackConfirm+":renew:"+uintToStr(accountID)+":30"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("90天", CallbackConfirm+":renew:"+uintToStr(accountID)+":90"),
-			tgbotapi.NewInlineKeyboardButtonData("365天", CallbackConfirm+":renew:"+uintToStr(accountID)+":365"),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("renew.days_option", 90), CallbackConfirm+":renew:"+uintToStr(accountID)+":90"),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("renew.days_option", 365), CallbackConfirm+":renew:"+uintToStr(accountID)+":365"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("⬅️ 取消", CallbackAccountInfo+":"+uintToStr(accountID)),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("common.back_cancel"), CallbackAccountInfo+":"+uintToStr(accountID)),
 		),
 	)
 }
@@ -161,7 +242,7 @@ func RenewDaysKeyboard(accountID uint) tgbotapi.InlineKeyboardMarkup {
 // ParentalRatingKeyboard 家长控制评级选择键盘
 // Emby 实际评级映射:
 // 3=TV-Y7, 4=TV-Y7-FV, 5=TV-PG, 7=PG-13, 8=TV-14, 9=TV-MA, 10=NC-17, 15=AO
-func ParentalRatingKeyboard(accountID uint) tgbotapi.InlineKeyboardMarkup {
+func ParentalRatingKeyboard(loc *i18n.Localizer, accountID uint) tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("TV-Y7(3)", CallbackConfirm+":rating:"+uintToStr(accountID)+":3"),
@@ -178,23 +259,23 @@ func ParentalRatingKeyboard(accountID uint) tgbotapi.InlineKeyboardMarkup {
 			tgbotapi.NewInlineKeyboardButtonData("AO(15)", CallbackConfirm+":rating:"+uintToStr(accountID)+":15"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("⬅️ 取消", CallbackAccountInfo+":"+uintToStr(accountID)),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("common.back_cancel"), CallbackAccountInfo+":"+uintToStr(accountID)),
 		),
 	)
 }
 
 // ConfirmKeyboard 确认操作键盘
-func ConfirmKeyboard(action string, param string) tgbotapi.InlineKeyboardMarkup {
+func ConfirmKeyboard(loc *i18n.Localizer, action string, param string) tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("✅ 确认", CallbackConfirm+":"+action+":"+param),
-			tgbotapi.NewInlineKeyboardButtonData("❌ 取消", CallbackCancel),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("common.confirm"), CallbackConfirm+":"+action+":"+param),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("common.cancel"), CallbackCancel),
 		),
 	)
 }
 
 // PaginationKeyboard 分页键盘
-func PaginationKeyboard(prefix string, currentPage, totalPages int, backCallback string) tgbotapi.InlineKeyboardMarkup {
+func PaginationKeyboard(loc *i18n.Localizer, prefix string, currentPage, totalPages int, backCallback string) tgbotapi.InlineKeyboardMarkup {
 	var rows [][]tgbotapi.InlineKeyboardButton
 
 	// 分页按钮
@@ -202,7 +283,7 @@ func PaginationKeyboard(prefix string, currentPage, totalPages int, backCallback
 		var pageRow []tgbotapi.InlineKeyboardButton
 
 		if currentPage > 1 {
-			pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData("⬅️ 上一页", prefix+":"+intToStr(currentPage-1)))
+			pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData(loc.T("common.prev_page"), prefix+":"+intToStr(currentPage-1)))
 		}
 
 		pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData(
@@ -211,7 +292,7 @@ func PaginationKeyboard(prefix string, currentPage, totalPages int, backCallback
 		))
 
 		if currentPage < totalPages {
-			pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData("➡️ 下一页", prefix+":"+intToStr(currentPage+1)))
+			pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData(loc.T("common.next_page_arrow"), prefix+":"+intToStr(currentPage+1)))
 		}
 
 		rows = append(rows, pageRow)
@@ -219,17 +300,17 @@ func PaginationKeyboard(prefix string, currentPage, totalPages int, backCallback
 
 	// 返回按钮
 	rows = append(rows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("⬅️ 返回", backCallback),
+		tgbotapi.NewInlineKeyboardButtonData(loc.T("common.back"), backCallback),
 	})
 
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
 // BackButton 返回按钮
-func BackButton(callback string) tgbotapi.InlineKeyboardMarkup {
+func BackButton(loc *i18n.Localizer, callback string) tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("⬅️ 返回", callback),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("common.back"), callback),
 		),
 	)
 }
@@ -245,60 +326,348 @@ func intToStr(n int) string {
 }
 
 // MainReplyKeyboard 主菜单回复键盘（显示在输入框下方）
-func MainReplyKeyboard(isAdmin bool) tgbotapi.ReplyKeyboardMarkup {
+// isStaff 为 true 时显示管理菜单入口
+func MainReplyKeyboard(loc *i18n.Localizer, isStaff bool) tgbotapi.ReplyKeyboardMarkup {
 	rows := [][]tgbotapi.KeyboardButton{
 		{
-			tgbotapi.NewKeyboardButton("📋 我的账号"),
-			tgbotapi.NewKeyboardButton("➕ 创建账号"),
+			tgbotapi.NewKeyboardButton(loc.T("keyboard.my_accounts")),
+			tgbotapi.NewKeyboardButton(loc.T("keyboard.create")),
 		},
 		{
-			tgbotapi.NewKeyboardButton("❓ 帮助"),
+			tgbotapi.NewKeyboardButton(loc.T("keyboard.help")),
 		},
 	}
 
-	if isAdmin {
+	if isStaff {
 		rows = append(rows, []tgbotapi.KeyboardButton{
-			tgbotapi.NewKeyboardButton("🔑 管理员菜单"),
+			tgbotapi.NewKeyboardButton(loc.T("keyboard.admin")),
 		})
 	}
 
 	return tgbotapi.NewReplyKeyboard(rows...)
 }
 
+// LanguageKeyboard 界面语言选择键盘
+// 每种语言的按钮使用该语言自身的名称
+func LanguageKeyboard(loc *i18n.Localizer, catalog *i18n.Catalog) tgbotapi.InlineKeyboardMarkup {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, lang := range catalog.Languages() {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(catalog.T(lang, "language.name"), CallbackLanguage+":"+lang))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		row,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("language.auto"), CallbackLanguage+":"+languageAuto),
+		),
+	)
+}
+
 // RemoveReplyKeyboard 移除回复键盘
 func RemoveReplyKeyboard() tgbotapi.ReplyKeyboardRemove {
 	return tgbotapi.NewRemoveKeyboard(true)
 }
 
 // AdminAccountActionsKeyboard 管理员账号操作键盘
-func AdminAccountActionsKeyboard(accountID uint, status string, page int) tgbotapi.InlineKeyboardMarkup {
+// 只显示当前用户有权限使用的操作
+func AdminAccountActionsKeyboard(loc *i18n.Localizer, accountID uint, status string, listCallback string, can func(user.Permission) bool) tgbotapi.InlineKeyboardMarkup {
+	id := uintToStr(accountID)
+	managesCustomers := can(user.PermCustomerManage)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	var row []tgbotapi.InlineKeyboardButton
+	if can(user.PermAccountRenew) || managesCustomers {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(loc.T("account_actions.renew"), CallbackAccountRenew+":"+id))
+	}
+	if can(user.PermAccountPassword) || managesCustomers {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(loc.T("account_actions.password"), CallbackAccountPassword+":"+id))
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	if can(user.PermAccountPolicy) {
+		rows = append(rows,
+			[]tgbotapi.InlineKeyboardButton{
+				tgbotapi.NewInlineKeyboardButtonData(loc.T("account_actions.rating"), CallbackAccountRating+":"+id),
+				tgbotapi.NewInlineKeyboardButtonData(loc.T("policy.libraries_button"), CallbackAdminAccountLibraries+":"+id),
+			},
+			[]tgbotapi.InlineKeyboardButton{
+				tgbotapi.NewInlineKeyboardButtonData(loc.T("account_actions.settings"), CallbackAdminAccountSettings+":"+id),
+			},
+		)
+	}
+
+	if can(user.PermAccountSuspend) {
+		if status == "active" {
+			rows = append(rows, []tgbotapi.InlineKeyboardButton{
+				tgbotapi.NewInlineKeyboardButtonData(loc.T("account_actions.suspend"), CallbackAdminAccountSuspend+":"+id),
+			})
+		} else if status == "suspended" {
+			rows = append(rows, []tgbotapi.InlineKeyboardButton{
+				tgbotapi.NewInlineKeyboardButtonData(loc.T("account_actions.activate"), CallbackAdminAccountActivate+":"+id),
+			})
+		}
+	}
+
+	if can(user.PermAccountTransfer) {
+		rows = append(rows, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("account_actions.transfer"), CallbackAdminAccountTransfer+":"+id),
+		})
+	}
+
+	if can(user.PermAccountDelete) {
+		rows = append(rows, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("account_actions.delete"), CallbackAccountDelete+":"+id),
+		})
+	}
+
+	rows = append(rows, []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData(loc.T("common.back_to_list"), listCallback),
+	})
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// PolicyTemplateListKeyboard 策略模板列表键盘
+// 每个模板一行，点击后回调 prefix:templateID
+func PolicyTemplateListKeyboard(loc *i18n.Localizer, tpls []*policy.Template, prefix string, backCallback string) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	for _, tpl := range tpls {
+		label := "📐 " + tpl.Name
+		if tpl.IsDefault {
+			label += " ⭐"
+		}
+		rows = append(rows, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(label, prefix+":"+uintToStr(tpl.ID)),
+		})
+	}
+
+	rows = append(rows, []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData(loc.T("common.back"), backCallback),
+	})
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// PolicyTemplateDetailKeyboard 策略模板编辑键盘
+func PolicyTemplateDetailKeyboard(loc *i18n.Localizer, tpl *policy.Template) tgbotapi.InlineKeyboardMarkup {
+	id := uintToStr(tpl.ID)
+	setPrefix := CallbackAdminPolicyTemplateSet + ":" + id + ":"
+
 	rows := [][]tgbotapi.InlineKeyboardButton{
 		{
-			tgbotapi.NewInlineKeyboardButtonData("🔄 续期", CallbackAccountRenew+":"+uintToStr(accountID)),
-			tgbotapi.NewInlineKeyboardButtonData("🔑 改密", CallbackAccountPassword+":"+uintToStr(accountID)),
+			tgbotapi.NewInlineKeyboardButtonData(boolEmoji(tpl.EnableTranscoding)+" "+loc.T("policy.transcoding"), setPrefix+"tc"),
+			tgbotapi.NewInlineKeyboardButtonData(boolEmoji(tpl.EnableDownloads)+" "+loc.T("policy.downloads"), setPrefix+"dl"),
 		},
 		{
-			tgbotapi.NewInlineKeyboardButtonData("🔞 设置评级", CallbackAccountRating+":"+uintToStr(accountID)),
+			tgbotapi.NewInlineKeyboardButtonData(boolEmoji(tpl.EnableRemoteAccess)+" "+loc.T("policy.remote_access"), setPrefix+"ra"),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("policy.libraries_button"), setPrefix+"lib"),
+		},
+		{
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("policy.bitrate_button"), setPrefix+"br"),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("policy.rating_button"), setPrefix+"rt"),
+		},
+		{
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("policy.clone_button"), CallbackAdminPolicyTemplateClone+":"+id),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("policy.apply_button"), CallbackAdminUpdatePolicies+":"+id),
 		},
 	}
 
-	if status == "active" {
+	if !tpl.IsDefault {
 		rows = append(rows, []tgbotapi.InlineKeyboardButton{
-			tgbotapi.NewInlineKeyboardButtonData("⏸️ 停用", CallbackAdminAccountSuspend+":"+uintToStr(accountID)),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("policy.set_default_button"), CallbackAdminPolicyTemplateDefault+":"+id),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("policy.delete_button"), CallbackAdminPolicyTemplateDelete+":"+id),
 		})
-	} else if status == "suspended" {
+	}
+
+	rows = append(rows, []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData(loc.T("policy.back_templates"), CallbackAdminPolicyTemplates),
+	})
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// TemplateBitrateKeyboard 模板码率上限选择键盘（单位 bps）
+func TemplateBitrateKeyboard(loc *i18n.Localizer, templateID uint) tgbotapi.InlineKeyboardMarkup {
+	return bitrateKeyboard(loc, CallbackAdminPolicyTemplateSet+":"+uintToStr(templateID)+":br:", CallbackAdminPolicyTemplate+":"+uintToStr(templateID))
+}
+
+// AccountBitrateKeyboard 账号码率上限选择键盘（单位 bps）
+func AccountBitrateKeyboard(loc *i18n.Localizer, accountID uint) tgbotapi.InlineKeyboardMarkup {
+	return bitrateKeyboard(loc, CallbackAdminAccountSettings+":"+uintToStr(accountID)+":br:", CallbackAdminAccountSettings+":"+uintToStr(accountID))
+}
+
+// bitrateKeyboard 码率上限选择键盘，prefix 后拼接码率取值
+func bitrateKeyboard(loc *i18n.Localizer, prefix, cancelCallback string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("policy.bitrate_unlimited"), prefix+"0"),
+			tgbotapi.NewInlineKeyboardButtonData("4 Mbps", prefix+"4000000"),
+			tgbotapi.NewInlineKeyboardButtonData("8 Mbps", prefix+"8000000"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("12 Mbps", prefix+"12000000"),
+			tgbotapi.NewInlineKeyboardButtonData("20 Mbps", prefix+"20000000"),
+			tgbotapi.NewInlineKeyboardButtonData("40 Mbps", prefix+"40000000"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("common.back_cancel"), cancelCallback),
+		),
+	)
+}
+
+// TemplateRatingKeyboard 模板家长控制评级选择键盘
+// 评级取值与 ParentalRatingKeyboard 一致
+func TemplateRatingKeyboard(loc *i18n.Localizer, templateID uint) tgbotapi.InlineKeyboardMarkup {
+	prefix := CallbackAdminPolicyTemplateSet + ":" + uintToStr(templateID) + ":rt:"
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("TV-Y7(3)", prefix+"3"),
+			tgbotapi.NewInlineKeyboardButtonData("TV-Y7-FV(4)", prefix+"4"),
+			tgbotapi.NewInlineKeyboardButtonData("TV-PG(5)", prefix+"5"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("PG-13(7)", prefix+"7"),
+			tgbotapi.NewInlineKeyboardButtonData("TV-14(8)", prefix+"8"),
+			tgbotapi.NewInlineKeyboardButtonData("TV-MA(9)", prefix+"9"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("NC-17(10)", prefix+"10"),
+			tgbotapi.NewInlineKeyboardButtonData("AO(15)", prefix+"15"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("common.back_cancel"), CallbackAdminPolicyTemplate+":"+uintToStr(templateID)),
+		),
+	)
+}
+
+// PolicyScopeKeyboard 批量应用策略的账号范围选择键盘
+func PolicyScopeKeyboard(loc *i18n.Localizer, templateID uint) tgbotapi.InlineKeyboardMarkup {
+	prefix := CallbackAdminUpdatePolicies + ":" + uintToStr(templateID) + ":"
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("policy.scope_active"), prefix+policyScopeActive),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("policy.scope_all"), prefix+policyScopeAll),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("policy.scope_emby"), prefix+policyScopeEmby),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("common.back"), CallbackAdminUpdatePolicies),
+		),
+	)
+}
+
+// PolicyDriftListKeyboard 存在策略偏差的账号列表键盘
+func PolicyDriftListKeyboard(loc *i18n.Localizer, reports []*account.DriftReport) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	for _, r := range reports {
+		text := loc.T("drift.list_item", r.Account.Username, len(r.Diffs))
 		rows = append(rows, []tgbotapi.InlineKeyboardButton{
-			tgbotapi.NewInlineKeyboardButtonData("✅ 激活", CallbackAdminAccountActivate+":"+uintToStr(accountID)),
+			tgbotapi.NewInlineKeyboardButtonData(text, CallbackAdminPolicyDriftAccount+":"+uintToStr(r.Account.ID)),
 		})
 	}
 
 	rows = append(rows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("❌ 删除账号", CallbackAccountDelete+":"+uintToStr(accountID)),
+		tgbotapi.NewInlineKeyboardButtonData(loc.T("drift.recheck"), CallbackAdminPolicyDrift),
+		tgbotapi.NewInlineKeyboardButtonData(loc.T("common.back"), CallbackAdminEmby),
 	})
 
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// PolicyDriftActionsKeyboard 单个账号策略偏差处理键盘
+func PolicyDriftActionsKeyboard(loc *i18n.Localizer, accountID uint, hasDrift bool) tgbotapi.InlineKeyboardMarkup {
+	id := uintToStr(accountID)
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	if hasDrift {
+		rows = append(rows, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("drift.fix_button"), CallbackAdminPolicyDriftFix+":"+id),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("drift.accept_button"), CallbackAdminPolicyDriftAccept+":"+id),
+		})
+	}
+
 	rows = append(rows, []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("⬅️ 返回列表", CallbackAdminAccounts+":"+intToStr(page)),
+		tgbotapi.NewInlineKeyboardButtonData(loc.T("drift.recheck"), CallbackAdminPolicyDriftAccount+":"+id),
+		tgbotapi.NewInlineKeyboardButtonData(loc.T("common.back_to_list"), CallbackAdminPolicyDrift),
 	})
 
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
+
+// AccountSettingsKeyboard 账号播放设置键盘
+func AccountSettingsKeyboard(loc *i18n.Localizer, accountID uint, p *emby.UserPolicy, overridden bool) tgbotapi.InlineKeyboardMarkup {
+	prefix := CallbackAdminAccountSettings + ":" + uintToStr(accountID) + ":"
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		{
+			tgbotapi.NewInlineKeyboardButtonData(boolEmoji(p.EnableContentDownloading)+" "+loc.T("policy.downloads"), prefix+"dl"),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("policy.bitrate_button"), prefix+"br"),
+		},
+		{
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("policy.rating_button"), CallbackAccountRating+":"+uintToStr(accountID)),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("policy.libraries_button"), CallbackAdminAccountLibraries+":"+uintToStr(accountID)),
+		},
+	}
+
+	if overridden {
+		rows = append(rows, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("settings.reset_all"), prefix+"reset"),
+		})
+	}
+
+	rows = append(rows, []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData(loc.T("common.back"), CallbackAdminAccountDetail+":"+uintToStr(accountID)),
+	})
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// LibraryAccessKeyboard 媒体库多选键盘
+// 点击媒体库切换选中状态，togglePrefix 后拼接媒体库 ID；extraRows 放在返回按钮之前
+func LibraryAccessKeyboard(loc *i18n.Localizer, folders []*emby.VirtualFolder, allFolders bool, selected []string, togglePrefix string, extraRows [][]tgbotapi.InlineKeyboardButton, backCallback string) tgbotapi.InlineKeyboardMarkup {
+	chosen := make(map[string]bool, len(selected))
+	for _, id := range selected {
+		chosen[id] = true
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+
+	for _, f := range folders {
+		text := boolEmoji(allFolders || chosen[f.ItemID]) + " " + f.Name
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(text, togglePrefix+f.ItemID))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	rows = append(rows, []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData(boolEmoji(allFolders)+" "+loc.T("library.all"), togglePrefix+libraryAll),
+	})
+	rows = append(rows, extraRows...)
+	rows = append(rows, []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData(loc.T("common.back"), backCallback),
+	})
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// boolEmoji 布尔值对应的状态图标
+func boolEmoji(v bool) string {
+	if v {
+		return "✅"
+	}
+	return "❌"
+}
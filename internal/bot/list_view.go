@@ -0,0 +1,373 @@
+// Package bot 管理列表的筛选、排序和搜索
+package bot
+
+import (
+	"context"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/conversation"
+	"emby-telegram/internal/invitecode"
+	"emby-telegram/internal/user"
+)
+
+const (
+	// maxListSearchBytes 搜索词的最大字节数
+	// 搜索词随列表状态编码进回调数据，需要保证最长的回调数据不超过 Telegram 的 64 字节限制
+	maxListSearchBytes = 16
+
+	// listChipsPerRow 每行显示的筛选按钮数
+	listChipsPerRow = 3
+
+	// listDefaultCode 默认筛选和排序的编码
+	listDefaultCode = '-'
+)
+
+// listOption 列表的一个筛选或排序选项
+type listOption struct {
+	code  byte   // 回调数据中的单字符编码
+	value string // 对应的查询条件取值
+	key   string // 按钮文本的 i18n 键
+}
+
+// listSpec 可筛选列表的定义
+// 第一个筛选和排序选项为默认值，编码均为 listDefaultCode
+type listSpec struct {
+	kind       string          // 搜索状态中保存的列表类型
+	callback   string          // 列表回调前缀
+	search     string          // 开始搜索的回调
+	hintKey    string          // 搜索提示的 i18n 键
+	permission user.Permission // 查看列表所需权限，为空表示工作人员即可
+	filters    []listOption
+	sorts      []listOption
+}
+
+var (
+	usersListSpec = &listSpec{
+		kind:       "users",
+		callback:   CallbackAdminUsers,
+		search:     CallbackAdminUsersSearch,
+		hintKey:    "list.search_hint_users",
+		permission: user.PermUserManage,
+		filters: []listOption{
+			{listDefaultCode, string(user.FilterAll), "list.filter_all"},
+			{'b', string(user.FilterBlocked), "list.filter_blocked"},
+			{'x', string(user.FilterBotBlocked), "list.filter_bot_blocked"},
+		},
+		sorts: []listOption{
+			{listDefaultCode, string(user.SortCreated), "list.sort_created"},
+			{'u', string(user.SortUsername), "list.sort_username"},
+		},
+	}
+
+	accountsListSpec = &listSpec{
+		kind:     "accounts",
+		callback: CallbackAdminAccounts,
+		search:   CallbackAdminAccountsSearch,
+		hintKey:  "list.search_hint_accounts",
+		filters: []listOption{
+			{listDefaultCode, string(account.FilterAll), "list.filter_all"},
+			{'a', string(account.FilterActive), "list.filter_active"},
+			{'s', string(account.FilterSuspended), "list.filter_suspended"},
+			{'e', string(account.FilterExpired), "list.filter_expired"},
+			{'w', string(account.FilterExpiring), "list.filter_expiring"},
+			{'f', string(account.FilterSyncFailed), "list.filter_sync_failed"},
+		},
+		sorts: []listOption{
+			{listDefaultCode, string(account.SortCreated), "list.sort_created"},
+			{'x', string(account.SortExpiry), "list.sort_expiry"},
+			{'u', string(account.SortUsername), "list.sort_username"},
+		},
+	}
+
+	inviteCodesListSpec = &listSpec{
+		kind:       "invitecodes",
+		callback:   CallbackAdminInviteCodes,
+		search:     CallbackAdminInviteCodesSearch,
+		hintKey:    "list.search_hint_invitecodes",
+		permission: user.PermInviteManage,
+		filters: []listOption{
+			{listDefaultCode, string(invitecode.FilterAll), "list.filter_all"},
+			{'a', string(invitecode.FilterActive), "list.filter_active"},
+			{'e', string(invitecode.FilterExpired), "list.filter_expired"},
+			{'x', string(invitecode.FilterExhausted), "list.filter_exhausted"},
+			{'r', string(invitecode.FilterRevoked), "list.filter_revoked"},
+		},
+		sorts: []listOption{
+			{listDefaultCode, string(invitecode.SortCreated), "list.sort_created"},
+			{'x', string(invitecode.SortExpiry), "list.sort_expiry"},
+			{'c', string(invitecode.SortCode), "list.sort_code"},
+		},
+	}
+
+	// listSpecs 按列表类型索引，用于处理搜索输入
+	listSpecs = map[string]*listSpec{
+		usersListSpec.kind:       usersListSpec,
+		accountsListSpec.kind:    accountsListSpec,
+		inviteCodesListSpec.kind: inviteCodesListSpec,
+	}
+)
+
+// listView 列表当前的筛选、排序和搜索状态
+// 编码为 "<筛选><排序><搜索词>" 附加在回调数据末尾，默认状态编码为空字符串
+type listView struct {
+	filter byte
+	sort   byte
+	search string
+}
+
+// parse 解析回调数据中的列表状态，无法识别的编码按默认值处理
+func (s *listSpec) parse(data string) listView {
+	view := listView{filter: listDefaultCode, sort: listDefaultCode}
+	if len(data) < 2 {
+		return view
+	}
+	if _, ok := findListOption(s.filters, data[0]); ok {
+		view.filter = data[0]
+	}
+	if _, ok := findListOption(s.sorts, data[1]); ok {
+		view.sort = data[1]
+	}
+	view.search = clipListSearch(data[2:])
+	return view
+}
+
+// filterValue 返回当前筛选条件的查询取值
+func (s *listSpec) filterValue(view listView) string {
+	opt, _ := findListOption(s.filters, view.filter)
+	return opt.value
+}
+
+// sortValue 返回当前排序方式的查询取值
+func (s *listSpec) sortValue(view listView) string {
+	opt, _ := findListOption(s.sorts, view.sort)
+	return opt.value
+}
+
+// isDefault 是否为未筛选、默认排序且没有搜索的状态
+func (v listView) isDefault() bool {
+	return v.filter == listDefaultCode && v.sort == listDefaultCode && v.search == ""
+}
+
+// encode 编码列表状态
+func (v listView) encode() string {
+	if v.isDefault() {
+		return ""
+	}
+	return string([]byte{v.filter, v.sort}) + v.search
+}
+
+// suffix 返回附加到回调数据末尾的列表状态，默认状态为空
+func (v listView) suffix() string {
+	if enc := v.encode(); enc != "" {
+		return ":" + enc
+	}
+	return ""
+}
+
+// findListOption 按编码查找选项，找不到时返回第一个(默认)选项
+func findListOption(options []listOption, code byte) (listOption, bool) {
+	for _, opt := range options {
+		if opt.code == code {
+			return opt, true
+		}
+	}
+	return options[0], false
+}
+
+// clipListSearch 截断搜索词，保证不超过 maxListSearchBytes 且不截断多字节字符
+func clipListSearch(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= maxListSearchBytes {
+		return s
+	}
+	s = s[:maxListSearchBytes]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// listViewParam 取回调参数中从 index 开始的列表状态，搜索词中可能包含冒号
+func listViewParam(parts []string, index int) string {
+	if len(parts) <= index {
+		return ""
+	}
+	return strings.Join(parts[index:], ":")
+}
+
+// listCallback 生成列表指定页的回调数据: <前缀>:<页码>[:<列表状态>]
+func listCallback(prefix string, page int, view listView) string {
+	return prefix + ":" + intToStr(page) + view.suffix()
+}
+
+// listSummary 非默认状态时显示当前的筛选、排序和搜索条件
+func (b *Bot) listSummary(ctx context.Context, spec *listSpec, view listView) string {
+	if view.isDefault() {
+		return ""
+	}
+
+	filter, _ := findListOption(spec.filters, view.filter)
+	sort, _ := findListOption(spec.sorts, view.sort)
+	text := b.t(ctx, "list.summary", b.t(ctx, filter.key), b.t(ctx, sort.key))
+	if view.search != "" {
+		text += b.t(ctx, "list.summary_search", html.EscapeString(view.search))
+	}
+	return text
+}
+
+// listPageRow 生成保留列表状态的分页按钮行
+func (b *Bot) listPageRow(ctx context.Context, prefix string, page, totalPages int, view listView) []tgbotapi.InlineKeyboardButton {
+	var pageRow []tgbotapi.InlineKeyboardButton
+	if page > 1 {
+		pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.prev_page"), listCallback(prefix, page-1, view)))
+	}
+	pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData(
+		intToStr(page)+"/"+intToStr(totalPages),
+		"page:current",
+	))
+	if page < totalPages {
+		pageRow = append(pageRow, tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.next_page_arrow"), listCallback(prefix, page+1, view)))
+	}
+	return pageRow
+}
+
+// listControlRows 生成筛选、排序和搜索按钮，切换条件后回到第一页
+func (b *Bot) listControlRows(ctx context.Context, spec *listSpec, view listView) [][]tgbotapi.InlineKeyboardButton {
+	var buttons []tgbotapi.InlineKeyboardButton
+	for _, opt := range spec.filters {
+		next := view
+		next.filter = opt.code
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(
+			b.listOptionLabel(ctx, opt, opt.code == view.filter),
+			listCallback(spec.callback, 1, next),
+		))
+	}
+	rows := chunkButtons(buttons, listChipsPerRow)
+
+	var sortRow []tgbotapi.InlineKeyboardButton
+	for _, opt := range spec.sorts {
+		next := view
+		next.sort = opt.code
+		sortRow = append(sortRow, tgbotapi.NewInlineKeyboardButtonData(
+			b.listOptionLabel(ctx, opt, opt.code == view.sort),
+			listCallback(spec.callback, 1, next),
+		))
+	}
+	rows = append(rows, sortRow)
+
+	if view.search == "" {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "list.search"), spec.search+view.suffix()),
+		))
+	} else {
+		cleared := view
+		cleared.search = ""
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "list.search_edit"), spec.search+view.suffix()),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "list.search_clear"), listCallback(spec.callback, 1, cleared)),
+		))
+	}
+
+	return rows
+}
+
+// listOptionLabel 选项按钮文本，当前选中的选项带有标记
+func (b *Bot) listOptionLabel(ctx context.Context, opt listOption, selected bool) string {
+	label := b.t(ctx, opt.key)
+	if selected {
+		return "✔️ " + label
+	}
+	return label
+}
+
+// chunkButtons 将按钮按每行 n 个分组
+func chunkButtons(buttons []tgbotapi.InlineKeyboardButton, n int) [][]tgbotapi.InlineKeyboardButton {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for len(buttons) > n {
+		rows = append(rows, buttons[:n])
+		buttons = buttons[n:]
+	}
+	if len(buttons) > 0 {
+		rows = append(rows, buttons)
+	}
+	return rows
+}
+
+// showList 显示指定列表
+func (b *Bot) showList(ctx context.Context, spec *listSpec, page int, view listView) CallbackResponse {
+	switch spec {
+	case usersListSpec:
+		return b.showUsersList(ctx, page, view)
+	case accountsListSpec:
+		return b.showAllAccountsList(ctx, page, view)
+	default:
+		return b.showInviteCodesList(ctx, page, view)
+	}
+}
+
+// startListSearch 进入搜索输入状态，保存当前列表状态以便输入后恢复筛选和排序
+func (b *Bot) startListSearch(ctx context.Context, currentUser *user.User, spec *listSpec, view listView) CallbackResponse {
+	payload := conversation.Payload{Values: map[string]string{
+		"list": spec.kind,
+		"view": view.encode(),
+	}}
+	if err := b.stateMachine.SetState(ctx, currentUser.TelegramID, StateWaitingListSearch, payload); err != nil {
+		return CallbackResponse{Answer: b.t(ctx, "common.system_error"), ShowAlert: true}
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.cancel"), CallbackCancel),
+		),
+	)
+
+	return CallbackResponse{
+		EditText:   b.t(ctx, "list.search_prompt", b.t(ctx, spec.hintKey), maxListSearchBytes),
+		EditMarkup: &keyboard,
+	}
+}
+
+// handleListSearchInput 处理管理列表的搜索关键字输入
+func (b *Bot) handleListSearchInput(ctx context.Context, msg *tgbotapi.Message, currentUser *user.User, payload conversation.Payload) {
+	spec, ok := listSpecs[payload.Values["list"]]
+	if !ok {
+		b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+		b.reply(msg.Chat.ID, b.t(ctx, "common.session_expired"))
+		return
+	}
+
+	allowed := b.userService.IsStaff(ctx, currentUser)
+	if spec.permission != "" {
+		allowed = b.userService.Can(ctx, currentUser, spec.permission)
+	}
+	if !allowed {
+		b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+		b.reply(msg.Chat.ID, b.t(ctx, "input.admin_required"))
+		return
+	}
+
+	b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+
+	view := spec.parse(payload.Values["view"])
+	view.search = clipListSearch(msg.Text)
+
+	response := b.showList(ctx, spec, 1, view)
+	if response.EditText == "" {
+		b.reply(msg.Chat.ID, response.Answer)
+		return
+	}
+
+	replyMsg := tgbotapi.NewMessage(msg.Chat.ID, response.EditText)
+	replyMsg.ParseMode = "HTML"
+	if response.EditMarkup != nil {
+		replyMsg.ReplyMarkup = response.EditMarkup
+	}
+	if _, err := b.send(ctx, msg.Chat.ID, replyMsg); err != nil {
+		b.reply(msg.Chat.ID, response.EditText)
+	}
+}
@@ -0,0 +1,333 @@
+// Package bot 命令与回调中间件
+package bot
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/user"
+)
+
+// commandSpec 命令元数据
+// 声明式描述命令的执行条件，由中间件统一校验
+type commandSpec struct {
+	handler           CommandHandler
+	permission        user.Permission // 需要的权限，为空表示不检查
+	staffOnly         bool            // 需要拥有任一管理权限
+	adminOnly         bool            // 仅超级管理员可用
+	privateOnly       bool            // 仅私聊可用
+	privateHint       string          // privateOnly 命令在非私聊中的提示消息 ID，为空时使用默认提示
+	groupAllowed      bool            // 允许在群组中使用
+	groupOnly         bool            // 仅群组可用
+	accountArg        int             // 大于 0 时第 accountArg 个参数为账号用户名，需要 accountPermission 权限
+	accountPermission string          // 操作该账号所需的权限(account 包中的权限名)
+
+	run CommandHandler // 套上中间件后的处理函数
+}
+
+// CommandMiddleware 命令中间件
+type CommandMiddleware func(next CommandHandler) CommandHandler
+
+// CallbackHandler 回调处理函数类型
+type CallbackHandler func(ctx context.Context, query *tgbotapi.CallbackQuery, parts []string, currentUser *user.User) CallbackResponse
+
+// callbackSpec 回调路由元数据
+type callbackSpec struct {
+	handler           CallbackHandler
+	permission        user.Permission   // 需要的权限，为空表示不检查
+	anyPermission     []user.Permission // 需要其中任一权限，为空表示不检查
+	staffOnly         bool              // 需要拥有任一管理权限
+	accountParam      int               // 大于 0 时 parts[accountParam] 为账号 ID
+	accountPermission string            // 操作该账号所需的权限，为空时要求账号归属于当前用户
+
+	run CallbackHandler // 套上中间件后的处理函数
+}
+
+// CallbackMiddleware 回调中间件
+type CallbackMiddleware func(next CallbackHandler) CallbackHandler
+
+type contextKey int
+
+const (
+	currentUserKey contextKey = iota
+	accountKey
+	localizerKey
+)
+
+// withCurrentUser 将当前用户放入上下文
+func withCurrentUser(ctx context.Context, u *user.User) context.Context {
+	return context.WithValue(ctx, currentUserKey, u)
+}
+
+// currentUserFromContext 获取上下文中的当前用户
+func currentUserFromContext(ctx context.Context) *user.User {
+	u, _ := ctx.Value(currentUserKey).(*user.User)
+	return u
+}
+
+// withAccount 将归属校验通过的账号放入上下文
+func withAccount(ctx context.Context, acc *account.Account) context.Context {
+	return context.WithValue(ctx, accountKey, acc)
+}
+
+// accountFromContext 获取归属校验通过的账号
+func accountFromContext(ctx context.Context) *account.Account {
+	acc, _ := ctx.Value(accountKey).(*account.Account)
+	return acc
+}
+
+// command 注册命令并构建中间件链
+func (b *Bot) command(name string, spec commandSpec) {
+	spec.run = chainCommand(spec.handler,
+		b.recoverCommand(name),
+		b.logCommand(name),
+		b.rateLimitCommand(),
+		b.requireCommand(&spec),
+	)
+	b.commands[name] = &spec
+}
+
+// callback 注册回调路由并构建中间件链
+// route 为 callback data 的第一段，或 "第一段:第二段" 以对子操作单独声明条件
+func (b *Bot) callback(route string, spec callbackSpec) {
+	spec.run = chainCallback(spec.handler,
+		b.recoverCallback(route),
+		b.logCallback(route),
+		b.rateLimitCallback(),
+		b.requireCallback(&spec),
+	)
+	b.callbacks[route] = &spec
+}
+
+// lookupCallback 查找回调路由，子操作路由优先
+func (b *Bot) lookupCallback(parts []string) (*callbackSpec, bool) {
+	if len(parts) >= 2 {
+		if spec, ok := b.callbacks[parts[0]+":"+parts[1]]; ok {
+			return spec, true
+		}
+	}
+	spec, ok := b.callbacks[parts[0]]
+	return spec, ok
+}
+
+// chainCommand 按顺序套上中间件，第一个中间件位于最外层
+func chainCommand(h CommandHandler, mws ...CommandMiddleware) CommandHandler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// chainCallback 按顺序套上中间件，第一个中间件位于最外层
+func chainCallback(h CallbackHandler, mws ...CallbackMiddleware) CallbackHandler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// recoverCommand 恢复命令处理中的 panic，向用户返回通用错误
+func (b *Bot) recoverCommand(name string) CommandMiddleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx context.Context, msg *tgbotapi.Message, args []string) (reply string, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Errorf("panic in command /%s: %v\n%s", name, r, debug.Stack())
+					reply, err = b.t(ctx, "common.system_error_icon"), nil
+				}
+			}()
+			return next(ctx, msg, args)
+		}
+	}
+}
+
+// logCommand 记录命令执行情况与耗时
+func (b *Bot) logCommand(name string) CommandMiddleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+			start := time.Now()
+			reply, err := next(ctx, msg, args)
+			who := fmt.Sprint(msg.From.ID)
+			if u := currentUserFromContext(ctx); u != nil {
+				who = u.DisplayName()
+			}
+			logger.Infof("user executed command: %s, command: %s, duration: %s", who, name, time.Since(start))
+			return reply, err
+		}
+	}
+}
+
+// rateLimitCommand 按用户限制命令频率，管理员不受限制
+func (b *Bot) rateLimitCommand() CommandMiddleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+			if !isAdminUser(currentUserFromContext(ctx)) && !b.limiter.Allow(msg.From.ID) {
+				return b.t(ctx, "common.rate_limited"), nil
+			}
+			return next(ctx, msg, args)
+		}
+	}
+}
+
+// requireCommand 校验命令元数据中声明的执行条件
+func (b *Bot) requireCommand(spec *commandSpec) CommandMiddleware {
+	return func(next CommandHandler) CommandHandler {
+		return func(ctx context.Context, msg *tgbotapi.Message, args []string) (string, error) {
+			if spec.privateOnly && !isPrivateChat(msg) {
+				if spec.privateHint != "" {
+					return b.t(ctx, spec.privateHint), nil
+				}
+				return b.t(ctx, "common.private_only"), nil
+			}
+			if spec.groupOnly && !isGroupChat(msg) {
+				return b.t(ctx, "common.group_only"), nil
+			}
+			if isGroupChat(msg) && !spec.groupAllowed && !spec.groupOnly {
+				return b.t(ctx, "common.group_not_allowed"), nil
+			}
+
+			currentUser := currentUserFromContext(ctx)
+			if spec.adminOnly && !isAdminUser(currentUser) {
+				return b.t(ctx, "common.admin_required"), nil
+			}
+			if !b.hasAccess(ctx, currentUser, spec.permission, spec.staffOnly) {
+				return b.t(ctx, "common.command_forbidden"), nil
+			}
+
+			// 缺少账号参数时交给处理函数提示用法
+			if spec.accountArg > 0 && hasArg(args, spec.accountArg) {
+				acc, err := b.accountService.GetByUsername(ctx, getArg(args, spec.accountArg-1))
+				if err != nil {
+					return "", fmt.Errorf("get account: %w", err)
+				}
+				if err := b.checkAccountAccess(ctx, currentUser, acc, spec.accountPermission); err != nil {
+					return b.t(ctx, "common.account_forbidden"), nil
+				}
+				ctx = withAccount(ctx, acc)
+			}
+
+			return next(ctx, msg, args)
+		}
+	}
+}
+
+// recoverCallback 恢复回调处理中的 panic，向用户返回通用错误
+func (b *Bot) recoverCallback(route string) CallbackMiddleware {
+	return func(next CallbackHandler) CallbackHandler {
+		return func(ctx context.Context, query *tgbotapi.CallbackQuery, parts []string, currentUser *user.User) (resp CallbackResponse) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Errorf("panic in callback %s: %v\n%s", route, r, debug.Stack())
+					resp = CallbackResponse{Answer: b.t(ctx, "common.system_error"), ShowAlert: true}
+				}
+			}()
+			return next(ctx, query, parts, currentUser)
+		}
+	}
+}
+
+// logCallback 记录按钮操作与耗时
+func (b *Bot) logCallback(route string) CallbackMiddleware {
+	return func(next CallbackHandler) CallbackHandler {
+		return func(ctx context.Context, query *tgbotapi.CallbackQuery, parts []string, currentUser *user.User) CallbackResponse {
+			start := time.Now()
+			resp := next(ctx, query, parts, currentUser)
+			logger.Infof("user clicked button: %s, data: %s, duration: %s", currentUser.DisplayName(), query.Data, time.Since(start))
+			return resp
+		}
+	}
+}
+
+// rateLimitCallback 按用户限制按钮操作频率，管理员不受限制
+func (b *Bot) rateLimitCallback() CallbackMiddleware {
+	return func(next CallbackHandler) CallbackHandler {
+		return func(ctx context.Context, query *tgbotapi.CallbackQuery, parts []string, currentUser *user.User) CallbackResponse {
+			if !currentUser.IsAdmin() && !b.limiter.Allow(currentUser.TelegramID) {
+				return CallbackResponse{Answer: b.t(ctx, "common.rate_limited"), ShowAlert: true}
+			}
+			return next(ctx, query, parts, currentUser)
+		}
+	}
+}
+
+// requireCallback 校验回调路由中声明的执行条件
+func (b *Bot) requireCallback(spec *callbackSpec) CallbackMiddleware {
+	return func(next CallbackHandler) CallbackHandler {
+		return func(ctx context.Context, query *tgbotapi.CallbackQuery, parts []string, currentUser *user.User) CallbackResponse {
+			if !b.hasAccess(ctx, currentUser, spec.permission, spec.staffOnly) || !b.hasAnyPermission(ctx, currentUser, spec.anyPermission) {
+				return CallbackResponse{Answer: b.t(ctx, "common.feature_forbidden"), ShowAlert: true}
+			}
+
+			if spec.accountParam > 0 {
+				if len(parts) <= spec.accountParam {
+					return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+				}
+				acc, err := b.accountService.Get(ctx, strToUint(parts[spec.accountParam]))
+				if err != nil {
+					return CallbackResponse{Answer: b.t(ctx, "common.get_account_failed"), ShowAlert: true}
+				}
+				if err := b.checkAccountAccess(ctx, currentUser, acc, spec.accountPermission); err != nil {
+					return CallbackResponse{Answer: b.t(ctx, "common.account_forbidden_short"), ShowAlert: true}
+				}
+				ctx = withAccount(ctx, acc)
+			}
+
+			return next(ctx, query, parts, currentUser)
+		}
+	}
+}
+
+// hasAccess 检查用户是否满足权限要求
+func (b *Bot) hasAccess(ctx context.Context, u *user.User, perm user.Permission, staffOnly bool) bool {
+	if u == nil {
+		return false
+	}
+	if staffOnly && !b.userService.IsStaff(ctx, u) {
+		return false
+	}
+	return perm == "" || b.userService.Can(ctx, u, perm)
+}
+
+// hasAnyPermission 检查用户是否拥有其中任一权限，perms 为空时不检查
+func (b *Bot) hasAnyPermission(ctx context.Context, u *user.User, perms []user.Permission) bool {
+	if len(perms) == 0 {
+		return true
+	}
+	for _, perm := range perms {
+		if b.userService.Can(ctx, u, perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAccountAccess 检查用户能否操作账号
+// permission 为空时要求账号归属于当前用户(拥有策略管理权限的用户除外)，否则按账号服务的权限规则判断
+func (b *Bot) checkAccountAccess(ctx context.Context, u *user.User, acc *account.Account, permission string) error {
+	if permission == "" {
+		if b.userService.Can(ctx, u, user.PermAccountPolicy) {
+			return nil
+		}
+		return b.accountService.CheckOwnership(ctx, acc.ID, u.ID)
+	}
+	return b.accountService.CanManage(ctx, u.ID, acc, permission)
+}
+
+// permissionChecker 返回当前用户的权限判断函数，用于按权限生成菜单
+func (b *Bot) permissionChecker(ctx context.Context) func(user.Permission) bool {
+	u := currentUserFromContext(ctx)
+	return func(perm user.Permission) bool {
+		return b.userService.Can(ctx, u, perm)
+	}
+}
+
+// isAdminUser 检查用户是否为管理员，所有管理员判断都以数据库角色为准
+func isAdminUser(u *user.User) bool {
+	return u != nil && u.IsAdmin()
+}
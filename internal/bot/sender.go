@@ -0,0 +1,302 @@
+// Package bot 消息发送队列
+package bot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/logger"
+	"emby-telegram/pkg/ratelimit"
+)
+
+// 发送队列默认参数，与 Telegram 公布的限制一致
+const (
+	defaultSendRate           = 30 // 全局每秒消息数
+	defaultChatSendRate       = 1  // 每个聊天每秒消息数
+	defaultGroupSendPerMinute = 20 // 每个群组每分钟消息数
+	defaultSendRetries        = 3  // 失败后的最大重试次数
+)
+
+// sendRetryBackoff 网络错误重试的初始等待时间，每次重试翻倍
+const sendRetryBackoff = time.Second
+
+// laneIdleTimeout 聊天通道空闲多久后回收
+const laneIdleTimeout = time.Minute
+
+// SendResult 消息发送结果
+type SendResult struct {
+	Message tgbotapi.Message
+	Err     error
+}
+
+// SenderStats 发送队列运行指标
+type SenderStats struct {
+	Pending   int64 // 排队中的消息数
+	Sent      int64 // 发送成功数
+	Failed    int64 // 最终失败数
+	Retried   int64 // 重试次数
+	Throttled int64 // 收到 429 的次数
+}
+
+// sendRequest 待发送的消息
+type sendRequest struct {
+	ctx    context.Context
+	chatID int64
+	msg    tgbotapi.Chattable
+	result chan SendResult // 容量为 1，为 nil 时由发送队列记录失败日志
+}
+
+// chatLane 单个聊天的发送通道，保证同一聊天的消息按提交顺序发送
+type chatLane struct {
+	pending  []*sendRequest
+	running  bool
+	lastSent time.Time
+	recent   []time.Time // 群组最近一分钟的发送时间
+	idleAt   time.Time
+}
+
+// sender 出站消息队列
+// 全局按令牌桶限速，每个聊天按最小间隔限速，群组另有每分钟上限；
+// 遇到 429 按 retry_after 等待后重试，网络错误按指数退避重试
+type sender struct {
+	api            *tgbotapi.BotAPI
+	global         *ratelimit.Limiter
+	chatInterval   time.Duration
+	groupPerMinute int
+	retries        int
+
+	mu        sync.Mutex
+	lanes     map[int64]*chatLane
+	lastSweep time.Time
+	wg        sync.WaitGroup
+
+	pending   atomic.Int64
+	sent      atomic.Int64
+	failed    atomic.Int64
+	retried   atomic.Int64
+	throttled atomic.Int64
+}
+
+// newSender 创建发送队列，速率和群组上限不大于 0 时使用默认值
+// retries 小于 0 时使用默认值，0 表示不重试
+func newSender(api *tgbotapi.BotAPI, rate, chatRate float64, groupPerMinute, retries int) *sender {
+	if rate <= 0 {
+		rate = defaultSendRate
+	}
+	if chatRate <= 0 {
+		chatRate = defaultChatSendRate
+	}
+	if groupPerMinute <= 0 {
+		groupPerMinute = defaultGroupSendPerMinute
+	}
+	if retries < 0 {
+		retries = defaultSendRetries
+	}
+
+	return &sender{
+		api:            api,
+		global:         ratelimit.New(rate, int(rate)),
+		chatInterval:   time.Duration(float64(time.Second) / chatRate),
+		groupPerMinute: groupPerMinute,
+		retries:        retries,
+		lanes:          make(map[int64]*chatLane),
+		lastSweep:      time.Now(),
+	}
+}
+
+// enqueue 提交消息，立即返回
+// 返回的通道在消息发送成功或最终失败后收到一次结果；ctx 结束时未发送的消息被放弃
+func (s *sender) enqueue(ctx context.Context, chatID int64, msg tgbotapi.Chattable) <-chan SendResult {
+	result := make(chan SendResult, 1)
+	s.submit(&sendRequest{ctx: ctx, chatID: chatID, msg: msg, result: result})
+	return result
+}
+
+// send 提交消息并等待发送结果
+func (s *sender) send(ctx context.Context, chatID int64, msg tgbotapi.Chattable) (tgbotapi.Message, error) {
+	select {
+	case r := <-s.enqueue(ctx, chatID, msg):
+		return r.Message, r.Err
+	case <-ctx.Done():
+		return tgbotapi.Message{}, ctx.Err()
+	}
+}
+
+// post 提交消息且不关心结果，失败时只记录日志
+func (s *sender) post(ctx context.Context, chatID int64, msg tgbotapi.Chattable) {
+	s.submit(&sendRequest{ctx: ctx, chatID: chatID, msg: msg})
+}
+
+// submit 将消息放入所属聊天的通道，通道空闲时启动发送协程
+func (s *sender) submit(req *sendRequest) {
+	s.pending.Add(1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	lane, ok := s.lanes[req.chatID]
+	if !ok {
+		lane = &chatLane{}
+		s.lanes[req.chatID] = lane
+	}
+	lane.pending = append(lane.pending, req)
+
+	if !lane.running {
+		lane.running = true
+		s.wg.Add(1)
+		go s.drain(req.chatID, lane)
+	}
+}
+
+// wait 等待所有已提交的消息处理完成
+func (s *sender) wait() {
+	s.wg.Wait()
+}
+
+// stats 返回当前运行指标
+func (s *sender) stats() SenderStats {
+	return SenderStats{
+		Pending:   s.pending.Load(),
+		Sent:      s.sent.Load(),
+		Failed:    s.failed.Load(),
+		Retried:   s.retried.Load(),
+		Throttled: s.throttled.Load(),
+	}
+}
+
+// drain 按顺序发送一个聊天通道中的消息，通道清空后退出
+func (s *sender) drain(chatID int64, lane *chatLane) {
+	defer s.wg.Done()
+
+	for {
+		s.mu.Lock()
+		if len(lane.pending) == 0 {
+			lane.running = false
+			lane.idleAt = time.Now()
+			s.mu.Unlock()
+			return
+		}
+		req := lane.pending[0]
+		lane.pending[0] = nil
+		lane.pending = lane.pending[1:]
+		s.mu.Unlock()
+
+		msg, err := s.deliver(req, lane)
+		s.pending.Add(-1)
+		if err != nil {
+			s.failed.Add(1)
+		} else {
+			s.sent.Add(1)
+		}
+
+		if req.result != nil {
+			req.result <- SendResult{Message: msg, Err: err}
+		} else if err != nil {
+			logger.Errorf("failed to send message to chat %d: %v", chatID, err)
+		}
+	}
+}
+
+// deliver 等待限速后发送消息，必要时重试
+func (s *sender) deliver(req *sendRequest, lane *chatLane) (tgbotapi.Message, error) {
+	backoff := sendRetryBackoff
+
+	for attempt := 0; ; attempt++ {
+		if err := s.waitTurn(req.ctx, req.chatID, lane); err != nil {
+			return tgbotapi.Message{}, err
+		}
+
+		msg, err := s.api.Send(req.msg)
+		if err == nil {
+			return msg, nil
+		}
+		if attempt >= s.retries {
+			return tgbotapi.Message{}, err
+		}
+
+		var delay time.Duration
+		var apiErr *tgbotapi.Error
+		switch {
+		case errors.As(err, &apiErr) && apiErr.RetryAfter > 0:
+			s.throttled.Add(1)
+			delay = time.Duration(apiErr.RetryAfter) * time.Second
+			logger.Warnf("telegram rate limit hit for chat %d, retrying after %s", req.chatID, delay)
+		case errors.As(err, &apiErr):
+			// 其他 API 错误(如 400、403)重试也不会成功
+			return tgbotapi.Message{}, err
+		default:
+			delay = backoff
+			backoff *= 2
+		}
+
+		s.retried.Add(1)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.ctx.Done():
+			timer.Stop()
+			return tgbotapi.Message{}, req.ctx.Err()
+		}
+	}
+}
+
+// waitTurn 等待聊天间隔、群组每分钟上限与全局令牌
+func (s *sender) waitTurn(ctx context.Context, chatID int64, lane *chatLane) error {
+	now := time.Now()
+	next := lane.lastSent.Add(s.chatInterval)
+
+	// 群组聊天 ID 为负数
+	if chatID < 0 {
+		cutoff := now.Add(-time.Minute)
+		for len(lane.recent) > 0 && lane.recent[0].Before(cutoff) {
+			lane.recent = lane.recent[1:]
+		}
+		if len(lane.recent) >= s.groupPerMinute {
+			if t := lane.recent[0].Add(time.Minute); t.After(next) {
+				next = t
+			}
+		}
+	}
+
+	if delay := time.Until(next); delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+
+	if err := s.global.Wait(ctx); err != nil {
+		return err
+	}
+
+	lane.lastSent = time.Now()
+	if chatID < 0 {
+		lane.recent = append(lane.recent, lane.lastSent)
+	}
+	return nil
+}
+
+// sweep 回收长时间空闲的聊天通道，调用方需持有锁
+func (s *sender) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < laneIdleTimeout {
+		return
+	}
+	s.lastSweep = now
+
+	for chatID, lane := range s.lanes {
+		if !lane.running && now.Sub(lane.idleAt) > laneIdleTimeout {
+			delete(s.lanes, chatID)
+		}
+	}
+}
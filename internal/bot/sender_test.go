@@ -0,0 +1,233 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init("error", "stderr")
+	os.Exit(m.Run())
+}
+
+// fakeTelegram 模拟 Bot API，sendMessage 依次返回预设的响应，用完后返回成功
+type fakeTelegram struct {
+	mu        sync.Mutex
+	responses []string
+	calls     []time.Time
+}
+
+func (f *fakeTelegram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/getMe") {
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"test","username":"test_bot"}}`))
+		return
+	}
+
+	f.mu.Lock()
+	f.calls = append(f.calls, time.Now())
+	resp := `{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"}}}`
+	if len(f.responses) > 0 {
+		resp = f.responses[0]
+		f.responses = f.responses[1:]
+	}
+	f.mu.Unlock()
+
+	var parsed struct {
+		ErrorCode int `json:"error_code"`
+	}
+	_ = json.Unmarshal([]byte(resp), &parsed)
+	if parsed.ErrorCode != 0 {
+		w.WriteHeader(parsed.ErrorCode)
+	}
+	_, _ = w.Write([]byte(resp))
+}
+
+func (f *fakeTelegram) callTimes() []time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]time.Time(nil), f.calls...)
+}
+
+func newTestSender(t *testing.T, fake *fakeTelegram, chatRate float64, groupPerMinute, retries int) *sender {
+	t.Helper()
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	api, err := tgbotapi.NewBotAPIWithAPIEndpoint("token", srv.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatalf("create bot api: %v", err)
+	}
+	return newSender(api, 1000, chatRate, groupPerMinute, retries)
+}
+
+const (
+	tooManyRequests = `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 1","parameters":{"retry_after":1}}`
+	badRequest      = `{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`
+)
+
+func TestSenderRetry(t *testing.T) {
+	tests := []struct {
+		name          string
+		responses     []string
+		retries       int
+		wantErr       bool
+		wantCalls     int
+		wantThrottled int64
+		wantMinWait   time.Duration // 第一次与最后一次请求之间的最短间隔
+	}{
+		{
+			name:      "success",
+			retries:   3,
+			wantCalls: 1,
+		},
+		{
+			name:          "waits for retry_after",
+			responses:     []string{tooManyRequests},
+			retries:       3,
+			wantCalls:     2,
+			wantThrottled: 1,
+			wantMinWait:   time.Second,
+		},
+		{
+			name:          "gives up after retries",
+			responses:     []string{tooManyRequests, tooManyRequests},
+			retries:       1,
+			wantErr:       true,
+			wantCalls:     2,
+			wantThrottled: 1,
+			wantMinWait:   time.Second,
+		},
+		{
+			name:      "zero retries",
+			responses: []string{tooManyRequests},
+			retries:   0,
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		{
+			name:      "api error not retried",
+			responses: []string{badRequest},
+			retries:   3,
+			wantErr:   true,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTelegram{responses: tt.responses}
+			s := newTestSender(t, fake, 100, 100, tt.retries)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_, err := s.send(ctx, 42, tgbotapi.NewMessage(42, "hi"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			calls := fake.callTimes()
+			if len(calls) != tt.wantCalls {
+				t.Fatalf("requests = %d, want %d", len(calls), tt.wantCalls)
+			}
+			if got := calls[len(calls)-1].Sub(calls[0]); got < tt.wantMinWait {
+				t.Errorf("retried after %v, want at least %v", got, tt.wantMinWait)
+			}
+			if got := s.stats().Throttled; got != tt.wantThrottled {
+				t.Errorf("throttled = %d, want %d", got, tt.wantThrottled)
+			}
+		})
+	}
+}
+
+func TestSenderChatSpacing(t *testing.T) {
+	tests := []struct {
+		name    string
+		chatIDs []int64
+		minGap  time.Duration // 相邻两次请求的最小间隔
+		maxSpan time.Duration // 全部请求的最大跨度
+	}{
+		{name: "same chat spaced", chatIDs: []int64{1, 1, 1}, minGap: 90 * time.Millisecond},
+		{name: "different chats not spaced", chatIDs: []int64{1, 2, 3}, maxSpan: 80 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTelegram{}
+			s := newTestSender(t, fake, 10, 100, 0)
+
+			ctx := context.Background()
+			var results []<-chan SendResult
+			for _, id := range tt.chatIDs {
+				results = append(results, s.enqueue(ctx, id, tgbotapi.NewMessage(id, "hi")))
+			}
+			for _, r := range results {
+				if res := <-r; res.Err != nil {
+					t.Fatalf("send error = %v", res.Err)
+				}
+			}
+
+			calls := fake.callTimes()
+			if tt.minGap > 0 {
+				for i := 1; i < len(calls); i++ {
+					if gap := calls[i].Sub(calls[i-1]); gap < tt.minGap {
+						t.Errorf("gap between request %d and %d = %v, want at least %v", i-1, i, gap, tt.minGap)
+					}
+				}
+			}
+			if tt.maxSpan > 0 {
+				if span := calls[len(calls)-1].Sub(calls[0]); span > tt.maxSpan {
+					t.Errorf("requests spread over %v, want at most %v", span, tt.maxSpan)
+				}
+			}
+		})
+	}
+}
+
+func TestSenderGroupPerMinute(t *testing.T) {
+	tests := []struct {
+		name     string
+		chatID   int64
+		recent   int           // 最近一分钟内已发送的消息数
+		wantWait time.Duration // 期望的最短等待
+	}{
+		{name: "group under limit", chatID: -100, recent: 1},
+		{name: "group at limit", chatID: -100, recent: 2, wantWait: 150 * time.Millisecond},
+		{name: "private chat ignores group limit", chatID: 100, recent: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSender(t, &fakeTelegram{}, 1000, 2, 0)
+
+			// 最早一条消息即将移出一分钟窗口
+			oldest := time.Now().Add(-time.Minute + 200*time.Millisecond)
+			lane := &chatLane{}
+			for i := 0; i < tt.recent; i++ {
+				lane.recent = append(lane.recent, oldest)
+			}
+
+			start := time.Now()
+			if err := s.waitTurn(context.Background(), tt.chatID, lane); err != nil {
+				t.Fatalf("waitTurn() error = %v", err)
+			}
+			waited := time.Since(start)
+			if waited < tt.wantWait {
+				t.Errorf("waited %v, want at least %v", waited, tt.wantWait)
+			}
+			if tt.wantWait == 0 && waited > 100*time.Millisecond {
+				t.Errorf("waited %v, want no wait", waited)
+			}
+		})
+	}
+}
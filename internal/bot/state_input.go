@@ -4,250 +4,60 @@ package bot
 import (
 	"context"
 	"errors"
-	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"emby-telegram/internal/account"
+	"emby-telegram/internal/conversation"
 	"emby-telegram/internal/invitecode"
+	"emby-telegram/internal/policy"
 	"emby-telegram/internal/user"
-	"emby-telegram/pkg/timeutil"
 )
 
 // handleStateInput 处理用户在状态机中的输入
-func (b *Bot) handleStateInput(ctx context.Context, msg *tgbotapi.Message, currentUser *user.User, state UserState, stateData map[string]interface{}) {
+func (b *Bot) handleStateInput(ctx context.Context, msg *tgbotapi.Message, currentUser *user.User, state UserState, payload conversation.Payload) {
 	// 检查是否取消
 	if msg.Text == "/cancel" {
-		b.stateMachine.ClearState(currentUser.TelegramID)
-		b.reply(msg.Chat.ID, "操作已取消")
+		b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+		b.reply(msg.Chat.ID, b.t(ctx, "input.cancelled"))
+		return
+	}
+
+	if w, ok := b.wizardFor(state); ok {
+		b.handleWizardInput(ctx, msg, currentUser, w, payload)
 		return
 	}
 
 	switch state {
-	case StateWaitingUsername:
-		b.handleUsernameInput(ctx, msg, currentUser)
-	case StateWaitingPassword:
-		b.handlePasswordInput(ctx, msg, currentUser, stateData)
 	case StateWaitingDays:
-		b.handleDaysInput(ctx, msg, currentUser, stateData)
+		b.handleDaysInput(ctx, msg, currentUser, payload)
 	case StateWaitingInviteCode:
 		b.handleInviteCodeInput(ctx, msg, currentUser)
+	case StateWaitingTemplateName:
+		b.handleTemplateNameInput(ctx, msg, currentUser, payload)
+	case StateWaitingListSearch:
+		b.handleListSearchInput(ctx, msg, currentUser, payload)
+	case StateWaitingImportFile, StateWaitingImportConfirm:
+		b.handleImportFileInput(ctx, msg, currentUser, payload)
 	default:
-		b.stateMachine.ClearState(currentUser.TelegramID)
-		b.reply(msg.Chat.ID, "会话已过期，请重新开始")
-	}
-}
-
-// handleUsernameInput 处理用户名输入
-func (b *Bot) handleUsernameInput(ctx context.Context, msg *tgbotapi.Message, currentUser *user.User) {
-	username := strings.TrimSpace(msg.Text)
-
-	// 验证用户名格式
-	if !isValidUsername(username) {
-		text := `❌ 用户名格式不正确
-
-<b>用户名要求：</b>
-• 只能包含字母、数字和下划线
-• 长度 3-20 个字符
-
-请重新输入，或点击下方按钮取消：`
-
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("❌ 取消", CallbackCancel),
-			),
-		)
-
-		replyMsg := tgbotapi.NewMessage(msg.Chat.ID, text)
-		replyMsg.ParseMode = "HTML"
-		replyMsg.ReplyMarkup = keyboard
-
-		if _, err := b.api.Send(replyMsg); err != nil {
-			b.reply(msg.Chat.ID, text)
-		}
-		return
-	}
-
-	// 检查用户名是否已存在
-	if _, err := b.accountService.GetByUsername(ctx, username); err == nil {
-		text := fmt.Sprintf("❌ 用户名 <code>%s</code> 已存在，请使用其他用户名：", username)
-
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("❌ 取消", CallbackCancel),
-			),
-		)
-
-		replyMsg := tgbotapi.NewMessage(msg.Chat.ID, text)
-		replyMsg.ParseMode = "HTML"
-		replyMsg.ReplyMarkup = keyboard
-
-		if _, err := b.api.Send(replyMsg); err != nil {
-			b.reply(msg.Chat.ID, text)
-		}
-		return
-	}
-
-	// 创建账号
-	acc, plainPassword, err := b.accountService.Create(ctx, username, currentUser.ID)
-	if err != nil {
-		var errMsg string
-		if errors.Is(err, account.ErrNotAuthorized) {
-			errMsg = "❌ 您尚未获得创建账号的授权\n\n请在管理群组联系管理员申请"
-		} else if errors.Is(err, account.ErrAccountLimitExceeded) {
-			errMsg = fmt.Sprintf("❌ %v\n\n如需更多配额，请联系管理员", err)
-		} else {
-			errMsg = fmt.Sprintf("❌ 创建账号失败: %v", err)
-		}
-		b.reply(msg.Chat.ID, errMsg)
-		b.stateMachine.ClearState(currentUser.TelegramID)
-		return
-	}
-
-	// 清除状态
-	b.stateMachine.ClearState(currentUser.TelegramID)
-
-	expireInfo := timeutil.FormatExpireTime(acc.ExpireAt)
-
-	text := fmt.Sprintf(`✅ <b>账号创建成功！</b>
-
-<b>用户名:</b> <code>%s</code>
-<b>密码:</b> <code>%s</code>
-<b>有效期:</b> %s
-<b>最大设备数:</b> %d
-
-⚠️ <b>重要提示:</b>
-• 请立即保存密码，此密码只显示一次
-• 可通过账号详情页面修改密码`,
-		acc.Username,
-		plainPassword,
-		expireInfo,
-		acc.MaxDevices,
-	)
-
-	// 添加操作按钮
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📝 查看详情", CallbackAccountInfo+":"+uintToStr(acc.ID)),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📋 我的账号", CallbackMyAccounts+":1"),
-			tgbotapi.NewInlineKeyboardButtonData("⬅️ 主菜单", CallbackMainMenu),
-		),
-	)
-
-	replyMsg := tgbotapi.NewMessage(msg.Chat.ID, text)
-	replyMsg.ParseMode = "HTML"
-	replyMsg.ReplyMarkup = keyboard
-
-	if _, err := b.api.Send(replyMsg); err != nil {
-		b.reply(msg.Chat.ID, text)
-	}
-}
-
-// handlePasswordInput 处理密码输入
-func (b *Bot) handlePasswordInput(ctx context.Context, msg *tgbotapi.Message, currentUser *user.User, stateData map[string]interface{}) {
-	password := strings.TrimSpace(msg.Text)
-
-	// 验证密码格式
-	if len(password) < 6 {
-		text := `❌ 密码长度至少 6 个字符
-
-请重新输入，或点击下方按钮取消：`
-
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("❌ 取消", CallbackCancel),
-			),
-		)
-
-		replyMsg := tgbotapi.NewMessage(msg.Chat.ID, text)
-		replyMsg.ParseMode = "HTML"
-		replyMsg.ReplyMarkup = keyboard
-
-		if _, err := b.api.Send(replyMsg); err != nil {
-			b.reply(msg.Chat.ID, text)
-		}
-		return
-	}
-
-	// 获取账号 ID
-	accountID, ok := stateData["account_id"].(uint)
-	if !ok {
-		b.stateMachine.ClearState(currentUser.TelegramID)
-		b.reply(msg.Chat.ID, "❌ 会话已过期，请重新开始")
-		return
-	}
-
-	// 获取账号信息
-	acc, err := b.accountService.Get(ctx, accountID)
-	if err != nil {
-		b.stateMachine.ClearState(currentUser.TelegramID)
-		b.reply(msg.Chat.ID, fmt.Sprintf("❌ 获取账号信息失败: %v", err))
-		return
-	}
-
-	// 检查所有权
-	if err := b.accountService.CheckOwnership(ctx, acc.ID, currentUser.ID); err != nil {
-		b.stateMachine.ClearState(currentUser.TelegramID)
-		b.reply(msg.Chat.ID, "❌ 您没有权限操作此账号")
-		return
-	}
-
-	// 修改密码
-	if err := b.accountService.ChangePassword(ctx, acc.ID, password); err != nil {
-		b.stateMachine.ClearState(currentUser.TelegramID)
-		b.reply(msg.Chat.ID, fmt.Sprintf("❌ 修改密码失败: %v", err))
-		return
-	}
-
-	// 清除状态
-	b.stateMachine.ClearState(currentUser.TelegramID)
-
-	text := fmt.Sprintf(`✅ <b>密码修改成功！</b>
-
-账号 <b>%s</b> 的密码已更新
-新密码: <code>%s</code>
-
-⚠️ 请妥善保管新密码`,
-		acc.Username,
-		password,
-	)
-
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📝 返回账号详情", CallbackAccountInfo+":"+uintToStr(acc.ID)),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📋 我的账号", CallbackMyAccounts+":1"),
-		),
-	)
-
-	replyMsg := tgbotapi.NewMessage(msg.Chat.ID, text)
-	replyMsg.ParseMode = "HTML"
-	replyMsg.ReplyMarkup = keyboard
-
-	if _, err := b.api.Send(replyMsg); err != nil {
-		b.reply(msg.Chat.ID, text)
+		b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+		b.reply(msg.Chat.ID, b.t(ctx, "common.session_expired"))
 	}
 }
 
 // handleDaysInput 处理天数输入
-func (b *Bot) handleDaysInput(ctx context.Context, msg *tgbotapi.Message, currentUser *user.User, stateData map[string]interface{}) {
+func (b *Bot) handleDaysInput(ctx context.Context, msg *tgbotapi.Message, currentUser *user.User, payload conversation.Payload) {
 	daysStr := strings.TrimSpace(msg.Text)
 
 	days, err := strconv.Atoi(daysStr)
 	if err != nil || days <= 0 || days > 3650 {
-		text := `❌ 请输入有效的天数（1-3650）
-
-请重新输入，或点击下方按钮取消：`
+		text := b.t(ctx, "input.invalid_days")
 
 		keyboard := tgbotapi.NewInlineKeyboardMarkup(
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("❌ 取消", CallbackCancel),
+				tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.cancel"), CallbackCancel),
 			),
 		)
 
@@ -255,53 +65,54 @@ func (b *Bot) handleDaysInput(ctx context.Context, msg *tgbotapi.Message, curren
 		replyMsg.ParseMode = "HTML"
 		replyMsg.ReplyMarkup = keyboard
 
-		if _, err := b.api.Send(replyMsg); err != nil {
+		if _, err := b.send(ctx, msg.Chat.ID, replyMsg); err != nil {
 			b.reply(msg.Chat.ID, text)
 		}
 		return
 	}
 
 	// 获取账号 ID
-	accountID, ok := stateData["account_id"].(uint)
-	if !ok {
-		b.stateMachine.ClearState(currentUser.TelegramID)
-		b.reply(msg.Chat.ID, "❌ 会话已过期，请重新开始")
+	accountID := payload.AccountID
+	if accountID == 0 {
+		b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+		b.reply(msg.Chat.ID, b.t(ctx, "input.session_expired"))
 		return
 	}
 
 	// 获取账号信息
 	acc, err := b.accountService.Get(ctx, accountID)
 	if err != nil {
-		b.stateMachine.ClearState(currentUser.TelegramID)
-		b.reply(msg.Chat.ID, fmt.Sprintf("❌ 获取账号信息失败: %v", err))
+		b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+		b.reply(msg.Chat.ID, b.t(ctx, "common.get_account_failed_reason", b.errorText(ctx, err)))
 		return
 	}
 
 	// 检查所有权
 	if err := b.accountService.CheckOwnership(ctx, acc.ID, currentUser.ID); err != nil {
-		b.stateMachine.ClearState(currentUser.TelegramID)
-		b.reply(msg.Chat.ID, "❌ 您没有权限操作此账号")
+		b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+		b.reply(msg.Chat.ID, b.t(ctx, "common.account_forbidden"))
 		return
 	}
 
 	// 续期
 	if err := b.accountService.Renew(ctx, acc.ID, days); err != nil {
-		b.stateMachine.ClearState(currentUser.TelegramID)
-		b.reply(msg.Chat.ID, fmt.Sprintf("❌ 续期失败: %v", err))
+		b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+		if errors.Is(err, account.ErrMaintenance) {
+			b.reply(msg.Chat.ID, b.maintenanceText(ctx))
+			return
+		}
+		b.reply(msg.Chat.ID, b.t(ctx, "input.renew_failed", b.errorText(ctx, err)))
 		return
 	}
 
 	// 清除状态
-	b.stateMachine.ClearState(currentUser.TelegramID)
+	b.stateMachine.ClearState(ctx, currentUser.TelegramID)
 
 	// 重新获取更新后的账号信息
 	acc, _ = b.accountService.Get(ctx, acc.ID)
-	expireInfo := timeutil.FormatExpireTime(acc.ExpireAt)
+	expireInfo := b.expireText(ctx, acc.ExpireAt)
 
-	text := fmt.Sprintf(`✅ <b>续期成功！</b>
-
-账号 <b>%s</b> 已续期 %d 天
-新的到期时间: %s`,
+	text := b.t(ctx, "renew.success",
 		acc.Username,
 		days,
 		expireInfo,
@@ -309,10 +120,10 @@ func (b *Bot) handleDaysInput(ctx context.Context, msg *tgbotapi.Message, curren
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📝 返回账号详情", CallbackAccountInfo+":"+uintToStr(acc.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "input.back_to_details"), CallbackAccountInfo+":"+uintToStr(acc.ID)),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📋 我的账号", CallbackMyAccounts+":1"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "keyboard.my_accounts"), CallbackMyAccounts+":1"),
 		),
 	)
 
@@ -320,7 +131,7 @@ func (b *Bot) handleDaysInput(ctx context.Context, msg *tgbotapi.Message, curren
 	replyMsg.ParseMode = "HTML"
 	replyMsg.ReplyMarkup = keyboard
 
-	if _, err := b.api.Send(replyMsg); err != nil {
+	if _, err := b.send(ctx, msg.Chat.ID, replyMsg); err != nil {
 		b.reply(msg.Chat.ID, text)
 	}
 }
@@ -334,26 +145,26 @@ func (b *Bot) handleInviteCodeInput(ctx context.Context, msg *tgbotapi.Message,
 		var needsRetry bool
 
 		if errors.Is(err, invitecode.ErrNotFound) {
-			errMsg = fmt.Sprintf("❌ 邀请码 <code>%s</code> 不存在或无效\n\n请检查后重新输入，或点击下方按钮取消", code)
+			errMsg = b.t(ctx, "invite.not_found", code)
 			needsRetry = true
 		} else if errors.Is(err, invitecode.ErrAlreadyUsed) {
-			errMsg = "❌ 您已使用过邀请码，无法重复激活"
-			b.stateMachine.ClearState(currentUser.TelegramID)
+			errMsg = b.t(ctx, "invite.already_used")
+			b.stateMachine.ClearState(ctx, currentUser.TelegramID)
 		} else if errors.Is(err, invitecode.ErrHasQuota) {
-			errMsg = "❌ 您已有账号配额，无需使用邀请码"
-			b.stateMachine.ClearState(currentUser.TelegramID)
+			errMsg = b.t(ctx, "invite.has_quota")
+			b.stateMachine.ClearState(ctx, currentUser.TelegramID)
 		} else if errors.Is(err, invitecode.ErrCodeExpired) {
-			errMsg = fmt.Sprintf("❌ 邀请码 <code>%s</code> 已过期\n\n请联系管理员获取新的邀请码", code)
-			b.stateMachine.ClearState(currentUser.TelegramID)
+			errMsg = b.t(ctx, "invite.expired", code)
+			b.stateMachine.ClearState(ctx, currentUser.TelegramID)
 		} else if errors.Is(err, invitecode.ErrCodeExhausted) {
-			errMsg = fmt.Sprintf("❌ 邀请码 <code>%s</code> 使用次数已达上限\n\n请联系管理员获取新的邀请码", code)
-			b.stateMachine.ClearState(currentUser.TelegramID)
+			errMsg = b.t(ctx, "invite.exhausted", code)
+			b.stateMachine.ClearState(ctx, currentUser.TelegramID)
 		} else if errors.Is(err, invitecode.ErrCodeRevoked) {
-			errMsg = fmt.Sprintf("❌ 邀请码 <code>%s</code> 已被撤销\n\n请联系管理员获取新的邀请码", code)
-			b.stateMachine.ClearState(currentUser.TelegramID)
+			errMsg = b.t(ctx, "invite.revoked", code)
+			b.stateMachine.ClearState(ctx, currentUser.TelegramID)
 		} else {
-			errMsg = fmt.Sprintf("❌ 激活失败: %v", err)
-			b.stateMachine.ClearState(currentUser.TelegramID)
+			errMsg = b.t(ctx, "invite.failed", b.errorText(ctx, err))
+			b.stateMachine.ClearState(ctx, currentUser.TelegramID)
 		}
 
 		replyMsg := tgbotapi.NewMessage(msg.Chat.ID, errMsg)
@@ -362,34 +173,29 @@ func (b *Bot) handleInviteCodeInput(ctx context.Context, msg *tgbotapi.Message,
 		if needsRetry {
 			keyboard := tgbotapi.NewInlineKeyboardMarkup(
 				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData("❌ 取消", CallbackCancel),
+					tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.cancel"), CallbackCancel),
 				),
 			)
 			replyMsg.ReplyMarkup = keyboard
 		}
 
-		if _, err := b.api.Send(replyMsg); err != nil {
+		if _, err := b.send(ctx, msg.Chat.ID, replyMsg); err != nil {
 			b.reply(msg.Chat.ID, errMsg)
 		}
 		return
 	}
 
-	b.stateMachine.ClearState(currentUser.TelegramID)
-
-	text := fmt.Sprintf(`🎉 <b>激活成功！</b>
-
-邀请码 <code>%s</code> 已激活
-您获得了 <b>1 个账号配额</b>
+	b.stateMachine.ClearState(ctx, currentUser.TelegramID)
 
-现在可以使用 /create 创建账号了！`, code)
+	text := b.t(ctx, "invite.activated", code)
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("➕ 创建账号", CallbackCreateAccount),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "keyboard.create"), CallbackCreateAccount),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📋 我的账号", CallbackMyAccounts+":1"),
-			tgbotapi.NewInlineKeyboardButtonData("⬅️ 主菜单", CallbackMainMenu),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "keyboard.my_accounts"), CallbackMyAccounts+":1"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.main_menu"), CallbackMainMenu),
 		),
 	)
 
@@ -397,16 +203,65 @@ func (b *Bot) handleInviteCodeInput(ctx context.Context, msg *tgbotapi.Message,
 	replyMsg.ParseMode = "HTML"
 	replyMsg.ReplyMarkup = keyboard
 
-	if _, err := b.api.Send(replyMsg); err != nil {
+	if _, err := b.send(ctx, msg.Chat.ID, replyMsg); err != nil {
 		b.reply(msg.Chat.ID, text)
 	}
 }
 
-// isValidUsername 验证用户名格式
-func isValidUsername(username string) bool {
-	if len(username) < 3 || len(username) > 20 {
-		return false
+// handleTemplateNameInput 处理复制策略模板时的新名称输入
+func (b *Bot) handleTemplateNameInput(ctx context.Context, msg *tgbotapi.Message, currentUser *user.User, payload conversation.Payload) {
+	if !b.userService.Can(ctx, currentUser, user.PermAccountPolicy) {
+		b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+		b.reply(msg.Chat.ID, b.t(ctx, "input.admin_required"))
+		return
+	}
+
+	templateID := payload.TemplateID
+	if templateID == 0 {
+		b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+		b.reply(msg.Chat.ID, b.t(ctx, "common.session_expired"))
+		return
+	}
+
+	name := strings.TrimSpace(msg.Text)
+
+	tpl, err := b.policyService.Clone(ctx, templateID, name)
+	if err != nil {
+		var text string
+		if errors.Is(err, policy.ErrInvalidInput) || errors.Is(err, policy.ErrAlreadyExists) {
+			text = b.t(ctx, "input.clone_invalid", b.errorText(ctx, err))
+		} else {
+			b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+			b.reply(msg.Chat.ID, b.t(ctx, "input.clone_failed", b.errorText(ctx, err)))
+			return
+		}
+
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.cancel"), CallbackCancel),
+			),
+		)
+
+		replyMsg := tgbotapi.NewMessage(msg.Chat.ID, text)
+		replyMsg.ParseMode = "HTML"
+		replyMsg.ReplyMarkup = keyboard
+
+		if _, err := b.send(ctx, msg.Chat.ID, replyMsg); err != nil {
+			b.reply(msg.Chat.ID, text)
+		}
+		return
+	}
+
+	b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+
+	text := formatPolicyTemplate(b.loc(ctx), tpl)
+	keyboard := PolicyTemplateDetailKeyboard(b.loc(ctx), tpl)
+
+	replyMsg := tgbotapi.NewMessage(msg.Chat.ID, text)
+	replyMsg.ParseMode = "HTML"
+	replyMsg.ReplyMarkup = keyboard
+
+	if _, err := b.send(ctx, msg.Chat.ID, replyMsg); err != nil {
+		b.reply(msg.Chat.ID, text)
 	}
-	match, _ := regexp.MatchString(`^[a-zA-Z0-9_]+$`, username)
-	return match
 }
@@ -2,39 +2,55 @@
 package bot
 
 import (
+	"context"
+	"errors"
 	"sync"
 	"time"
+
+	"emby-telegram/internal/conversation"
+	"emby-telegram/internal/logger"
 )
 
 // UserState 用户状态类型
 type UserState string
 
 const (
-	StateIdle              UserState = "idle"               // 空闲状态
-	StateWaitingUsername   UserState = "waiting_username"   // 等待输入用户名
-	StateWaitingPassword   UserState = "waiting_password"   // 等待输入密码
-	StateWaitingDays       UserState = "waiting_days"       // 等待输入天数
-	StateWaitingInviteCode UserState = "waiting_invite_code" // 等待输入邀请码
+	StateIdle                 UserState = "idle"                   // 空闲状态
+	StateWaitingDays          UserState = "waiting_days"           // 等待输入天数
+	StateWaitingInviteCode    UserState = "waiting_invite_code"    // 等待输入邀请码
+	StateWaitingTemplateName  UserState = "waiting_template_name"  // 等待输入策略模板名称
+	StateWaitingListSearch    UserState = "waiting_list_search"    // 等待输入管理列表搜索关键字
+	StateWaitingImportFile    UserState = "waiting_import_file"    // 等待上传导入文件
+	StateWaitingImportConfirm UserState = "waiting_import_confirm" // 等待确认导入
 )
 
-// StateData 状态数据
-type StateData struct {
-	State     UserState              // 当前状态
-	Data      map[string]interface{} // 附加数据
-	UpdatedAt time.Time              // 更新时间
-}
+// defaultStateTTL 未配置时的状态有效期
+const defaultStateTTL = 10 * time.Minute
+
+// stateCleanupInterval 清理过期状态的间隔
+const stateCleanupInterval = 5 * time.Minute
 
 // StateMachine 状态机
+// 状态保存在 StateStore 中，使用数据库存储时可在重启后恢复，并在多个实例间共享
 type StateMachine struct {
-	mu     sync.RWMutex
-	states map[int64]*StateData // userID -> StateData
-	stopCh chan struct{}        // 停止信号
+	store    conversation.StateStore
+	ttl      time.Duration               // 默认状态有效期
+	ttls     map[UserState]time.Duration // 按状态覆盖的有效期
+	stopCh   chan struct{}               // 停止信号
+	stopOnce sync.Once
 }
 
 // NewStateMachine 创建状态机
-func NewStateMachine() *StateMachine {
+// ttl 为默认状态有效期，ttls 按状态覆盖，未覆盖的状态使用默认值
+func NewStateMachine(store conversation.StateStore, ttl time.Duration, ttls map[UserState]time.Duration) *StateMachine {
+	if ttl <= 0 {
+		ttl = defaultStateTTL
+	}
+
 	sm := &StateMachine{
-		states: make(map[int64]*StateData),
+		store:  store,
+		ttl:    ttl,
+		ttls:   ttls,
 		stopCh: make(chan struct{}),
 	}
 
@@ -46,52 +62,49 @@ func NewStateMachine() *StateMachine {
 
 // Stop 停止状态机
 func (sm *StateMachine) Stop() {
-	close(sm.stopCh)
+	sm.stopOnce.Do(func() { close(sm.stopCh) })
 }
 
-// SetState 设置用户状态
-func (sm *StateMachine) SetState(userID int64, state UserState, data map[string]interface{}) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-
-	if data == nil {
-		data = make(map[string]interface{})
-	}
-
-	sm.states[userID] = &StateData{
-		State:     state,
-		Data:      data,
-		UpdatedAt: time.Now(),
+// TTL 返回指定状态的有效期
+func (sm *StateMachine) TTL(state UserState) time.Duration {
+	if ttl, ok := sm.ttls[state]; ok && ttl > 0 {
+		return ttl
 	}
+	return sm.ttl
 }
 
-// GetState 获取用户状态
-func (sm *StateMachine) GetState(userID int64) (UserState, map[string]interface{}) {
-	sm.mu.RLock()
-	defer sm.mu.RUnlock()
+// SetState 设置用户状态
+func (sm *StateMachine) SetState(ctx context.Context, userID int64, state UserState, payload conversation.Payload) error {
+	return sm.store.Save(ctx, &conversation.State{
+		TelegramID: userID,
+		Name:       string(state),
+		Payload:    payload,
+		ExpiresAt:  time.Now().Add(sm.TTL(state)),
+	})
+}
 
-	if stateData, ok := sm.states[userID]; ok {
-		// 检查是否过期（10分钟）
-		if time.Since(stateData.UpdatedAt) > 10*time.Minute {
-			return StateIdle, nil
+// GetState 获取用户状态，不存在、已过期或读取失败时返回空闲状态
+func (sm *StateMachine) GetState(ctx context.Context, userID int64) (UserState, conversation.Payload) {
+	state, err := sm.store.Get(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, conversation.ErrNotFound) {
+			logger.Errorf("failed to load conversation state for %d: %v", userID, err)
 		}
-		return stateData.State, stateData.Data
+		return StateIdle, conversation.Payload{}
 	}
-
-	return StateIdle, nil
+	return UserState(state.Name), state.Payload
 }
 
 // ClearState 清除用户状态
-func (sm *StateMachine) ClearState(userID int64) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-
-	delete(sm.states, userID)
+func (sm *StateMachine) ClearState(ctx context.Context, userID int64) {
+	if err := sm.store.Delete(ctx, userID); err != nil {
+		logger.Errorf("failed to clear conversation state for %d: %v", userID, err)
+	}
 }
 
 // cleanupExpiredStates 清理过期状态
 func (sm *StateMachine) cleanupExpiredStates() {
-	ticker := time.NewTicker(5 * time.Minute)
+	ticker := time.NewTicker(stateCleanupInterval)
 	defer ticker.Stop()
 
 	for {
@@ -99,14 +112,11 @@ func (sm *StateMachine) cleanupExpiredStates() {
 		case <-sm.stopCh:
 			return // 优雅退出
 		case <-ticker.C:
-			sm.mu.Lock()
-			now := time.Now()
-			for userID, stateData := range sm.states {
-				if now.Sub(stateData.UpdatedAt) > 10*time.Minute {
-					delete(sm.states, userID)
-				}
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			if _, err := sm.store.DeleteExpired(ctx, time.Now()); err != nil {
+				logger.Warnf("failed to clean up expired conversation states: %v", err)
 			}
-			sm.mu.Unlock()
+			cancel()
 		}
 	}
 }
@@ -0,0 +1,276 @@
+// Package bot 账号转让
+package bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/conversation"
+	"emby-telegram/internal/i18n"
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/user"
+	"emby-telegram/pkg/timeutil"
+)
+
+// transferHistoryLimit 管理员账号详情中显示的转让记录数
+const transferHistoryLimit = 3
+
+// transferWizard 账号转让: 输入接收者，确认后转移
+// 管理员直接转移；账号所有者发起的转让需要接收者接受
+func (b *Bot) transferWizard(name string) *Wizard {
+	direct := name == WizardTransferAccount
+
+	return &Wizard{
+		Name: name,
+		Steps: []WizardStep{
+			{
+				Key: "recipient",
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					acc, err := b.accountService.Get(ctx, s.Payload.AccountID)
+					if err != nil {
+						return b.t(ctx, "common.get_account_failed")
+					}
+					return b.t(ctx, "transfer.prompt_recipient", acc.Username)
+				},
+				Validate: func(ctx context.Context, s *WizardSession, value string) error {
+					acc, err := b.accountService.Get(ctx, s.Payload.AccountID)
+					if err != nil {
+						return err
+					}
+					recipient, err := b.resolveUser(ctx, value)
+					if err != nil {
+						return err
+					}
+					if recipient.ID == acc.UserID {
+						return account.SameOwnerError(acc.Username)
+					}
+					return nil
+				},
+			},
+			{
+				Key: "confirm",
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					acc, recipient, err := b.transferTarget(ctx, s)
+					if err != nil {
+						return b.t(ctx, "transfer.failed", b.errorText(ctx, err))
+					}
+					if direct {
+						return b.t(ctx, "transfer.confirm_direct", acc.Username, b.userLabelByID(ctx, acc.UserID), userLabel(recipient))
+					}
+					return b.t(ctx, "transfer.confirm_offer", acc.Username, userLabel(recipient), int(account.TransferOfferTTL.Hours()))
+				},
+				Choices: func(ctx context.Context, s *WizardSession) []WizardChoice {
+					return []WizardChoice{{Label: b.t(ctx, "transfer.confirm_button"), Value: "run"}}
+				},
+				Validate: func(ctx context.Context, s *WizardSession, value string) error {
+					if value != "run" {
+						return newWizardInputError("broadcast.use_buttons")
+					}
+					return nil
+				},
+			},
+		},
+		Commit: func(ctx context.Context, s *WizardSession) WizardResult {
+			acc, recipient, err := b.transferTarget(ctx, s)
+			if err != nil {
+				return WizardResult{Text: b.t(ctx, "transfer.failed", b.errorText(ctx, err))}
+			}
+			if direct {
+				return b.commitTransfer(ctx, s, acc, recipient)
+			}
+			return b.commitOfferTransfer(ctx, s, acc, recipient)
+		},
+		Cancel: func(ctx context.Context, s *WizardSession) CallbackResponse {
+			if direct {
+				return b.showAdminAccountDetail(ctx, s.Payload.AccountID, 1, accountsListSpec.parse(""))
+			}
+			return b.showAccountInfo(ctx, s.User, s.Payload.AccountID)
+		},
+	}
+}
+
+// transferTarget 获取向导中的账号与接收者
+func (b *Bot) transferTarget(ctx context.Context, s *WizardSession) (*account.Account, *user.User, error) {
+	acc, err := b.accountService.Get(ctx, s.Payload.AccountID)
+	if err != nil {
+		return nil, nil, err
+	}
+	recipient, err := b.resolveUser(ctx, s.Value("recipient"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return acc, recipient, nil
+}
+
+// commitTransfer 管理员直接转移账号，并通知原所有者和接收者
+func (b *Bot) commitTransfer(ctx context.Context, s *WizardSession, acc *account.Account, recipient *user.User) WizardResult {
+	t, err := b.accountService.Transfer(ctx, acc.ID, recipient.ID, s.User.TelegramID)
+	if err != nil {
+		return WizardResult{Text: b.t(ctx, "transfer.failed", b.errorText(ctx, err))}
+	}
+
+	b.notifyTransfer(ctx, t.FromUserID, nil, "transfer.removed_notice", acc.Username)
+	b.notifyTransfer(ctx, t.ToUserID, viewAccountMarkup(acc.ID), "transfer.received_notice", acc.Username)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "input.back_to_details"), CallbackAdminAccountDetail+":"+uintToStr(acc.ID)),
+		),
+	)
+	return WizardResult{Text: b.t(ctx, "transfer.done", acc.Username, userLabel(recipient)), Markup: &keyboard}
+}
+
+// commitOfferTransfer 账号所有者发起转让，向接收者发送接受和拒绝按钮
+func (b *Bot) commitOfferTransfer(ctx context.Context, s *WizardSession, acc *account.Account, recipient *user.User) WizardResult {
+	t, err := b.accountService.OfferTransfer(ctx, acc.ID, s.User.ID, recipient.ID, s.User.TelegramID)
+	if err != nil {
+		return WizardResult{Text: b.t(ctx, "transfer.failed", b.errorText(ctx, err))}
+	}
+
+	id := uintToStr(t.ID)
+	hours := int(account.TransferOfferTTL.Hours())
+	b.notifyTransfer(ctx, recipient.ID, func(loc *i18n.Localizer) tgbotapi.InlineKeyboardMarkup {
+		return tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(loc.T("transfer.accept_button"), CallbackTransfer+":accept:"+id),
+				tgbotapi.NewInlineKeyboardButtonData(loc.T("transfer.decline_button"), CallbackTransfer+":decline:"+id),
+			),
+		)
+	}, "transfer.offer_notice", userLabel(s.User), acc.Username, hours)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "transfer.cancel_button"), CallbackTransfer+":cancel:"+id),
+		),
+	)
+	return WizardResult{Text: b.t(ctx, "transfer.offered", acc.Username, userLabel(recipient), hours), Markup: &keyboard}
+}
+
+// startOfferTransfer 账号所有者开始转让流程
+func (b *Bot) startOfferTransfer(ctx context.Context, currentUser *user.User, accountID uint) CallbackResponse {
+	if err := b.accountService.CheckOwnership(ctx, accountID, currentUser.ID); err != nil {
+		return CallbackResponse{Answer: b.t(ctx, "common.account_forbidden"), ShowAlert: true}
+	}
+	return b.startWizard(ctx, currentUser, WizardOfferTransfer, conversation.Payload{AccountID: accountID})
+}
+
+// handleTransferCallback 处理转让按钮: transfer:<accept|decline|cancel>:<转让ID>
+// 接收者可以接受或拒绝，发起者可以取消，身份由账号服务校验
+func (b *Bot) handleTransferCallback(ctx context.Context, query *tgbotapi.CallbackQuery, parts []string, currentUser *user.User) CallbackResponse {
+	if len(parts) < 3 {
+		return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+	}
+	transferID := strToUint(parts[2])
+
+	var t *account.Transfer
+	var err error
+	switch parts[1] {
+	case "accept":
+		t, err = b.accountService.AcceptTransfer(ctx, transferID, currentUser.ID)
+	case "decline", "cancel":
+		t, err = b.accountService.RejectTransfer(ctx, transferID, currentUser.ID)
+	default:
+		return CallbackResponse{Answer: b.t(ctx, "common.unknown_action"), ShowAlert: true}
+	}
+	if err != nil {
+		return CallbackResponse{Answer: b.t(ctx, "transfer.failed", b.errorText(ctx, err)), ShowAlert: true}
+	}
+
+	username := b.transferAccountName(ctx, t.AccountID)
+	switch t.Status {
+	case account.TransferCompleted:
+		b.notifyTransfer(ctx, t.FromUserID, nil, "transfer.accepted_notice", userLabel(currentUser), username)
+		keyboard := viewAccountMarkup(t.AccountID)(b.loc(ctx))
+		return CallbackResponse{
+			Answer:     b.t(ctx, "transfer.accepted_short"),
+			EditText:   b.t(ctx, "transfer.accepted", username),
+			EditMarkup: &keyboard,
+		}
+	case account.TransferDeclined:
+		b.notifyTransfer(ctx, t.FromUserID, nil, "transfer.declined_notice", userLabel(currentUser), username)
+		return CallbackResponse{EditText: b.t(ctx, "transfer.declined", username)}
+	default:
+		b.notifyTransfer(ctx, t.ToUserID, nil, "transfer.cancelled_notice", userLabel(currentUser), username)
+		return CallbackResponse{EditText: b.t(ctx, "transfer.cancelled", username)}
+	}
+}
+
+// transferAccountName 获取转让账号的用户名，账号已删除时显示 ID
+func (b *Bot) transferAccountName(ctx context.Context, accountID uint) string {
+	acc, err := b.accountService.Get(ctx, accountID)
+	if err != nil {
+		return fmt.Sprintf("#%d", accountID)
+	}
+	return acc.Username
+}
+
+// transferHistoryText 管理员账号详情中的最近转让记录，没有记录时为空
+func (b *Bot) transferHistoryText(ctx context.Context, accountID uint) string {
+	transfers, err := b.accountService.ListTransfers(ctx, accountID, transferHistoryLimit)
+	if err != nil {
+		logger.Errorf("failed to list transfers of account %d: %v", accountID, err)
+		return ""
+	}
+	if len(transfers) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString(b.t(ctx, "transfer.history_title"))
+	for _, t := range transfers {
+		builder.WriteString(b.t(ctx, "transfer.history_line",
+			timeutil.FormatDateTime(t.CreatedAt),
+			b.userLabelByID(ctx, t.FromUserID),
+			b.userLabelByID(ctx, t.ToUserID),
+			b.t(ctx, "transfer.status_"+string(t.Status)),
+		))
+	}
+	return builder.String()
+}
+
+// notifyTransfer 以对方的界面语言发送转让通知，markup 为空时不带按钮
+func (b *Bot) notifyTransfer(ctx context.Context, userID uint, markup func(loc *i18n.Localizer) tgbotapi.InlineKeyboardMarkup, key string, args ...any) {
+	u, err := b.userService.Get(ctx, userID)
+	if err != nil {
+		logger.Errorf("failed to get user %d for transfer notice: %v", userID, err)
+		return
+	}
+
+	loc := b.catalog.Localizer(b.userLanguage(u, nil))
+	msg := tgbotapi.NewMessage(u.TelegramID, loc.T(key, args...))
+	msg.ParseMode = "HTML"
+	if markup != nil {
+		msg.ReplyMarkup = markup(loc)
+	}
+	b.sender.post(b.handlerCtx, u.TelegramID, msg)
+}
+
+// viewAccountMarkup 查看账号详情按钮
+func viewAccountMarkup(accountID uint) func(loc *i18n.Localizer) tgbotapi.InlineKeyboardMarkup {
+	return func(loc *i18n.Localizer) tgbotapi.InlineKeyboardMarkup {
+		return tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(loc.T("input.view_details"), CallbackAccountInfo+":"+uintToStr(accountID)),
+			),
+		)
+	}
+}
+
+// userLabel 用户的显示名称和 Telegram ID
+func userLabel(u *user.User) string {
+	return fmt.Sprintf("%s (ID: %d)", html.EscapeString(u.DisplayName()), u.TelegramID)
+}
+
+// userLabelByID 根据用户 ID 获取显示名称，用户不存在时显示 ID
+func (b *Bot) userLabelByID(ctx context.Context, userID uint) string {
+	u, err := b.userService.Get(ctx, userID)
+	if err != nil {
+		return fmt.Sprintf("#%d", userID)
+	}
+	return userLabel(u)
+}
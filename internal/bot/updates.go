@@ -0,0 +1,203 @@
+// Package bot 更新接收(长轮询 / Webhook)
+package bot
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/logger"
+)
+
+// 更新接收方式
+const (
+	ModePolling = "polling" // 长轮询
+	ModeWebhook = "webhook" // Webhook
+)
+
+// secretTokenHeader Telegram 在 Webhook 请求中携带 secret_token 的请求头
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// maxWebhookBodySize Webhook 请求体大小上限，单条更新远小于此值
+const maxWebhookBodySize = 1 << 20
+
+// ReceiveConfig 更新接收与分发配置
+type ReceiveConfig struct {
+	Mode          string // polling 或 webhook
+	PollTimeout   int    // 长轮询超时(秒)
+	WebhookListen string // Webhook 本地监听地址，如 :8080
+	WebhookURL    string // Telegram 回调的公网地址，路径部分作为本地路由
+	WebhookSecret string // secret_token，为空时启动 Webhook 前随机生成
+	Workers       int    // 处理更新的工作协程数
+	QueueSize     int    // 每个工作协程的队列容量
+	RateLimit     int    // 每个用户每分钟允许的命令与按钮操作次数，0 表示不限流
+	RateBurst     int    // 每个用户允许的突发操作次数
+
+	SendRate           float64 // 全局每秒发送消息数
+	ChatSendRate       float64 // 每个聊天每秒发送消息数
+	GroupSendPerMinute int     // 每个群组每分钟发送消息数
+	SendRetries        int     // 发送失败后的最大重试次数
+
+	WebAppShortName string // Mini App 短名称，为空时主菜单不显示 Mini App 入口
+}
+
+// receiveUpdates 按配置启动更新接收，返回的通道由 Start 统一分发
+func (b *Bot) receiveUpdates(ctx context.Context) (tgbotapi.UpdatesChannel, error) {
+	if b.receiveConfig.Mode == ModeWebhook {
+		return b.startWebhook(ctx)
+	}
+	return b.startPolling()
+}
+
+// startPolling 启动长轮询
+// 之前设置过 Webhook 时 Telegram 会拒绝 getUpdates，因此先删除 Webhook
+func (b *Bot) startPolling() (tgbotapi.UpdatesChannel, error) {
+	if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		logger.Warnf("failed to delete webhook before polling: %v", err)
+	}
+
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = b.receiveConfig.PollTimeout
+
+	logger.Infof("receiving updates via long polling (timeout: %ds)", u.Timeout)
+	return b.api.GetUpdatesChan(u), nil
+}
+
+// startWebhook 启动 Webhook HTTP 服务并向 Telegram 注册
+func (b *Bot) startWebhook(ctx context.Context) (tgbotapi.UpdatesChannel, error) {
+	cfg := b.receiveConfig
+
+	publicURL, err := url.Parse(cfg.WebhookURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse webhook url: %w", err)
+	}
+
+	path := publicURL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	// 始终校验 secret_token，未配置时生成随机值，只有 Telegram 通过 setWebhook 得知
+	if cfg.WebhookSecret == "" {
+		secret, err := randomSecretToken()
+		if err != nil {
+			return nil, err
+		}
+		cfg.WebhookSecret = secret
+		b.receiveConfig.WebhookSecret = secret
+		logger.Info("webhook secret_token not configured, using a random one for this run")
+	}
+
+	// 先绑定端口再注册 Webhook，监听失败时不会让 Telegram 向无人监听的地址投递
+	ln, err := net.Listen("tcp", cfg.WebhookListen)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", cfg.WebhookListen, err)
+	}
+
+	updates := make(chan tgbotapi.Update, b.api.Buffer)
+
+	mux := http.NewServeMux()
+	mux.Handle(path, b.webhookHandler(ctx, updates))
+
+	b.webhookServer = &http.Server{
+		Addr:              cfg.WebhookListen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := b.webhookServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("webhook server error: %v", err)
+		}
+	}()
+
+	params := tgbotapi.Params{}
+	params["url"] = cfg.WebhookURL
+	params["secret_token"] = cfg.WebhookSecret
+	if _, err := b.api.MakeRequest("setWebhook", params); err != nil {
+		b.shutdownWebhookServer()
+		return nil, fmt.Errorf("set webhook: %w", err)
+	}
+
+	logger.Infof("receiving updates via webhook: %s (listen: %s)", cfg.WebhookURL, cfg.WebhookListen)
+	return updates, nil
+}
+
+// webhookHandler 校验 secret_token 后将更新写入通道，secret_token 为空时拒绝所有请求
+func (b *Bot) webhookHandler(ctx context.Context, updates chan<- tgbotapi.Update) http.Handler {
+	secret := []byte(b.receiveConfig.WebhookSecret)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if len(secret) == 0 || subtle.ConstantTimeCompare([]byte(r.Header.Get(secretTokenHeader)), secret) != 1 {
+			logger.Warnf("webhook request rejected: invalid secret token from %s", r.RemoteAddr)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxWebhookBodySize)
+		var update tgbotapi.Update
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case updates <- update:
+			w.WriteHeader(http.StatusOK)
+		case <-ctx.Done():
+			// 正在关闭，让 Telegram 稍后重试
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		}
+	})
+}
+
+// stopReceiving 停止接收更新
+func (b *Bot) stopReceiving() {
+	if b.receiveConfig.Mode != ModeWebhook {
+		b.api.StopReceivingUpdates()
+		return
+	}
+
+	if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		logger.Warnf("failed to delete webhook: %v", err)
+	}
+	b.shutdownWebhookServer()
+}
+
+// shutdownWebhookServer 关闭 Webhook HTTP 服务
+func (b *Bot) shutdownWebhookServer() {
+	if b.webhookServer == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := b.webhookServer.Shutdown(ctx); err != nil {
+		logger.Warnf("failed to shutdown webhook server: %v", err)
+	}
+}
+
+// randomSecretToken 生成 32 字节随机数的十六进制字符串，符合 secret_token 允许的字符
+func randomSecretToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate webhook secret token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,64 @@
+package bot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	const secret = "s3cret"
+	valid := `{"update_id":42,"message":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"},"text":"/start"}}`
+	// 合法 JSON 但超过大小上限，解码时必须被截断
+	oversize := `{"update_id":42,"message":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"},"text":"` +
+		strings.Repeat("a", maxWebhookBodySize) + `"}}`
+
+	tests := []struct {
+		name      string
+		method    string
+		secret    string
+		body      string
+		want      int
+		delivered bool
+	}{
+		{name: "valid update", method: http.MethodPost, secret: secret, body: valid, want: http.StatusOK, delivered: true},
+		{name: "wrong method", method: http.MethodGet, secret: secret, body: valid, want: http.StatusMethodNotAllowed},
+		{name: "wrong secret", method: http.MethodPost, secret: "other", body: valid, want: http.StatusForbidden},
+		{name: "missing secret", method: http.MethodPost, body: valid, want: http.StatusForbidden},
+		{name: "malformed body", method: http.MethodPost, secret: secret, body: "{", want: http.StatusBadRequest},
+		{name: "oversize body", method: http.MethodPost, secret: secret, body: oversize, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Bot{receiveConfig: ReceiveConfig{WebhookSecret: secret}}
+			updates := make(chan tgbotapi.Update, 1)
+			handler := b.webhookHandler(context.Background(), updates)
+
+			req := httptest.NewRequest(tt.method, "/webhook", strings.NewReader(tt.body))
+			req.Header.Set(secretTokenHeader, tt.secret)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			select {
+			case u := <-updates:
+				if !tt.delivered {
+					t.Errorf("unexpected update %d delivered", u.UpdateID)
+				} else if u.UpdateID != 42 {
+					t.Errorf("UpdateID = %d, want 42", u.UpdateID)
+				}
+			default:
+				if tt.delivered {
+					t.Error("update not delivered")
+				}
+			}
+		})
+	}
+}
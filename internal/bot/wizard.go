@@ -0,0 +1,347 @@
+// Package bot 多步骤对话向导
+package bot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/conversation"
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/user"
+)
+
+// wizardStatePrefix 向导状态名称前缀，完整状态名称为 wizard_<向导名称>
+const wizardStatePrefix = "wizard_"
+
+// CallbackWizard 向导按钮回调: wiz:<向导名称>:<步骤>:choice:<选项序号> | back | cancel
+const CallbackWizard = "wiz"
+
+// Wizard 多步骤对话向导
+// 按顺序执行各步骤收集输入，全部完成后调用 Commit
+type Wizard struct {
+	Name   string       // 向导名称，只能包含小写字母和下划线
+	Steps  []WizardStep // 步骤列表，至少一个
+	Commit func(ctx context.Context, s *WizardSession) WizardResult
+	// Cancel 取消时的响应，为空时显示通用的取消提示
+	Cancel func(ctx context.Context, s *WizardSession) CallbackResponse
+}
+
+// WizardStep 向导步骤
+type WizardStep struct {
+	Key    string                                             // 输入保存到会话中的键
+	Prompt func(ctx context.Context, s *WizardSession) string // 提示文案
+	// Choices 内联选项，点击选项等同于输入对应的值，可为空
+	Choices func(ctx context.Context, s *WizardSession) []WizardChoice
+	// Validate 校验输入，返回的错误经 errorText 翻译后提示用户重新输入，可为空
+	// pkg/validator 的校验函数可直接使用
+	Validate func(ctx context.Context, s *WizardSession, value string) error
+	// Skip 根据已收集的输入判断是否跳过该步骤，可为空
+	Skip func(ctx context.Context, s *WizardSession) bool
+	// Content 为 true 时接受任意消息(文本、图片、转发等)，输入值为消息引用，用 parseMessageRef 解析
+	Content bool
+	// Preview 进入该步骤时先发送的预览消息，提示随后作为新消息发送，可为空
+	Preview func(ctx context.Context, s *WizardSession, chatID int64) tgbotapi.Chattable
+}
+
+// WizardChoice 向导步骤的内联选项
+type WizardChoice struct {
+	Label string // 按钮文本
+	Value string // 选中后作为输入的值
+}
+
+// WizardSession 向导会话
+type WizardSession struct {
+	User    *user.User
+	Payload conversation.Payload // 启动时传入的上下文与已收集的输入
+}
+
+// Value 获取指定步骤的输入
+func (s *WizardSession) Value(key string) string {
+	return s.Payload.Values[key]
+}
+
+// WizardResult 向导完成后的回复
+type WizardResult struct {
+	Text   string
+	Markup *tgbotapi.InlineKeyboardMarkup
+}
+
+// wizardInputError 向导输入错误，Key 为消息 ID，由 errorText 翻译
+type wizardInputError struct {
+	Key  string
+	Args []any
+}
+
+// newWizardInputError 创建向导输入错误
+func newWizardInputError(key string, args ...any) error {
+	return &wizardInputError{Key: key, Args: args}
+}
+
+// Error 实现 error 接口
+func (e *wizardInputError) Error() string {
+	return "invalid wizard input: " + e.Key
+}
+
+// wizardReply 向导步骤的回复，fresh 为 true 时作为新消息发送而不是编辑原消息
+type wizardReply struct {
+	text   string
+	markup *tgbotapi.InlineKeyboardMarkup
+	fresh  bool
+}
+
+// response 转换为回调响应
+func (r wizardReply) response() CallbackResponse {
+	if r.fresh {
+		return CallbackResponse{NewMessage: r.text, NewMarkup: r.markup}
+	}
+	return CallbackResponse{EditText: r.text, EditMarkup: r.markup}
+}
+
+// messageRef 消息引用: <聊天ID>:<消息ID>
+func messageRef(msg *tgbotapi.Message) string {
+	return strconv.FormatInt(msg.Chat.ID, 10) + ":" + strconv.Itoa(msg.MessageID)
+}
+
+// parseMessageRef 解析消息引用
+func parseMessageRef(ref string) (chatID int64, messageID int, ok bool) {
+	chatPart, msgPart, found := strings.Cut(ref, ":")
+	if !found {
+		return 0, 0, false
+	}
+	chatID, err := strconv.ParseInt(chatPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	messageID, err = strconv.Atoi(msgPart)
+	if err != nil {
+		return 0, 0, false
+	}
+	return chatID, messageID, true
+}
+
+// registerWizard 注册向导
+func (b *Bot) registerWizard(w *Wizard) {
+	if len(w.Steps) == 0 {
+		panic("wizard " + w.Name + " has no steps")
+	}
+	b.wizards[w.Name] = w
+}
+
+// wizardState 向导对应的状态名称
+func wizardState(name string) UserState {
+	return UserState(wizardStatePrefix + name)
+}
+
+// wizardFor 根据状态查找向导
+func (b *Bot) wizardFor(state UserState) (*Wizard, bool) {
+	name, ok := strings.CutPrefix(string(state), wizardStatePrefix)
+	if !ok {
+		return nil, false
+	}
+	w, ok := b.wizards[name]
+	return w, ok
+}
+
+// startWizard 开始向导，返回第一步的提示
+// payload 用于带入账号 ID 等上下文，已收集的输入会被清空
+func (b *Bot) startWizard(ctx context.Context, currentUser *user.User, name string, payload conversation.Payload) CallbackResponse {
+	w, ok := b.wizards[name]
+	if !ok {
+		return CallbackResponse{Answer: b.t(ctx, "common.unknown_action"), ShowAlert: true}
+	}
+
+	payload.Values = make(map[string]string, len(w.Steps))
+	s := &WizardSession{User: currentUser, Payload: payload}
+	s.Payload.Step = w.nextStep(ctx, s, 0)
+
+	if err := b.stateMachine.SetState(ctx, currentUser.TelegramID, wizardState(w.Name), s.Payload); err != nil {
+		logger.Errorf("failed to save wizard state: %v", err)
+		return CallbackResponse{Answer: b.t(ctx, "common.system_error"), ShowAlert: true}
+	}
+
+	return b.enterStep(ctx, w, s).response()
+}
+
+// nextStep 从 from 开始第一个不跳过的步骤，没有时返回步骤总数
+func (w *Wizard) nextStep(ctx context.Context, s *WizardSession, from int) int {
+	for i := from; i < len(w.Steps); i++ {
+		if w.Steps[i].Skip == nil || !w.Steps[i].Skip(ctx, s) {
+			return i
+		}
+	}
+	return len(w.Steps)
+}
+
+// prevStep 当前步骤之前最近一个不跳过的步骤，没有时返回 -1
+func (w *Wizard) prevStep(ctx context.Context, s *WizardSession) int {
+	for i := s.Payload.Step - 1; i >= 0; i-- {
+		if w.Steps[i].Skip == nil || !w.Steps[i].Skip(ctx, s) {
+			return i
+		}
+	}
+	return -1
+}
+
+// enterStep 显示当前步骤，有预览时先发送预览
+func (b *Bot) enterStep(ctx context.Context, w *Wizard, s *WizardSession) wizardReply {
+	step := w.Steps[s.Payload.Step]
+	fresh := false
+	if step.Preview != nil {
+		// 向导只在私聊中使用，聊天 ID 即用户的 Telegram ID
+		chatID := s.User.TelegramID
+		if _, err := b.send(ctx, chatID, step.Preview(ctx, s, chatID)); err != nil {
+			logger.Warnf("failed to send wizard preview: %v", err)
+		}
+		fresh = true
+	}
+
+	text, markup := b.wizardPrompt(ctx, w, s)
+	return wizardReply{text: text, markup: &markup, fresh: fresh}
+}
+
+// wizardPrompt 当前步骤的提示文案与按钮
+func (b *Bot) wizardPrompt(ctx context.Context, w *Wizard, s *WizardSession) (string, tgbotapi.InlineKeyboardMarkup) {
+	step := w.Steps[s.Payload.Step]
+	prefix := CallbackWizard + ":" + w.Name + ":" + strconv.Itoa(s.Payload.Step) + ":"
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if step.Choices != nil {
+		for i, choice := range step.Choices(ctx, s) {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(choice.Label, prefix+"choice:"+strconv.Itoa(i)),
+			))
+		}
+	}
+
+	var controls []tgbotapi.InlineKeyboardButton
+	if w.prevStep(ctx, s) >= 0 {
+		controls = append(controls, tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "wizard.back"), prefix+"back"))
+	}
+	controls = append(controls, tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.cancel"), prefix+"cancel"))
+	rows = append(rows, controls)
+
+	return step.Prompt(ctx, s), tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// handleWizardInput 处理向导中的文本输入
+func (b *Bot) handleWizardInput(ctx context.Context, msg *tgbotapi.Message, currentUser *user.User, w *Wizard, payload conversation.Payload) {
+	if payload.Step < 0 || payload.Step >= len(w.Steps) {
+		b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+		b.reply(msg.Chat.ID, b.t(ctx, "common.session_expired"))
+		return
+	}
+
+	value := strings.TrimSpace(msg.Text)
+	if w.Steps[payload.Step].Content {
+		value = messageRef(msg)
+	}
+
+	s := &WizardSession{User: currentUser, Payload: payload}
+	reply := b.advanceWizard(ctx, w, s, value)
+	b.sendWithMarkup(ctx, msg.Chat.ID, reply.text, reply.markup)
+}
+
+// advanceWizard 校验并保存当前步骤的输入，进入下一步或完成向导
+func (b *Bot) advanceWizard(ctx context.Context, w *Wizard, s *WizardSession, value string) wizardReply {
+	step := w.Steps[s.Payload.Step]
+
+	if step.Validate != nil {
+		if err := step.Validate(ctx, s, value); err != nil {
+			prompt, markup := b.wizardPrompt(ctx, w, s)
+			return wizardReply{text: b.t(ctx, "wizard.invalid", b.errorText(ctx, err), prompt), markup: &markup}
+		}
+	}
+
+	if s.Payload.Values == nil {
+		s.Payload.Values = make(map[string]string, len(w.Steps))
+	}
+	s.Payload.Values[step.Key] = value
+	s.Payload.Step = w.nextStep(ctx, s, s.Payload.Step+1)
+
+	if s.Payload.Step >= len(w.Steps) {
+		b.stateMachine.ClearState(ctx, s.User.TelegramID)
+		result := w.Commit(ctx, s)
+		return wizardReply{text: result.Text, markup: result.Markup}
+	}
+
+	if err := b.stateMachine.SetState(ctx, s.User.TelegramID, wizardState(w.Name), s.Payload); err != nil {
+		logger.Errorf("failed to save wizard state: %v", err)
+		return wizardReply{text: b.t(ctx, "common.system_error_icon")}
+	}
+
+	return b.enterStep(ctx, w, s)
+}
+
+// handleWizardCallback 处理向导按钮: 选项、上一步与取消
+func (b *Bot) handleWizardCallback(ctx context.Context, query *tgbotapi.CallbackQuery, parts []string, currentUser *user.User) CallbackResponse {
+	if len(parts) < 4 {
+		return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+	}
+
+	w, ok := b.wizards[parts[1]]
+	if !ok {
+		return CallbackResponse{Answer: b.t(ctx, "common.unknown_action"), ShowAlert: true}
+	}
+
+	// 按钮必须属于当前进行中的向导步骤，旧消息上的按钮视为过期
+	state, payload := b.stateMachine.GetState(ctx, currentUser.TelegramID)
+	step, err := strconv.Atoi(parts[2])
+	if err != nil || state != wizardState(w.Name) || step != payload.Step || step >= len(w.Steps) {
+		return CallbackResponse{Answer: b.t(ctx, "common.session_expired"), ShowAlert: true}
+	}
+
+	s := &WizardSession{User: currentUser, Payload: payload}
+
+	switch parts[3] {
+	case "choice":
+		if len(parts) < 5 || w.Steps[step].Choices == nil {
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+		}
+		choices := w.Steps[step].Choices(ctx, s)
+		index, err := strconv.Atoi(parts[4])
+		if err != nil || index < 0 || index >= len(choices) {
+			return CallbackResponse{Answer: b.t(ctx, "common.invalid_action"), ShowAlert: true}
+		}
+		return b.advanceWizard(ctx, w, s, choices[index].Value).response()
+
+	case "back":
+		if prev := w.prevStep(ctx, s); prev >= 0 {
+			s.Payload.Step = prev
+			delete(s.Payload.Values, w.Steps[prev].Key)
+		}
+		if err := b.stateMachine.SetState(ctx, currentUser.TelegramID, wizardState(w.Name), s.Payload); err != nil {
+			logger.Errorf("failed to save wizard state: %v", err)
+			return CallbackResponse{Answer: b.t(ctx, "common.system_error"), ShowAlert: true}
+		}
+		return b.enterStep(ctx, w, s).response()
+
+	case "cancel":
+		b.stateMachine.ClearState(ctx, currentUser.TelegramID)
+		if w.Cancel != nil {
+			return w.Cancel(ctx, s)
+		}
+		return CallbackResponse{
+			Answer:   b.t(ctx, "cancel.answer"),
+			EditText: b.t(ctx, "cancel.text"),
+		}
+
+	default:
+		return CallbackResponse{Answer: b.t(ctx, "common.unknown_action"), ShowAlert: true}
+	}
+}
+
+// sendWithMarkup 发送带按钮的消息，发送失败时退回纯文本
+func (b *Bot) sendWithMarkup(ctx context.Context, chatID int64, text string, markup *tgbotapi.InlineKeyboardMarkup) {
+	replyMsg := tgbotapi.NewMessage(chatID, text)
+	replyMsg.ParseMode = "HTML"
+	if markup != nil {
+		replyMsg.ReplyMarkup = *markup
+	}
+
+	if _, err := b.send(ctx, chatID, replyMsg); err != nil {
+		b.reply(chatID, text)
+	}
+}
@@ -0,0 +1,339 @@
+// Package bot 对话向导定义
+package bot
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/user"
+	"emby-telegram/pkg/validator"
+)
+
+// 向导名称
+const (
+	WizardCreateAccount   = "create_account"   // 创建账号
+	WizardChangePassword  = "change_password"  // 修改账号密码，需要带入 AccountID
+	WizardBroadcast       = "broadcast"        // 群发消息
+	WizardBulkAccounts    = "bulk_accounts"    // 批量账号操作，从账号列表发起时带入 ListView
+	WizardTransferAccount = "transfer_account" // 管理员直接转移账号，需要带入 AccountID
+	WizardOfferTransfer   = "offer_transfer"   // 账号所有者发起转让，需要带入 AccountID
+)
+
+// registerWizards 注册对话向导
+func (b *Bot) registerWizards() {
+	b.registerWizard(b.createAccountWizard())
+	b.registerWizard(b.changePasswordWizard())
+	b.registerWizard(b.broadcastWizard())
+	b.registerWizard(b.bulkAccountsWizard())
+	b.registerWizard(b.transferWizard(WizardTransferAccount))
+	b.registerWizard(b.transferWizard(WizardOfferTransfer))
+}
+
+// createAccountWizard 创建账号: 输入用户名后创建
+func (b *Bot) createAccountWizard() *Wizard {
+	return &Wizard{
+		Name: WizardCreateAccount,
+		Steps: []WizardStep{
+			{
+				Key: "username",
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					return b.t(ctx, "create.prompt")
+				},
+				Validate: func(ctx context.Context, s *WizardSession, username string) error {
+					if err := validator.ValidateUsername(username); err != nil {
+						return err
+					}
+					if _, err := b.accountService.GetByUsername(ctx, username); err == nil {
+						return account.AlreadyExistsError(username)
+					}
+					return nil
+				},
+			},
+		},
+		Commit: func(ctx context.Context, s *WizardSession) WizardResult {
+			acc, plainPassword, err := b.accountService.Create(ctx, s.Value("username"), s.User.ID)
+			if err != nil {
+				switch {
+				case errors.Is(err, account.ErrNotAuthorized):
+					return WizardResult{Text: b.t(ctx, "create.not_authorized")}
+				case errors.Is(err, account.ErrAccountLimitExceeded):
+					return WizardResult{Text: b.t(ctx, "create.limit_exceeded", b.errorText(ctx, err))}
+				case errors.Is(err, account.ErrMaintenance):
+					return WizardResult{Text: b.maintenanceText(ctx)}
+				default:
+					return WizardResult{Text: b.t(ctx, "input.create_failed", b.errorText(ctx, err))}
+				}
+			}
+
+			text := b.t(ctx, "input.create_success",
+				acc.Username,
+				plainPassword,
+				b.expireText(ctx, acc.ExpireAt),
+				acc.MaxDevices,
+			)
+
+			keyboard := tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "input.view_details"), CallbackAccountInfo+":"+uintToStr(acc.ID)),
+				),
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "keyboard.my_accounts"), CallbackMyAccounts+":1"),
+					tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "common.main_menu"), CallbackMainMenu),
+				),
+			)
+			return WizardResult{Text: text, Markup: &keyboard}
+		},
+		Cancel: func(ctx context.Context, s *WizardSession) CallbackResponse {
+			return b.showMainMenu(ctx, s.User)
+		},
+	}
+}
+
+// changePasswordWizard 修改账号密码: 输入新密码后修改
+func (b *Bot) changePasswordWizard() *Wizard {
+	return &Wizard{
+		Name: WizardChangePassword,
+		Steps: []WizardStep{
+			{
+				Key: "password",
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					acc, err := b.accountService.Get(ctx, s.Payload.AccountID)
+					if err != nil {
+						return b.t(ctx, "common.get_account_failed")
+					}
+					return b.t(ctx, "account.password_prompt", acc.Username)
+				},
+				Validate: func(ctx context.Context, s *WizardSession, password string) error {
+					return validator.ValidatePassword(password)
+				},
+			},
+		},
+		Commit: func(ctx context.Context, s *WizardSession) WizardResult {
+			acc, err := b.accountService.Get(ctx, s.Payload.AccountID)
+			if err != nil {
+				return WizardResult{Text: b.t(ctx, "common.get_account_failed_reason", b.errorText(ctx, err))}
+			}
+
+			// 检查所有权
+			if err := b.accountService.CheckOwnership(ctx, acc.ID, s.User.ID); err != nil {
+				return WizardResult{Text: b.t(ctx, "common.account_forbidden")}
+			}
+
+			password := s.Value("password")
+			if err := b.accountService.ChangePassword(ctx, acc.ID, password); err != nil {
+				return WizardResult{Text: b.t(ctx, "input.password_failed", b.errorText(ctx, err))}
+			}
+
+			text := b.t(ctx, "password.success", acc.Username, password)
+
+			keyboard := tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "input.back_to_details"), CallbackAccountInfo+":"+uintToStr(acc.ID)),
+				),
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(b.t(ctx, "keyboard.my_accounts"), CallbackMyAccounts+":1"),
+				),
+			)
+			return WizardResult{Text: text, Markup: &keyboard}
+		},
+		Cancel: func(ctx context.Context, s *WizardSession) CallbackResponse {
+			return b.showAccountInfo(ctx, s.User, s.Payload.AccountID)
+		},
+	}
+}
+
+// broadcastDayOptions 到期天数的快捷选项
+var broadcastDayOptions = []int{3, 7, 14, 30}
+
+// broadcastWizard 群发消息: 内容、接收对象、对象参数，预览后确认发送
+func (b *Bot) broadcastWizard() *Wizard {
+	audienceIs := func(kind user.AudienceKind) func(ctx context.Context, s *WizardSession) bool {
+		return func(ctx context.Context, s *WizardSession) bool {
+			return user.AudienceKind(s.Value("audience")) != kind
+		}
+	}
+
+	return &Wizard{
+		Name: WizardBroadcast,
+		Steps: []WizardStep{
+			{
+				Key:     "content",
+				Content: true,
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					return b.t(ctx, "broadcast.prompt_content")
+				},
+			},
+			{
+				Key: "audience",
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					return b.t(ctx, "broadcast.prompt_audience")
+				},
+				Choices: func(ctx context.Context, s *WizardSession) []WizardChoice {
+					kinds := []user.AudienceKind{user.AudienceAll, user.AudienceActive, user.AudienceExpiring, user.AudiencePlan, user.AudienceRole}
+					choices := make([]WizardChoice, len(kinds))
+					for i, kind := range kinds {
+						choices[i] = WizardChoice{Label: b.t(ctx, "broadcast.audience_"+string(kind)), Value: string(kind)}
+					}
+					return choices
+				},
+				Validate: func(ctx context.Context, s *WizardSession, value string) error {
+					if !user.IsValidAudienceKind(user.AudienceKind(value)) {
+						return newWizardInputError("broadcast.use_buttons")
+					}
+					return nil
+				},
+			},
+			{
+				Key:  "days",
+				Skip: audienceIs(user.AudienceExpiring),
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					return b.t(ctx, "broadcast.prompt_days")
+				},
+				Choices: func(ctx context.Context, s *WizardSession) []WizardChoice {
+					choices := make([]WizardChoice, len(broadcastDayOptions))
+					for i, days := range broadcastDayOptions {
+						choices[i] = WizardChoice{Label: b.t(ctx, "broadcast.days_option", days), Value: strconv.Itoa(days)}
+					}
+					return choices
+				},
+				Validate: func(ctx context.Context, s *WizardSession, value string) error {
+					_, err := validator.ParseDays(value)
+					return err
+				},
+			},
+			{
+				Key:  "plan",
+				Skip: audienceIs(user.AudiencePlan),
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					return b.t(ctx, "broadcast.prompt_plan")
+				},
+				Choices: func(ctx context.Context, s *WizardSession) []WizardChoice {
+					templates, err := b.policyService.List(ctx)
+					if err != nil {
+						logger.Errorf("failed to list policy templates: %v", err)
+						return nil
+					}
+					choices := make([]WizardChoice, len(templates))
+					for i, tpl := range templates {
+						choices[i] = WizardChoice{Label: tpl.Name, Value: uintToStr(tpl.ID)}
+					}
+					return choices
+				},
+				Validate: func(ctx context.Context, s *WizardSession, value string) error {
+					_, err := b.policyService.Get(ctx, strToUint(value))
+					return err
+				},
+			},
+			{
+				Key:  "role",
+				Skip: audienceIs(user.AudienceRole),
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					return b.t(ctx, "broadcast.prompt_role")
+				},
+				Choices: func(ctx context.Context, s *WizardSession) []WizardChoice {
+					roles, err := b.userService.ListRoles(ctx)
+					if err != nil {
+						logger.Errorf("failed to list roles: %v", err)
+						return nil
+					}
+					choices := make([]WizardChoice, len(roles))
+					for i, role := range roles {
+						choices[i] = WizardChoice{Label: string(role.Name), Value: string(role.Name)}
+					}
+					return choices
+				},
+				Validate: func(ctx context.Context, s *WizardSession, value string) error {
+					_, err := b.userService.Role(ctx, user.Role(value))
+					return err
+				},
+			},
+			{
+				Key: "confirm",
+				Preview: func(ctx context.Context, s *WizardSession, chatID int64) tgbotapi.Chattable {
+					fromChatID, messageID, _ := parseMessageRef(s.Value("content"))
+					return tgbotapi.NewCopyMessage(chatID, fromChatID, messageID)
+				},
+				Prompt: func(ctx context.Context, s *WizardSession) string {
+					audience, desc, err := b.broadcastAudience(ctx, s)
+					if err != nil {
+						return b.t(ctx, "broadcast.list_failed", b.errorText(ctx, err))
+					}
+					recipients, err := b.userService.ListByAudience(ctx, audience)
+					if err != nil {
+						return b.t(ctx, "broadcast.list_failed", b.errorText(ctx, err))
+					}
+					return b.t(ctx, "broadcast.confirm", desc, len(recipients))
+				},
+				Choices: func(ctx context.Context, s *WizardSession) []WizardChoice {
+					return []WizardChoice{{Label: b.t(ctx, "broadcast.send_button"), Value: "send"}}
+				},
+				Validate: func(ctx context.Context, s *WizardSession, value string) error {
+					if value != "send" {
+						return newWizardInputError("broadcast.use_buttons")
+					}
+					return nil
+				},
+			},
+		},
+		Commit: func(ctx context.Context, s *WizardSession) WizardResult {
+			fromChatID, messageID, ok := parseMessageRef(s.Value("content"))
+			if !ok {
+				return WizardResult{Text: b.t(ctx, "common.session_expired")}
+			}
+
+			audience, _, err := b.broadcastAudience(ctx, s)
+			if err != nil {
+				return WizardResult{Text: b.t(ctx, "broadcast.list_failed", b.errorText(ctx, err))}
+			}
+			recipients, err := b.userService.ListByAudience(ctx, audience)
+			if err != nil {
+				return WizardResult{Text: b.t(ctx, "broadcast.list_failed", b.errorText(ctx, err))}
+			}
+			if len(recipients) == 0 {
+				return WizardResult{Text: b.t(ctx, "broadcast.no_recipients")}
+			}
+
+			b.startBroadcast(ctx, s.User.TelegramID, fromChatID, messageID, recipients)
+			return WizardResult{Text: b.t(ctx, "broadcast.started", len(recipients))}
+		},
+	}
+}
+
+// broadcastAudience 根据向导输入生成群发目标及其说明
+func (b *Bot) broadcastAudience(ctx context.Context, s *WizardSession) (user.Audience, string, error) {
+	audience := user.Audience{Kind: user.AudienceKind(s.Value("audience"))}
+
+	switch audience.Kind {
+	case user.AudienceExpiring:
+		days, err := validator.ParseDays(s.Value("days"))
+		if err != nil {
+			return audience, "", err
+		}
+		audience.Days = days
+		return audience, b.t(ctx, "broadcast.desc_expiring", days), nil
+
+	case user.AudiencePlan:
+		tpl, err := b.policyService.Get(ctx, strToUint(s.Value("plan")))
+		if err != nil {
+			return audience, "", err
+		}
+		// 未指定模板的账号使用默认模板
+		audience.TemplateIDs = []uint{tpl.ID}
+		if tpl.IsDefault {
+			audience.TemplateIDs = append(audience.TemplateIDs, 0)
+		}
+		return audience, b.t(ctx, "broadcast.desc_plan", tpl.Name), nil
+
+	case user.AudienceRole:
+		audience.Role = user.Role(s.Value("role"))
+		return audience, b.t(ctx, "broadcast.desc_role", audience.Role), nil
+
+	default:
+		return audience, b.t(ctx, "broadcast.desc_"+string(audience.Kind)), nil
+	}
+}
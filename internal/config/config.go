@@ -3,34 +3,83 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
+// secretTokenPattern Telegram Webhook secret_token 允许的格式
+var secretTokenPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
 // Config 应用配置结构
 type Config struct {
-	App      AppConfig
-	Telegram TelegramConfig
-	Database DatabaseConfig
-	Account  AccountConfig
-	Emby     EmbyConfig
-	Log      LogConfig
+	App       AppConfig
+	Telegram  TelegramConfig
+	Database  DatabaseConfig
+	Account   AccountConfig
+	Emby      EmbyConfig
+	HTTP      HTTPConfig
+	API       APIConfig
+	Dashboard DashboardConfig
+	Log       LogConfig
 }
 
 // AppConfig 应用配置
 type AppConfig struct {
-	Name    string
-	Version string
-	Debug   bool
+	Name            string
+	Version         string
+	Debug           bool
+	ShutdownTimeout int `mapstructure:"shutdown_timeout"` // 关闭时等待处理中请求的最长时间(秒)
 }
 
 // TelegramConfig Telegram Bot 配置
 type TelegramConfig struct {
-	Token    string
-	Timeout  int
-	AdminIDs []int64 `mapstructure:"admin_ids"`
+	Token           string
+	Timeout         int
+	AdminIDs        []int64            `mapstructure:"admin_ids"`
+	Mode            string             // 更新接收方式: polling 或 webhook
+	Webhook         WebhookConfig      // Webhook 模式配置
+	Workers         int                // 处理更新的工作协程数
+	QueueSize       int                `mapstructure:"queue_size"`       // 每个工作协程的队列容量
+	RateLimit       int                `mapstructure:"rate_limit"`       // 每个用户每分钟允许的命令与按钮操作次数，0 表示不限流
+	RateBurst       int                `mapstructure:"rate_burst"`       // 每个用户允许的突发操作次数
+	DefaultLanguage string             `mapstructure:"default_language"` // 无法识别用户语言时使用的界面语言
+	Conversation    ConversationConfig // 对话状态配置
+	Send            SendConfig         // 出站消息发送队列配置
+	WebApp          WebAppConfig       `mapstructure:"webapp"` // Mini App 配置
+}
+
+// WebAppConfig Telegram Mini App 配置
+type WebAppConfig struct {
+	Enabled     bool
+	URL         string // 页面的公网 HTTPS 地址，路径部分作为本地路由
+	ShortName   string `mapstructure:"short_name"`    // 在 BotFather 中通过 /newapp 创建的 Mini App 短名称
+	InitDataTTL int    `mapstructure:"init_data_ttl"` // initData 有效期(秒)
+}
+
+// SendConfig 出站消息发送队列配置，默认值与 Telegram 的发送限制一致
+type SendConfig struct {
+	Rate           float64 // 全局每秒发送消息数
+	ChatRate       float64 `mapstructure:"chat_rate"`        // 每个聊天每秒发送消息数
+	GroupPerMinute int     `mapstructure:"group_per_minute"` // 每个群组每分钟发送消息数
+	Retries        int     // 网络错误或 429 后的最大重试次数
+}
+
+// ConversationConfig 对话状态配置
+type ConversationConfig struct {
+	Store     string         // 状态存储: database(跨重启、多实例共享) 或 memory
+	TTL       int            // 默认状态有效期(秒)
+	StateTTLs map[string]int `mapstructure:"state_ttls"` // 按状态名称覆盖的有效期(秒)
+}
+
+// WebhookConfig Telegram Webhook 配置
+type WebhookConfig struct {
+	Listen      string // 本地监听地址
+	URL         string // Telegram 回调的公网 HTTPS 地址
+	SecretToken string `mapstructure:"secret_token"` // 校验 X-Telegram-Bot-Api-Secret-Token，为空时每次启动随机生成
 }
 
 // DatabaseConfig 数据库配置
@@ -52,13 +101,34 @@ type AccountConfig struct {
 
 // EmbyConfig Emby 服务器配置
 type EmbyConfig struct {
-	ServerURL    string `mapstructure:"server_url"`
-	APIKey       string `mapstructure:"api_key"`
-	EnableSync   bool   `mapstructure:"enable_sync"`
-	SyncOnCreate bool   `mapstructure:"sync_on_create"`
-	SyncOnDelete bool   `mapstructure:"sync_on_delete"`
-	Timeout      int    `mapstructure:"timeout"`
-	RetryCount   int    `mapstructure:"retry_count"`
+	ServerURL     string  `mapstructure:"server_url"`
+	APIKey        string  `mapstructure:"api_key"`
+	EnableSync    bool    `mapstructure:"enable_sync"`
+	SyncOnCreate  bool    `mapstructure:"sync_on_create"`
+	SyncOnDelete  bool    `mapstructure:"sync_on_delete"`
+	Timeout       int     `mapstructure:"timeout"`
+	RetryCount    int     `mapstructure:"retry_count"`
+	RateLimit     float64 `mapstructure:"rate_limit"`     // 每秒请求数，0 表示不限流
+	RateBurst     int     `mapstructure:"rate_burst"`     // 令牌桶容量
+	MaxConcurrent int     `mapstructure:"max_concurrent"` // 最大并发请求数，0 表示不限制
+	CacheTTL      int     `mapstructure:"cache_ttl"`      // 用户查询缓存时间(秒)，0 表示不缓存
+}
+
+// HTTPConfig 内置 HTTP 服务配置，Mini App 等页面和接口挂载在这里
+type HTTPConfig struct {
+	Listen string // 本地监听地址
+}
+
+// APIConfig 管理 HTTP API 配置
+type APIConfig struct {
+	Enabled bool // 是否在内置 HTTP 服务上提供 /api/v1/
+}
+
+// DashboardConfig 网页管理后台配置
+type DashboardConfig struct {
+	Enabled    bool
+	URL        string // 后台的公网地址，路径部分作为本地路由，域名需在 BotFather 中通过 /setdomain 绑定
+	SessionTTL int    `mapstructure:"session_ttl"` // 登录会话有效期(秒)
 }
 
 // LogConfig 日志配置
@@ -102,6 +172,10 @@ func Load() (*Config, error) {
 		cfg.Telegram.Token = token
 	}
 
+	if secret := os.Getenv("TELEGRAM_WEBHOOK_SECRET"); secret != "" {
+		cfg.Telegram.Webhook.SecretToken = secret
+	}
+
 	if driver := os.Getenv("DB_DRIVER"); driver != "" {
 		cfg.Database.Driver = driver
 	}
@@ -132,10 +206,30 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("app.name", "Emby Telegram Bot")
 	v.SetDefault("app.version", "1.0.0")
 	v.SetDefault("app.debug", false)
+	v.SetDefault("app.shutdown_timeout", 30)
 
 	// Telegram 默认值
 	v.SetDefault("telegram.timeout", 60)
 	v.SetDefault("telegram.admin_ids", []int64{})
+	v.SetDefault("telegram.mode", "polling")
+	v.SetDefault("telegram.workers", 8)
+	v.SetDefault("telegram.queue_size", 64)
+	v.SetDefault("telegram.rate_limit", 30)
+	v.SetDefault("telegram.rate_burst", 10)
+	v.SetDefault("telegram.default_language", "zh")
+	v.SetDefault("telegram.conversation.store", "database")
+	v.SetDefault("telegram.conversation.ttl", 600)
+	v.SetDefault("telegram.send.rate", 30)
+	v.SetDefault("telegram.send.chat_rate", 1)
+	v.SetDefault("telegram.send.group_per_minute", 20)
+	v.SetDefault("telegram.send.retries", 3)
+	v.SetDefault("telegram.webhook.listen", ":8080")
+	v.SetDefault("telegram.webhook.url", "")
+	v.SetDefault("telegram.webhook.secret_token", "")
+	v.SetDefault("telegram.webapp.enabled", false)
+	v.SetDefault("telegram.webapp.url", "")
+	v.SetDefault("telegram.webapp.short_name", "")
+	v.SetDefault("telegram.webapp.init_data_ttl", 86400)
 
 	// Database 默认值
 	v.SetDefault("database.driver", "sqlite")
@@ -158,6 +252,21 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("emby.sync_on_delete", true)
 	v.SetDefault("emby.timeout", 30)
 	v.SetDefault("emby.retry_count", 3)
+	v.SetDefault("emby.rate_limit", 10)
+	v.SetDefault("emby.rate_burst", 20)
+	v.SetDefault("emby.max_concurrent", 5)
+	v.SetDefault("emby.cache_ttl", 30)
+
+	// HTTP 默认值
+	v.SetDefault("http.listen", ":8090")
+
+	// API 默认值
+	v.SetDefault("api.enabled", false)
+
+	// Dashboard 默认值
+	v.SetDefault("dashboard.enabled", false)
+	v.SetDefault("dashboard.url", "")
+	v.SetDefault("dashboard.session_ttl", 43200)
 
 	// Log 默认值
 	v.SetDefault("log.level", "info")
@@ -170,10 +279,123 @@ func (c *Config) validate() error {
 		return fmt.Errorf("telegram.token is required")
 	}
 
+	if c.App.ShutdownTimeout <= 0 {
+		c.App.ShutdownTimeout = 30
+	}
+
 	if c.Telegram.Timeout <= 0 {
 		c.Telegram.Timeout = 60
 	}
 
+	if c.Telegram.Workers <= 0 {
+		c.Telegram.Workers = 8
+	}
+
+	if c.Telegram.QueueSize <= 0 {
+		c.Telegram.QueueSize = 64
+	}
+
+	if c.Telegram.RateLimit < 0 {
+		c.Telegram.RateLimit = 0
+	}
+
+	if c.Telegram.RateBurst <= 0 {
+		c.Telegram.RateBurst = 10
+	}
+
+	if c.Telegram.DefaultLanguage == "" {
+		c.Telegram.DefaultLanguage = "zh"
+	}
+
+	switch c.Telegram.Conversation.Store {
+	case "":
+		c.Telegram.Conversation.Store = "database"
+	case "database", "memory":
+	default:
+		return fmt.Errorf("telegram.conversation.store must be database or memory, got %q", c.Telegram.Conversation.Store)
+	}
+
+	if c.Telegram.Conversation.TTL <= 0 {
+		c.Telegram.Conversation.TTL = 600
+	}
+
+	for state, ttl := range c.Telegram.Conversation.StateTTLs {
+		if ttl <= 0 {
+			return fmt.Errorf("telegram.conversation.state_ttls.%s must be positive", state)
+		}
+	}
+
+	if c.Telegram.Send.Rate <= 0 {
+		c.Telegram.Send.Rate = 30
+	}
+
+	if c.Telegram.Send.ChatRate <= 0 {
+		c.Telegram.Send.ChatRate = 1
+	}
+
+	if c.Telegram.Send.GroupPerMinute <= 0 {
+		c.Telegram.Send.GroupPerMinute = 20
+	}
+
+	if c.Telegram.Send.Retries < 0 {
+		c.Telegram.Send.Retries = 0
+	}
+
+	switch c.Telegram.Mode {
+	case "":
+		c.Telegram.Mode = "polling"
+	case "polling":
+	case "webhook":
+		if c.Telegram.Webhook.URL == "" {
+			return fmt.Errorf("telegram.webhook.url is required in webhook mode")
+		}
+		u, err := url.Parse(c.Telegram.Webhook.URL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("telegram.webhook.url must be an https url")
+		}
+		if c.Telegram.Webhook.Listen == "" {
+			return fmt.Errorf("telegram.webhook.listen is required in webhook mode")
+		}
+		// Telegram 要求 secret_token 为 1-256 个 A-Z a-z 0-9 _ - 字符
+		if secret := c.Telegram.Webhook.SecretToken; secret != "" && !secretTokenPattern.MatchString(secret) {
+			return fmt.Errorf("telegram.webhook.secret_token may only contain A-Z, a-z, 0-9, _ and - (max 256)")
+		}
+	default:
+		return fmt.Errorf("telegram.mode must be polling or webhook, got %q", c.Telegram.Mode)
+	}
+
+	if c.Telegram.WebApp.Enabled {
+		u, err := url.Parse(c.Telegram.WebApp.URL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("telegram.webapp.url must be an https url")
+		}
+		if c.Telegram.WebApp.ShortName == "" {
+			return fmt.Errorf("telegram.webapp.short_name is required when the web app is enabled")
+		}
+		if c.Telegram.WebApp.InitDataTTL < 0 {
+			c.Telegram.WebApp.InitDataTTL = 0
+		}
+	}
+
+	if c.Dashboard.Enabled {
+		u, err := url.Parse(c.Dashboard.URL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("dashboard.url must be an http(s) url")
+		}
+		if c.Dashboard.SessionTTL <= 0 {
+			c.Dashboard.SessionTTL = 43200
+		}
+	}
+
+	if c.HTTPEnabled() {
+		if c.HTTP.Listen == "" {
+			return fmt.Errorf("http.listen is required when the web app, api or dashboard is enabled")
+		}
+		if c.Telegram.Mode == "webhook" && c.HTTP.Listen == c.Telegram.Webhook.Listen {
+			return fmt.Errorf("http.listen must differ from telegram.webhook.listen")
+		}
+	}
+
 	if c.Database.Driver == "" {
 		return fmt.Errorf("database.driver is required")
 	}
@@ -206,16 +428,48 @@ func (c *Config) validate() error {
 		if c.Emby.RetryCount < 0 {
 			c.Emby.RetryCount = 0
 		}
+		if c.Emby.RateLimit < 0 {
+			c.Emby.RateLimit = 0
+		}
+		if c.Emby.RateBurst < 0 {
+			c.Emby.RateBurst = 0
+		}
+		if c.Emby.MaxConcurrent < 0 {
+			c.Emby.MaxConcurrent = 0
+		}
+		if c.Emby.CacheTTL < 0 {
+			c.Emby.CacheTTL = 0
+		}
 	}
 
 	return nil
 }
 
+// HTTPEnabled 是否需要启动内置 HTTP 服务
+func (c *Config) HTTPEnabled() bool {
+	return c.Telegram.WebApp.Enabled || c.API.Enabled || c.Dashboard.Enabled
+}
+
+// GetShutdownTimeout 获取关闭超时时间
+func (c *AppConfig) GetShutdownTimeout() time.Duration {
+	return time.Duration(c.ShutdownTimeout) * time.Second
+}
+
 // GetTimeout 获取超时时间
 func (c *TelegramConfig) GetTimeout() time.Duration {
 	return time.Duration(c.Timeout) * time.Second
 }
 
+// GetInitDataTTL 获取 Mini App initData 有效期，0 表示不检查
+func (c *WebAppConfig) GetInitDataTTL() time.Duration {
+	return time.Duration(c.InitDataTTL) * time.Second
+}
+
+// GetSessionTTL 获取管理后台登录会话有效期
+func (c *DashboardConfig) GetSessionTTL() time.Duration {
+	return time.Duration(c.SessionTTL) * time.Second
+}
+
 // IsAdmin 检查用户是否为管理员
 func (c *TelegramConfig) IsAdmin(userID int64) bool {
 	for _, id := range c.AdminIDs {
@@ -0,0 +1,7 @@
+// Package conversation 领域错误定义
+package conversation
+
+import "errors"
+
+// ErrNotFound 会话状态不存在或已过期
+var ErrNotFound = errors.New("conversation state not found")
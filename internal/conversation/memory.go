@@ -0,0 +1,67 @@
+// Package conversation 内存存储实现
+package conversation
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore 基于内存的会话状态存储
+// 进程重启后状态丢失，也不能在多个实例间共享
+type MemoryStore struct {
+	mu     sync.RWMutex
+	states map[int64]State
+}
+
+// NewMemoryStore 创建内存存储实例
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: make(map[int64]State)}
+}
+
+// Get 获取用户的会话状态
+func (s *MemoryStore) Get(ctx context.Context, telegramID int64) (*State, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.states[telegramID]
+	if !ok || state.IsExpired(time.Now()) {
+		return nil, ErrNotFound
+	}
+	return &state, nil
+}
+
+// Save 保存用户的会话状态
+func (s *MemoryStore) Save(ctx context.Context, state *State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *state
+	saved.UpdatedAt = time.Now()
+	s.states[state.TelegramID] = saved
+	return nil
+}
+
+// Delete 删除用户的会话状态
+func (s *MemoryStore) Delete(ctx context.Context, telegramID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.states, telegramID)
+	return nil
+}
+
+// DeleteExpired 删除过期的会话状态
+func (s *MemoryStore) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for id, state := range s.states {
+		if state.IsExpired(before) {
+			delete(s.states, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,34 @@
+// Package conversation 会话状态定义
+package conversation
+
+import "time"
+
+// Payload 会话附加数据
+// 字段按需填写，未使用的字段保持零值
+type Payload struct {
+	AccountID  uint   `json:"account_id,omitempty"`  // 正在操作的账号
+	TemplateID uint   `json:"template_id,omitempty"` // 正在操作的策略模板
+	ListView   string `json:"list_view,omitempty"`   // 从管理列表发起时的列表状态
+
+	Step   int               `json:"step,omitempty"`   // 向导当前步骤
+	Values map[string]string `json:"values,omitempty"` // 向导已收集的输入，按步骤键保存
+}
+
+// State 用户会话状态
+type State struct {
+	TelegramID int64     `gorm:"primarykey;autoIncrement:false" json:"telegram_id"`
+	Name       string    `gorm:"size:64;not null" json:"name"`             // 状态名称
+	Payload    Payload   `gorm:"serializer:json;type:text" json:"payload"` // 附加数据
+	ExpiresAt  time.Time `gorm:"index;not null" json:"expires_at"`         // 过期时间
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (State) TableName() string {
+	return "conversation_states"
+}
+
+// IsExpired 检查状态是否已过期
+func (s *State) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,22 @@
+// Package conversation 存储接口定义
+package conversation
+
+import (
+	"context"
+	"time"
+)
+
+// StateStore 会话状态存储接口
+type StateStore interface {
+	// Get 获取用户的会话状态，不存在或已过期时返回 ErrNotFound
+	Get(ctx context.Context, telegramID int64) (*State, error)
+
+	// Save 保存用户的会话状态，已存在时覆盖
+	Save(ctx context.Context, state *State) error
+
+	// Delete 删除用户的会话状态
+	Delete(ctx context.Context, telegramID int64) error
+
+	// DeleteExpired 删除在指定时间之前过期的会话状态，返回删除数量
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,13 @@
+// Package dashboard 领域错误定义
+package dashboard
+
+import "errors"
+
+// 领域错误定义
+var (
+	// ErrInvalidLogin 登录数据缺失、格式错误或签名不匹配
+	ErrInvalidLogin = errors.New("invalid telegram login data")
+
+	// ErrLoginExpired 登录数据超过有效期
+	ErrLoginExpired = errors.New("telegram login data expired")
+)
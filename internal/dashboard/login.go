@@ -0,0 +1,85 @@
+// Package dashboard Telegram Login Widget 登录数据校验
+package dashboard
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// loginFields Login Widget 回调中参与签名的字段
+var loginFields = map[string]bool{
+	"id":         true,
+	"first_name": true,
+	"last_name":  true,
+	"username":   true,
+	"photo_url":  true,
+	"auth_date":  true,
+}
+
+// LoginData 校验通过的 Telegram 登录数据
+type LoginData struct {
+	ID        int64
+	FirstName string
+	LastName  string
+	Username  string
+	PhotoURL  string
+	AuthDate  time.Time
+}
+
+// VerifyLogin 校验 Telegram Login Widget 回调的查询参数
+// 签名算法: secret = SHA256(botToken)，hash = hex(HMAC_SHA256(secret, data_check_string))，
+// 其中 data_check_string 为除 hash 外的字段按键名排序后以换行连接的 key=value
+// maxAge 大于 0 时拒绝 auth_date 早于 now-maxAge 的数据
+func VerifyLogin(values url.Values, botToken string, maxAge time.Duration, now time.Time) (*LoginData, error) {
+	hash := values.Get("hash")
+	if hash == "" {
+		return nil, fmt.Errorf("%w: missing hash", ErrInvalidLogin)
+	}
+
+	pairs := make([]string, 0, len(loginFields))
+	for key := range values {
+		if loginFields[key] {
+			pairs = append(pairs, key+"="+values.Get(key))
+		}
+	}
+	sort.Strings(pairs)
+
+	secret := sha256.Sum256([]byte(botToken))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+
+	got, err := hex.DecodeString(hash)
+	if err != nil || !hmac.Equal(got, mac.Sum(nil)) {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidLogin)
+	}
+
+	authUnix, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid auth_date", ErrInvalidLogin)
+	}
+	authDate := time.Unix(authUnix, 0)
+	if maxAge > 0 && now.Sub(authDate) > maxAge {
+		return nil, ErrLoginExpired
+	}
+
+	id, err := strconv.ParseInt(values.Get("id"), 10, 64)
+	if err != nil || id == 0 {
+		return nil, fmt.Errorf("%w: invalid id", ErrInvalidLogin)
+	}
+
+	return &LoginData{
+		ID:        id,
+		FirstName: values.Get("first_name"),
+		LastName:  values.Get("last_name"),
+		Username:  values.Get("username"),
+		PhotoURL:  values.Get("photo_url"),
+		AuthDate:  authDate,
+	}, nil
+}
@@ -0,0 +1,83 @@
+package dashboard
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// testLoginHash 是 testLogin 默认参数的签名，密钥为 sha256(testBotToken)
+const (
+	testBotToken  = "123456:TEST-TOKEN"
+	testAuthDate  = 1760000000
+	testLoginHash = "58eb32bf13da0fe9385598e87daabd5876edf1dde584cbbcb42c7bfe09fbc787"
+)
+
+// testLogin 返回已签名的登录回调参数，modify 可以在校验前篡改参数
+func testLogin(modify func(url.Values)) url.Values {
+	values := url.Values{
+		"id":         {"279058397"},
+		"first_name": {"Alice"},
+		"username":   {"alice"},
+		"photo_url":  {"https://t.me/i/userpic/320/alice.jpg"},
+		"auth_date":  {"1760000000"},
+		"hash":       {testLoginHash},
+	}
+	if modify != nil {
+		modify(values)
+	}
+	return values
+}
+
+func TestVerifyLogin(t *testing.T) {
+	authDate := time.Unix(testAuthDate, 0)
+
+	tests := []struct {
+		name    string
+		values  url.Values
+		token   string
+		maxAge  time.Duration
+		now     time.Time
+		wantErr error
+	}{
+		{name: "valid login", values: testLogin(nil), token: testBotToken, maxAge: time.Hour, now: authDate.Add(time.Minute)},
+		{name: "unsigned fields ignored", values: testLogin(func(v url.Values) { v.Set("next", "/accounts") }), token: testBotToken, maxAge: time.Hour, now: authDate},
+		{name: "no max age", values: testLogin(nil), token: testBotToken, now: authDate.Add(365 * 24 * time.Hour)},
+		{name: "login at max age", values: testLogin(nil), token: testBotToken, maxAge: time.Hour, now: authDate.Add(time.Hour)},
+		{name: "login past max age", values: testLogin(nil), token: testBotToken, maxAge: time.Hour, now: authDate.Add(time.Hour + time.Second), wantErr: ErrLoginExpired},
+		{name: "wrong bot token", values: testLogin(nil), token: "654321:OTHER-TOKEN", maxAge: time.Hour, now: authDate, wantErr: ErrInvalidLogin},
+		{name: "other user id", values: testLogin(func(v url.Values) { v.Set("id", "1") }), token: testBotToken, maxAge: time.Hour, now: authDate, wantErr: ErrInvalidLogin},
+		{name: "tampered auth_date", values: testLogin(func(v url.Values) { v.Set("auth_date", "1760003600") }), token: testBotToken, maxAge: time.Hour, now: authDate, wantErr: ErrInvalidLogin},
+		{name: "added signed field", values: testLogin(func(v url.Values) { v.Set("last_name", "Liddell") }), token: testBotToken, maxAge: time.Hour, now: authDate, wantErr: ErrInvalidLogin},
+		{name: "removed signed field", values: testLogin(func(v url.Values) { v.Del("photo_url") }), token: testBotToken, maxAge: time.Hour, now: authDate, wantErr: ErrInvalidLogin},
+		{name: "tampered hash", values: testLogin(func(v url.Values) { v.Set("hash", "68"+testLoginHash[2:]) }), token: testBotToken, maxAge: time.Hour, now: authDate, wantErr: ErrInvalidLogin},
+		{name: "malformed hash", values: testLogin(func(v url.Values) { v.Set("hash", "zz") }), token: testBotToken, maxAge: time.Hour, now: authDate, wantErr: ErrInvalidLogin},
+		{name: "missing hash", values: testLogin(func(v url.Values) { v.Del("hash") }), token: testBotToken, maxAge: time.Hour, now: authDate, wantErr: ErrInvalidLogin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := VerifyLogin(tt.values, tt.token, tt.maxAge, tt.now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("VerifyLogin() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyLogin() error = %v", err)
+			}
+			want := LoginData{
+				ID:        279058397,
+				FirstName: "Alice",
+				Username:  "alice",
+				PhotoURL:  "https://t.me/i/userpic/320/alice.jpg",
+				AuthDate:  authDate,
+			}
+			if *data != want {
+				t.Errorf("VerifyLogin() = %+v, want %+v", *data, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,440 @@
+// Package dashboard 后台页面与表单操作
+package dashboard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/emby"
+	"emby-telegram/internal/invitecode"
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/user"
+)
+
+// pageSize 列表每页条数
+const pageSize = 20
+
+// pager 列表分页，页码从 1 开始
+type pager struct {
+	Page  int
+	Pages int
+	Total int64
+}
+
+// newPager 读取 page 查询参数，超出范围时取最近的有效页
+func newPager(r *http.Request, total int64) pager {
+	pages := int((total + pageSize - 1) / pageSize)
+	if pages < 1 {
+		pages = 1
+	}
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	if page > pages {
+		page = pages
+	}
+	return pager{Page: page, Pages: pages, Total: total}
+}
+
+// Offset 当前页的偏移量
+func (p pager) Offset() int {
+	return (p.Page - 1) * pageSize
+}
+
+// Prev 上一页页码，没有上一页时返回 0
+func (p pager) Prev() int {
+	if p.Page > 1 {
+		return p.Page - 1
+	}
+	return 0
+}
+
+// Next 下一页页码，没有下一页时返回 0
+func (p pager) Next() int {
+	if p.Page < p.Pages {
+		return p.Page + 1
+	}
+	return 0
+}
+
+// bar 图表中的一项
+type bar struct {
+	Label string
+	Count int64
+}
+
+// chart 条形图，Max 为条形的满格值
+type chart struct {
+	Max  int64
+	Bars []bar
+}
+
+// newChart 构造条形图
+func newChart(bars []bar) chart {
+	c := chart{Bars: bars}
+	for _, b := range bars {
+		c.Max = max(c.Max, b.Count)
+	}
+	// 全部为 0 时 meter 的 max 不能为 0
+	c.Max = max(c.Max, 1)
+	return c
+}
+
+// overviewView 概览页数据
+type overviewView struct {
+	Users         int64
+	Accounts      int64
+	InviteCodes   int64
+	AccountStatus chart
+	UserRoles     chart
+	Playback      *playbackView
+	EmbyError     bool
+}
+
+// playbackView 当前播放概况
+type playbackView struct {
+	Sessions    int
+	Playing     int
+	Transcoding int
+	Clients     chart
+}
+
+// handleOverview 概览：用户、账号、邀请码统计和当前播放情况
+func (s *Server) handleOverview(r *http.Request, v *viewer) (string, any, error) {
+	ctx := r.Context()
+	var data overviewView
+	var err error
+
+	if data.Users, err = s.users.Count(ctx); err != nil {
+		return "", nil, err
+	}
+	if data.Accounts, err = s.accounts.Count(ctx); err != nil {
+		return "", nil, err
+	}
+	if data.InviteCodes, err = s.inviteCodes.Count(ctx); err != nil {
+		return "", nil, err
+	}
+
+	var statusBars []bar
+	for _, status := range []account.Status{account.StatusActive, account.StatusSuspended, account.StatusExpired} {
+		count, err := s.accounts.CountByStatus(ctx, status)
+		if err != nil {
+			return "", nil, err
+		}
+		statusBars = append(statusBars, bar{Label: v.loc.T("dashboard.status_" + string(status)), Count: count})
+	}
+	data.AccountStatus = newChart(statusBars)
+
+	roles, err := s.users.ListRoles(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	roleBars := make([]bar, 0, len(roles))
+	for _, role := range roles {
+		count, err := s.users.CountByRole(ctx, role.Name)
+		if err != nil {
+			return "", nil, err
+		}
+		roleBars = append(roleBars, bar{Label: string(role.Name), Count: count})
+	}
+	data.UserRoles = newChart(roleBars)
+
+	// Emby 不可用时仍显示其他统计
+	if s.embyClient != nil {
+		sessions, err := s.embyClient.GetSessions(ctx)
+		if err != nil {
+			logger.Warnf("dashboard: failed to get emby sessions: %v", err)
+			data.EmbyError = true
+		} else {
+			data.Playback = newPlaybackView(sessions)
+		}
+	}
+
+	return "overview", data, nil
+}
+
+// newPlaybackView 汇总播放会话，客户端按会话数从多到少排列
+func newPlaybackView(sessions []emby.SessionInfo) *playbackView {
+	p := &playbackView{Sessions: len(sessions)}
+	clients := make(map[string]int64)
+	for i := range sessions {
+		session := &sessions[i]
+		clients[session.Client]++
+		if session.IsPlaying() {
+			p.Playing++
+		}
+		if session.PlayState != nil && session.PlayState.PlayMethod == "Transcode" {
+			p.Transcoding++
+		}
+	}
+
+	bars := make([]bar, 0, len(clients))
+	for client, count := range clients {
+		bars = append(bars, bar{Label: client, Count: count})
+	}
+	sort.Slice(bars, func(i, j int) bool {
+		if bars[i].Count != bars[j].Count {
+			return bars[i].Count > bars[j].Count
+		}
+		return bars[i].Label < bars[j].Label
+	})
+	p.Clients = newChart(bars)
+	return p
+}
+
+// listView 列表页数据，Query 不为空时为搜索结果，不分页
+type listView[T any] struct {
+	Query string
+	Items []T
+	Pager pager
+}
+
+// handleUsers 用户列表，可按 Telegram ID 或用户名搜索
+func (s *Server) handleUsers(r *http.Request, v *viewer) (string, any, error) {
+	ctx := r.Context()
+	data := listView[*user.User]{Query: strings.TrimSpace(r.URL.Query().Get("q"))}
+
+	if data.Query != "" {
+		u, err := s.findUser(ctx, data.Query)
+		if err != nil && !errors.Is(err, user.ErrNotFound) {
+			return "", nil, err
+		}
+		if u != nil {
+			data.Items = append(data.Items, u)
+		}
+		return "users", data, nil
+	}
+
+	total, err := s.users.Count(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	data.Pager = newPager(r, total)
+	if data.Items, err = s.users.List(ctx, data.Pager.Offset(), pageSize); err != nil {
+		return "", nil, err
+	}
+	return "users", data, nil
+}
+
+// findUser 按 Telegram ID 或 @用户名查找用户
+func (s *Server) findUser(ctx context.Context, query string) (*user.User, error) {
+	if telegramID, err := strconv.ParseInt(query, 10, 64); err == nil {
+		return s.users.GetByTelegramID(ctx, telegramID)
+	}
+	return s.users.GetByUsername(ctx, strings.TrimPrefix(query, "@"))
+}
+
+// handleBlockUser 封禁用户
+func (s *Server) handleBlockUser(r *http.Request, v *viewer) (string, error) {
+	telegramID, err := strconv.ParseInt(r.PathValue("telegram_id"), 10, 64)
+	if err != nil {
+		return "", errInvalidParam
+	}
+	if telegramID == v.user.TelegramID {
+		return "", errBlockSelf
+	}
+
+	if err := s.users.Block(r.Context(), telegramID); err != nil {
+		return "", err
+	}
+	logger.Infof("dashboard: admin %d blocked user %d", v.user.TelegramID, telegramID)
+	return v.loc.T("dashboard.blocked_done", telegramID), nil
+}
+
+// handleUnblockUser 解封用户
+func (s *Server) handleUnblockUser(r *http.Request, v *viewer) (string, error) {
+	telegramID, err := strconv.ParseInt(r.PathValue("telegram_id"), 10, 64)
+	if err != nil {
+		return "", errInvalidParam
+	}
+
+	if err := s.users.Unblock(r.Context(), telegramID); err != nil {
+		return "", err
+	}
+	logger.Infof("dashboard: admin %d unblocked user %d", v.user.TelegramID, telegramID)
+	return v.loc.T("dashboard.unblocked_done", telegramID), nil
+}
+
+// handleAccounts 账号列表，可按账号用户名搜索
+func (s *Server) handleAccounts(r *http.Request, v *viewer) (string, any, error) {
+	ctx := r.Context()
+	data := listView[*account.AccountWithUser]{Query: strings.TrimSpace(r.URL.Query().Get("q"))}
+
+	if data.Query != "" {
+		acc, err := s.accounts.GetByUsername(ctx, data.Query)
+		if err != nil {
+			if errors.Is(err, account.ErrNotFound) {
+				return "accounts", data, nil
+			}
+			return "", nil, err
+		}
+		withUser, err := s.accounts.GetWithUser(ctx, acc.ID)
+		if err != nil {
+			return "", nil, err
+		}
+		data.Items = append(data.Items, withUser)
+		return "accounts", data, nil
+	}
+
+	total, err := s.accounts.Count(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	data.Pager = newPager(r, total)
+	if data.Items, err = s.accounts.ListAllWithUser(ctx, data.Pager.Offset(), pageSize); err != nil {
+		return "", nil, err
+	}
+	return "accounts", data, nil
+}
+
+// handleSuspendAccount 停用账号
+func (s *Server) handleSuspendAccount(r *http.Request, v *viewer) (string, error) {
+	acc, err := s.pathAccount(r)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.accounts.SuspendAs(r.Context(), v.user.ID, acc.ID); err != nil {
+		return "", err
+	}
+	logger.Infof("dashboard: admin %d suspended account %s", v.user.TelegramID, acc.Username)
+	return v.loc.T("dashboard.suspended_done", acc.Username), nil
+}
+
+// handleActivateAccount 激活账号
+func (s *Server) handleActivateAccount(r *http.Request, v *viewer) (string, error) {
+	acc, err := s.pathAccount(r)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.accounts.ActivateAs(r.Context(), v.user.ID, acc.ID); err != nil {
+		return "", err
+	}
+	logger.Infof("dashboard: admin %d activated account %s", v.user.TelegramID, acc.Username)
+	return v.loc.T("dashboard.activated_done", acc.Username), nil
+}
+
+// pathAccount 读取路径中的账号 ID 并获取账号
+func (s *Server) pathAccount(r *http.Request) (*account.Account, error) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, errInvalidParam
+	}
+	return s.accounts.Get(r.Context(), uint(id))
+}
+
+// handleInviteCodes 邀请码列表，可按邀请码搜索
+func (s *Server) handleInviteCodes(r *http.Request, v *viewer) (string, any, error) {
+	ctx := r.Context()
+	data := listView[*invitecode.InviteCode]{Query: strings.TrimSpace(r.URL.Query().Get("q"))}
+
+	if data.Query != "" {
+		code, err := s.inviteCodes.GetByCode(ctx, data.Query)
+		if err != nil && !errors.Is(err, invitecode.ErrNotFound) {
+			return "", nil, err
+		}
+		if code != nil {
+			data.Items = append(data.Items, code)
+		}
+		return "invitecodes", data, nil
+	}
+
+	total, err := s.inviteCodes.Count(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	data.Pager = newPager(r, total)
+	if data.Items, err = s.inviteCodes.List(ctx, data.Pager.Offset(), pageSize); err != nil {
+		return "", nil, err
+	}
+	return "invitecodes", data, nil
+}
+
+// handleCreateInviteCode 生成邀请码
+func (s *Server) handleCreateInviteCode(r *http.Request, v *viewer) (string, error) {
+	maxUses, err := strconv.Atoi(strings.TrimSpace(r.PostFormValue("max_uses")))
+	if err != nil {
+		return "", errInvalidParam
+	}
+	expireDays, err := strconv.Atoi(strings.TrimSpace(r.PostFormValue("expire_days")))
+	if err != nil || expireDays < 0 {
+		return "", errInvalidParam
+	}
+	description := strings.TrimSpace(r.PostFormValue("description"))
+	if len([]rune(description)) > 200 {
+		return "", errInvalidParam
+	}
+
+	code, err := s.inviteCodes.Generate(r.Context(), maxUses, expireDays, description, v.user.TelegramID)
+	if err != nil {
+		return "", err
+	}
+	logger.Infof("dashboard: admin %d generated invite code %s", v.user.TelegramID, code.Code)
+	return v.loc.T("dashboard.invitecode_created", code.Code), nil
+}
+
+// handleRevokeInviteCode 吊销邀请码
+func (s *Server) handleRevokeInviteCode(r *http.Request, v *viewer) (string, error) {
+	code := r.PathValue("code")
+	if err := s.inviteCodes.Revoke(r.Context(), code); err != nil {
+		return "", err
+	}
+	logger.Infof("dashboard: admin %d revoked invite code %s", v.user.TelegramID, code)
+	return v.loc.T("dashboard.revoked_done", strings.ToUpper(code)), nil
+}
+
+// sessionsView 播放页数据
+type sessionsView struct {
+	Unavailable bool
+	EmbyError   bool
+	Playback    *playbackView
+	Sessions    []sessionRow
+}
+
+// sessionRow 播放会话
+type sessionRow struct {
+	emby.SessionInfo
+	NowPlaying string
+	Progress   int
+	Paused     bool
+	PlayMethod string
+}
+
+// handleSessions Emby 当前播放会话
+func (s *Server) handleSessions(r *http.Request, v *viewer) (string, any, error) {
+	if s.embyClient == nil {
+		return "sessions", sessionsView{Unavailable: true}, nil
+	}
+
+	sessions, err := s.embyClient.GetSessions(r.Context())
+	if err != nil {
+		logger.Warnf("dashboard: failed to get emby sessions: %v", err)
+		return "sessions", sessionsView{EmbyError: true}, nil
+	}
+
+	data := sessionsView{Playback: newPlaybackView(sessions)}
+	for _, session := range sessions {
+		row := sessionRow{SessionInfo: session}
+		if item := session.NowPlayingItem; item != nil {
+			row.NowPlaying = item.GetDisplayName()
+			row.Progress = int(session.GetProgress())
+		}
+		if state := session.PlayState; state != nil {
+			row.Paused = state.IsPaused
+			row.PlayMethod = state.PlayMethod
+		}
+		data.Sessions = append(data.Sessions, row)
+	}
+	// 正在播放的会话排在前面
+	sort.SliceStable(data.Sessions, func(i, j int) bool {
+		return data.Sessions[i].NowPlaying != "" && data.Sessions[j].NowPlaying == ""
+	})
+	return "sessions", data, nil
+}
@@ -0,0 +1,459 @@
+// Package dashboard 网页管理后台
+// 页面由服务端使用 html/template 渲染，管理员通过 Telegram Login Widget 登录，
+// 登录后以会话 Cookie 识别身份，所有表单提交都校验 CSRF 令牌
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"embed"
+	"errors"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/emby"
+	"emby-telegram/internal/i18n"
+	"emby-telegram/internal/invitecode"
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/user"
+	"emby-telegram/pkg/timeutil"
+	"emby-telegram/pkg/validator"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+//go:embed static
+var staticFS embed.FS
+
+const (
+	// sessionCookie 会话 Cookie 名称
+	sessionCookie = "et_dashboard"
+	// csrfField 表单中 CSRF 令牌的字段名
+	csrfField = "csrf_token"
+	// loginMaxAge Login Widget 登录数据的有效期
+	loginMaxAge = time.Hour
+	// maxBodySize 表单大小上限
+	maxBodySize = 16 << 10
+)
+
+// contentSecurityPolicy 只允许加载本站资源和 Telegram 登录组件
+const contentSecurityPolicy = "default-src 'self'; script-src 'self' https://telegram.org; " +
+	"frame-src https://oauth.telegram.org; img-src 'self' https://telegram.org https://t.me data:; " +
+	"style-src 'self'; form-action 'self'; frame-ancestors 'none'; base-uri 'none'"
+
+// pageTemplates 页面模板，每个页面与 layout.html 组合
+var pageTemplates = []string{"login", "error", "overview", "users", "accounts", "invitecodes", "sessions"}
+
+// 内部错误
+var (
+	errNotLoggedIn  = errors.New("not logged in")
+	errInvalidParam = errors.New("invalid parameter")
+	errBlockSelf    = errors.New("cannot block yourself")
+)
+
+// Server 管理后台 HTTP 服务
+type Server struct {
+	accounts    *account.Service
+	users       *user.Service
+	inviteCodes *invitecode.Service
+	embyClient  *emby.Client
+	catalog     *i18n.Catalog
+	botToken    string
+	botUsername string
+	publicURL   *url.URL
+	basePath    string
+	secure      bool
+	sessions    *sessionStore
+	templates   map[string]*template.Template
+}
+
+// NewServer 创建管理后台服务
+// publicURL 为后台的公网地址，其路径部分作为本地路由；embyClient 为 nil 时不显示播放数据
+func NewServer(accounts *account.Service, users *user.Service, inviteCodes *invitecode.Service, embyClient *emby.Client, catalog *i18n.Catalog, botToken, botUsername string, publicURL *url.URL, sessionTTL time.Duration) *Server {
+	u := *publicURL
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+
+	templates := make(map[string]*template.Template, len(pageTemplates))
+	for _, name := range pageTemplates {
+		templates[name] = template.Must(template.New(name).Funcs(templateFuncs).
+			ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html"))
+	}
+
+	return &Server{
+		accounts:    accounts,
+		users:       users,
+		inviteCodes: inviteCodes,
+		embyClient:  embyClient,
+		catalog:     catalog,
+		botToken:    botToken,
+		botUsername: botUsername,
+		publicURL:   &u,
+		basePath:    u.Path,
+		secure:      u.Scheme == "https",
+		sessions:    newSessionStore(sessionTTL),
+		templates:   templates,
+	}
+}
+
+// BasePath 返回后台所在路径
+func (s *Server) BasePath() string {
+	return s.basePath
+}
+
+// Handler 返回管理后台的路由
+func (s *Server) Handler() http.Handler {
+	static, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		panic("dashboard: static files not embedded")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET "+s.basePath+"static/", http.StripPrefix(s.basePath+"static/", http.FileServerFS(static)))
+	mux.HandleFunc("GET "+s.basePath+"login", s.handleLogin)
+	mux.HandleFunc("POST "+s.basePath+"logout", s.handleLogout)
+
+	mux.Handle("GET "+s.basePath+"{$}", s.page(s.handleOverview))
+	mux.Handle("GET "+s.basePath+"users", s.page(s.handleUsers))
+	mux.Handle("POST "+s.basePath+"users/{telegram_id}/block", s.action(s.handleBlockUser))
+	mux.Handle("POST "+s.basePath+"users/{telegram_id}/unblock", s.action(s.handleUnblockUser))
+	mux.Handle("GET "+s.basePath+"accounts", s.page(s.handleAccounts))
+	mux.Handle("POST "+s.basePath+"accounts/{id}/suspend", s.action(s.handleSuspendAccount))
+	mux.Handle("POST "+s.basePath+"accounts/{id}/activate", s.action(s.handleActivateAccount))
+	mux.Handle("GET "+s.basePath+"invitecodes", s.page(s.handleInviteCodes))
+	mux.Handle("POST "+s.basePath+"invitecodes", s.action(s.handleCreateInviteCode))
+	mux.Handle("POST "+s.basePath+"invitecodes/{code}/revoke", s.action(s.handleRevokeInviteCode))
+	mux.Handle("GET "+s.basePath+"sessions", s.page(s.handleSessions))
+
+	return mux
+}
+
+// viewer 已登录的管理员
+type viewer struct {
+	user    *user.User
+	session *session
+	loc     *i18n.Localizer
+}
+
+// pageFunc 页面处理函数，返回模板名和页面数据
+type pageFunc func(r *http.Request, v *viewer) (string, any, error)
+
+// actionFunc 表单操作，返回成功提示，完成后重定向回提交表单的页面
+type actionFunc func(r *http.Request, v *viewer) (string, error)
+
+// page 校验登录状态后渲染页面，未登录时跳转到登录页
+func (s *Server) page(fn pageFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, err := s.authorize(r)
+		if err != nil {
+			s.handleAuthError(w, r, err)
+			return
+		}
+
+		name, data, err := fn(r, v)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				logger.Errorf("dashboard %s failed: %v", r.URL.Path, err)
+			}
+			s.render(w, r, http.StatusInternalServerError, "error", s.newPageData(r, v, v.loc.T("dashboard.error_internal")))
+			return
+		}
+
+		s.render(w, r, http.StatusOK, name, s.newPageData(r, v, data))
+	})
+}
+
+// action 校验登录状态和 CSRF 令牌后执行表单操作
+// 操作结果保存在会话中，重定向后在页面上显示一次
+func (s *Server) action(fn actionFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+		v, err := s.authorize(r)
+		if err != nil {
+			s.handleAuthError(w, r, err)
+			return
+		}
+		if !checkCSRF(r, v.session) {
+			logger.Warnf("dashboard: csrf check failed for %d on %s", v.user.TelegramID, r.URL.Path)
+			s.render(w, r, http.StatusForbidden, "error", s.newPageData(r, v, v.loc.T("dashboard.error_csrf")))
+			return
+		}
+
+		notice, err := fn(r, v)
+		f := &flash{Text: notice}
+		if err != nil {
+			f = &flash{Error: true, Text: s.errorText(v.loc, err)}
+		}
+		s.sessions.setFlash(v.session.id, f)
+
+		http.Redirect(w, r, s.redirectTarget(r), http.StatusSeeOther)
+	})
+}
+
+// authorize 根据会话 Cookie 识别管理员
+// 每次请求都重新读取用户，被降级或封禁的管理员立即失去访问权限
+func (s *Server) authorize(r *http.Request) (*viewer, error) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil, errNotLoggedIn
+	}
+	sess, ok := s.sessions.get(cookie.Value)
+	if !ok {
+		return nil, errNotLoggedIn
+	}
+
+	u, err := s.users.GetByTelegramID(r.Context(), sess.telegramID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			s.sessions.delete(sess.id)
+			return nil, errNotLoggedIn
+		}
+		return nil, err
+	}
+	if !u.IsAdmin() || !u.CanAccess() {
+		logger.Warnf("dashboard session of %d revoked: no longer an admin", u.TelegramID)
+		s.sessions.delete(sess.id)
+		return nil, errNotLoggedIn
+	}
+
+	return &viewer{user: u, session: sess, loc: s.localizer(u)}, nil
+}
+
+// handleAuthError 未登录时跳转到登录页，其他错误显示错误页
+func (s *Server) handleAuthError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, errNotLoggedIn) {
+		http.Redirect(w, r, s.basePath+"login", http.StatusSeeOther)
+		return
+	}
+	logger.Errorf("dashboard authorization failed: %v", err)
+	loc := s.catalog.Localizer(s.catalog.DefaultLanguage())
+	s.render(w, r, http.StatusInternalServerError, "error", s.newPageData(r, &viewer{loc: loc}, loc.T("dashboard.error_internal")))
+}
+
+// handleLogin 显示登录页，或校验 Login Widget 回调的登录数据并创建会话
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	loc := s.catalog.Localizer(s.catalog.DefaultLanguage())
+	query := r.URL.Query()
+
+	if !query.Has("hash") {
+		if _, err := s.authorize(r); err == nil {
+			http.Redirect(w, r, s.basePath, http.StatusSeeOther)
+			return
+		}
+		s.renderLogin(w, r, http.StatusOK, loc, "")
+		return
+	}
+
+	data, err := VerifyLogin(query, s.botToken, loginMaxAge, time.Now())
+	if err != nil {
+		logger.Warnf("dashboard login rejected from %s: %v", r.RemoteAddr, err)
+		key := "dashboard.login_invalid"
+		if errors.Is(err, ErrLoginExpired) {
+			key = "dashboard.login_expired"
+		}
+		s.renderLogin(w, r, http.StatusUnauthorized, loc, loc.T(key))
+		return
+	}
+
+	u, err := s.users.GetByTelegramID(r.Context(), data.ID)
+	if err != nil && !errors.Is(err, user.ErrNotFound) {
+		s.handleAuthError(w, r, err)
+		return
+	}
+	if err != nil || !u.IsAdmin() || !u.CanAccess() {
+		logger.Warnf("dashboard login denied for telegram user %d from %s", data.ID, r.RemoteAddr)
+		s.renderLogin(w, r, http.StatusForbidden, loc, loc.T("dashboard.login_denied"))
+		return
+	}
+
+	sess, err := s.sessions.create(u.TelegramID)
+	if err != nil {
+		s.handleAuthError(w, r, err)
+		return
+	}
+	http.SetCookie(w, s.sessionCookie(sess.id, sess.expiresAt))
+	logger.Infof("dashboard: admin %d logged in from %s", u.TelegramID, r.RemoteAddr)
+
+	http.Redirect(w, r, s.basePath, http.StatusSeeOther)
+}
+
+// handleLogout 退出登录
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+	v, err := s.authorize(r)
+	if err != nil {
+		s.handleAuthError(w, r, err)
+		return
+	}
+	if !checkCSRF(r, v.session) {
+		s.render(w, r, http.StatusForbidden, "error", s.newPageData(r, v, v.loc.T("dashboard.error_csrf")))
+		return
+	}
+
+	s.sessions.delete(v.session.id)
+	http.SetCookie(w, s.sessionCookie("", time.Unix(0, 0)))
+	logger.Infof("dashboard: admin %d logged out", v.user.TelegramID)
+
+	http.Redirect(w, r, s.basePath+"login", http.StatusSeeOther)
+}
+
+// sessionCookie 构造会话 Cookie，只在后台路径下发送，HTTPS 部署时仅通过 HTTPS 发送
+func (s *Server) sessionCookie(value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     sessionCookie,
+		Value:    value,
+		Path:     s.basePath,
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   s.secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// checkCSRF 校验表单中的 CSRF 令牌
+func checkCSRF(r *http.Request, sess *session) bool {
+	token := r.PostFormValue(csrfField)
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(sess.csrfToken)) == 1
+}
+
+// redirectTarget 表单操作完成后返回的页面，只允许后台内的路径
+func (s *Server) redirectTarget(r *http.Request) string {
+	to := r.PostFormValue("redirect")
+	if strings.HasPrefix(to, s.basePath) && !strings.HasPrefix(to, "//") && !strings.Contains(to, "\\") {
+		return to
+	}
+	return s.basePath
+}
+
+// localizer 按用户的语言设置选择翻译器
+func (s *Server) localizer(u *user.User) *i18n.Localizer {
+	if u.Language != "" && s.catalog.Supports(u.Language) {
+		return s.catalog.Localizer(u.Language)
+	}
+	return s.catalog.Localizer(s.catalog.DefaultLanguage())
+}
+
+// errorText 将表单操作的错误转换为当前语言的提示
+func (s *Server) errorText(loc *i18n.Localizer, err error) string {
+	var verr *validator.Error
+	switch {
+	case errors.As(err, &verr):
+		return loc.T(verr.Key, verr.Args...)
+	case errors.Is(err, errInvalidParam):
+		return loc.T("dashboard.error_bad_request")
+	case errors.Is(err, errBlockSelf):
+		return loc.T("dashboard.error_block_self")
+	case errors.Is(err, user.ErrNotFound):
+		return loc.T("error.user_not_found")
+	case errors.Is(err, account.ErrNotFound):
+		return loc.T("error.account_not_found")
+	case errors.Is(err, account.ErrUnauthorized):
+		return loc.T("error.unauthorized")
+	case errors.Is(err, account.ErrMaintenance):
+		return loc.T("maintenance.alert_generic")
+	case errors.Is(err, account.ErrSyncDisabled):
+		return loc.T("error.sync_disabled")
+	case errors.Is(err, account.ErrNotSynced):
+		return loc.T("error.not_synced")
+	case errors.Is(err, invitecode.ErrNotFound):
+		return loc.T("dashboard.error_invitecode_not_found")
+	case errors.Is(err, invitecode.ErrInvalidMaxUses):
+		return loc.T("dashboard.error_invalid_max_uses")
+	}
+
+	logger.Errorf("dashboard action failed: %v", err)
+	return loc.T("dashboard.error_internal")
+}
+
+// pageData 传给模板的数据，嵌入翻译器以便模板中使用 {{.T "key"}}
+type pageData struct {
+	*i18n.Localizer
+	Page  string
+	Base  string
+	Self  string
+	Admin *user.User
+	CSRF  string
+	Flash *flash
+	Data  any
+}
+
+// newPageData 构造页面数据，同时取出会话中待显示的操作结果
+func (s *Server) newPageData(r *http.Request, v *viewer, data any) *pageData {
+	p := &pageData{
+		Localizer: v.loc,
+		Base:      s.basePath,
+		Self:      r.URL.RequestURI(),
+		Admin:     v.user,
+		Data:      data,
+	}
+	if v.session != nil {
+		p.CSRF = v.session.csrfToken
+		p.Flash = s.sessions.takeFlash(v.session.id)
+	}
+	return p
+}
+
+// loginView 登录页数据
+type loginView struct {
+	BotUsername string
+	AuthURL     string
+	Error       string
+}
+
+// renderLogin 渲染包含 Login Widget 的登录页
+func (s *Server) renderLogin(w http.ResponseWriter, r *http.Request, status int, loc *i18n.Localizer, errText string) {
+	authURL := s.publicURL.JoinPath("login").String()
+	data := loginView{BotUsername: s.botUsername, AuthURL: authURL, Error: errText}
+	s.render(w, r, status, "login", s.newPageData(r, &viewer{loc: loc}, data))
+}
+
+// render 渲染页面
+func (s *Server) render(w http.ResponseWriter, r *http.Request, status int, name string, data *pageData) {
+	data.Page = name
+	var buf bytes.Buffer
+	if err := s.templates[name].ExecuteTemplate(&buf, "layout", data); err != nil {
+		logger.Errorf("dashboard: render %s failed: %v", name, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "text/html; charset=utf-8")
+	h.Set("Cache-Control", "no-store")
+	h.Set("Content-Security-Policy", contentSecurityPolicy)
+	h.Set("X-Frame-Options", "DENY")
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("Referrer-Policy", "same-origin")
+	w.WriteHeader(status)
+	if _, err := buf.WriteTo(w); err != nil {
+		logger.Debugf("dashboard: write %s response: %v", r.URL.Path, err)
+	}
+}
+
+// templateFuncs 模板函数
+var templateFuncs = template.FuncMap{
+	// datetime 格式化时间，nil 时返回空字符串
+	"datetime": func(t any) string {
+		switch v := t.(type) {
+		case time.Time:
+			return timeutil.FormatMinute(v)
+		case *time.Time:
+			if v != nil {
+				return timeutil.FormatMinute(*v)
+			}
+		}
+		return ""
+	},
+	// expired 检查时间是否已过
+	"expired": timeutil.IsExpired,
+}
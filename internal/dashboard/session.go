@@ -0,0 +1,125 @@
+// Package dashboard 登录会话
+package dashboard
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// flash 表单操作结果，在重定向后的下一个页面显示一次
+type flash struct {
+	Error bool
+	Text  string
+}
+
+// session 管理员登录会话
+// 会话只保存在内存中，进程重启后需要重新登录
+type session struct {
+	id         string
+	telegramID int64
+	csrfToken  string
+	expiresAt  time.Time
+	flash      *flash
+}
+
+// sessionStore 内存会话存储
+type sessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+	ttl      time.Duration
+}
+
+// newSessionStore 创建会话存储，ttl 为会话有效期
+func newSessionStore(ttl time.Duration) *sessionStore {
+	return &sessionStore{
+		sessions: make(map[string]*session),
+		ttl:      ttl,
+	}
+}
+
+// create 为管理员创建会话，同时清理已过期的会话
+func (s *sessionStore) create(telegramID int64) (*session, error) {
+	id, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	csrfToken, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sess := &session{
+		id:         id,
+		telegramID: telegramID,
+		csrfToken:  csrfToken,
+		expiresAt:  now.Add(s.ttl),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, existing := range s.sessions {
+		if now.After(existing.expiresAt) {
+			delete(s.sessions, key)
+		}
+	}
+	s.sessions[id] = sess
+	return sess, nil
+}
+
+// get 获取未过期的会话
+func (s *sessionStore) get(id string) (*session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(sess.expiresAt) {
+		delete(s.sessions, id)
+		return nil, false
+	}
+	return sess, true
+}
+
+// delete 删除会话
+func (s *sessionStore) delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}
+
+// setFlash 保存操作结果
+func (s *sessionStore) setFlash(id string, f *flash) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sess, ok := s.sessions[id]; ok {
+		sess.flash = f
+	}
+}
+
+// takeFlash 取出并清除操作结果
+func (s *sessionStore) takeFlash(id string) *flash {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil
+	}
+	f := sess.flash
+	sess.flash = nil
+	return f
+}
+
+// randomToken 生成 32 字节随机数的十六进制字符串
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate random token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
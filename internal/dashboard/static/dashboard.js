@@ -0,0 +1,7 @@
+// 带 data-confirm 属性的表单提交前要求确认
+document.addEventListener('submit', function (event) {
+  var message = event.target.getAttribute('data-confirm');
+  if (message && !window.confirm(message)) {
+    event.preventDefault();
+  }
+});
@@ -0,0 +1,142 @@
+// Package dataio 用户、账号、邀请码和邀请码使用记录的 CSV/JSON 导入导出
+// 用于在实例之间迁移数据和生成对账表格
+package dataio
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/invitecode"
+	"emby-telegram/internal/policy"
+	"emby-telegram/internal/user"
+)
+
+// Dataset 数据集
+type Dataset string
+
+const (
+	// DatasetUsers Telegram 用户
+	DatasetUsers Dataset = "users"
+	// DatasetAccounts Emby 账号，包含所有者信息
+	DatasetAccounts Dataset = "accounts"
+	// DatasetInviteCodes 邀请码
+	DatasetInviteCodes Dataset = "invitecodes"
+	// DatasetUsage 邀请码使用记录，只支持导出
+	DatasetUsage Dataset = "usage"
+)
+
+// Datasets 可以导出的数据集
+var Datasets = []Dataset{DatasetUsers, DatasetAccounts, DatasetInviteCodes, DatasetUsage}
+
+// ImportDatasets 可以导入的数据集，按迁移时的导入顺序排列(账号的所有者需要先导入)
+var ImportDatasets = []Dataset{DatasetUsers, DatasetAccounts, DatasetInviteCodes}
+
+// Format 文件格式
+type Format string
+
+const (
+	// FormatCSV 带表头的 CSV
+	FormatCSV Format = "csv"
+	// FormatJSON 对象数组
+	FormatJSON Format = "json"
+)
+
+// 错误定义
+var (
+	ErrUnknownDataset    = errors.New("unknown dataset")
+	ErrUnknownFormat     = errors.New("unknown format")
+	ErrImportUnsupported = errors.New("dataset cannot be imported")
+	ErrTooManyRows       = errors.New("too many rows")
+)
+
+// ParseDataset 解析数据集名称
+func ParseDataset(s string) (Dataset, error) {
+	dataset := Dataset(strings.ToLower(strings.TrimSpace(s)))
+	for _, d := range Datasets {
+		if d == dataset {
+			return dataset, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownDataset, s)
+}
+
+// ParseFormat 解析文件格式，为空时使用 CSV
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownFormat, s)
+	}
+}
+
+// FormatFromFilename 根据文件扩展名确定格式
+func FormatFromFilename(name string) (Format, error) {
+	ext := strings.TrimPrefix(path.Ext(name), ".")
+	if ext == "" {
+		return "", fmt.Errorf("%w: %s", ErrUnknownFormat, name)
+	}
+	return ParseFormat(ext)
+}
+
+// Filename 导出文件的默认名称
+func Filename(dataset Dataset, format Format) string {
+	return string(dataset) + "." + string(format)
+}
+
+// ContentType 文件格式对应的 MIME 类型
+func (f Format) ContentType() string {
+	if f == FormatJSON {
+		return "application/json"
+	}
+	return "text/csv"
+}
+
+// FieldError 字段取值无效
+type FieldError struct {
+	Field string
+	Value string
+}
+
+// Error 实现 error 接口
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid %s: %q", e.Field, e.Value)
+}
+
+// ConflictError 唯一字段与已有数据或文件中前面的行冲突
+type ConflictError struct {
+	Field  string
+	Value  string
+	InFile bool // true 表示与文件中前面的行重复
+}
+
+// Error 实现 error 接口
+func (e *ConflictError) Error() string {
+	if e.InFile {
+		return fmt.Sprintf("duplicate %s %s in file", e.Field, e.Value)
+	}
+	return fmt.Sprintf("%s %s already exists", e.Field, e.Value)
+}
+
+// Service 导入导出服务
+type Service struct {
+	users    *user.Service
+	accounts *account.Service
+	codes    *invitecode.Service
+	policies *policy.Service
+}
+
+// NewService 创建导入导出服务
+func NewService(users *user.Service, accounts *account.Service, codes *invitecode.Service, policies *policy.Service) *Service {
+	return &Service{
+		users:    users,
+		accounts: accounts,
+		codes:    codes,
+		policies: policies,
+	}
+}
@@ -0,0 +1,179 @@
+// Package dataio 导出
+package dataio
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/invitecode"
+	"emby-telegram/internal/user"
+)
+
+// exportPageSize 导出时每次从数据库读取的记录数
+const exportPageSize = 500
+
+// Export 将数据集写入 w，返回导出的记录数
+// 按页读取并逐条写出，不会一次性加载全部数据
+func (s *Service) Export(ctx context.Context, w io.Writer, dataset Dataset, format Format) (int, error) {
+	switch dataset {
+	case DatasetUsers:
+		return writeRecords(w, format, userColumns, func(offset int) ([]*UserRecord, error) {
+			users, _, err := s.users.ListByQuery(ctx, user.ListQuery{Offset: offset, Limit: exportPageSize})
+			if err != nil {
+				return nil, err
+			}
+			records := make([]*UserRecord, len(users))
+			for i, u := range users {
+				records[i] = newUserRecord(u)
+			}
+			return records, nil
+		})
+
+	case DatasetAccounts:
+		return writeRecords(w, format, accountColumns, func(offset int) ([]*AccountRecord, error) {
+			// 按用户名排序，导出过程中新建账号不会导致分页重复或遗漏已有账号
+			accs, _, err := s.accounts.ListByQuery(ctx, account.ListQuery{Sort: account.SortUsername, Offset: offset, Limit: exportPageSize})
+			if err != nil {
+				return nil, err
+			}
+			records := make([]*AccountRecord, len(accs))
+			for i, acc := range accs {
+				records[i] = newAccountRecord(acc)
+			}
+			return records, nil
+		})
+
+	case DatasetInviteCodes:
+		return writeRecords(w, format, inviteCodeColumns, func(offset int) ([]*InviteCodeRecord, error) {
+			codes, _, err := s.codes.ListByQuery(ctx, invitecode.ListQuery{Sort: invitecode.SortCode, Offset: offset, Limit: exportPageSize})
+			if err != nil {
+				return nil, err
+			}
+			records := make([]*InviteCodeRecord, len(codes))
+			for i, ic := range codes {
+				records[i] = newInviteCodeRecord(ic)
+			}
+			return records, nil
+		})
+
+	case DatasetUsage:
+		return writeRecords(w, format, usageColumns, func(offset int) ([]*UsageRecord, error) {
+			usage, err := s.codes.ListUsage(ctx, offset, exportPageSize)
+			if err != nil {
+				return nil, err
+			}
+			records := make([]*UsageRecord, len(usage))
+			for i, r := range usage {
+				records[i] = newUsageRecord(r)
+			}
+			return records, nil
+		})
+
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnknownDataset, dataset)
+	}
+}
+
+// writeRecords 分页读取记录并按格式写出
+// next 返回从 offset 开始的一页记录，返回的记录少于一页时结束
+func writeRecords[T any](w io.Writer, format Format, columns []column[T], next func(offset int) ([]*T, error)) (int, error) {
+	bw := bufio.NewWriter(w)
+
+	var write func(r *T) error
+	var finish func() error
+
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(bw)
+		header := make([]string, len(columns))
+		for i, col := range columns {
+			header[i] = col.name
+		}
+		if err := cw.Write(header); err != nil {
+			return 0, fmt.Errorf("write csv header: %w", err)
+		}
+		row := make([]string, len(columns))
+		write = func(r *T) error {
+			for i, col := range columns {
+				row[i] = escapeCSVCell(col.get(r))
+			}
+			return cw.Write(row)
+		}
+		finish = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+
+	case FormatJSON:
+		if _, err := bw.WriteString("["); err != nil {
+			return 0, fmt.Errorf("write json: %w", err)
+		}
+		first := true
+		write = func(r *T) error {
+			data, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+			sep := ",\n"
+			if first {
+				sep = "\n"
+				first = false
+			}
+			if _, err := bw.WriteString(sep); err != nil {
+				return err
+			}
+			_, err = bw.Write(data)
+			return err
+		}
+		finish = func() error {
+			_, err := bw.WriteString("\n]\n")
+			return err
+		}
+
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+
+	count := 0
+	for offset := 0; ; offset += exportPageSize {
+		records, err := next(offset)
+		if err != nil {
+			return count, fmt.Errorf("read records: %w", err)
+		}
+		for _, r := range records {
+			if err := write(r); err != nil {
+				return count, fmt.Errorf("write record: %w", err)
+			}
+			count++
+		}
+		if len(records) < exportPageSize {
+			break
+		}
+	}
+
+	if err := finish(); err != nil {
+		return count, fmt.Errorf("write records: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return count, fmt.Errorf("flush: %w", err)
+	}
+	return count, nil
+}
+
+// csvFormulaPrefixes 表格软件会当作公式解析的单元格开头字符
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// escapeCSVCell 在可能被表格软件当作公式的单元格前加单引号
+// 用户名、Telegram 名字等字段来自用户输入，导出文件在表格中打开时不能执行其中的公式；导入时会去掉该前缀
+func escapeCSVCell(v string) string {
+	if v != "" && strings.ContainsRune(csvFormulaPrefixes, rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
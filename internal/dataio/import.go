@@ -0,0 +1,408 @@
+// Package dataio 导入
+package dataio
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/invitecode"
+	"emby-telegram/internal/user"
+	"emby-telegram/pkg/validator"
+)
+
+// MaxImportRows 单次导入的最大行数
+const MaxImportRows = 10000
+
+// maxInviteCodeLength 邀请码的最大长度，与数据库字段一致
+const maxInviteCodeLength = 20
+
+// Issue 未导入的行及原因
+type Issue struct {
+	Row int    // 数据行序号，从 1 开始，不含 CSV 表头
+	Key string // 行的唯一字段取值，解析失败时可能为空
+	Err error
+}
+
+// ImportReport 导入结果
+type ImportReport struct {
+	Dataset  Dataset
+	DryRun   bool
+	Total    int     // 数据行数
+	Valid    int     // 通过校验且没有冲突的行数
+	Imported int     // 实际写入的行数，试运行时为 0
+	Issues   []Issue // 校验失败、冲突或写入失败的行
+}
+
+// Import 从 r 导入数据集
+// 每行先经过校验和冲突检测，有问题的行跳过并记录到报告中，不影响其他行；
+// dryRun 为 true 时只校验不写入。已存在的数据不会被覆盖
+func (s *Service) Import(ctx context.Context, r io.Reader, dataset Dataset, format Format, dryRun bool) (*ImportReport, error) {
+	report := &ImportReport{Dataset: dataset, DryRun: dryRun}
+
+	var err error
+	switch dataset {
+	case DatasetUsers:
+		seen := make(map[string]bool)
+		err = readRecords(r, format, userColumns, func(row int, rec *UserRecord, parseErr error) error {
+			return s.importRow(report, row, formatInt64(rec.TelegramID), parseErr, func() (func() error, error) {
+				u, err := s.validateUser(ctx, rec, seen)
+				if err != nil {
+					return nil, err
+				}
+				return func() error { return s.users.Import(ctx, u) }, nil
+			})
+		})
+
+	case DatasetAccounts:
+		seen := make(map[string]bool)
+		err = readRecords(r, format, accountColumns, func(row int, rec *AccountRecord, parseErr error) error {
+			return s.importRow(report, row, rec.Username, parseErr, func() (func() error, error) {
+				acc, err := s.validateAccount(ctx, rec, seen)
+				if err != nil {
+					return nil, err
+				}
+				return func() error { return s.accounts.Import(ctx, acc) }, nil
+			})
+		})
+
+	case DatasetInviteCodes:
+		seen := make(map[string]bool)
+		err = readRecords(r, format, inviteCodeColumns, func(row int, rec *InviteCodeRecord, parseErr error) error {
+			return s.importRow(report, row, rec.Code, parseErr, func() (func() error, error) {
+				ic, err := s.validateInviteCode(ctx, rec, seen)
+				if err != nil {
+					return nil, err
+				}
+				return func() error { return s.codes.Import(ctx, ic) }, nil
+			})
+		})
+
+	case DatasetUsage:
+		return nil, fmt.Errorf("%w: %s", ErrImportUnsupported, dataset)
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDataset, dataset)
+	}
+
+	if err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// importRow 校验一行并在非试运行时写入，结果计入报告
+// validate 返回写入函数，校验失败时返回错误
+func (s *Service) importRow(report *ImportReport, row int, key string, parseErr error, validate func() (func() error, error)) error {
+	report.Total++
+	if report.Total > MaxImportRows {
+		return fmt.Errorf("%w: more than %d", ErrTooManyRows, MaxImportRows)
+	}
+
+	if parseErr != nil {
+		report.Issues = append(report.Issues, Issue{Row: row, Key: key, Err: parseErr})
+		return nil
+	}
+
+	commit, err := validate()
+	if err != nil {
+		report.Issues = append(report.Issues, Issue{Row: row, Key: key, Err: err})
+		return nil
+	}
+	report.Valid++
+
+	if report.DryRun {
+		return nil
+	}
+	if err := commit(); err != nil {
+		report.Issues = append(report.Issues, Issue{Row: row, Key: key, Err: err})
+		return nil
+	}
+	report.Imported++
+	return nil
+}
+
+// validateUser 校验用户记录，telegram_id 和 username 不能与已有用户或前面的行重复
+func (s *Service) validateUser(ctx context.Context, rec *UserRecord, seen map[string]bool) (*user.User, error) {
+	if rec.TelegramID <= 0 {
+		return nil, &FieldError{Field: "telegram_id", Value: formatInt64(rec.TelegramID)}
+	}
+	if rec.AccountQuota < 0 {
+		return nil, &FieldError{Field: "account_quota", Value: fmt.Sprint(rec.AccountQuota)}
+	}
+
+	role := user.Role(rec.Role)
+	if role == "" {
+		role = user.RoleUser
+	}
+	if _, err := s.users.Role(ctx, role); err != nil {
+		return nil, err
+	}
+
+	username := strings.TrimPrefix(strings.TrimSpace(rec.Username), "@")
+
+	idKey := "telegram_id:" + formatInt64(rec.TelegramID)
+	if seen[idKey] {
+		return nil, &ConflictError{Field: "telegram_id", Value: formatInt64(rec.TelegramID), InFile: true}
+	}
+	_, err := s.users.GetByTelegramID(ctx, rec.TelegramID)
+	if err = conflict(err, user.ErrNotFound, "telegram_id", formatInt64(rec.TelegramID)); err != nil {
+		return nil, err
+	}
+	if username != "" {
+		nameKey := "username:" + strings.ToLower(username)
+		if seen[nameKey] {
+			return nil, &ConflictError{Field: "username", Value: username, InFile: true}
+		}
+		_, err := s.users.GetByUsername(ctx, username)
+		if err = conflict(err, user.ErrNotFound, "username", username); err != nil {
+			return nil, err
+		}
+		seen[nameKey] = true
+	}
+	seen[idKey] = true
+
+	return &user.User{
+		TelegramID:     rec.TelegramID,
+		Username:       username,
+		FirstName:      rec.FirstName,
+		LastName:       rec.LastName,
+		Role:           role,
+		IsBlocked:      rec.IsBlocked,
+		AccountQuota:   rec.AccountQuota,
+		UsedInviteCode: rec.UsedInviteCode,
+		Language:       rec.Language,
+		CreatedAt:      rec.CreatedAt,
+	}, nil
+}
+
+// validateAccount 校验账号记录，所有者必须已存在，username 和 emby_user_id 不能与已有账号或前面的行重复
+func (s *Service) validateAccount(ctx context.Context, rec *AccountRecord, seen map[string]bool) (*account.Account, error) {
+	username := validator.SanitizeUsername(rec.Username)
+	if err := validator.ValidateUsername(username); err != nil {
+		return nil, err
+	}
+
+	email := validator.SanitizeEmail(rec.Email)
+	if email != "" {
+		if err := validator.ValidateEmail(email); err != nil {
+			return nil, err
+		}
+	}
+
+	status := account.Status(rec.Status)
+	switch status {
+	case "":
+		status = account.StatusActive
+	case account.StatusActive, account.StatusSuspended, account.StatusExpired:
+	default:
+		return nil, &FieldError{Field: "status", Value: rec.Status}
+	}
+
+	if rec.MaxDevices < 0 {
+		return nil, &FieldError{Field: "max_devices", Value: fmt.Sprint(rec.MaxDevices)}
+	}
+	if rec.PolicyTemplateID != 0 {
+		if _, err := s.policies.Get(ctx, rec.PolicyTemplateID); err != nil {
+			return nil, err
+		}
+	}
+
+	owner, err := s.users.GetByTelegramID(ctx, rec.OwnerTelegramID)
+	if err != nil {
+		return nil, err
+	}
+
+	nameKey := "username:" + username
+	if seen[nameKey] {
+		return nil, &ConflictError{Field: "username", Value: username, InFile: true}
+	}
+	_, err = s.accounts.GetByUsername(ctx, username)
+	if err = conflict(err, account.ErrNotFound, "username", username); err != nil {
+		return nil, err
+	}
+	if rec.EmbyUserID != "" {
+		embyKey := "emby_user_id:" + rec.EmbyUserID
+		if seen[embyKey] {
+			return nil, &ConflictError{Field: "emby_user_id", Value: rec.EmbyUserID, InFile: true}
+		}
+		_, err = s.accounts.GetByEmbyUserID(ctx, rec.EmbyUserID)
+		if err = conflict(err, account.ErrNotFound, "emby_user_id", rec.EmbyUserID); err != nil {
+			return nil, err
+		}
+		seen[embyKey] = true
+	}
+	seen[nameKey] = true
+
+	syncStatus := "pending"
+	if rec.EmbyUserID != "" {
+		syncStatus = "synced"
+	}
+
+	return &account.Account{
+		Username:         username,
+		Email:            email,
+		UserID:           owner.ID,
+		Status:           status,
+		ExpireAt:         rec.ExpireAt,
+		MaxDevices:       rec.MaxDevices,
+		PolicyTemplateID: rec.PolicyTemplateID,
+		EmbyUserID:       rec.EmbyUserID,
+		SyncStatus:       syncStatus,
+		CreatedAt:        rec.CreatedAt,
+	}, nil
+}
+
+// validateInviteCode 校验邀请码记录，code 不能与已有邀请码或前面的行重复
+func (s *Service) validateInviteCode(ctx context.Context, rec *InviteCodeRecord, seen map[string]bool) (*invitecode.InviteCode, error) {
+	code := strings.ToUpper(strings.TrimSpace(rec.Code))
+	if code == "" || utf8.RuneCountInString(code) > maxInviteCodeLength {
+		return nil, &FieldError{Field: "code", Value: rec.Code}
+	}
+	if rec.MaxUses < -1 || rec.MaxUses == 0 {
+		return nil, &FieldError{Field: "max_uses", Value: fmt.Sprint(rec.MaxUses)}
+	}
+	if rec.CurrentUses < 0 {
+		return nil, &FieldError{Field: "current_uses", Value: fmt.Sprint(rec.CurrentUses)}
+	}
+
+	status := invitecode.Status(rec.Status)
+	switch status {
+	case "":
+		status = invitecode.StatusActive
+	case invitecode.StatusActive, invitecode.StatusExpired, invitecode.StatusRevoked:
+	default:
+		return nil, &FieldError{Field: "status", Value: rec.Status}
+	}
+
+	if seen[code] {
+		return nil, &ConflictError{Field: "code", Value: code, InFile: true}
+	}
+	_, err := s.codes.GetByCode(ctx, code)
+	if err = conflict(err, invitecode.ErrNotFound, "code", code); err != nil {
+		return nil, err
+	}
+	seen[code] = true
+
+	return &invitecode.InviteCode{
+		Code:        code,
+		MaxUses:     rec.MaxUses,
+		CurrentUses: rec.CurrentUses,
+		Description: rec.Description,
+		ExpireAt:    rec.ExpireAt,
+		Status:      status,
+		CreatedBy:   rec.CreatedBy,
+		CreatedAt:   rec.CreatedAt,
+	}, nil
+}
+
+// conflict 根据已有数据的查询结果判断冲突
+// 查询成功说明取值已被占用，返回 ConflictError；记录不存在时没有冲突；其他查询错误原样返回
+func conflict(lookupErr, notFound error, field, value string) error {
+	switch {
+	case lookupErr == nil:
+		return &ConflictError{Field: field, Value: value}
+	case errors.Is(lookupErr, notFound):
+		return nil
+	default:
+		return lookupErr
+	}
+}
+
+// readRecords 逐行读取记录并调用 visit
+// CSV 按表头匹配列，列的顺序不限，未知的列被忽略；单行解析失败时以 parseErr 传给 visit。
+// JSON 必须是对象数组，格式错误时无法继续读取，直接返回错误
+func readRecords[T any](r io.Reader, format Format, columns []column[T], visit func(row int, rec *T, parseErr error) error) error {
+	switch format {
+	case FormatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+
+		header, err := cr.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("read csv header: %w", err)
+		}
+		index := make(map[int]column[T], len(header))
+		for i, name := range header {
+			name = strings.TrimPrefix(strings.TrimSpace(name), "\ufeff")
+			for _, col := range columns {
+				if col.name == name {
+					index[i] = col
+				}
+			}
+		}
+		if len(index) == 0 {
+			return &FieldError{Field: "header", Value: strings.Join(header, ",")}
+		}
+
+		for row := 1; ; row++ {
+			values, err := cr.Read()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("read csv row %d: %w", row, err)
+			}
+
+			rec := new(T)
+			var parseErr error
+			for i, v := range values {
+				col, ok := index[i]
+				if !ok {
+					continue
+				}
+				if err := col.set(rec, strings.TrimSpace(unescapeCSVCell(v))); err != nil && parseErr == nil {
+					parseErr = err
+				}
+			}
+			if err := visit(row, rec, parseErr); err != nil {
+				return err
+			}
+		}
+
+	case FormatJSON:
+		dec := json.NewDecoder(r)
+		tok, err := dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("read json: %w", err)
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return fmt.Errorf("read json: expected an array of objects")
+		}
+
+		for row := 1; dec.More(); row++ {
+			rec := new(T)
+			if err := dec.Decode(rec); err != nil {
+				return fmt.Errorf("read json row %d: %w", row, err)
+			}
+			if err := visit(row, rec, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
+
+// unescapeCSVCell 去掉导出时为防止公式解析加上的单引号
+func unescapeCSVCell(v string) string {
+	if len(v) > 1 && v[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(v[1])) {
+		return v[1:]
+	}
+	return v
+}
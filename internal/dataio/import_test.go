@@ -0,0 +1,305 @@
+package dataio
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/database"
+	"emby-telegram/internal/invitecode"
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/policy"
+	"emby-telegram/internal/storage"
+	"emby-telegram/internal/user"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init("error", "stderr")
+	os.Exit(m.Run())
+}
+
+// noQuotaUsers 邀请码服务要求的用户接口，导入不会调用
+type noQuotaUsers struct{}
+
+func (noQuotaUsers) Get(context.Context, uint) (invitecode.User, error) {
+	return invitecode.User{}, errors.New("not used")
+}
+func (noQuotaUsers) SetQuota(context.Context, uint, int) error      { return errors.New("not used") }
+func (noQuotaUsers) MarkInviteCodeUsed(context.Context, uint) error { return errors.New("not used") }
+
+// newTestService 基于临时 SQLite 数据库创建导入服务，并写入一个用户和一个已关联 Emby 的账号
+func newTestService(t *testing.T) (*Service, *storage.Stores) {
+	t.Helper()
+
+	stores, err := storage.NewStores("sqlite", filepath.Join(t.TempDir(), "test.db"), false)
+	if err != nil {
+		t.Fatalf("open stores: %v", err)
+	}
+	sqlDB, err := stores.DB.DB()
+	if err != nil {
+		t.Fatalf("get sql db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := database.RunMigrations(sqlDB, "sqlite", "../../migrations", logger.Logger()); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+	// 迁移中的 accounts 表缺少模型的 email 和 last_sync_at 字段
+	for _, stmt := range []string{
+		"ALTER TABLE accounts ADD COLUMN email TEXT",
+		"ALTER TABLE accounts ADD COLUMN last_sync_at TIMESTAMP",
+	} {
+		if _, err := sqlDB.Exec(stmt); err != nil {
+			t.Fatalf("patch accounts schema: %v", err)
+		}
+	}
+
+	users := user.NewService(stores.UserStore, stores.RoleStore, nil)
+	accounts := account.NewService(stores.AccountStore, nil, nil, nil, nil, "", 30, 2, 12, 0, 0, false, false, false)
+	codes := invitecode.NewService(stores.InviteCodeStore, noQuotaUsers{})
+	svc := NewService(users, accounts, codes, policy.NewService(stores.PolicyStore))
+
+	ctx := context.Background()
+	owner := &user.User{TelegramID: 1001, Username: "alice", Role: user.RoleUser}
+	if err := users.Import(ctx, owner); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	if err := accounts.Import(ctx, &account.Account{
+		Username:   "existing",
+		UserID:     owner.ID,
+		Status:     account.StatusActive,
+		EmbyUserID: "emby-1",
+		SyncStatus: "synced",
+	}); err != nil {
+		t.Fatalf("seed account: %v", err)
+	}
+	return svc, stores
+}
+
+func TestImportAccountConflicts(t *testing.T) {
+	const header = "username,owner_telegram_id,emby_user_id\n"
+
+	tests := []struct {
+		name     string
+		csv      string
+		valid    int
+		conflict []ConflictError
+	}{
+		{
+			name:  "no conflict",
+			csv:   header + "fresh,1001,emby-2\nnolink,1001,\n",
+			valid: 2,
+		},
+		{
+			name:     "username exists",
+			csv:      header + "existing,1001,\n",
+			conflict: []ConflictError{{Field: "username", Value: "existing"}},
+		},
+		{
+			name:     "emby_user_id exists",
+			csv:      header + "fresh,1001,emby-1\n",
+			conflict: []ConflictError{{Field: "emby_user_id", Value: "emby-1"}},
+		},
+		{
+			name:     "username repeated in file",
+			csv:      header + "fresh,1001,\nfresh,1001,\n",
+			valid:    1,
+			conflict: []ConflictError{{Field: "username", Value: "fresh", InFile: true}},
+		},
+		{
+			name:     "emby_user_id repeated in file",
+			csv:      header + "first,1001,emby-2\nsecond,1001,emby-2\n",
+			valid:    1,
+			conflict: []ConflictError{{Field: "emby_user_id", Value: "emby-2", InFile: true}},
+		},
+		{
+			name:  "empty emby_user_id is not a conflict",
+			csv:   header + "first,1001,\nsecond,1001,\n",
+			valid: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestService(t)
+
+			report, err := svc.Import(context.Background(), strings.NewReader(tt.csv), DatasetAccounts, FormatCSV, true)
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			if report.Valid != tt.valid {
+				t.Errorf("Valid = %d, want %d (issues: %v)", report.Valid, tt.valid, report.Issues)
+			}
+			if len(report.Issues) != len(tt.conflict) {
+				t.Fatalf("got %d issues, want %d: %v", len(report.Issues), len(tt.conflict), report.Issues)
+			}
+			for i, issue := range report.Issues {
+				var ce *ConflictError
+				if !errors.As(issue.Err, &ce) {
+					t.Fatalf("issue %d error = %v, want ConflictError", i, issue.Err)
+				}
+				if *ce != tt.conflict[i] {
+					t.Errorf("issue %d = %+v, want %+v", i, *ce, tt.conflict[i])
+				}
+			}
+		})
+	}
+}
+
+func TestImportUserConflicts(t *testing.T) {
+	const header = "telegram_id,username\n"
+
+	tests := []struct {
+		name     string
+		csv      string
+		valid    int
+		conflict []ConflictError
+	}{
+		{
+			name:  "no conflict",
+			csv:   header + "2001,bob\n2002,\n",
+			valid: 2,
+		},
+		{
+			name:     "telegram_id exists",
+			csv:      header + "1001,bob\n",
+			conflict: []ConflictError{{Field: "telegram_id", Value: "1001"}},
+		},
+		{
+			name:     "username exists",
+			csv:      header + "2001,alice\n",
+			conflict: []ConflictError{{Field: "username", Value: "alice"}},
+		},
+		{
+			name:     "telegram_id repeated in file",
+			csv:      header + "2001,bob\n2001,carol\n",
+			valid:    1,
+			conflict: []ConflictError{{Field: "telegram_id", Value: "2001", InFile: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestService(t)
+
+			report, err := svc.Import(context.Background(), strings.NewReader(tt.csv), DatasetUsers, FormatCSV, true)
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			if report.Valid != tt.valid {
+				t.Errorf("Valid = %d, want %d (issues: %v)", report.Valid, tt.valid, report.Issues)
+			}
+			if len(report.Issues) != len(tt.conflict) {
+				t.Fatalf("got %d issues, want %d: %v", len(report.Issues), len(tt.conflict), report.Issues)
+			}
+			for i, issue := range report.Issues {
+				var ce *ConflictError
+				if !errors.As(issue.Err, &ce) {
+					t.Fatalf("issue %d error = %v, want ConflictError", i, issue.Err)
+				}
+				if *ce != tt.conflict[i] {
+					t.Errorf("issue %d = %+v, want %+v", i, *ce, tt.conflict[i])
+				}
+			}
+		})
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	const csv = "username,owner_telegram_id,emby_user_id\nfresh,1001,emby-2\nexisting,1001,\n"
+
+	tests := []struct {
+		name     string
+		dryRun   bool
+		imported int
+		stored   bool
+	}{
+		{name: "dry run writes nothing", dryRun: true, imported: 0, stored: false},
+		{name: "import writes valid rows", dryRun: false, imported: 1, stored: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, stores := newTestService(t)
+			ctx := context.Background()
+
+			report, err := svc.Import(ctx, strings.NewReader(csv), DatasetAccounts, FormatCSV, tt.dryRun)
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			if report.DryRun != tt.dryRun || report.Total != 2 || report.Valid != 1 || len(report.Issues) != 1 {
+				t.Errorf("report = %+v, want DryRun=%v Total=2 Valid=1 and one issue", report, tt.dryRun)
+			}
+			if report.Imported != tt.imported {
+				t.Errorf("Imported = %d, want %d", report.Imported, tt.imported)
+			}
+
+			_, err = stores.AccountStore.GetByEmbyUserID(ctx, "emby-2")
+			switch {
+			case tt.stored && err != nil:
+				t.Errorf("imported account not found: %v", err)
+			case !tt.stored && !errors.Is(err, account.ErrNotFound):
+				t.Errorf("GetByEmbyUserID() error = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestImportLookupError(t *testing.T) {
+	svc, stores := newTestService(t)
+	ctx := context.Background()
+
+	sqlDB, err := stores.DB.DB()
+	if err != nil {
+		t.Fatalf("get sql db: %v", err)
+	}
+	if _, err := sqlDB.Exec("DROP TABLE accounts"); err != nil {
+		t.Fatalf("drop accounts: %v", err)
+	}
+
+	report, err := svc.Import(ctx, strings.NewReader("username,owner_telegram_id\nfresh,1001\n"), DatasetAccounts, FormatCSV, true)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if report.Valid != 0 || len(report.Issues) != 1 {
+		t.Fatalf("report = %+v, want the row rejected", report)
+	}
+	var ce *ConflictError
+	if errors.As(report.Issues[0].Err, &ce) || errors.Is(report.Issues[0].Err, account.ErrNotFound) {
+		t.Errorf("issue error = %v, want the lookup error", report.Issues[0].Err)
+	}
+}
+
+func TestCSVCellEscaping(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain text", in: "alice", want: "alice"},
+		{name: "empty", in: "", want: ""},
+		{name: "formula", in: `=HYPERLINK("http://x","y")`, want: `'=HYPERLINK("http://x","y")`},
+		{name: "plus", in: "+1", want: "'+1"},
+		{name: "minus", in: "-1", want: "'-1"},
+		{name: "at", in: "@SUM(A1)", want: "'@SUM(A1)"},
+		{name: "tab", in: "\tx", want: "'\tx"},
+		{name: "carriage return", in: "\rx", want: "'\rx"},
+		{name: "quote not at start", in: "o'neil", want: "o'neil"},
+		{name: "leading quote kept", in: "'quoted", want: "'quoted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := escapeCSVCell(tt.in)
+			if got != tt.want {
+				t.Fatalf("escapeCSVCell(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if back := unescapeCSVCell(got); back != tt.in {
+				t.Errorf("unescapeCSVCell(%q) = %q, want %q", got, back, tt.in)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+// Package dataio 导入导出的记录格式
+package dataio
+
+import (
+	"strconv"
+	"time"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/invitecode"
+	"emby-telegram/internal/user"
+)
+
+// UserRecord 用户记录
+type UserRecord struct {
+	TelegramID     int64     `json:"telegram_id"`
+	Username       string    `json:"username"`
+	FirstName      string    `json:"first_name"`
+	LastName       string    `json:"last_name"`
+	Role           string    `json:"role"`
+	AccountQuota   int       `json:"account_quota"`
+	IsBlocked      bool      `json:"is_blocked"`
+	UsedInviteCode bool      `json:"used_invite_code"`
+	Language       string    `json:"language"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// AccountRecord 账号记录
+// 所有者以 Telegram ID 关联，owner_username 和 owner_first_name 只用于阅读，导入时忽略；
+// 不导出密码，导入的账号以 Emby 中的密码为准
+type AccountRecord struct {
+	Username         string     `json:"username"`
+	Email            string     `json:"email"`
+	OwnerTelegramID  int64      `json:"owner_telegram_id"`
+	OwnerUsername    string     `json:"owner_username"`
+	OwnerFirstName   string     `json:"owner_first_name"`
+	Status           string     `json:"status"`
+	ExpireAt         *time.Time `json:"expire_at"`
+	MaxDevices       int        `json:"max_devices"`
+	PolicyTemplateID uint       `json:"policy_template_id"`
+	EmbyUserID       string     `json:"emby_user_id"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// InviteCodeRecord 邀请码记录
+type InviteCodeRecord struct {
+	Code        string     `json:"code"`
+	MaxUses     int        `json:"max_uses"`
+	CurrentUses int        `json:"current_uses"`
+	Description string     `json:"description"`
+	ExpireAt    *time.Time `json:"expire_at"`
+	Status      string     `json:"status"`
+	CreatedBy   int64      `json:"created_by"` // 创建者的 Telegram ID
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// UsageRecord 邀请码使用记录
+type UsageRecord struct {
+	Code       string    `json:"code"`
+	TelegramID int64     `json:"telegram_id"`
+	UsedAt     time.Time `json:"used_at"`
+}
+
+// column CSV 列，get 用于导出，set 用于导入
+type column[T any] struct {
+	name string
+	get  func(r *T) string
+	set  func(r *T, v string) error
+}
+
+var userColumns = []column[UserRecord]{
+	{"telegram_id", func(r *UserRecord) string { return formatInt64(r.TelegramID) }, func(r *UserRecord, v string) error { return parseInt64("telegram_id", v, &r.TelegramID) }},
+	{"username", func(r *UserRecord) string { return r.Username }, func(r *UserRecord, v string) error { r.Username = v; return nil }},
+	{"first_name", func(r *UserRecord) string { return r.FirstName }, func(r *UserRecord, v string) error { r.FirstName = v; return nil }},
+	{"last_name", func(r *UserRecord) string { return r.LastName }, func(r *UserRecord, v string) error { r.LastName = v; return nil }},
+	{"role", func(r *UserRecord) string { return r.Role }, func(r *UserRecord, v string) error { r.Role = v; return nil }},
+	{"account_quota", func(r *UserRecord) string { return strconv.Itoa(r.AccountQuota) }, func(r *UserRecord, v string) error { return parseInt("account_quota", v, &r.AccountQuota) }},
+	{"is_blocked", func(r *UserRecord) string { return strconv.FormatBool(r.IsBlocked) }, func(r *UserRecord, v string) error { return parseBool("is_blocked", v, &r.IsBlocked) }},
+	{"used_invite_code", func(r *UserRecord) string { return strconv.FormatBool(r.UsedInviteCode) }, func(r *UserRecord, v string) error { return parseBool("used_invite_code", v, &r.UsedInviteCode) }},
+	{"language", func(r *UserRecord) string { return r.Language }, func(r *UserRecord, v string) error { r.Language = v; return nil }},
+	{"created_at", func(r *UserRecord) string { return formatTime(r.CreatedAt) }, func(r *UserRecord, v string) error { return parseTime("created_at", v, &r.CreatedAt) }},
+}
+
+var accountColumns = []column[AccountRecord]{
+	{"username", func(r *AccountRecord) string { return r.Username }, func(r *AccountRecord, v string) error { r.Username = v; return nil }},
+	{"email", func(r *AccountRecord) string { return r.Email }, func(r *AccountRecord, v string) error { r.Email = v; return nil }},
+	{"owner_telegram_id", func(r *AccountRecord) string { return formatInt64(r.OwnerTelegramID) }, func(r *AccountRecord, v string) error { return parseInt64("owner_telegram_id", v, &r.OwnerTelegramID) }},
+	{"owner_username", func(r *AccountRecord) string { return r.OwnerUsername }, func(r *AccountRecord, v string) error { r.OwnerUsername = v; return nil }},
+	{"owner_first_name", func(r *AccountRecord) string { return r.OwnerFirstName }, func(r *AccountRecord, v string) error { r.OwnerFirstName = v; return nil }},
+	{"status", func(r *AccountRecord) string { return r.Status }, func(r *AccountRecord, v string) error { r.Status = v; return nil }},
+	{"expire_at", func(r *AccountRecord) string { return formatTimePtr(r.ExpireAt) }, func(r *AccountRecord, v string) error { return parseTimePtr("expire_at", v, &r.ExpireAt) }},
+	{"max_devices", func(r *AccountRecord) string { return strconv.Itoa(r.MaxDevices) }, func(r *AccountRecord, v string) error { return parseInt("max_devices", v, &r.MaxDevices) }},
+	{"policy_template_id", func(r *AccountRecord) string { return formatUint(r.PolicyTemplateID) }, func(r *AccountRecord, v string) error { return parseUint("policy_template_id", v, &r.PolicyTemplateID) }},
+	{"emby_user_id", func(r *AccountRecord) string { return r.EmbyUserID }, func(r *AccountRecord, v string) error { r.EmbyUserID = v; return nil }},
+	{"created_at", func(r *AccountRecord) string { return formatTime(r.CreatedAt) }, func(r *AccountRecord, v string) error { return parseTime("created_at", v, &r.CreatedAt) }},
+}
+
+var inviteCodeColumns = []column[InviteCodeRecord]{
+	{"code", func(r *InviteCodeRecord) string { return r.Code }, func(r *InviteCodeRecord, v string) error { r.Code = v; return nil }},
+	{"max_uses", func(r *InviteCodeRecord) string { return strconv.Itoa(r.MaxUses) }, func(r *InviteCodeRecord, v string) error { return parseInt("max_uses", v, &r.MaxUses) }},
+	{"current_uses", func(r *InviteCodeRecord) string { return strconv.Itoa(r.CurrentUses) }, func(r *InviteCodeRecord, v string) error { return parseInt("current_uses", v, &r.CurrentUses) }},
+	{"description", func(r *InviteCodeRecord) string { return r.Description }, func(r *InviteCodeRecord, v string) error { r.Description = v; return nil }},
+	{"expire_at", func(r *InviteCodeRecord) string { return formatTimePtr(r.ExpireAt) }, func(r *InviteCodeRecord, v string) error { return parseTimePtr("expire_at", v, &r.ExpireAt) }},
+	{"status", func(r *InviteCodeRecord) string { return r.Status }, func(r *InviteCodeRecord, v string) error { r.Status = v; return nil }},
+	{"created_by", func(r *InviteCodeRecord) string { return formatInt64(r.CreatedBy) }, func(r *InviteCodeRecord, v string) error { return parseInt64("created_by", v, &r.CreatedBy) }},
+	{"created_at", func(r *InviteCodeRecord) string { return formatTime(r.CreatedAt) }, func(r *InviteCodeRecord, v string) error { return parseTime("created_at", v, &r.CreatedAt) }},
+}
+
+var usageColumns = []column[UsageRecord]{
+	{"code", func(r *UsageRecord) string { return r.Code }, nil},
+	{"telegram_id", func(r *UsageRecord) string { return formatInt64(r.TelegramID) }, nil},
+	{"used_at", func(r *UsageRecord) string { return formatTime(r.UsedAt) }, nil},
+}
+
+// newUserRecord 转换为用户记录
+func newUserRecord(u *user.User) *UserRecord {
+	return &UserRecord{
+		TelegramID:     u.TelegramID,
+		Username:       u.Username,
+		FirstName:      u.FirstName,
+		LastName:       u.LastName,
+		Role:           string(u.Role),
+		AccountQuota:   u.AccountQuota,
+		IsBlocked:      u.IsBlocked,
+		UsedInviteCode: u.UsedInviteCode,
+		Language:       u.Language,
+		CreatedAt:      u.CreatedAt,
+	}
+}
+
+// newAccountRecord 转换为账号记录
+func newAccountRecord(acc *account.AccountWithUser) *AccountRecord {
+	return &AccountRecord{
+		Username:         acc.Username,
+		Email:            acc.Email,
+		OwnerTelegramID:  acc.OwnerTelegramID,
+		OwnerUsername:    acc.OwnerUsername,
+		OwnerFirstName:   acc.OwnerFirstName,
+		Status:           string(acc.Status),
+		ExpireAt:         acc.ExpireAt,
+		MaxDevices:       acc.MaxDevices,
+		PolicyTemplateID: acc.PolicyTemplateID,
+		EmbyUserID:       acc.EmbyUserID,
+		CreatedAt:        acc.CreatedAt,
+	}
+}
+
+// newInviteCodeRecord 转换为邀请码记录
+func newInviteCodeRecord(ic *invitecode.InviteCode) *InviteCodeRecord {
+	return &InviteCodeRecord{
+		Code:        ic.Code,
+		MaxUses:     ic.MaxUses,
+		CurrentUses: ic.CurrentUses,
+		Description: ic.Description,
+		ExpireAt:    ic.ExpireAt,
+		Status:      string(ic.Status),
+		CreatedBy:   ic.CreatedBy,
+		CreatedAt:   ic.CreatedAt,
+	}
+}
+
+// newUsageRecord 转换为使用记录
+func newUsageRecord(r *invitecode.UsageRecord) *UsageRecord {
+	return &UsageRecord{
+		Code:       r.Code,
+		TelegramID: r.TelegramID,
+		UsedAt:     r.UsedAt,
+	}
+}
+
+// formatTime 以 RFC 3339 格式输出时间，零值输出空字符串
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// formatTimePtr 输出可为空的时间
+func formatTimePtr(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return formatTime(*t)
+}
+
+func formatInt64(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
+
+func formatUint(v uint) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+// parseTime 解析 RFC 3339 时间，空字符串保留零值
+func parseTime(field, v string, dst *time.Time) error {
+	if v == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return &FieldError{Field: field, Value: v}
+	}
+	*dst = t
+	return nil
+}
+
+// parseTimePtr 解析可为空的时间
+func parseTimePtr(field, v string, dst **time.Time) error {
+	if v == "" {
+		*dst = nil
+		return nil
+	}
+	var t time.Time
+	if err := parseTime(field, v, &t); err != nil {
+		return err
+	}
+	*dst = &t
+	return nil
+}
+
+func parseInt(field, v string, dst *int) error {
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return &FieldError{Field: field, Value: v}
+	}
+	*dst = n
+	return nil
+}
+
+func parseInt64(field, v string, dst *int64) error {
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return &FieldError{Field: field, Value: v}
+	}
+	*dst = n
+	return nil
+}
+
+func parseUint(field, v string, dst *uint) error {
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return &FieldError{Field: field, Value: v}
+	}
+	*dst = uint(n)
+	return nil
+}
+
+func parseBool(field, v string, dst *bool) error {
+	if v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return &FieldError{Field: field, Value: v}
+	}
+	*dst = b
+	return nil
+}
@@ -0,0 +1,152 @@
+// Package emby 用户查询缓存
+package emby
+
+import (
+	"sync"
+	"time"
+)
+
+// userCache ListUsers/GetUser 结果的短时缓存
+// 缓存中保存副本，读取时再复制一份，避免调用方修改策略时污染缓存
+// 每次失效递增 gen，请求发出前记录的版本已过期时不再写入，避免失效前发出的查询写回旧数据
+type userCache struct {
+	mu     sync.RWMutex
+	ttl    time.Duration
+	gen    uint64
+	list   []EmbyUser
+	listAt time.Time
+	users  map[string]cachedUser
+}
+
+type cachedUser struct {
+	user     EmbyUser
+	cachedAt time.Time
+}
+
+// newUserCache 创建用户缓存，ttl <= 0 时返回 nil 表示不缓存
+func newUserCache(ttl time.Duration) *userCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &userCache{
+		ttl:   ttl,
+		users: make(map[string]cachedUser),
+	}
+}
+
+// getList 获取缓存的用户列表
+func (c *userCache) getList() ([]*EmbyUser, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.list == nil || time.Since(c.listAt) > c.ttl {
+		return nil, false
+	}
+
+	users := make([]*EmbyUser, len(c.list))
+	for i := range c.list {
+		u := cloneUser(c.list[i])
+		users[i] = &u
+	}
+	return users, true
+}
+
+// generation 当前缓存版本，在发出查询请求前获取
+func (c *userCache) generation() uint64 {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.gen
+}
+
+// setList 缓存用户列表，同时刷新单个用户缓存
+// gen 为查询前获取的版本，期间缓存已失效时丢弃结果
+func (c *userCache) setList(users []*EmbyUser, gen uint64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if gen != c.gen {
+		return
+	}
+
+	now := time.Now()
+	c.list = make([]EmbyUser, 0, len(users))
+	for _, u := range users {
+		if u == nil {
+			continue
+		}
+		clone := cloneUser(*u)
+		c.list = append(c.list, clone)
+		c.users[u.ID] = cachedUser{user: clone, cachedAt: now}
+	}
+	c.listAt = now
+}
+
+// getUser 获取缓存的单个用户
+func (c *userCache) getUser(userID string) (*EmbyUser, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.users[userID]
+	if !ok || time.Since(entry.cachedAt) > c.ttl {
+		return nil, false
+	}
+
+	u := cloneUser(entry.user)
+	return &u, true
+}
+
+// setUser 缓存单个用户
+// gen 为查询前获取的版本，期间缓存已失效时丢弃结果
+func (c *userCache) setUser(user *EmbyUser, gen uint64) {
+	if c == nil || user == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if gen != c.gen {
+		return
+	}
+
+	c.users[user.ID] = cachedUser{user: cloneUser(*user), cachedAt: time.Now()}
+}
+
+// invalidate 使指定用户及用户列表缓存失效
+// userID 为空时仅使列表失效
+func (c *userCache) invalidate(userID string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	if userID != "" {
+		delete(c.users, userID)
+	}
+	c.list = nil
+}
+
+// cloneUser 深拷贝用户（包括策略中的切片字段）
+func cloneUser(u EmbyUser) EmbyUser {
+	u.Policy = *u.Policy.Clone()
+	return u
+}
@@ -0,0 +1,198 @@
+package emby
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"emby-telegram/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init("error", "stderr")
+	os.Exit(m.Run())
+}
+
+func TestUserCacheGetUser(t *testing.T) {
+	tests := []struct {
+		name  string
+		ttl   time.Duration
+		setup func(c *userCache)
+		want  bool
+	}{
+		{
+			name:  "hit",
+			ttl:   time.Minute,
+			setup: func(c *userCache) { c.setUser(&EmbyUser{ID: "u1"}, c.generation()) },
+			want:  true,
+		},
+		{
+			name: "expired",
+			ttl:  time.Minute,
+			setup: func(c *userCache) {
+				c.setUser(&EmbyUser{ID: "u1"}, c.generation())
+				entry := c.users["u1"]
+				entry.cachedAt = time.Now().Add(-2 * time.Minute)
+				c.users["u1"] = entry
+			},
+			want: false,
+		},
+		{
+			name: "invalidated",
+			ttl:  time.Minute,
+			setup: func(c *userCache) {
+				c.setUser(&EmbyUser{ID: "u1"}, c.generation())
+				c.invalidate("u1")
+			},
+			want: false,
+		},
+		{
+			name: "stale generation discarded",
+			ttl:  time.Minute,
+			setup: func(c *userCache) {
+				gen := c.generation()
+				c.invalidate("u1")
+				c.setUser(&EmbyUser{ID: "u1"}, gen)
+			},
+			want: false,
+		},
+		{
+			name: "other user invalidated",
+			ttl:  time.Minute,
+			setup: func(c *userCache) {
+				c.setUser(&EmbyUser{ID: "u1"}, c.generation())
+				c.invalidate("u2")
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newUserCache(tt.ttl)
+			tt.setup(c)
+			if _, ok := c.getUser("u1"); ok != tt.want {
+				t.Errorf("getUser() ok = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserCacheList(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(c *userCache)
+		want  bool
+	}{
+		{
+			name:  "hit",
+			setup: func(c *userCache) { c.setList([]*EmbyUser{{ID: "u1"}}, c.generation()) },
+			want:  true,
+		},
+		{
+			name: "any invalidation drops list",
+			setup: func(c *userCache) {
+				c.setList([]*EmbyUser{{ID: "u1"}}, c.generation())
+				c.invalidate("u2")
+			},
+			want: false,
+		},
+		{
+			name: "stale generation discarded",
+			setup: func(c *userCache) {
+				gen := c.generation()
+				c.invalidate("")
+				c.setList([]*EmbyUser{{ID: "u1"}}, gen)
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newUserCache(time.Minute)
+			tt.setup(c)
+			if _, ok := c.getList(); ok != tt.want {
+				t.Errorf("getList() ok = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserCacheReturnsCopies(t *testing.T) {
+	c := newUserCache(time.Minute)
+	c.setUser(&EmbyUser{ID: "u1", Policy: UserPolicy{EnabledFolders: []string{"a"}}}, c.generation())
+
+	u, _ := c.getUser("u1")
+	u.Policy.EnabledFolders[0] = "b"
+	u.Policy.IsDisabled = true
+
+	again, _ := c.getUser("u1")
+	if again.Policy.EnabledFolders[0] != "a" || again.Policy.IsDisabled {
+		t.Errorf("cached user modified through returned copy: %+v", again.Policy)
+	}
+}
+
+func TestNilUserCache(t *testing.T) {
+	c := newUserCache(0)
+	if c != nil {
+		t.Fatalf("newUserCache(0) = %v, want nil", c)
+	}
+	c.setUser(&EmbyUser{ID: "u1"}, c.generation())
+	c.invalidate("u1")
+	if _, ok := c.getUser("u1"); ok {
+		t.Error("nil cache returned a user")
+	}
+}
+
+func TestClientPolicyReads(t *testing.T) {
+	tests := []struct {
+		name      string
+		read      func(c *Client) error
+		wantCalls int32
+	}{
+		{
+			name: "cached read hits cache",
+			read: func(c *Client) error {
+				_, err := c.GetUserPolicy(context.Background(), "u1")
+				return err
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "live read bypasses cache",
+			read: func(c *Client) error {
+				_, err := c.GetUserPolicyLive(context.Background(), "u1")
+				return err
+			},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				_ = json.NewEncoder(w).Encode(EmbyUser{ID: "u1", Policy: UserPolicy{SimultaneousStreamLimit: 2}})
+			}))
+			defer srv.Close()
+
+			c := NewClient(srv.URL, "key", 5, 0, true, 0, 0, 0, 60)
+			if _, err := c.GetUser(context.Background(), "u1"); err != nil {
+				t.Fatalf("GetUser() error = %v", err)
+			}
+			if err := tt.read(c); err != nil {
+				t.Fatalf("read error = %v", err)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("requests = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
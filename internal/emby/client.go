@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"emby-telegram/internal/logger"
+	"emby-telegram/pkg/ratelimit"
 )
 
 // Client Emby HTTP 客户端
@@ -22,10 +23,20 @@ type Client struct {
 	httpClient *http.Client
 	enabled    bool
 	retryCount int
+	limiter    *ratelimit.Limiter // 令牌桶限流，nil 表示不限流
+	inflight   chan struct{}      // 最大并发请求数信号量，nil 表示不限制
+	cache      *userCache         // 用户查询缓存，nil 表示不缓存
 }
 
 // NewClient 创建 Emby 客户端实例
-func NewClient(baseURL, apiKey string, timeout int, retryCount int, enabled bool) *Client {
+// rateLimit 为每秒最大请求数(<=0 不限流)，rateBurst 为突发容量，
+// maxConcurrent 为最大并发请求数(<=0 不限制)，cacheTTL 为用户缓存秒数(<=0 不缓存)
+func NewClient(baseURL, apiKey string, timeout int, retryCount int, enabled bool, rateLimit float64, rateBurst, maxConcurrent, cacheTTL int) *Client {
+	var inflight chan struct{}
+	if maxConcurrent > 0 {
+		inflight = make(chan struct{}, maxConcurrent)
+	}
+
 	return &Client{
 		baseURL: baseURL,
 		apiKey:  apiKey,
@@ -34,6 +45,9 @@ func NewClient(baseURL, apiKey string, timeout int, retryCount int, enabled bool
 		},
 		enabled:    enabled,
 		retryCount: retryCount,
+		limiter:    ratelimit.New(rateLimit, rateBurst),
+		inflight:   inflight,
+		cache:      newUserCache(time.Duration(cacheTTL) * time.Second),
 	}
 }
 
@@ -65,6 +79,11 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 		if err == ErrUnauthorized || err == ErrUserNotFound || err == ErrUserAlreadyExists {
 			return err
 		}
+
+		// 上下文已结束（包括等待限流时被取消），不再重试
+		if ctx.Err() != nil {
+			return err
+		}
 	}
 
 	return lastErr
@@ -92,6 +111,10 @@ func (c *Client) doFormRequest(ctx context.Context, method, path string, formDat
 		if err == ErrUnauthorized || err == ErrUserNotFound || err == ErrUserAlreadyExists {
 			return err
 		}
+
+		if ctx.Err() != nil {
+			return err
+		}
 	}
 
 	return lastErr
@@ -125,6 +148,13 @@ func (c *Client) doRequestOnce(ctx context.Context, method, path string, body in
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("X-Emby-Token", c.apiKey)
 
+	// 限流与并发控制
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	// 发送请求
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -173,6 +203,13 @@ func (c *Client) doFormRequestOnce(ctx context.Context, method, path string, for
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("X-Emby-Token", c.apiKey)
 
+	// 限流与并发控制
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	// 发送请求
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
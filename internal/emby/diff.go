@@ -0,0 +1,91 @@
+// Package emby 用户策略比对
+package emby
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// PolicyDiff 策略字段差异
+type PolicyDiff struct {
+	Field    string // UserPolicy 字段名
+	Expected string // 期望值
+	Actual   string // 实际值
+}
+
+// volatilePolicyFields 由 Emby 自行维护、不参与比对的字段
+var volatilePolicyFields = map[string]bool{
+	"LockedOutDate":            true,
+	"InvalidLoginAttemptCount": true,
+	"AuthenticationProviderId": true,
+}
+
+// DiffPolicy 逐字段比对期望策略与实际策略
+// 切片按集合比较，忽略顺序与重复，nil 切片与空切片视为相等；EnableAllFolders 为 true 时忽略 EnabledFolders
+func DiffPolicy(expected, actual *UserPolicy) []PolicyDiff {
+	ev := reflect.ValueOf(expected).Elem()
+	av := reflect.ValueOf(actual).Elem()
+	t := ev.Type()
+
+	var diffs []PolicyDiff
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Name
+		if volatilePolicyFields[name] {
+			continue
+		}
+		if name == "EnabledFolders" && expected.EnableAllFolders && actual.EnableAllFolders {
+			continue
+		}
+
+		e := ev.Field(i)
+		a := av.Field(i)
+		if policyValueEqual(e, a) {
+			continue
+		}
+
+		diffs = append(diffs, PolicyDiff{
+			Field:    name,
+			Expected: formatPolicyValue(e),
+			Actual:   formatPolicyValue(a),
+		})
+	}
+
+	return diffs
+}
+
+func policyValueEqual(a, b reflect.Value) bool {
+	if a.Kind() == reflect.Slice {
+		as, bs := sliceSet(a), sliceSet(b)
+		if len(as) != len(bs) {
+			return false
+		}
+		for item := range as {
+			if !bs[item] {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// sliceSet 将切片转换为集合，Emby 返回的媒体库等列表顺序不固定
+func sliceSet(v reflect.Value) map[any]bool {
+	set := make(map[any]bool, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		set[v.Index(i).Interface()] = true
+	}
+	return set
+}
+
+func formatPolicyValue(v reflect.Value) string {
+	if v.Kind() == reflect.Slice {
+		items := make([]string, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,122 @@
+package emby
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(expected, actual *UserPolicy)
+		want   []string // 有差异的字段
+	}{
+		{
+			name:   "identical",
+			modify: func(e, a *UserPolicy) {},
+		},
+		{
+			name:   "scalar field",
+			modify: func(e, a *UserPolicy) { a.SimultaneousStreamLimit = 5 },
+			want:   []string{"SimultaneousStreamLimit"},
+		},
+		{
+			name: "multiple fields in declaration order",
+			modify: func(e, a *UserPolicy) {
+				a.EnableRemoteAccess = !e.EnableRemoteAccess
+				a.MaxParentalRating = 1
+			},
+			want: []string{"MaxParentalRating", "EnableRemoteAccess"},
+		},
+		{
+			name: "volatile fields ignored",
+			modify: func(e, a *UserPolicy) {
+				a.LockedOutDate = 123
+				a.InvalidLoginAttemptCount = 3
+				a.AuthenticationProviderId = "other"
+			},
+		},
+		{
+			name: "nil and empty slices equal",
+			modify: func(e, a *UserPolicy) {
+				e.BlockedTags = nil
+				a.BlockedTags = []string{}
+			},
+		},
+		{
+			name: "folders compared as sets",
+			modify: func(e, a *UserPolicy) {
+				e.EnableAllFolders, a.EnableAllFolders = false, false
+				e.EnabledFolders = []string{"a", "b"}
+				a.EnabledFolders = []string{"b", "a"}
+			},
+		},
+		{
+			name: "duplicate folders ignored",
+			modify: func(e, a *UserPolicy) {
+				e.EnableAllFolders, a.EnableAllFolders = false, false
+				e.EnabledFolders = []string{"a", "b"}
+				a.EnabledFolders = []string{"a", "b", "a"}
+			},
+		},
+		{
+			name: "different folders",
+			modify: func(e, a *UserPolicy) {
+				e.EnableAllFolders, a.EnableAllFolders = false, false
+				e.EnabledFolders = []string{"a", "b"}
+				a.EnabledFolders = []string{"a", "c"}
+			},
+			want: []string{"EnabledFolders"},
+		},
+		{
+			name: "folders ignored when all folders enabled",
+			modify: func(e, a *UserPolicy) {
+				e.EnabledFolders = []string{"a"}
+				a.EnabledFolders = []string{"b"}
+			},
+		},
+		{
+			name: "other slices compared as sets",
+			modify: func(e, a *UserPolicy) {
+				e.BlockedTags = []string{"x", "y"}
+				a.BlockedTags = []string{"y", "x"}
+				a.EnabledDevices = []string{"d1"}
+			},
+			want: []string{"EnabledDevices"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := CreateDefaultPolicy(2)
+			actual := expected.Clone()
+			tt.modify(expected, actual)
+
+			var got []string
+			for _, d := range DiffPolicy(expected, actual) {
+				got = append(got, d.Field)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffPolicy() fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffPolicyValues(t *testing.T) {
+	expected := CreateDefaultPolicy(2)
+	actual := expected.Clone()
+	actual.SimultaneousStreamLimit = 3
+	actual.EnableAllFolders = false
+	expected.EnableAllFolders = false
+	expected.EnabledFolders = []string{"a"}
+	actual.EnabledFolders = []string{"a", "b"}
+
+	want := []PolicyDiff{
+		{Field: "EnabledFolders", Expected: "[a]", Actual: "[a,b]"},
+		{Field: "SimultaneousStreamLimit", Expected: "2", Actual: "3"},
+	}
+	if got := DiffPolicy(expected, actual); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffPolicy() = %+v, want %+v", got, want)
+	}
+}
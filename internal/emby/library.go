@@ -0,0 +1,19 @@
+// Package emby 媒体库 API
+package emby
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ListMediaFolders 列出服务器上的所有媒体库
+func (c *Client) ListMediaFolders(ctx context.Context) ([]*VirtualFolder, error) {
+	var folders []*VirtualFolder
+
+	if err := c.doRequest(ctx, http.MethodGet, "/Library/VirtualFolders", nil, &folders); err != nil {
+		return nil, fmt.Errorf("list media folders: %w", err)
+	}
+
+	return folders, nil
+}
@@ -0,0 +1,25 @@
+// Package emby 请求限流与并发控制
+package emby
+
+import (
+	"context"
+)
+
+// acquire 获取请求许可：先等待令牌，再占用并发槽位
+// 返回的 release 必须在请求结束后调用
+func (c *Client) acquire(ctx context.Context) (func(), error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if c.inflight == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case c.inflight <- struct{}{}:
+		return func() { <-c.inflight }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
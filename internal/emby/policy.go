@@ -20,9 +20,21 @@ func (c *Client) GetUserPolicy(ctx context.Context, userID string) (*UserPolicy,
 	return &user.Policy, nil
 }
 
+// GetUserPolicyLive 绕过缓存获取用户策略
+// 读取策略后修改再写回时使用，保证基于 Emby 上的最新策略修改
+func (c *Client) GetUserPolicyLive(ctx context.Context, userID string) (*UserPolicy, error) {
+	user, err := c.fetchUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user policy: %w", err)
+	}
+
+	return &user.Policy, nil
+}
+
 // UpdateUserPolicy 更新用户策略
 func (c *Client) UpdateUserPolicy(ctx context.Context, userID string, policy *UserPolicy) error {
 	path := fmt.Sprintf("/Users/%s/Policy", userID)
+	defer c.cache.invalidate(userID)
 
 	if err := c.doRequest(ctx, http.MethodPost, path, policy, nil); err != nil {
 		return fmt.Errorf("update user policy: %w", err)
@@ -34,7 +46,7 @@ func (c *Client) UpdateUserPolicy(ctx context.Context, userID string, policy *Us
 // SetMaxActiveSessions 设置最大活动会话数(设备数)
 // 使用 SimultaneousStreamLimit 字段来限制设备数
 func (c *Client) SetMaxActiveSessions(ctx context.Context, userID string, maxSessions int) error {
-	policy, err := c.GetUserPolicy(ctx, userID)
+	policy, err := c.GetUserPolicyLive(ctx, userID)
 	if err != nil {
 		return err
 	}
@@ -46,18 +58,12 @@ func (c *Client) SetMaxActiveSessions(ctx context.Context, userID string, maxSes
 
 // SetMediaLibraryAccess 设置媒体库访问权限
 func (c *Client) SetMediaLibraryAccess(ctx context.Context, userID string, folderIDs []string) error {
-	policy, err := c.GetUserPolicy(ctx, userID)
+	policy, err := c.GetUserPolicyLive(ctx, userID)
 	if err != nil {
 		return err
 	}
 
-	if len(folderIDs) == 0 {
-		policy.EnableAllFolders = true
-		policy.EnabledFolders = []string{}
-	} else {
-		policy.EnableAllFolders = false
-		policy.EnabledFolders = folderIDs
-	}
+	policy.SetFolders(folderIDs)
 
 	return c.UpdateUserPolicy(ctx, userID, policy)
 }
@@ -111,13 +117,18 @@ func CreateDefaultPolicy(maxDevices int) *UserPolicy {
 	}
 }
 
-func (c *Client) BatchUpdateNonAdminPolicies(ctx context.Context) (int, int, error) {
-	users, err := c.ListUsers(ctx)
+// BatchUpdateNonAdminPolicies 将策略批量应用到所有非管理员用户
+// base 为 nil 时使用 CreateDefaultPolicy，每个用户保留自己的设备数和家长控制评级
+// adjust 在推送前对每个用户的策略做最后调整(如应用本地保存的账号设置)，可为 nil
+// 用户列表绕过缓存读取，保留的字段来自 Emby 上的最新策略
+// 返回推送成功的用户 ID 和失败的数量
+func (c *Client) BatchUpdateNonAdminPolicies(ctx context.Context, base *UserPolicy, adjust func(userID string, p *UserPolicy)) ([]string, int, error) {
+	users, err := c.fetchUsers(ctx)
 	if err != nil {
-		return 0, 0, fmt.Errorf("get all users: %w", err)
+		return nil, 0, fmt.Errorf("get all users: %w", err)
 	}
 
-	updated := 0
+	var updated []string
 	failed := 0
 
 	for _, user := range users {
@@ -125,14 +136,24 @@ func (c *Client) BatchUpdateNonAdminPolicies(ctx context.Context) (int, int, err
 			continue
 		}
 
-		policy := CreateDefaultPolicy(int(user.Policy.SimultaneousStreamLimit))
+		var policy *UserPolicy
+		if base != nil {
+			policy = base.Clone()
+			policy.SimultaneousStreamLimit = user.Policy.SimultaneousStreamLimit
+		} else {
+			policy = CreateDefaultPolicy(int(user.Policy.SimultaneousStreamLimit))
+		}
 		policy.MaxParentalRating = user.Policy.MaxParentalRating
+		policy.IsDisabled = user.Policy.IsDisabled
+		if adjust != nil {
+			adjust(user.ID, policy)
+		}
 
 		if err := c.UpdateUserPolicy(ctx, user.ID, policy); err != nil {
 			failed++
 			continue
 		}
-		updated++
+		updated = append(updated, user.ID)
 	}
 
 	return updated, failed, nil
@@ -26,6 +26,7 @@ func (c *Client) CreateUser(ctx context.Context, name, password string) (*EmbyUs
 	}
 
 	logger.Infof("emby user created: %s (id: %s)", user.Name, user.ID)
+	c.cache.invalidate(user.ID)
 
 	// 检查密码是否已成功设置
 	if password != "" && !user.HasPassword && !user.HasConfiguredPassword {
@@ -40,7 +41,7 @@ func (c *Client) CreateUser(ctx context.Context, name, password string) (*EmbyUs
 		logger.Infof("password set successfully for user: %s", user.Name)
 
 		// 重新获取用户信息以验证密码是否已设置
-		updatedUser, err := c.GetUser(ctx, user.ID)
+		updatedUser, err := c.fetchUser(ctx, user.ID)
 		if err != nil {
 			logger.Warnf("failed to re-fetch user info for verification: %v", err)
 		} else {
@@ -53,7 +54,19 @@ func (c *Client) CreateUser(ctx context.Context, name, password string) (*EmbyUs
 }
 
 // GetUser 根据 ID 获取用户
+// 结果会被短时缓存，写操作会使对应缓存失效
 func (c *Client) GetUser(ctx context.Context, userID string) (*EmbyUser, error) {
+	if cached, ok := c.cache.getUser(userID); ok {
+		return cached, nil
+	}
+	return c.fetchUser(ctx, userID)
+}
+
+// fetchUser 绕过缓存从 Emby 获取用户，结果写回缓存
+// 读取后修改再写回的操作必须使用此方法，避免基于过期的策略覆盖 Emby 上的修改
+func (c *Client) fetchUser(ctx context.Context, userID string) (*EmbyUser, error) {
+	gen := c.cache.generation()
+
 	var user EmbyUser
 	path := fmt.Sprintf("/Users/%s", userID)
 
@@ -61,17 +74,30 @@ func (c *Client) GetUser(ctx context.Context, userID string) (*EmbyUser, error)
 		return nil, fmt.Errorf("get emby user: %w", err)
 	}
 
+	c.cache.setUser(&user, gen)
 	return &user, nil
 }
 
 // ListUsers 列出所有用户
+// 结果会被短时缓存，写操作会使缓存失效
 func (c *Client) ListUsers(ctx context.Context) ([]*EmbyUser, error) {
+	if cached, ok := c.cache.getList(); ok {
+		return cached, nil
+	}
+	return c.fetchUsers(ctx)
+}
+
+// fetchUsers 绕过缓存从 Emby 获取用户列表，结果写回缓存
+func (c *Client) fetchUsers(ctx context.Context) ([]*EmbyUser, error) {
+	gen := c.cache.generation()
+
 	var users []*EmbyUser
 
 	if err := c.doRequest(ctx, http.MethodGet, "/Users", nil, &users); err != nil {
 		return nil, fmt.Errorf("list emby users: %w", err)
 	}
 
+	c.cache.setList(users, gen)
 	return users, nil
 }
 
@@ -79,6 +105,8 @@ func (c *Client) ListUsers(ctx context.Context) ([]*EmbyUser, error) {
 func (c *Client) DeleteUser(ctx context.Context, userID string) error {
 	path := fmt.Sprintf("/Users/%s", userID)
 
+	defer c.cache.invalidate(userID)
+
 	if err := c.doRequest(ctx, http.MethodDelete, path, nil, nil); err != nil {
 		return fmt.Errorf("delete emby user: %w", err)
 	}
@@ -95,6 +123,7 @@ func (c *Client) UpdatePassword(ctx context.Context, userID, newPassword string)
 	formData.Set("NewPw", newPassword)
 
 	path := fmt.Sprintf("/Users/%s/Password", userID)
+	defer c.cache.invalidate(userID)
 
 	// 使用 form-urlencoded 格式发送请求
 	if err := c.doFormRequest(ctx, http.MethodPost, path, formData, nil); err != nil {
@@ -112,10 +141,10 @@ func (c *Client) AuthenticateUser(ctx context.Context, username, password string
 	}
 
 	var authResponse struct {
-		User         EmbyUser `json:"User"`
-		SessionInfo  map[string]interface{} `json:"SessionInfo"`
-		AccessToken  string `json:"AccessToken"`
-		ServerId     string `json:"ServerId"`
+		User        EmbyUser               `json:"User"`
+		SessionInfo map[string]interface{} `json:"SessionInfo"`
+		AccessToken string                 `json:"AccessToken"`
+		ServerId    string                 `json:"ServerId"`
 	}
 
 	if err := c.doRequest(ctx, http.MethodPost, "/Users/AuthenticateByName", req, &authResponse); err != nil {
@@ -143,26 +172,24 @@ func (c *Client) GetUserByName(ctx context.Context, name string) (*EmbyUser, err
 
 // DisableUser 禁用用户(通过更新策略)
 func (c *Client) DisableUser(ctx context.Context, userID string) error {
-	user, err := c.GetUser(ctx, userID)
+	policy, err := c.GetUserPolicyLive(ctx, userID)
 	if err != nil {
 		return err
 	}
 
-	policy := user.Policy
 	policy.IsDisabled = true
 
-	return c.UpdateUserPolicy(ctx, userID, &policy)
+	return c.UpdateUserPolicy(ctx, userID, policy)
 }
 
 // EnableUser 启用用户
 func (c *Client) EnableUser(ctx context.Context, userID string) error {
-	user, err := c.GetUser(ctx, userID)
+	policy, err := c.GetUserPolicyLive(ctx, userID)
 	if err != nil {
 		return err
 	}
 
-	policy := user.Policy
 	policy.IsDisabled = false
 
-	return c.UpdateUserPolicy(ctx, userID, &policy)
+	return c.UpdateUserPolicy(ctx, userID, policy)
 }
@@ -0,0 +1,58 @@
+// Package httpserver 内置 HTTP 服务
+// Mini App 等页面和接口挂载在同一个监听地址上，由反向代理对外提供 HTTPS
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"emby-telegram/internal/logger"
+)
+
+// Server 内置 HTTP 服务
+type Server struct {
+	mux    *http.ServeMux
+	server *http.Server
+}
+
+// New 创建 HTTP 服务
+func New(listen string) *Server {
+	mux := http.NewServeMux()
+	return &Server{
+		mux: mux,
+		server: &http.Server{
+			Addr:              listen,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// Handle 挂载处理器，pattern 语法与 http.ServeMux 相同
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Start 开始监听并在后台处理请求，监听失败时直接返回错误
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", s.server.Addr, err)
+	}
+
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("http server error: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown 停止接收新请求并等待处理中的请求完成
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
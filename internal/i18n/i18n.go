@@ -0,0 +1,165 @@
+// Package i18n 提供多语言消息目录
+// 每种语言一个 YAML 文件(locales/<语言代码>.yaml)，键为消息 ID，值为 fmt 格式的消息模板
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+//go:embed locales/*.yaml
+var localeFS embed.FS
+
+// FallbackLanguage 消息缺失时回退的语言，其目录包含全部消息
+const FallbackLanguage = "zh"
+
+// Catalog 消息目录
+type Catalog struct {
+	messages        map[string]map[string]string // 语言 -> 消息 ID -> 模板
+	languages       []string
+	defaultLanguage string
+}
+
+// Load 加载内置的消息目录
+// defaultLanguage 为无法识别用户语言时使用的语言
+func Load(defaultLanguage string) (*Catalog, error) {
+	entries, err := localeFS.ReadDir("locales")
+	if err != nil {
+		return nil, fmt.Errorf("read locales: %w", err)
+	}
+
+	c := &Catalog{messages: make(map[string]map[string]string)}
+	for _, entry := range entries {
+		name := entry.Name()
+		lang := strings.TrimSuffix(name, path.Ext(name))
+
+		data, err := localeFS.ReadFile("locales/" + name)
+		if err != nil {
+			return nil, fmt.Errorf("read locale %s: %w", lang, err)
+		}
+
+		messages := make(map[string]string)
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("parse locale %s: %w", lang, err)
+		}
+
+		c.messages[lang] = messages
+		c.languages = append(c.languages, lang)
+	}
+	sort.Strings(c.languages)
+
+	if _, ok := c.messages[FallbackLanguage]; !ok {
+		return nil, fmt.Errorf("fallback locale %q not found", FallbackLanguage)
+	}
+
+	if defaultLanguage == "" {
+		defaultLanguage = FallbackLanguage
+	}
+	if !c.Supports(defaultLanguage) {
+		return nil, fmt.Errorf("unsupported default language %q, available: %s", defaultLanguage, strings.Join(c.languages, ", "))
+	}
+	c.defaultLanguage = defaultLanguage
+
+	return c, nil
+}
+
+// Languages 返回支持的语言代码
+func (c *Catalog) Languages() []string {
+	return c.languages
+}
+
+// DefaultLanguage 返回默认语言
+func (c *Catalog) DefaultLanguage() string {
+	return c.defaultLanguage
+}
+
+// Supports 检查是否支持指定语言
+func (c *Catalog) Supports(lang string) bool {
+	_, ok := c.messages[lang]
+	return ok
+}
+
+// Match 根据 Telegram 客户端的语言代码(IETF 标签，如 en-US、zh-hans)选择语言
+// 不支持时返回默认语言
+func (c *Catalog) Match(languageCode string) string {
+	code := strings.ToLower(languageCode)
+	if c.Supports(code) {
+		return code
+	}
+	if i := strings.IndexAny(code, "-_"); i > 0 && c.Supports(code[:i]) {
+		return code[:i]
+	}
+	return c.defaultLanguage
+}
+
+// T 翻译消息，有参数时按 fmt 格式化
+// 语言中缺少的消息回退到 FallbackLanguage，仍缺失时返回消息 ID
+func (c *Catalog) T(lang, key string, args ...any) string {
+	tmpl, ok := c.messages[lang][key]
+	if !ok {
+		if tmpl, ok = c.messages[FallbackLanguage][key]; !ok {
+			return key
+		}
+	}
+
+	if len(args) == 0 {
+		return tmpl
+	}
+	return fmt.Sprintf(tmpl, args...)
+}
+
+// KeyOf 在所有语言中查找与文本完全一致的消息，返回其 ID
+// 只在 keys 范围内查找，用于将回复键盘的按钮文本还原为按钮 ID
+func (c *Catalog) KeyOf(text string, keys ...string) (string, bool) {
+	for _, key := range keys {
+		for _, lang := range c.languages {
+			if c.messages[lang][key] == text {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Prefix 返回 ID 以 prefix 开头的全部消息模板，用于把一组消息交给前端页面
+// 语言中缺少的消息回退到 FallbackLanguage
+func (c *Catalog) Prefix(lang, prefix string) map[string]string {
+	result := make(map[string]string)
+	for _, l := range []string{FallbackLanguage, lang} {
+		for key, tmpl := range c.messages[l] {
+			if strings.HasPrefix(key, prefix) {
+				result[key] = tmpl
+			}
+		}
+	}
+	return result
+}
+
+// Localizer 绑定了语言的翻译器
+type Localizer struct {
+	catalog *Catalog
+	lang    string
+}
+
+// Localizer 创建指定语言的翻译器
+func (c *Catalog) Localizer(lang string) *Localizer {
+	if !c.Supports(lang) {
+		lang = c.defaultLanguage
+	}
+	return &Localizer{catalog: c, lang: lang}
+}
+
+// Language 返回翻译器的语言
+func (l *Localizer) Language() string {
+	return l.lang
+}
+
+// T 翻译消息
+func (l *Localizer) T(key string, args ...any) string {
+	return l.catalog.T(l.lang, key, args...)
+}
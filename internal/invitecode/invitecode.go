@@ -75,6 +75,15 @@ func (InviteCodeUsage) TableName() string {
 	return "invite_code_usage"
 }
 
+type UsageRecord struct {
+	ID           uint      `json:"id"`
+	InviteCodeID uint      `json:"invite_code_id"`
+	Code         string    `json:"code"`
+	UserID       uint      `json:"user_id"`
+	TelegramID   int64     `json:"telegram_id"`
+	UsedAt       time.Time `json:"used_at"`
+}
+
 type InviteCodeWithUsage struct {
 	*InviteCode
 	UsageRecords []*InviteCodeUsage `json:"usage_records"`
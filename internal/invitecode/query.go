@@ -0,0 +1,27 @@
+package invitecode
+
+type Filter string
+
+const (
+	FilterAll       Filter = ""
+	FilterActive    Filter = "active"
+	FilterExpired   Filter = "expired"
+	FilterExhausted Filter = "exhausted"
+	FilterRevoked   Filter = "revoked"
+)
+
+type Sort string
+
+const (
+	SortCreated Sort = ""
+	SortExpiry  Sort = "expiry"
+	SortCode    Sort = "code"
+)
+
+type ListQuery struct {
+	Filter Filter
+	Sort   Sort
+	Search string
+	Offset int
+	Limit  int
+}
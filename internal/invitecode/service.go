@@ -181,6 +181,29 @@ func (s *Service) List(ctx context.Context, offset, limit int) ([]*InviteCode, e
 	return codes, nil
 }
 
+func (s *Service) ListByQuery(ctx context.Context, q ListQuery) ([]*InviteCode, int64, error) {
+	codes, total, err := s.store.ListByQuery(ctx, q)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list invite codes: %w", err)
+	}
+	return codes, total, nil
+}
+
+func (s *Service) ListUsage(ctx context.Context, offset, limit int) ([]*UsageRecord, error) {
+	records, err := s.store.ListUsage(ctx, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list invite code usage: %w", err)
+	}
+	return records, nil
+}
+
+func (s *Service) Import(ctx context.Context, inviteCode *InviteCode) error {
+	if err := s.store.Create(ctx, inviteCode); err != nil {
+		return fmt.Errorf("import invite code: %w", err)
+	}
+	return nil
+}
+
 func (s *Service) Count(ctx context.Context) (int64, error) {
 	count, err := s.store.Count(ctx)
 	if err != nil {
@@ -10,6 +10,8 @@ type Store interface {
 	Update(ctx context.Context, inviteCode *InviteCode) error
 	List(ctx context.Context, offset, limit int) ([]*InviteCode, error)
 	Count(ctx context.Context) (int64, error)
+	ListByQuery(ctx context.Context, q ListQuery) ([]*InviteCode, int64, error)
 	RecordUsage(ctx context.Context, usage *InviteCodeUsage) error
 	GetUsageByUser(ctx context.Context, userID uint) (*InviteCodeUsage, error)
+	ListUsage(ctx context.Context, offset, limit int) ([]*UsageRecord, error)
 }
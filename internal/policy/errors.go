@@ -0,0 +1,42 @@
+// Package policy 领域错误定义
+package policy
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 领域错误定义
+var (
+	// ErrNotFound 模板不存在
+	ErrNotFound = errors.New("policy template not found")
+
+	// ErrAlreadyExists 模板已存在
+	ErrAlreadyExists = errors.New("policy template already exists")
+
+	// ErrInvalidInput 无效输入
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrDeleteDefault 不能删除默认模板
+	ErrDeleteDefault = errors.New("cannot delete default policy template")
+)
+
+// NotFoundError 创建模板不存在错误
+func NotFoundError(name string) error {
+	return fmt.Errorf("policy template %q: %w", name, ErrNotFound)
+}
+
+// AlreadyExistsError 创建模板已存在错误
+func AlreadyExistsError(name string) error {
+	return fmt.Errorf("policy template %q: %w", name, ErrAlreadyExists)
+}
+
+// ValidationError 创建验证错误
+func ValidationError(field, reason string) error {
+	return fmt.Errorf("validation failed for %s: %s: %w", field, reason, ErrInvalidInput)
+}
+
+// InvalidFieldError 包装字段验证失败的原因
+func InvalidFieldError(field string, cause error) error {
+	return fmt.Errorf("validation failed for %s: %w: %w", field, cause, ErrInvalidInput)
+}
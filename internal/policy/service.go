@@ -0,0 +1,168 @@
+// Package policy 策略模板业务服务
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"emby-telegram/internal/emby"
+	"emby-telegram/internal/logger"
+	"emby-telegram/pkg/validator"
+)
+
+// Service 策略模板业务服务
+type Service struct {
+	store Store
+}
+
+// NewService 创建策略模板服务实例
+func NewService(store Store) *Service {
+	if store == nil {
+		panic("policy.NewService: store cannot be nil")
+	}
+	return &Service{store: store}
+}
+
+// List 列出所有模板
+func (s *Service) List(ctx context.Context) ([]*Template, error) {
+	tpls, err := s.store.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list policy templates: %w", err)
+	}
+	return tpls, nil
+}
+
+// Get 根据 ID 获取模板
+func (s *Service) Get(ctx context.Context, id uint) (*Template, error) {
+	tpl, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get policy template: %w", err)
+	}
+	return tpl, nil
+}
+
+// GetByName 根据名称获取模板
+func (s *Service) GetByName(ctx context.Context, name string) (*Template, error) {
+	name = strings.TrimSpace(name)
+
+	tpl, err := s.store.GetByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, NotFoundError(name)
+		}
+		return nil, fmt.Errorf("get policy template: %w", err)
+	}
+	return tpl, nil
+}
+
+// Default 获取默认模板，数据库中没有默认模板时返回内置模板
+func (s *Service) Default(ctx context.Context) (*Template, error) {
+	tpl, err := s.store.GetDefault(ctx)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Builtin(), nil
+		}
+		return nil, fmt.Errorf("get default policy template: %w", err)
+	}
+	return tpl, nil
+}
+
+// Resolve 解析账号使用的模板
+// id 为 0 或模板已被删除时回退到默认模板
+func (s *Service) Resolve(ctx context.Context, id uint) (*Template, error) {
+	if id == 0 {
+		return s.Default(ctx)
+	}
+
+	tpl, err := s.store.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			logger.Warnf("policy template %d not found, falling back to default", id)
+			return s.Default(ctx)
+		}
+		return nil, fmt.Errorf("get policy template: %w", err)
+	}
+	return tpl, nil
+}
+
+// BuildPolicy 根据模板生成完整的 Emby 用户策略
+func (s *Service) BuildPolicy(ctx context.Context, templateID uint, maxDevices int) (*emby.UserPolicy, error) {
+	tpl, err := s.Resolve(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	return tpl.Build(maxDevices), nil
+}
+
+// Clone 复制模板
+func (s *Service) Clone(ctx context.Context, id uint, name string) (*Template, error) {
+	name = strings.TrimSpace(name)
+	if err := validator.ValidateTemplateName(name); err != nil {
+		return nil, InvalidFieldError("name", err)
+	}
+
+	if _, err := s.store.GetByName(ctx, name); err == nil {
+		return nil, AlreadyExistsError(name)
+	}
+
+	src, err := s.Resolve(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	tpl := src.CloneAs(name)
+	if err := s.store.Create(ctx, tpl); err != nil {
+		return nil, fmt.Errorf("create policy template: %w", err)
+	}
+
+	logger.Infof("policy template cloned: %s -> %s", src.Name, tpl.Name)
+	return tpl, nil
+}
+
+// Update 更新模板
+func (s *Service) Update(ctx context.Context, tpl *Template) error {
+	if tpl.BitrateLimit < 0 {
+		return ValidationError("bitrate_limit", "码率上限不能为负数")
+	}
+	if tpl.MaxParentalRating < 0 {
+		return ValidationError("max_parental_rating", "评级不能为负数")
+	}
+
+	if err := s.store.Update(ctx, tpl); err != nil {
+		return fmt.Errorf("update policy template: %w", err)
+	}
+	return nil
+}
+
+// SetDefault 设置默认模板
+func (s *Service) SetDefault(ctx context.Context, id uint) error {
+	if _, err := s.store.Get(ctx, id); err != nil {
+		return fmt.Errorf("get policy template: %w", err)
+	}
+
+	if err := s.store.SetDefault(ctx, id); err != nil {
+		return fmt.Errorf("set default policy template: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除模板（默认模板不可删除）
+func (s *Service) Delete(ctx context.Context, id uint) error {
+	tpl, err := s.store.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get policy template: %w", err)
+	}
+
+	if tpl.IsDefault {
+		return ErrDeleteDefault
+	}
+
+	if err := s.store.Delete(ctx, id); err != nil {
+		return fmt.Errorf("delete policy template: %w", err)
+	}
+
+	logger.Infof("policy template deleted: %s", tpl.Name)
+	return nil
+}
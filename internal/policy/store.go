@@ -0,0 +1,31 @@
+// Package policy 存储接口定义
+package policy
+
+import "context"
+
+// Store 策略模板存储接口
+type Store interface {
+	// Create 创建模板
+	Create(ctx context.Context, tpl *Template) error
+
+	// Get 根据 ID 获取模板
+	Get(ctx context.Context, id uint) (*Template, error)
+
+	// GetByName 根据名称获取模板
+	GetByName(ctx context.Context, name string) (*Template, error)
+
+	// GetDefault 获取默认模板
+	GetDefault(ctx context.Context) (*Template, error)
+
+	// List 列出所有模板
+	List(ctx context.Context) ([]*Template, error)
+
+	// Update 更新模板
+	Update(ctx context.Context, tpl *Template) error
+
+	// SetDefault 将指定模板设为默认(同时取消其他模板的默认标记)
+	SetDefault(ctx context.Context, id uint) error
+
+	// Delete 删除模板
+	Delete(ctx context.Context, id uint) error
+}
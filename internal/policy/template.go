@@ -0,0 +1,96 @@
+// Package policy 提供 Emby 用户策略模板领域模型和业务逻辑
+package policy
+
+import (
+	"time"
+
+	"emby-telegram/internal/emby"
+)
+
+// DefaultTemplateName 内置默认模板名称
+const DefaultTemplateName = "default"
+
+// Template 策略模板实体
+// 只保存常用的关键字段，其余字段沿用 emby.CreateDefaultPolicy 的安全默认值
+// 布尔和数值字段不设置 gorm default，避免零值在创建时被默认值替换
+type Template struct {
+	ID                 uint      `gorm:"primarykey" json:"id"`
+	Name               string    `gorm:"uniqueIndex;size:50;not null" json:"name"`
+	Description        string    `gorm:"size:200" json:"description"`
+	IsDefault          bool      `gorm:"not null" json:"is_default"`
+	EnableTranscoding  bool      `gorm:"not null" json:"enable_transcoding"`               // 音视频转码及重新封装
+	EnableDownloads    bool      `gorm:"not null" json:"enable_downloads"`                 // 内容下载及字幕下载
+	EnableRemoteAccess bool      `gorm:"not null" json:"enable_remote_access"`             // 远程访问
+	BitrateLimit       int       `gorm:"not null" json:"bitrate_limit"`                    // 远程码率上限(bps)，0 表示不限制
+	MaxParentalRating  int       `gorm:"not null" json:"max_parental_rating"`              // 家长控制评级
+	EnableAllFolders   bool      `gorm:"not null" json:"enable_all_folders"`               // 允许访问全部媒体库
+	EnabledFolders     []string  `gorm:"serializer:json;type:text" json:"enabled_folders"` // 允许访问的媒体库 ID
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Template) TableName() string {
+	return "policy_templates"
+}
+
+// Builtin 返回内置默认模板，与 emby.CreateDefaultPolicy 的取值一致
+// 数据库中没有任何模板时作为兜底
+func Builtin() *Template {
+	return &Template{
+		Name:               DefaultTemplateName,
+		Description:        "内置默认策略",
+		IsDefault:          true,
+		EnableTranscoding:  false,
+		EnableDownloads:    false,
+		EnableRemoteAccess: true,
+		BitrateLimit:       0,
+		MaxParentalRating:  10,
+		EnableAllFolders:   true,
+		EnabledFolders:     []string{},
+	}
+}
+
+// Apply 将模板字段覆盖到给定策略上
+func (t *Template) Apply(p *emby.UserPolicy) {
+	p.EnableAudioPlaybackTranscoding = t.EnableTranscoding
+	p.EnableVideoPlaybackTranscoding = t.EnableTranscoding
+	p.EnablePlaybackRemuxing = t.EnableTranscoding
+	p.EnableContentDownloading = t.EnableDownloads
+	p.EnableSubtitleDownloading = t.EnableDownloads
+	p.EnableRemoteAccess = t.EnableRemoteAccess
+	p.RemoteClientBitrateLimit = int32(t.BitrateLimit)
+	p.MaxParentalRating = int32(t.MaxParentalRating)
+
+	p.SetFolders(t.Folders())
+}
+
+// Folders 返回模板允许访问的媒体库，nil 表示全部媒体库
+func (t *Template) Folders() []string {
+	if t.EnableAllFolders {
+		return nil
+	}
+	return t.EnabledFolders
+}
+
+// Build 基于默认策略和模板生成完整的用户策略
+func (t *Template) Build(maxDevices int) *emby.UserPolicy {
+	p := emby.CreateDefaultPolicy(maxDevices)
+	t.Apply(p)
+	return p
+}
+
+// CloneAs 复制模板为新名称（不复制默认标记）
+func (t *Template) CloneAs(name string) *Template {
+	return &Template{
+		Name:               name,
+		Description:        t.Description,
+		EnableTranscoding:  t.EnableTranscoding,
+		EnableDownloads:    t.EnableDownloads,
+		EnableRemoteAccess: t.EnableRemoteAccess,
+		BitrateLimit:       t.BitrateLimit,
+		MaxParentalRating:  t.MaxParentalRating,
+		EnableAllFolders:   t.EnableAllFolders,
+		EnabledFolders:     append([]string{}, t.EnabledFolders...),
+	}
+}
@@ -0,0 +1,30 @@
+// Package schedule 领域错误定义
+package schedule
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 领域错误定义
+var (
+	// ErrNotFound 公告或维护窗口不存在
+	ErrNotFound = errors.New("schedule entry not found")
+
+	// ErrEmptyText 公告内容为空
+	ErrEmptyText = errors.New("announcement text is empty")
+
+	// ErrTimeInPast 指定的时间已经过去
+	ErrTimeInPast = errors.New("time is in the past")
+
+	// ErrInvalidCron 无效的 cron 表达式
+	ErrInvalidCron = errors.New("invalid cron expression")
+
+	// ErrInvalidWindow 维护窗口的结束时间不晚于开始时间
+	ErrInvalidWindow = errors.New("maintenance window must end after it starts")
+)
+
+// InvalidCronError 包装 cron 表达式解析失败的原因
+func InvalidCronError(cause error) error {
+	return fmt.Errorf("%w: %w", ErrInvalidCron, cause)
+}
@@ -0,0 +1,53 @@
+// Package schedule 定时公告与维护窗口
+package schedule
+
+import "time"
+
+// Announcement 定时公告
+type Announcement struct {
+	ID        uint       `gorm:"primarykey" json:"id"`
+	ChatID    int64      `gorm:"not null;default:0" json:"chat_id"` // 目标群组，0 表示所有用户
+	Text      string     `gorm:"type:text;not null" json:"text"`
+	Cron      string     `gorm:"size:100" json:"cron"` // cron 表达式，为空表示一次性公告
+	NextRunAt time.Time  `gorm:"not null;index" json:"next_run_at"`
+	LastRunAt *time.Time `json:"last_run_at"`
+	CreatedBy int64      `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Announcement) TableName() string {
+	return "announcements"
+}
+
+// IsBroadcast 是否发送给所有用户
+func (a *Announcement) IsBroadcast() bool {
+	return a.ChatID == 0
+}
+
+// IsRecurring 是否为周期公告
+func (a *Announcement) IsRecurring() bool {
+	return a.Cron != ""
+}
+
+// MaintenanceWindow 维护窗口，期间暂停创建与续期账号
+type MaintenanceWindow struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	StartsAt  time.Time `gorm:"not null;index" json:"starts_at"`
+	EndsAt    time.Time `gorm:"not null;index" json:"ends_at"`
+	Reason    string    `gorm:"size:200" json:"reason"`
+	CreatedBy int64     `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (MaintenanceWindow) TableName() string {
+	return "maintenance_windows"
+}
+
+// IsActive 检查指定时间是否处于维护窗口内
+func (w *MaintenanceWindow) IsActive(now time.Time) bool {
+	return !now.Before(w.StartsAt) && now.Before(w.EndsAt)
+}
@@ -0,0 +1,197 @@
+// Package schedule 定时公告与维护窗口业务服务
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"emby-telegram/pkg/cron"
+)
+
+// Service 定时公告与维护窗口业务服务
+type Service struct {
+	store Store
+}
+
+// NewService 创建定时任务服务实例
+func NewService(store Store) *Service {
+	if store == nil {
+		panic("schedule.NewService: store cannot be nil")
+	}
+	return &Service{store: store}
+}
+
+// ScheduleOnce 创建一次性公告，chatID 为 0 时发送给所有用户
+func (s *Service) ScheduleOnce(ctx context.Context, chatID int64, text string, at time.Time, createdBy int64) (*Announcement, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrEmptyText
+	}
+	if !at.After(time.Now()) {
+		return nil, ErrTimeInPast
+	}
+
+	a := &Announcement{
+		ChatID:    chatID,
+		Text:      text,
+		NextRunAt: at,
+		CreatedBy: createdBy,
+	}
+	if err := s.store.CreateAnnouncement(ctx, a); err != nil {
+		return nil, fmt.Errorf("create announcement: %w", err)
+	}
+	return a, nil
+}
+
+// ScheduleCron 创建周期公告，chatID 为 0 时发送给所有用户
+func (s *Service) ScheduleCron(ctx context.Context, chatID int64, text, expr string, createdBy int64) (*Announcement, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrEmptyText
+	}
+
+	sched, err := cron.Parse(expr)
+	if err != nil {
+		return nil, InvalidCronError(err)
+	}
+	next := sched.Next(time.Now())
+	if next.IsZero() {
+		return nil, InvalidCronError(fmt.Errorf("cron expression %q never matches", expr))
+	}
+
+	a := &Announcement{
+		ChatID:    chatID,
+		Text:      text,
+		Cron:      sched.String(),
+		NextRunAt: next,
+		CreatedBy: createdBy,
+	}
+	if err := s.store.CreateAnnouncement(ctx, a); err != nil {
+		return nil, fmt.Errorf("create announcement: %w", err)
+	}
+	return a, nil
+}
+
+// ListAnnouncements 列出所有待发送的公告
+func (s *Service) ListAnnouncements(ctx context.Context) ([]*Announcement, error) {
+	list, err := s.store.ListAnnouncements(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list announcements: %w", err)
+	}
+	return list, nil
+}
+
+// DeleteAnnouncement 删除公告
+func (s *Service) DeleteAnnouncement(ctx context.Context, id uint) error {
+	if err := s.store.DeleteAnnouncement(ctx, id); err != nil {
+		return fmt.Errorf("delete announcement: %w", err)
+	}
+	return nil
+}
+
+// Due 列出到期的公告
+func (s *Service) Due(ctx context.Context, now time.Time) ([]*Announcement, error) {
+	list, err := s.store.DueAnnouncements(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("list due announcements: %w", err)
+	}
+	return list, nil
+}
+
+// Advance 认领到期的公告：周期公告推进下次发送时间，一次性公告直接删除
+// 在发送之前调用，返回 true 时由调用者发送；推进或删除以原发送时间为条件，
+// 多个实例同时运行或进程重启时公告最多发送一次
+func (s *Service) Advance(ctx context.Context, a *Announcement, now time.Time) (bool, error) {
+	var next time.Time
+	if a.IsRecurring() {
+		if sched, err := cron.Parse(a.Cron); err == nil {
+			next = sched.Next(now)
+		}
+	}
+
+	if next.IsZero() {
+		if err := s.store.DeleteAnnouncement(ctx, a.ID); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return false, nil
+			}
+			return false, fmt.Errorf("delete announcement: %w", err)
+		}
+		return true, nil
+	}
+
+	claimed, err := s.store.AdvanceAnnouncement(ctx, a.ID, a.NextRunAt, next, now)
+	if err != nil {
+		return false, fmt.Errorf("advance announcement: %w", err)
+	}
+	if claimed {
+		a.NextRunAt = next
+		a.LastRunAt = &now
+	}
+	return claimed, nil
+}
+
+// AddWindow 创建维护窗口
+func (s *Service) AddWindow(ctx context.Context, startsAt, endsAt time.Time, reason string, createdBy int64) (*MaintenanceWindow, error) {
+	if !endsAt.After(startsAt) {
+		return nil, ErrInvalidWindow
+	}
+	if !endsAt.After(time.Now()) {
+		return nil, ErrTimeInPast
+	}
+
+	w := &MaintenanceWindow{
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		Reason:    strings.TrimSpace(reason),
+		CreatedBy: createdBy,
+	}
+	if err := s.store.CreateWindow(ctx, w); err != nil {
+		return nil, fmt.Errorf("create maintenance window: %w", err)
+	}
+	return w, nil
+}
+
+// ListWindows 列出进行中和未开始的维护窗口
+func (s *Service) ListWindows(ctx context.Context) ([]*MaintenanceWindow, error) {
+	list, err := s.store.ListWindows(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("list maintenance windows: %w", err)
+	}
+	return list, nil
+}
+
+// CancelWindow 取消维护窗口
+func (s *Service) CancelWindow(ctx context.Context, id uint) error {
+	if err := s.store.DeleteWindow(ctx, id); err != nil {
+		return fmt.Errorf("delete maintenance window: %w", err)
+	}
+	return nil
+}
+
+// ActiveWindow 获取当前所在的维护窗口，不在维护期间时返回 ErrNotFound
+func (s *Service) ActiveWindow(ctx context.Context) (*MaintenanceWindow, error) {
+	w, err := s.store.ActiveWindow(ctx, time.Now())
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get active maintenance window: %w", err)
+	}
+	return w, nil
+}
+
+// InMaintenance 检查当前是否处于维护窗口
+func (s *Service) InMaintenance(ctx context.Context) (bool, error) {
+	_, err := s.ActiveWindow(ctx)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, ErrNotFound):
+		return false, nil
+	default:
+		return false, err
+	}
+}
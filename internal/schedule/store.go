@@ -0,0 +1,38 @@
+// Package schedule 存储接口定义
+package schedule
+
+import (
+	"context"
+	"time"
+)
+
+// Store 定时公告与维护窗口存储接口
+type Store interface {
+	// CreateAnnouncement 创建公告
+	CreateAnnouncement(ctx context.Context, a *Announcement) error
+
+	// ListAnnouncements 按下次发送时间列出所有公告
+	ListAnnouncements(ctx context.Context) ([]*Announcement, error)
+
+	// DueAnnouncements 列出下次发送时间不晚于指定时间的公告
+	DueAnnouncements(ctx context.Context, now time.Time) ([]*Announcement, error)
+
+	// AdvanceAnnouncement 仅当下次发送时间仍为 from 时将其推进到 next，返回是否推进成功
+	// 多个实例同时处理同一公告时只有一个能推进成功
+	AdvanceAnnouncement(ctx context.Context, id uint, from, next, lastRun time.Time) (bool, error)
+
+	// DeleteAnnouncement 删除公告，不存在时返回 ErrNotFound
+	DeleteAnnouncement(ctx context.Context, id uint) error
+
+	// CreateWindow 创建维护窗口
+	CreateWindow(ctx context.Context, w *MaintenanceWindow) error
+
+	// ListWindows 按开始时间列出在指定时间之后结束的维护窗口
+	ListWindows(ctx context.Context, now time.Time) ([]*MaintenanceWindow, error)
+
+	// ActiveWindow 获取指定时间所在的维护窗口，有多个时返回结束最晚的，不存在时返回 ErrNotFound
+	ActiveWindow(ctx context.Context, now time.Time) (*MaintenanceWindow, error)
+
+	// DeleteWindow 删除维护窗口，不存在时返回 ErrNotFound
+	DeleteWindow(ctx context.Context, id uint) error
+}
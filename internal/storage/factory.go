@@ -6,7 +6,11 @@ import (
 	"gorm.io/gorm"
 
 	"emby-telegram/internal/account"
+	"emby-telegram/internal/apitoken"
+	"emby-telegram/internal/conversation"
 	"emby-telegram/internal/invitecode"
+	"emby-telegram/internal/policy"
+	"emby-telegram/internal/schedule"
 	"emby-telegram/internal/storage/mysql"
 	"emby-telegram/internal/storage/sqlite"
 	"emby-telegram/internal/user"
@@ -14,8 +18,13 @@ import (
 
 type Stores struct {
 	UserStore       user.Store
+	RoleStore       user.RoleStore
 	AccountStore    account.Store
 	InviteCodeStore invitecode.Store
+	PolicyStore     policy.Store
+	StateStore      conversation.StateStore
+	ScheduleStore   schedule.Store
+	APITokenStore   apitoken.Store
 	DB              *gorm.DB
 }
 
@@ -31,8 +40,13 @@ func NewStores(driver, dsn string, debug bool) (*Stores, error) {
 		}
 		return &Stores{
 			UserStore:       sqlite.NewUserStore(db),
+			RoleStore:       sqlite.NewRoleStore(db),
 			AccountStore:    sqlite.NewAccountStore(db),
 			InviteCodeStore: sqlite.NewInviteCodeStore(db),
+			PolicyStore:     sqlite.NewPolicyTemplateStore(db),
+			StateStore:      sqlite.NewStateStore(db),
+			ScheduleStore:   sqlite.NewScheduleStore(db),
+			APITokenStore:   sqlite.NewAPITokenStore(db),
 			DB:              db,
 		}, nil
 
@@ -43,8 +57,13 @@ func NewStores(driver, dsn string, debug bool) (*Stores, error) {
 		}
 		return &Stores{
 			UserStore:       mysql.NewUserStore(db),
+			RoleStore:       mysql.NewRoleStore(db),
 			AccountStore:    mysql.NewAccountStore(db),
 			InviteCodeStore: mysql.NewInviteCodeStore(db),
+			PolicyStore:     mysql.NewPolicyTemplateStore(db),
+			StateStore:      mysql.NewStateStore(db),
+			ScheduleStore:   mysql.NewScheduleStore(db),
+			APITokenStore:   mysql.NewAPITokenStore(db),
 			DB:              db,
 		}, nil
 
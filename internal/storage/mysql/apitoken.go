@@ -0,0 +1,68 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"emby-telegram/internal/apitoken"
+)
+
+type APITokenStore struct {
+	db *gorm.DB
+}
+
+func NewAPITokenStore(db *gorm.DB) *APITokenStore {
+	return &APITokenStore{db: db}
+}
+
+func (s *APITokenStore) Create(ctx context.Context, t *apitoken.Token) error {
+	if err := s.db.WithContext(ctx).Create(t).Error; err != nil {
+		return fmt.Errorf("create api token: %w", err)
+	}
+	return nil
+}
+
+func (s *APITokenStore) List(ctx context.Context) ([]*apitoken.Token, error) {
+	var list []*apitoken.Token
+	if err := s.db.WithContext(ctx).Order("id ASC").Find(&list).Error; err != nil {
+		return nil, fmt.Errorf("list api tokens: %w", err)
+	}
+	return list, nil
+}
+
+func (s *APITokenStore) GetByHash(ctx context.Context, hash string) (*apitoken.Token, error) {
+	var t apitoken.Token
+	if err := s.db.WithContext(ctx).Where("hash = ?", hash).First(&t).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apitoken.ErrNotFound
+		}
+		return nil, fmt.Errorf("get api token: %w", err)
+	}
+	return &t, nil
+}
+
+func (s *APITokenStore) Delete(ctx context.Context, id uint) error {
+	result := s.db.WithContext(ctx).Delete(&apitoken.Token{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("delete api token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apitoken.ErrNotFound
+	}
+	return nil
+}
+
+func (s *APITokenStore) TouchLastUsed(ctx context.Context, id uint, at time.Time) error {
+	err := s.db.WithContext(ctx).
+		Model(&apitoken.Token{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", at).Error
+	if err != nil {
+		return fmt.Errorf("update api token last used: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"emby-telegram/internal/conversation"
+)
+
+type StateStore struct {
+	db *gorm.DB
+}
+
+func NewStateStore(db *gorm.DB) *StateStore {
+	return &StateStore{db: db}
+}
+
+func (s *StateStore) Get(ctx context.Context, telegramID int64) (*conversation.State, error) {
+	var state conversation.State
+	err := s.db.WithContext(ctx).
+		Where("telegram_id = ? AND expires_at > ?", telegramID, time.Now()).
+		First(&state).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, conversation.ErrNotFound
+		}
+		return nil, fmt.Errorf("get conversation state: %w", err)
+	}
+	return &state, nil
+}
+
+func (s *StateStore) Save(ctx context.Context, state *conversation.State) error {
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "telegram_id"}},
+			UpdateAll: true,
+		}).
+		Create(state).Error
+	if err != nil {
+		return fmt.Errorf("save conversation state: %w", err)
+	}
+	return nil
+}
+
+func (s *StateStore) Delete(ctx context.Context, telegramID int64) error {
+	if err := s.db.WithContext(ctx).Where("telegram_id = ?", telegramID).Delete(&conversation.State{}).Error; err != nil {
+		return fmt.Errorf("delete conversation state: %w", err)
+	}
+	return nil
+}
+
+func (s *StateStore) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at <= ?", before).Delete(&conversation.State{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("delete expired conversation states: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
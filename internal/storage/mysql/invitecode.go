@@ -3,6 +3,8 @@ package mysql
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -89,6 +91,58 @@ func (s *InviteCodeStore) Count(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+func (s *InviteCodeStore) ListByQuery(ctx context.Context, q invitecode.ListQuery) ([]*invitecode.InviteCode, int64, error) {
+	now := time.Now()
+	query := s.db.WithContext(ctx).Model(&invitecode.InviteCode{})
+
+	switch q.Filter {
+	case invitecode.FilterAll:
+	case invitecode.FilterActive:
+		query = query.Where("status = ?", invitecode.StatusActive).
+			Where("expire_at IS NULL OR expire_at > ?", now).
+			Where("max_uses = -1 OR current_uses < max_uses")
+	case invitecode.FilterExpired:
+		query = query.Where("status = ? OR expire_at <= ?", invitecode.StatusExpired, now)
+	case invitecode.FilterExhausted:
+		query = query.Where("max_uses <> -1 AND current_uses >= max_uses")
+	case invitecode.FilterRevoked:
+		query = query.Where("status = ?", invitecode.StatusRevoked)
+	default:
+		return nil, 0, fmt.Errorf("unknown invite code filter %q", q.Filter)
+	}
+
+	if q.Search != "" {
+		pattern := "%" + escapeLike(q.Search) + "%"
+		query = query.Where("code LIKE ? ESCAPE '!' OR description LIKE ? ESCAPE '!'", pattern, pattern)
+	}
+
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	switch q.Sort {
+	case invitecode.SortExpiry:
+		query = query.Order("expire_at IS NULL, expire_at ASC")
+	case invitecode.SortCode:
+		query = query.Order("code ASC")
+	default:
+		query = query.Order("created_at DESC")
+	}
+
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit).Offset(q.Offset)
+	}
+
+	var codes []*invitecode.InviteCode
+	if err := query.Find(&codes).Error; err != nil {
+		return nil, 0, err
+	}
+	return codes, total, nil
+}
+
 func (s *InviteCodeStore) RecordUsage(ctx context.Context, usage *invitecode.InviteCodeUsage) error {
 	return s.db.WithContext(ctx).Create(usage).Error
 }
@@ -104,3 +158,20 @@ func (s *InviteCodeStore) GetUsageByUser(ctx context.Context, userID uint) (*inv
 	}
 	return &usage, nil
 }
+
+func (s *InviteCodeStore) ListUsage(ctx context.Context, offset, limit int) ([]*invitecode.UsageRecord, error) {
+	var records []*invitecode.UsageRecord
+	query := s.db.WithContext(ctx).
+		Table("invite_code_usage").
+		Select("invite_code_usage.id, invite_code_usage.invite_code_id, invite_codes.code, invite_code_usage.user_id, users.telegram_id, invite_code_usage.used_at").
+		Joins("LEFT JOIN invite_codes ON invite_codes.id = invite_code_usage.invite_code_id").
+		Joins("LEFT JOIN users ON users.id = invite_code_usage.user_id").
+		Order("invite_code_usage.id ASC")
+	if limit > 0 {
+		query = query.Limit(limit).Offset(offset)
+	}
+	if err := query.Scan(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
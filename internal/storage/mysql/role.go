@@ -0,0 +1,59 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"emby-telegram/internal/user"
+)
+
+type RoleStore struct {
+	db *gorm.DB
+}
+
+func NewRoleStore(db *gorm.DB) *RoleStore {
+	return &RoleStore{db: db}
+}
+
+func (s *RoleStore) Create(ctx context.Context, role *user.RoleDefinition) error {
+	if err := s.db.WithContext(ctx).Create(role).Error; err != nil {
+		return fmt.Errorf("create role: %w", err)
+	}
+	return nil
+}
+
+func (s *RoleStore) Get(ctx context.Context, name user.Role) (*user.RoleDefinition, error) {
+	var role user.RoleDefinition
+	if err := s.db.WithContext(ctx).Where("name = ?", name).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, user.RoleNotFoundError(name)
+		}
+		return nil, fmt.Errorf("get role: %w", err)
+	}
+	return &role, nil
+}
+
+func (s *RoleStore) List(ctx context.Context) ([]*user.RoleDefinition, error) {
+	var roles []*user.RoleDefinition
+	if err := s.db.WithContext(ctx).Order("id ASC").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("list roles: %w", err)
+	}
+	return roles, nil
+}
+
+func (s *RoleStore) Update(ctx context.Context, role *user.RoleDefinition) error {
+	if err := s.db.WithContext(ctx).Save(role).Error; err != nil {
+		return fmt.Errorf("update role: %w", err)
+	}
+	return nil
+}
+
+func (s *RoleStore) Delete(ctx context.Context, name user.Role) error {
+	if err := s.db.WithContext(ctx).Where("name = ?", name).Delete(&user.RoleDefinition{}).Error; err != nil {
+		return fmt.Errorf("delete role: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"emby-telegram/internal/schedule"
+)
+
+type ScheduleStore struct {
+	db *gorm.DB
+}
+
+func NewScheduleStore(db *gorm.DB) *ScheduleStore {
+	return &ScheduleStore{db: db}
+}
+
+func (s *ScheduleStore) CreateAnnouncement(ctx context.Context, a *schedule.Announcement) error {
+	if err := s.db.WithContext(ctx).Create(a).Error; err != nil {
+		return fmt.Errorf("create announcement: %w", err)
+	}
+	return nil
+}
+
+func (s *ScheduleStore) ListAnnouncements(ctx context.Context) ([]*schedule.Announcement, error) {
+	var list []*schedule.Announcement
+	if err := s.db.WithContext(ctx).Order("next_run_at ASC").Find(&list).Error; err != nil {
+		return nil, fmt.Errorf("list announcements: %w", err)
+	}
+	return list, nil
+}
+
+func (s *ScheduleStore) DueAnnouncements(ctx context.Context, now time.Time) ([]*schedule.Announcement, error) {
+	var list []*schedule.Announcement
+	err := s.db.WithContext(ctx).
+		Where("next_run_at <= ?", now).
+		Order("next_run_at ASC").
+		Find(&list).Error
+	if err != nil {
+		return nil, fmt.Errorf("list due announcements: %w", err)
+	}
+	return list, nil
+}
+
+func (s *ScheduleStore) AdvanceAnnouncement(ctx context.Context, id uint, from, next, lastRun time.Time) (bool, error) {
+	result := s.db.WithContext(ctx).
+		Model(&schedule.Announcement{}).
+		Where("id = ? AND next_run_at = ?", id, from).
+		Updates(map[string]any{"next_run_at": next, "last_run_at": lastRun})
+	if result.Error != nil {
+		return false, fmt.Errorf("advance announcement: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (s *ScheduleStore) DeleteAnnouncement(ctx context.Context, id uint) error {
+	result := s.db.WithContext(ctx).Delete(&schedule.Announcement{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("delete announcement: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return schedule.ErrNotFound
+	}
+	return nil
+}
+
+func (s *ScheduleStore) CreateWindow(ctx context.Context, w *schedule.MaintenanceWindow) error {
+	if err := s.db.WithContext(ctx).Create(w).Error; err != nil {
+		return fmt.Errorf("create maintenance window: %w", err)
+	}
+	return nil
+}
+
+func (s *ScheduleStore) ListWindows(ctx context.Context, now time.Time) ([]*schedule.MaintenanceWindow, error) {
+	var list []*schedule.MaintenanceWindow
+	err := s.db.WithContext(ctx).
+		Where("ends_at > ?", now).
+		Order("starts_at ASC").
+		Find(&list).Error
+	if err != nil {
+		return nil, fmt.Errorf("list maintenance windows: %w", err)
+	}
+	return list, nil
+}
+
+func (s *ScheduleStore) ActiveWindow(ctx context.Context, now time.Time) (*schedule.MaintenanceWindow, error) {
+	var w schedule.MaintenanceWindow
+	err := s.db.WithContext(ctx).
+		Where("starts_at <= ? AND ends_at > ?", now, now).
+		Order("ends_at DESC").
+		First(&w).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, schedule.ErrNotFound
+		}
+		return nil, fmt.Errorf("get active maintenance window: %w", err)
+	}
+	return &w, nil
+}
+
+func (s *ScheduleStore) DeleteWindow(ctx context.Context, id uint) error {
+	result := s.db.WithContext(ctx).Delete(&schedule.MaintenanceWindow{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("delete maintenance window: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return schedule.ErrNotFound
+	}
+	return nil
+}
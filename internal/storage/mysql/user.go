@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
+	"emby-telegram/internal/account"
 	"emby-telegram/internal/user"
 )
 
@@ -72,6 +76,77 @@ func (s *UserStore) List(ctx context.Context, offset, limit int) ([]*user.User,
 	return users, nil
 }
 
+func (s *UserStore) Search(ctx context.Context, query string, limit int) ([]*user.User, error) {
+	query = strings.TrimPrefix(strings.TrimSpace(query), "@")
+	if query == "" {
+		return nil, nil
+	}
+
+	var users []*user.User
+	db := s.db.WithContext(ctx).Where(s.searchCondition(query)).Order("created_at DESC")
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+
+	if err := db.Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("search users: %w", err)
+	}
+	return users, nil
+}
+
+func (s *UserStore) ListByQuery(ctx context.Context, q user.ListQuery) ([]*user.User, int64, error) {
+	query := s.db.WithContext(ctx).Model(&user.User{})
+
+	switch q.Filter {
+	case user.FilterAll:
+	case user.FilterBlocked:
+		query = query.Where("is_blocked = ?", true)
+	case user.FilterBotBlocked:
+		query = query.Where("bot_blocked_at IS NOT NULL")
+	default:
+		return nil, 0, fmt.Errorf("unknown user filter %q", q.Filter)
+	}
+
+	if search := strings.TrimPrefix(strings.TrimSpace(q.Search), "@"); search != "" {
+		query = query.Where(s.searchCondition(search))
+	}
+
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("count users by query: %w", err)
+	}
+
+	switch q.Sort {
+	case user.SortUsername:
+		query = query.Order("username IS NULL OR username = '', username ASC")
+	default:
+		query = query.Order("created_at DESC")
+	}
+
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit).Offset(q.Offset)
+	}
+
+	var users []*user.User
+	if err := query.Find(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("list users by query: %w", err)
+	}
+	return users, total, nil
+}
+
+func (s *UserStore) searchCondition(query string) *gorm.DB {
+	pattern := escapeLike(query)
+	cond := s.db.Where("username LIKE ? ESCAPE '!'", pattern+"%").
+		Or("first_name LIKE ? ESCAPE '!'", "%"+pattern+"%").
+		Or("last_name LIKE ? ESCAPE '!'", "%"+pattern+"%")
+	if telegramID, err := strconv.ParseInt(query, 10, 64); err == nil {
+		cond = cond.Or("telegram_id = ?", telegramID)
+	}
+	return cond
+}
+
 func (s *UserStore) Update(ctx context.Context, u *user.User) error {
 	if err := s.db.WithContext(ctx).Save(u).Error; err != nil {
 		return fmt.Errorf("update user: %w", err)
@@ -104,3 +179,44 @@ func (s *UserStore) CountByRole(ctx context.Context, role user.Role) (int64, err
 	}
 	return count, nil
 }
+
+func (s *UserStore) ListByAudience(ctx context.Context, audience user.Audience) ([]*user.User, error) {
+	now := time.Now()
+	query := s.db.WithContext(ctx).Where("is_blocked = ?", false).Order("id ASC")
+
+	accounts := s.db.Table("accounts").Select("user_id").Where("deleted_at IS NULL")
+
+	switch audience.Kind {
+	case user.AudienceAll:
+	case user.AudienceActive:
+		query = query.Where("id IN (?)", accounts.
+			Where("status = ?", account.StatusActive).
+			Where("expire_at IS NULL OR expire_at > ?", now))
+	case user.AudienceExpiring:
+		query = query.Where("id IN (?)", accounts.
+			Where("status = ?", account.StatusActive).
+			Where("expire_at > ? AND expire_at <= ?", now, now.AddDate(0, 0, audience.Days)))
+	case user.AudiencePlan:
+		query = query.Where("id IN (?)", accounts.Where("policy_template_id IN ?", audience.TemplateIDs))
+	case user.AudienceRole:
+		query = query.Where("role = ?", audience.Role)
+	default:
+		return nil, fmt.Errorf("unknown audience %q", audience.Kind)
+	}
+
+	var users []*user.User
+	if err := query.Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("list users by audience: %w", err)
+	}
+	return users, nil
+}
+
+func (s *UserStore) SetBotBlocked(ctx context.Context, telegramID int64, at *time.Time) error {
+	if err := s.db.WithContext(ctx).
+		Model(&user.User{}).
+		Where("telegram_id = ?", telegramID).
+		Update("bot_blocked_at", at).Error; err != nil {
+		return fmt.Errorf("set bot blocked: %w", err)
+	}
+	return nil
+}
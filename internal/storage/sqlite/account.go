@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -53,6 +54,18 @@ func (s *AccountStore) GetByUsername(ctx context.Context, username string) (*acc
 	return &acc, nil
 }
 
+// GetByEmbyUserID 根据 Emby 用户 ID 获取账号
+func (s *AccountStore) GetByEmbyUserID(ctx context.Context, embyUserID string) (*account.Account, error) {
+	var acc account.Account
+	if err := s.db.WithContext(ctx).Where("emby_user_id = ?", embyUserID).First(&acc).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, account.ErrNotFound
+		}
+		return nil, fmt.Errorf("get account by emby_user_id: %w", err)
+	}
+	return &acc, nil
+}
+
 // List 列出指定用户的所有账号
 func (s *AccountStore) List(ctx context.Context, userID uint) ([]*account.Account, error) {
 	var accounts []*account.Account
@@ -99,6 +112,112 @@ func (s *AccountStore) ListAllWithUser(ctx context.Context, offset, limit int) (
 	return results, nil
 }
 
+// ListByCreatorWithUser 列出指定用户代为创建的账号及关联用户信息(分页)
+func (s *AccountStore) ListByCreatorWithUser(ctx context.Context, creatorID uint, offset, limit int) ([]*account.AccountWithUser, error) {
+	var results []*account.AccountWithUser
+	query := s.db.WithContext(ctx).
+		Table("accounts").
+		Select("accounts.*, users.username as owner_username, users.first_name as owner_first_name, users.telegram_id as owner_telegram_id").
+		Joins("LEFT JOIN users ON users.id = accounts.user_id").
+		Where("accounts.created_by = ?", creatorID).
+		Order("accounts.created_at DESC")
+
+	if limit > 0 {
+		query = query.Limit(limit).Offset(offset)
+	}
+
+	if err := query.Scan(&results).Error; err != nil {
+		return nil, fmt.Errorf("list accounts by creator: %w", err)
+	}
+	return results, nil
+}
+
+// ListByQuery 按查询条件列出账号及关联用户信息，同时返回符合条件的总数
+func (s *AccountStore) ListByQuery(ctx context.Context, q account.ListQuery) ([]*account.AccountWithUser, int64, error) {
+	now := time.Now()
+	query := s.db.WithContext(ctx).
+		Table("accounts").
+		Joins("LEFT JOIN users ON users.id = accounts.user_id").
+		Where("accounts.deleted_at IS NULL")
+
+	if q.CreatorID > 0 {
+		query = query.Where("accounts.created_by = ?", q.CreatorID)
+	}
+
+	switch q.Filter {
+	case account.FilterAll:
+	case account.FilterActive:
+		query = query.Where("accounts.status = ?", account.StatusActive).
+			Where("accounts.expire_at IS NULL OR accounts.expire_at > ?", now)
+	case account.FilterSuspended:
+		query = query.Where("accounts.status = ?", account.StatusSuspended)
+	case account.FilterExpired:
+		query = query.Where("accounts.status = ? OR accounts.expire_at <= ?", account.StatusExpired, now)
+	case account.FilterSyncFailed:
+		query = query.Where("accounts.sync_status = ?", "failed")
+	case account.FilterExpiring:
+		query = query.Where("accounts.status = ?", account.StatusActive).
+			Where("accounts.expire_at > ? AND accounts.expire_at <= ?", now, now.Add(account.ExpiringWindow))
+	default:
+		return nil, 0, fmt.Errorf("unknown account filter %q", q.Filter)
+	}
+
+	if q.Search != "" {
+		pattern := escapeLike(q.Search)
+		query = query.Where("accounts.username LIKE ? ESCAPE '!' OR users.username LIKE ? ESCAPE '!'", "%"+pattern+"%", pattern+"%")
+	}
+
+	// 计数和查询共用筛选条件
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("count accounts by query: %w", err)
+	}
+
+	switch q.Sort {
+	case account.SortExpiry:
+		query = query.Order("accounts.expire_at IS NULL, accounts.expire_at ASC")
+	case account.SortUsername:
+		query = query.Order("accounts.username ASC")
+	default:
+		query = query.Order("accounts.created_at DESC")
+	}
+
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit).Offset(q.Offset)
+	}
+
+	var results []*account.AccountWithUser
+	if err := query.
+		Select("accounts.*, users.username as owner_username, users.first_name as owner_first_name, users.telegram_id as owner_telegram_id").
+		Scan(&results).Error; err != nil {
+		return nil, 0, fmt.Errorf("list accounts by query: %w", err)
+	}
+	return results, total, nil
+}
+
+// SearchByUsernamePrefix 按用户名前缀搜索账号及关联用户信息
+func (s *AccountStore) SearchByUsernamePrefix(ctx context.Context, prefix string, limit int) ([]*account.AccountWithUser, error) {
+	var results []*account.AccountWithUser
+	query := s.db.WithContext(ctx).
+		Table("accounts").
+		Select("accounts.*, users.username as owner_username, users.first_name as owner_first_name, users.telegram_id as owner_telegram_id").
+		Joins("LEFT JOIN users ON users.id = accounts.user_id").
+		Where("accounts.deleted_at IS NULL").
+		Where("accounts.username LIKE ? ESCAPE '!'", escapeLike(prefix)+"%").
+		Order("accounts.username ASC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Scan(&results).Error; err != nil {
+		return nil, fmt.Errorf("search accounts: %w", err)
+	}
+	return results, nil
+}
+
 // GetWithUser 根据 ID 获取账号及用户信息
 func (s *AccountStore) GetWithUser(ctx context.Context, id uint) (*account.AccountWithUser, error) {
 	var result account.AccountWithUser
@@ -118,7 +237,8 @@ func (s *AccountStore) GetWithUser(ctx context.Context, id uint) (*account.Accou
 
 // Update 更新账号
 func (s *AccountStore) Update(ctx context.Context, acc *account.Account) error {
-	if err := s.db.WithContext(ctx).Save(acc).Error; err != nil {
+	// 所有者只由转让事务修改，避免基于旧数据的写入把已转让的账号改回原所有者
+	if err := s.db.WithContext(ctx).Model(acc).Select("*").Omit("user_id").Updates(acc).Error; err != nil {
 		return fmt.Errorf("update account: %w", err)
 	}
 	return nil
@@ -154,6 +274,18 @@ func (s *AccountStore) CountByUser(ctx context.Context, userID uint) (int64, err
 	return count, nil
 }
 
+// CountByCreator 统计指定用户代为创建的账号数量
+func (s *AccountStore) CountByCreator(ctx context.Context, creatorID uint) (int64, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).
+		Model(&account.Account{}).
+		Where("created_by = ?", creatorID).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("count accounts by creator: %w", err)
+	}
+	return count, nil
+}
+
 // CountByStatus 统计指定状态的账号数量
 func (s *AccountStore) CountByStatus(ctx context.Context, status account.Status) (int64, error) {
 	var count int64
@@ -165,3 +297,136 @@ func (s *AccountStore) CountByStatus(ctx context.Context, status account.Status)
 	}
 	return count, nil
 }
+
+// CreatePolicyChange 记录策略变更
+func (s *AccountStore) CreatePolicyChange(ctx context.Context, change *account.PolicyChange) error {
+	if err := s.db.WithContext(ctx).Create(change).Error; err != nil {
+		return fmt.Errorf("create policy change: %w", err)
+	}
+	return nil
+}
+
+// ListPolicyChanges 列出账号最近的策略变更记录
+func (s *AccountStore) ListPolicyChanges(ctx context.Context, accountID uint, limit int) ([]*account.PolicyChange, error) {
+	var changes []*account.PolicyChange
+	query := s.db.WithContext(ctx).
+		Where("account_id = ?", accountID).
+		Order("created_at DESC, id DESC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("list policy changes: %w", err)
+	}
+	return changes, nil
+}
+
+// CreateTransfer 创建转让记录
+func (s *AccountStore) CreateTransfer(ctx context.Context, t *account.Transfer) error {
+	if err := s.db.WithContext(ctx).Create(t).Error; err != nil {
+		return fmt.Errorf("create transfer: %w", err)
+	}
+	return nil
+}
+
+// GetTransfer 根据 ID 获取转让记录
+func (s *AccountStore) GetTransfer(ctx context.Context, id uint) (*account.Transfer, error) {
+	var t account.Transfer
+	if err := s.db.WithContext(ctx).First(&t, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, account.ErrTransferNotFound
+		}
+		return nil, fmt.Errorf("get transfer: %w", err)
+	}
+	return &t, nil
+}
+
+// GetPendingTransfer 获取账号等待接受的转让
+func (s *AccountStore) GetPendingTransfer(ctx context.Context, accountID uint) (*account.Transfer, error) {
+	var t account.Transfer
+	err := s.db.WithContext(ctx).
+		Where("account_id = ? AND status = ?", accountID, account.TransferPending).
+		Order("id DESC").
+		First(&t).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, account.ErrTransferNotFound
+		}
+		return nil, fmt.Errorf("get pending transfer: %w", err)
+	}
+	return &t, nil
+}
+
+// ClaimTransfer 以等待状态为条件更新转让状态，返回是否更新成功
+func (s *AccountStore) ClaimTransfer(ctx context.Context, id uint, status account.TransferStatus) (bool, error) {
+	result := s.db.WithContext(ctx).
+		Model(&account.Transfer{}).
+		Where("id = ? AND status = ?", id, account.TransferPending).
+		Update("status", status)
+	if result.Error != nil {
+		return false, fmt.Errorf("claim transfer: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// TransferAccount 在同一事务中转移账号并写入已完成的转让记录
+func (s *AccountStore) TransferAccount(ctx context.Context, t *account.Transfer) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := moveAccount(tx, t); err != nil {
+			return err
+		}
+		if err := tx.Create(t).Error; err != nil {
+			return fmt.Errorf("create transfer: %w", err)
+		}
+		return nil
+	})
+}
+
+// CompleteTransfer 在同一事务中认领等待中的转让并转移账号
+func (s *AccountStore) CompleteTransfer(ctx context.Context, t *account.Transfer) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&account.Transfer{}).
+			Where("id = ? AND status = ?", t.ID, account.TransferPending).
+			Update("status", account.TransferCompleted)
+		if result.Error != nil {
+			return fmt.Errorf("claim transfer: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return account.ErrTransferClosed
+		}
+		return moveAccount(tx, t)
+	})
+}
+
+// moveAccount 以发起方仍是所有者为条件转移账号
+func moveAccount(tx *gorm.DB, t *account.Transfer) error {
+	result := tx.Model(&account.Account{}).
+		Where("id = ? AND user_id = ?", t.AccountID, t.FromUserID).
+		Update("user_id", t.ToUserID)
+	if result.Error != nil {
+		return fmt.Errorf("move account: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return account.ErrOwnerChanged
+	}
+	return nil
+}
+
+// ListTransfers 列出账号最近的转让记录
+func (s *AccountStore) ListTransfers(ctx context.Context, accountID uint, limit int) ([]*account.Transfer, error) {
+	var transfers []*account.Transfer
+	query := s.db.WithContext(ctx).
+		Where("account_id = ?", accountID).
+		Order("created_at DESC, id DESC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	if err := query.Find(&transfers).Error; err != nil {
+		return nil, fmt.Errorf("list transfers: %w", err)
+	}
+	return transfers, nil
+}
@@ -0,0 +1,70 @@
+// Package sqlite 会话状态存储实现
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"emby-telegram/internal/conversation"
+)
+
+// StateStore 会话状态存储实现
+type StateStore struct {
+	db *gorm.DB
+}
+
+// NewStateStore 创建会话状态存储实例
+func NewStateStore(db *gorm.DB) *StateStore {
+	return &StateStore{db: db}
+}
+
+// Get 获取未过期的会话状态
+func (s *StateStore) Get(ctx context.Context, telegramID int64) (*conversation.State, error) {
+	var state conversation.State
+	err := s.db.WithContext(ctx).
+		Where("telegram_id = ? AND expires_at > ?", telegramID, time.Now()).
+		First(&state).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, conversation.ErrNotFound
+		}
+		return nil, fmt.Errorf("get conversation state: %w", err)
+	}
+	return &state, nil
+}
+
+// Save 保存会话状态，已存在时覆盖
+func (s *StateStore) Save(ctx context.Context, state *conversation.State) error {
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "telegram_id"}},
+			UpdateAll: true,
+		}).
+		Create(state).Error
+	if err != nil {
+		return fmt.Errorf("save conversation state: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除会话状态
+func (s *StateStore) Delete(ctx context.Context, telegramID int64) error {
+	if err := s.db.WithContext(ctx).Where("telegram_id = ?", telegramID).Delete(&conversation.State{}).Error; err != nil {
+		return fmt.Errorf("delete conversation state: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired 删除过期的会话状态
+func (s *StateStore) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at <= ?", before).Delete(&conversation.State{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("delete expired conversation states: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
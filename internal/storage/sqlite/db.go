@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"gorm.io/driver/sqlite"
@@ -65,3 +66,11 @@ func Close(db *gorm.DB) error {
 
 	return nil
 }
+
+// likeEscaper 转义 LIKE 通配符，配合 ESCAPE '!' 使用
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// escapeLike 转义用户输入，使其在 LIKE 模式中按字面匹配
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
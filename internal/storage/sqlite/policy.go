@@ -0,0 +1,109 @@
+// Package sqlite 策略模板存储实现
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"emby-telegram/internal/policy"
+)
+
+// PolicyTemplateStore 策略模板存储实现
+type PolicyTemplateStore struct {
+	db *gorm.DB
+}
+
+// NewPolicyTemplateStore 创建策略模板存储实例
+func NewPolicyTemplateStore(db *gorm.DB) *PolicyTemplateStore {
+	return &PolicyTemplateStore{db: db}
+}
+
+// Create 创建模板
+func (s *PolicyTemplateStore) Create(ctx context.Context, tpl *policy.Template) error {
+	if err := s.db.WithContext(ctx).Create(tpl).Error; err != nil {
+		return fmt.Errorf("create policy template: %w", err)
+	}
+	return nil
+}
+
+// Get 根据 ID 获取模板
+func (s *PolicyTemplateStore) Get(ctx context.Context, id uint) (*policy.Template, error) {
+	var tpl policy.Template
+	if err := s.db.WithContext(ctx).First(&tpl, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, policy.ErrNotFound
+		}
+		return nil, fmt.Errorf("get policy template: %w", err)
+	}
+	return &tpl, nil
+}
+
+// GetByName 根据名称获取模板
+func (s *PolicyTemplateStore) GetByName(ctx context.Context, name string) (*policy.Template, error) {
+	var tpl policy.Template
+	if err := s.db.WithContext(ctx).Where("name = ?", name).First(&tpl).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, policy.ErrNotFound
+		}
+		return nil, fmt.Errorf("get policy template by name: %w", err)
+	}
+	return &tpl, nil
+}
+
+// GetDefault 获取默认模板
+func (s *PolicyTemplateStore) GetDefault(ctx context.Context) (*policy.Template, error) {
+	var tpl policy.Template
+	if err := s.db.WithContext(ctx).Where("is_default = ?", true).First(&tpl).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, policy.ErrNotFound
+		}
+		return nil, fmt.Errorf("get default policy template: %w", err)
+	}
+	return &tpl, nil
+}
+
+// List 列出所有模板
+func (s *PolicyTemplateStore) List(ctx context.Context) ([]*policy.Template, error) {
+	var tpls []*policy.Template
+	if err := s.db.WithContext(ctx).Order("id ASC").Find(&tpls).Error; err != nil {
+		return nil, fmt.Errorf("list policy templates: %w", err)
+	}
+	return tpls, nil
+}
+
+// Update 更新模板
+func (s *PolicyTemplateStore) Update(ctx context.Context, tpl *policy.Template) error {
+	if err := s.db.WithContext(ctx).Save(tpl).Error; err != nil {
+		return fmt.Errorf("update policy template: %w", err)
+	}
+	return nil
+}
+
+// SetDefault 将指定模板设为默认
+// 在事务中先清除所有默认标记，保证只有一个默认模板
+func (s *PolicyTemplateStore) SetDefault(ctx context.Context, id uint) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&policy.Template{}).
+			Where("is_default = ?", true).
+			Update("is_default", false).Error; err != nil {
+			return fmt.Errorf("clear default policy template: %w", err)
+		}
+		if err := tx.Model(&policy.Template{}).
+			Where("id = ?", id).
+			Update("is_default", true).Error; err != nil {
+			return fmt.Errorf("set default policy template: %w", err)
+		}
+		return nil
+	})
+}
+
+// Delete 删除模板
+func (s *PolicyTemplateStore) Delete(ctx context.Context, id uint) error {
+	if err := s.db.WithContext(ctx).Delete(&policy.Template{}, id).Error; err != nil {
+		return fmt.Errorf("delete policy template: %w", err)
+	}
+	return nil
+}
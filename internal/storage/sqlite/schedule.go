@@ -0,0 +1,126 @@
+// Package sqlite 定时公告与维护窗口存储实现
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"emby-telegram/internal/schedule"
+)
+
+// ScheduleStore 定时公告与维护窗口存储实现
+type ScheduleStore struct {
+	db *gorm.DB
+}
+
+// NewScheduleStore 创建定时任务存储实例
+func NewScheduleStore(db *gorm.DB) *ScheduleStore {
+	return &ScheduleStore{db: db}
+}
+
+// CreateAnnouncement 创建公告
+func (s *ScheduleStore) CreateAnnouncement(ctx context.Context, a *schedule.Announcement) error {
+	if err := s.db.WithContext(ctx).Create(a).Error; err != nil {
+		return fmt.Errorf("create announcement: %w", err)
+	}
+	return nil
+}
+
+// ListAnnouncements 按下次发送时间列出所有公告
+func (s *ScheduleStore) ListAnnouncements(ctx context.Context) ([]*schedule.Announcement, error) {
+	var list []*schedule.Announcement
+	if err := s.db.WithContext(ctx).Order("next_run_at ASC").Find(&list).Error; err != nil {
+		return nil, fmt.Errorf("list announcements: %w", err)
+	}
+	return list, nil
+}
+
+// DueAnnouncements 列出到期的公告
+func (s *ScheduleStore) DueAnnouncements(ctx context.Context, now time.Time) ([]*schedule.Announcement, error) {
+	var list []*schedule.Announcement
+	err := s.db.WithContext(ctx).
+		Where("next_run_at <= ?", now).
+		Order("next_run_at ASC").
+		Find(&list).Error
+	if err != nil {
+		return nil, fmt.Errorf("list due announcements: %w", err)
+	}
+	return list, nil
+}
+
+// AdvanceAnnouncement 以下次发送时间为条件推进公告，返回是否推进成功
+func (s *ScheduleStore) AdvanceAnnouncement(ctx context.Context, id uint, from, next, lastRun time.Time) (bool, error) {
+	result := s.db.WithContext(ctx).
+		Model(&schedule.Announcement{}).
+		Where("id = ? AND next_run_at = ?", id, from).
+		Updates(map[string]any{"next_run_at": next, "last_run_at": lastRun})
+	if result.Error != nil {
+		return false, fmt.Errorf("advance announcement: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteAnnouncement 删除公告
+func (s *ScheduleStore) DeleteAnnouncement(ctx context.Context, id uint) error {
+	result := s.db.WithContext(ctx).Delete(&schedule.Announcement{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("delete announcement: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return schedule.ErrNotFound
+	}
+	return nil
+}
+
+// CreateWindow 创建维护窗口
+func (s *ScheduleStore) CreateWindow(ctx context.Context, w *schedule.MaintenanceWindow) error {
+	if err := s.db.WithContext(ctx).Create(w).Error; err != nil {
+		return fmt.Errorf("create maintenance window: %w", err)
+	}
+	return nil
+}
+
+// ListWindows 列出尚未结束的维护窗口
+func (s *ScheduleStore) ListWindows(ctx context.Context, now time.Time) ([]*schedule.MaintenanceWindow, error) {
+	var list []*schedule.MaintenanceWindow
+	err := s.db.WithContext(ctx).
+		Where("ends_at > ?", now).
+		Order("starts_at ASC").
+		Find(&list).Error
+	if err != nil {
+		return nil, fmt.Errorf("list maintenance windows: %w", err)
+	}
+	return list, nil
+}
+
+// ActiveWindow 获取进行中的维护窗口
+func (s *ScheduleStore) ActiveWindow(ctx context.Context, now time.Time) (*schedule.MaintenanceWindow, error) {
+	var w schedule.MaintenanceWindow
+	err := s.db.WithContext(ctx).
+		Where("starts_at <= ? AND ends_at > ?", now, now).
+		Order("ends_at DESC").
+		First(&w).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, schedule.ErrNotFound
+		}
+		return nil, fmt.Errorf("get active maintenance window: %w", err)
+	}
+	return &w, nil
+}
+
+// DeleteWindow 删除维护窗口
+func (s *ScheduleStore) DeleteWindow(ctx context.Context, id uint) error {
+	result := s.db.WithContext(ctx).Delete(&schedule.MaintenanceWindow{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("delete maintenance window: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return schedule.ErrNotFound
+	}
+	return nil
+}
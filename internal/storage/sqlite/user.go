@@ -5,9 +5,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
+	"emby-telegram/internal/account"
 	"emby-telegram/internal/user"
 )
 
@@ -80,6 +84,84 @@ func (s *UserStore) List(ctx context.Context, offset, limit int) ([]*user.User,
 	return users, nil
 }
 
+// Search 按 Telegram ID、username 前缀或姓名搜索用户
+// 纯数字查询同时匹配 Telegram ID，开头的 @ 会被忽略
+func (s *UserStore) Search(ctx context.Context, query string, limit int) ([]*user.User, error) {
+	query = strings.TrimPrefix(strings.TrimSpace(query), "@")
+	if query == "" {
+		return nil, nil
+	}
+
+	var users []*user.User
+	db := s.db.WithContext(ctx).Where(s.searchCondition(query)).Order("created_at DESC")
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+
+	if err := db.Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("search users: %w", err)
+	}
+	return users, nil
+}
+
+// ListByQuery 按查询条件列出用户，同时返回符合条件的总数
+func (s *UserStore) ListByQuery(ctx context.Context, q user.ListQuery) ([]*user.User, int64, error) {
+	query := s.db.WithContext(ctx).Model(&user.User{})
+
+	switch q.Filter {
+	case user.FilterAll:
+	case user.FilterBlocked:
+		query = query.Where("is_blocked = ?", true)
+	case user.FilterBotBlocked:
+		query = query.Where("bot_blocked_at IS NOT NULL")
+	default:
+		return nil, 0, fmt.Errorf("unknown user filter %q", q.Filter)
+	}
+
+	if search := strings.TrimPrefix(strings.TrimSpace(q.Search), "@"); search != "" {
+		query = query.Where(s.searchCondition(search))
+	}
+
+	// 计数和查询共用筛选条件
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("count users by query: %w", err)
+	}
+
+	switch q.Sort {
+	case user.SortUsername:
+		// 没有 username 的用户排在最后
+		query = query.Order("username IS NULL OR username = '', username ASC")
+	default:
+		query = query.Order("created_at DESC")
+	}
+
+	if q.Limit > 0 {
+		query = query.Limit(q.Limit).Offset(q.Offset)
+	}
+
+	var users []*user.User
+	if err := query.Find(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("list users by query: %w", err)
+	}
+	return users, total, nil
+}
+
+// searchCondition 构造用户搜索条件
+// 匹配 username 前缀或姓名，纯数字查询同时匹配 Telegram ID
+func (s *UserStore) searchCondition(query string) *gorm.DB {
+	pattern := escapeLike(query)
+	cond := s.db.Where("username LIKE ? ESCAPE '!'", pattern+"%").
+		Or("first_name LIKE ? ESCAPE '!'", "%"+pattern+"%").
+		Or("last_name LIKE ? ESCAPE '!'", "%"+pattern+"%")
+	if telegramID, err := strconv.ParseInt(query, 10, 64); err == nil {
+		cond = cond.Or("telegram_id = ?", telegramID)
+	}
+	return cond
+}
+
 // Update 更新用户
 func (s *UserStore) Update(ctx context.Context, u *user.User) error {
 	if err := s.db.WithContext(ctx).Save(u).Error; err != nil {
@@ -116,3 +198,47 @@ func (s *UserStore) CountByRole(ctx context.Context, role user.Role) (int64, err
 	}
 	return count, nil
 }
+
+// ListByAudience 列出群发目标用户，按 ID 排序
+func (s *UserStore) ListByAudience(ctx context.Context, audience user.Audience) ([]*user.User, error) {
+	now := time.Now()
+	query := s.db.WithContext(ctx).Where("is_blocked = ?", false).Order("id ASC")
+
+	// 按账号筛选时只看未删除的账号
+	accounts := s.db.Table("accounts").Select("user_id").Where("deleted_at IS NULL")
+
+	switch audience.Kind {
+	case user.AudienceAll:
+	case user.AudienceActive:
+		query = query.Where("id IN (?)", accounts.
+			Where("status = ?", account.StatusActive).
+			Where("expire_at IS NULL OR expire_at > ?", now))
+	case user.AudienceExpiring:
+		query = query.Where("id IN (?)", accounts.
+			Where("status = ?", account.StatusActive).
+			Where("expire_at > ? AND expire_at <= ?", now, now.AddDate(0, 0, audience.Days)))
+	case user.AudiencePlan:
+		query = query.Where("id IN (?)", accounts.Where("policy_template_id IN ?", audience.TemplateIDs))
+	case user.AudienceRole:
+		query = query.Where("role = ?", audience.Role)
+	default:
+		return nil, fmt.Errorf("unknown audience %q", audience.Kind)
+	}
+
+	var users []*user.User
+	if err := query.Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("list users by audience: %w", err)
+	}
+	return users, nil
+}
+
+// SetBotBlocked 设置或清除屏蔽 Bot 标记
+func (s *UserStore) SetBotBlocked(ctx context.Context, telegramID int64, at *time.Time) error {
+	if err := s.db.WithContext(ctx).
+		Model(&user.User{}).
+		Where("telegram_id = ?", telegramID).
+		Update("bot_blocked_at", at).Error; err != nil {
+		return fmt.Errorf("set bot blocked: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+// Package user 群发消息目标
+package user
+
+// AudienceKind 群发目标类型
+type AudienceKind string
+
+const (
+	// AudienceAll 全部未封禁用户
+	AudienceAll AudienceKind = "all"
+	// AudienceActive 拥有未过期激活账号的用户
+	AudienceActive AudienceKind = "active"
+	// AudienceExpiring 有激活账号将在指定天数内到期的用户
+	AudienceExpiring AudienceKind = "expiring"
+	// AudiencePlan 有账号使用指定策略模板的用户
+	AudiencePlan AudienceKind = "plan"
+	// AudienceRole 指定角色的用户
+	AudienceRole AudienceKind = "role"
+)
+
+// Audience 群发目标
+// 被封禁的用户始终排除；曾屏蔽 Bot 的用户仍会尝试发送，送达后清除标记
+type Audience struct {
+	Kind        AudienceKind
+	Days        int    // AudienceExpiring: 到期天数
+	TemplateIDs []uint // AudiencePlan: 策略模板 ID，使用默认模板的账号记为 0
+	Role        Role   // AudienceRole: 角色名称
+}
+
+// IsValidAudienceKind 检查群发目标类型是否有效
+func IsValidAudienceKind(kind AudienceKind) bool {
+	switch kind {
+	case AudienceAll, AudienceActive, AudienceExpiring, AudiencePlan, AudienceRole:
+		return true
+	}
+	return false
+}
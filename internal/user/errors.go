@@ -22,6 +22,30 @@ var (
 
 	// ErrInvalidRole 无效角色
 	ErrInvalidRole = errors.New("invalid role")
+
+	// ErrConfigAdmin 配置文件中的管理员不能被降级
+	ErrConfigAdmin = errors.New("user is a configured admin")
+
+	// ErrRoleNotFound 角色不存在
+	ErrRoleNotFound = errors.New("role not found")
+
+	// ErrRoleExists 角色已存在
+	ErrRoleExists = errors.New("role already exists")
+
+	// ErrBuiltinRole 内置角色不能删除
+	ErrBuiltinRole = errors.New("builtin role cannot be deleted")
+
+	// ErrImmutableRole 管理员角色拥有全部权限，不能修改
+	ErrImmutableRole = errors.New("admin role cannot be modified")
+
+	// ErrRoleInUse 角色仍被用户使用
+	ErrRoleInUse = errors.New("role is in use")
+
+	// ErrInvalidPermission 无效权限
+	ErrInvalidPermission = errors.New("invalid permission")
+
+	// ErrInvalidAudience 无效群发目标
+	ErrInvalidAudience = errors.New("invalid audience")
 )
 
 // NotFoundError 创建用户不存在错误
@@ -48,3 +72,18 @@ func UnauthorizedError(action string) error {
 func InvalidRoleError(role string) error {
 	return fmt.Errorf("role %q: %w", role, ErrInvalidRole)
 }
+
+// ConfigAdminError 创建配置管理员不能降级错误
+func ConfigAdminError(telegramID int64) error {
+	return fmt.Errorf("user with telegram_id %d: %w", telegramID, ErrConfigAdmin)
+}
+
+// RoleNotFoundError 创建角色不存在错误
+func RoleNotFoundError(name Role) error {
+	return fmt.Errorf("role %q: %w", name, ErrRoleNotFound)
+}
+
+// InvalidPermissionError 创建无效权限错误
+func InvalidPermissionError(p Permission) error {
+	return fmt.Errorf("permission %q: %w", p, ErrInvalidPermission)
+}
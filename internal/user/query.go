@@ -0,0 +1,33 @@
+// Package user 列表查询条件
+package user
+
+// Filter 用户列表筛选条件
+type Filter string
+
+const (
+	// FilterAll 不筛选
+	FilterAll Filter = ""
+	// FilterBlocked 已被封禁
+	FilterBlocked Filter = "blocked"
+	// FilterBotBlocked 已屏蔽 Bot
+	FilterBotBlocked Filter = "bot_blocked"
+)
+
+// Sort 用户列表排序方式
+type Sort string
+
+const (
+	// SortCreated 按注册时间倒序
+	SortCreated Sort = ""
+	// SortUsername 按 Telegram username 升序
+	SortUsername Sort = "username"
+)
+
+// ListQuery 用户列表查询条件
+type ListQuery struct {
+	Filter Filter
+	Sort   Sort
+	Search string // 匹配 Telegram ID、username 前缀或姓名
+	Offset int
+	Limit  int // 0 表示不分页
+}
@@ -0,0 +1,94 @@
+// Package user 角色与权限定义
+package user
+
+import (
+	"slices"
+	"time"
+)
+
+// Permission 权限名称
+type Permission string
+
+const (
+	PermAccountView     Permission = "account.view"     // 查看所有账号
+	PermAccountCreate   Permission = "account.create"   // 为任意用户创建账号
+	PermAccountRenew    Permission = "account.renew"    // 续期任意账号
+	PermAccountPassword Permission = "account.password" // 修改任意账号密码
+	PermAccountSuspend  Permission = "account.suspend"  // 停用/启用账号
+	PermAccountDelete   Permission = "account.delete"   // 删除账号
+	PermAccountTransfer Permission = "account.transfer" // 将账号转移给其他用户
+	PermAccountPolicy   Permission = "account.policy"   // 管理策略模板、账号策略与媒体库
+	PermCustomerManage  Permission = "customer.manage"  // 为自己的客户创建、续期账号，只能看到自己创建的账号
+	PermSessionView     Permission = "session.view"     // 查看播放会话与统计
+	PermStatsView       Permission = "stats.view"       // 查看系统统计
+	PermUserManage      Permission = "user.manage"      // 用户授权、封禁
+	PermInviteManage    Permission = "invite.manage"    // 管理邀请码
+	PermEmbyManage      Permission = "emby.manage"      // Emby 服务器检查与同步
+	PermBroadcast       Permission = "broadcast.send"   // 向用户群发消息
+	PermScheduleManage  Permission = "schedule.manage"  // 管理定时公告与维护窗口
+)
+
+// AllPermissions 全部权限，按展示顺序排列
+// 权限说明在语言包中，键为 perm.<权限名>
+var AllPermissions = []Permission{
+	PermAccountView,
+	PermAccountCreate,
+	PermAccountRenew,
+	PermAccountPassword,
+	PermAccountSuspend,
+	PermAccountDelete,
+	PermAccountTransfer,
+	PermAccountPolicy,
+	PermCustomerManage,
+	PermSessionView,
+	PermStatsView,
+	PermUserManage,
+	PermInviteManage,
+	PermEmbyManage,
+	PermBroadcast,
+	PermScheduleManage,
+}
+
+// IsValidPermission 检查权限名称是否有效
+func IsValidPermission(p Permission) bool {
+	return slices.Contains(AllPermissions, p)
+}
+
+// RoleDefinition 角色定义
+// 管理员角色始终拥有全部权限，不读取权限列表，也不允许修改
+type RoleDefinition struct {
+	ID          uint         `gorm:"primarykey" json:"id"`
+	Name        Role         `gorm:"uniqueIndex;size:20;not null" json:"name"`
+	Description string       `gorm:"size:200" json:"description"`
+	Permissions []Permission `gorm:"serializer:json;type:text" json:"permissions"`
+	Builtin     bool         `gorm:"not null" json:"builtin"` // 内置角色不能删除
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (RoleDefinition) TableName() string {
+	return "roles"
+}
+
+// Has 检查角色是否拥有指定权限
+func (r *RoleDefinition) Has(p Permission) bool {
+	if r.Name == RoleAdmin {
+		return true
+	}
+	return slices.Contains(r.Permissions, p)
+}
+
+// Grant 授予权限
+func (r *RoleDefinition) Grant(p Permission) {
+	if !slices.Contains(r.Permissions, p) {
+		r.Permissions = append(r.Permissions, p)
+	}
+}
+
+// Revoke 收回权限
+func (r *RoleDefinition) Revoke(p Permission) {
+	r.Permissions = slices.DeleteFunc(r.Permissions, func(existing Permission) bool {
+		return existing == p
+	})
+}
@@ -0,0 +1,179 @@
+// Package user 角色与权限管理
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// roleNamePattern 自定义角色名称格式
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,19}$`)
+
+// Role 获取角色定义
+func (s *Service) Role(ctx context.Context, name Role) (*RoleDefinition, error) {
+	s.roleMu.RLock()
+	role, ok := s.roleCache[name]
+	s.roleMu.RUnlock()
+	if ok {
+		return role, nil
+	}
+
+	if err := s.loadRoles(ctx); err != nil {
+		return nil, err
+	}
+
+	s.roleMu.RLock()
+	defer s.roleMu.RUnlock()
+	if role, ok := s.roleCache[name]; ok {
+		return role, nil
+	}
+	return nil, RoleNotFoundError(name)
+}
+
+// ListRoles 列出所有角色
+func (s *Service) ListRoles(ctx context.Context) ([]*RoleDefinition, error) {
+	roles, err := s.roles.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list roles: %w", err)
+	}
+	return roles, nil
+}
+
+// CreateRole 创建自定义角色，初始没有任何权限
+func (s *Service) CreateRole(ctx context.Context, name, description string) (*RoleDefinition, error) {
+	if !roleNamePattern.MatchString(name) {
+		return nil, InvalidRoleError(name)
+	}
+
+	if _, err := s.roles.Get(ctx, Role(name)); err == nil {
+		return nil, fmt.Errorf("role %q: %w", name, ErrRoleExists)
+	} else if !errors.Is(err, ErrRoleNotFound) {
+		return nil, fmt.Errorf("get role: %w", err)
+	}
+
+	role := &RoleDefinition{
+		Name:        Role(name),
+		Description: description,
+		Permissions: []Permission{},
+	}
+	if err := s.roles.Create(ctx, role); err != nil {
+		return nil, fmt.Errorf("create role: %w", err)
+	}
+
+	s.invalidateRoles()
+	return role, nil
+}
+
+// SetRolePermission 授予或收回角色的权限
+func (s *Service) SetRolePermission(ctx context.Context, name Role, perm Permission, granted bool) (*RoleDefinition, error) {
+	if !IsValidPermission(perm) {
+		return nil, InvalidPermissionError(perm)
+	}
+	if name == RoleAdmin {
+		return nil, ErrImmutableRole
+	}
+
+	role, err := s.roles.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if granted {
+		role.Grant(perm)
+	} else {
+		role.Revoke(perm)
+	}
+
+	if err := s.roles.Update(ctx, role); err != nil {
+		return nil, fmt.Errorf("update role: %w", err)
+	}
+
+	s.invalidateRoles()
+	return role, nil
+}
+
+// DeleteRole 删除自定义角色，内置角色和仍有用户使用的角色不能删除
+func (s *Service) DeleteRole(ctx context.Context, name Role) error {
+	role, err := s.roles.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.Builtin {
+		return fmt.Errorf("role %q: %w", name, ErrBuiltinRole)
+	}
+
+	count, err := s.store.CountByRole(ctx, name)
+	if err != nil {
+		return fmt.Errorf("count users by role: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("role %q has %d users: %w", name, count, ErrRoleInUse)
+	}
+
+	if err := s.roles.Delete(ctx, name); err != nil {
+		return fmt.Errorf("delete role: %w", err)
+	}
+
+	s.invalidateRoles()
+	return nil
+}
+
+// Can 检查用户是否拥有指定权限
+// 管理员拥有全部权限；角色查询失败时按无权限处理
+func (s *Service) Can(ctx context.Context, u *User, perm Permission) bool {
+	if u == nil {
+		return false
+	}
+	if u.IsAdmin() {
+		return true
+	}
+
+	role, err := s.Role(ctx, u.Role)
+	if err != nil {
+		return false
+	}
+	return role.Has(perm)
+}
+
+// PermissionsOf 返回用户拥有的全部权限
+func (s *Service) PermissionsOf(ctx context.Context, u *User) []Permission {
+	var perms []Permission
+	for _, perm := range AllPermissions {
+		if s.Can(ctx, u, perm) {
+			perms = append(perms, perm)
+		}
+	}
+	return perms
+}
+
+// IsStaff 检查用户是否拥有任一管理权限
+func (s *Service) IsStaff(ctx context.Context, u *User) bool {
+	return len(s.PermissionsOf(ctx, u)) > 0
+}
+
+// loadRoles 从存储加载全部角色到缓存
+func (s *Service) loadRoles(ctx context.Context) error {
+	roles, err := s.roles.List(ctx)
+	if err != nil {
+		return fmt.Errorf("list roles: %w", err)
+	}
+
+	cache := make(map[Role]*RoleDefinition, len(roles))
+	for _, role := range roles {
+		cache[role.Name] = role
+	}
+
+	s.roleMu.Lock()
+	s.roleCache = cache
+	s.roleMu.Unlock()
+	return nil
+}
+
+// invalidateRoles 清空角色缓存
+func (s *Service) invalidateRoles() {
+	s.roleMu.Lock()
+	s.roleCache = nil
+	s.roleMu.Unlock()
+}
@@ -5,22 +5,67 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // Service 用户业务服务
+// 管理员身份以数据库中的角色为准，配置中的管理员在启动和首次使用时写入角色
 type Service struct {
-	store Store
+	store        Store
+	roles        RoleStore
+	configAdmins map[int64]bool // 配置文件中的管理员 Telegram ID
+
+	roleMu    sync.RWMutex
+	roleCache map[Role]*RoleDefinition // 角色定义缓存，角色变更时清空
 }
 
 // NewService 创建用户服务实例
-func NewService(store Store) *Service {
+func NewService(store Store, roles RoleStore, adminIDs []int64) *Service {
+	admins := make(map[int64]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = true
+	}
+
 	return &Service{
-		store: store,
+		store:        store,
+		roles:        roles,
+		configAdmins: admins,
 	}
 }
 
+// BootstrapAdmins 将配置中的管理员同步为数据库管理员角色
+// 尚未使用过 Bot 的管理员在首次创建用户时获得管理员角色
+func (s *Service) BootstrapAdmins(ctx context.Context) (int, error) {
+	promoted := 0
+	for telegramID := range s.configAdmins {
+		user, err := s.store.GetByTelegramID(ctx, telegramID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return promoted, fmt.Errorf("get admin %d: %w", telegramID, err)
+		}
+		if user.IsAdmin() {
+			continue
+		}
+
+		user.SetRole(RoleAdmin)
+		if err := s.store.Update(ctx, user); err != nil {
+			return promoted, fmt.Errorf("promote admin %d: %w", telegramID, err)
+		}
+		promoted++
+	}
+	return promoted, nil
+}
+
+// IsConfigAdmin 检查是否为配置文件中的管理员
+func (s *Service) IsConfigAdmin(telegramID int64) bool {
+	return s.configAdmins[telegramID]
+}
+
 // GetOrCreate 获取或创建用户
 // 如果用户不存在则自动创建
 func (s *Service) GetOrCreate(ctx context.Context, tgUser *tgbotapi.User) (*User, error) {
@@ -32,12 +77,17 @@ func (s *Service) GetOrCreate(ctx context.Context, tgUser *tgbotapi.User) (*User
 
 	// 如果不存在，创建新用户
 	if errors.Is(err, ErrNotFound) {
+		role := RoleUser
+		if s.configAdmins[tgUser.ID] {
+			role = RoleAdmin
+		}
+
 		user = &User{
 			TelegramID: tgUser.ID,
 			Username:   tgUser.UserName,
 			FirstName:  tgUser.FirstName,
 			LastName:   tgUser.LastName,
-			Role:       RoleUser,
+			Role:       role,
 			IsBlocked:  false,
 		}
 
@@ -64,11 +114,22 @@ func (s *Service) Get(ctx context.Context, id uint) (*User, error) {
 func (s *Service) GetByTelegramID(ctx context.Context, telegramID int64) (*User, error) {
 	user, err := s.store.GetByTelegramID(ctx, telegramID)
 	if err != nil {
-		return nil, NotFoundError(telegramID)
+		if errors.Is(err, ErrNotFound) {
+			return nil, NotFoundError(telegramID)
+		}
+		return nil, fmt.Errorf("get user: %w", err)
 	}
 	return user, nil
 }
 
+// Import 导入其他实例导出的用户记录
+func (s *Service) Import(ctx context.Context, user *User) error {
+	if err := s.store.Create(ctx, user); err != nil {
+		return fmt.Errorf("import user: %w", err)
+	}
+	return nil
+}
+
 // List 列出所有用户(分页)
 func (s *Service) List(ctx context.Context, offset, limit int) ([]*User, error) {
 	users, err := s.store.List(ctx, offset, limit)
@@ -78,17 +139,38 @@ func (s *Service) List(ctx context.Context, offset, limit int) ([]*User, error)
 	return users, nil
 }
 
-// SetRole 设置用户角色
+// ListByQuery 按查询条件列出用户，同时返回符合条件的总数
+func (s *Service) ListByQuery(ctx context.Context, q ListQuery) ([]*User, int64, error) {
+	users, total, err := s.store.ListByQuery(ctx, q)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list users: %w", err)
+	}
+	return users, total, nil
+}
+
+// Search 按 Telegram ID、username 或姓名搜索用户
+func (s *Service) Search(ctx context.Context, query string, limit int) ([]*User, error) {
+	users, err := s.store.Search(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("search users: %w", err)
+	}
+	return users, nil
+}
+
+// SetRole 设置用户角色，角色必须已在角色表中定义
 func (s *Service) SetRole(ctx context.Context, telegramID int64, role string) error {
 	// 验证角色
-	var userRole Role
-	switch role {
-	case "admin":
-		userRole = RoleAdmin
-	case "user":
-		userRole = RoleUser
-	default:
-		return InvalidRoleError(role)
+	userRole := Role(role)
+	if _, err := s.Role(ctx, userRole); err != nil {
+		if errors.Is(err, ErrRoleNotFound) {
+			return InvalidRoleError(role)
+		}
+		return err
+	}
+
+	// 配置中的管理员下次启动时会被重新提升，这里直接拒绝降级
+	if userRole != RoleAdmin && s.configAdmins[telegramID] {
+		return ConfigAdminError(telegramID)
 	}
 
 	user, err := s.store.GetByTelegramID(ctx, telegramID)
@@ -242,3 +324,49 @@ func (s *Service) MarkInviteCodeUsed(ctx context.Context, userID uint) error {
 
 	return nil
 }
+
+// SetLanguage 设置用户界面语言，空字符串表示跟随 Telegram 客户端
+func (s *Service) SetLanguage(ctx context.Context, userID uint, lang string) error {
+	user, err := s.store.Get(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+
+	user.Language = lang
+
+	if err := s.store.Update(ctx, user); err != nil {
+		return fmt.Errorf("update user: %w", err)
+	}
+
+	return nil
+}
+
+// ListByAudience 列出群发目标用户
+func (s *Service) ListByAudience(ctx context.Context, audience Audience) ([]*User, error) {
+	if !IsValidAudienceKind(audience.Kind) {
+		return nil, fmt.Errorf("audience %q: %w", audience.Kind, ErrInvalidAudience)
+	}
+
+	users, err := s.store.ListByAudience(ctx, audience)
+	if err != nil {
+		return nil, fmt.Errorf("list users by audience: %w", err)
+	}
+	return users, nil
+}
+
+// MarkBotBlocked 标记用户屏蔽了 Bot
+func (s *Service) MarkBotBlocked(ctx context.Context, telegramID int64) error {
+	now := time.Now()
+	if err := s.store.SetBotBlocked(ctx, telegramID, &now); err != nil {
+		return fmt.Errorf("mark bot blocked: %w", err)
+	}
+	return nil
+}
+
+// ClearBotBlocked 清除屏蔽 Bot 标记
+func (s *Service) ClearBotBlocked(ctx context.Context, telegramID int64) error {
+	if err := s.store.SetBotBlocked(ctx, telegramID, nil); err != nil {
+		return fmt.Errorf("clear bot blocked: %w", err)
+	}
+	return nil
+}
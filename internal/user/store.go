@@ -1,7 +1,10 @@
 // Package user 存储接口定义
 package user
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Store 用户存储接口
 type Store interface {
@@ -20,6 +23,12 @@ type Store interface {
 	// List 列出所有用户(分页)
 	List(ctx context.Context, offset, limit int) ([]*User, error)
 
+	// Search 按 Telegram ID、username 前缀或姓名搜索用户
+	Search(ctx context.Context, query string, limit int) ([]*User, error)
+
+	// ListByQuery 按查询条件列出用户，同时返回符合条件的总数
+	ListByQuery(ctx context.Context, q ListQuery) ([]*User, int64, error)
+
 	// Update 更新用户
 	Update(ctx context.Context, user *User) error
 
@@ -31,4 +40,28 @@ type Store interface {
 
 	// CountByRole 统计指定角色的用户数量
 	CountByRole(ctx context.Context, role Role) (int64, error)
+
+	// ListByAudience 列出群发目标用户，按 ID 排序
+	ListByAudience(ctx context.Context, audience Audience) ([]*User, error)
+
+	// SetBotBlocked 设置或清除屏蔽 Bot 标记，at 为 nil 表示清除
+	SetBotBlocked(ctx context.Context, telegramID int64, at *time.Time) error
+}
+
+// RoleStore 角色存储接口
+type RoleStore interface {
+	// Create 创建角色
+	Create(ctx context.Context, role *RoleDefinition) error
+
+	// Get 根据名称获取角色
+	Get(ctx context.Context, name Role) (*RoleDefinition, error)
+
+	// List 列出所有角色
+	List(ctx context.Context) ([]*RoleDefinition, error)
+
+	// Update 更新角色
+	Update(ctx context.Context, role *RoleDefinition) error
+
+	// Delete 删除角色
+	Delete(ctx context.Context, name Role) error
 }
@@ -13,8 +13,12 @@ type Role string
 const (
 	// RoleUser 普通用户
 	RoleUser Role = "user"
-	// RoleAdmin 管理员
+	// RoleAdmin 管理员(超级管理员)，拥有全部权限
 	RoleAdmin Role = "admin"
+	// RoleReseller 代理商，为自己的客户创建和续期账号
+	RoleReseller Role = "reseller"
+	// RoleSupport 客服，查看账号和播放会话
+	RoleSupport Role = "support"
 )
 
 // User 用户实体
@@ -28,6 +32,8 @@ type User struct {
 	IsBlocked      bool           `gorm:"default:false" json:"is_blocked"`
 	AccountQuota   int            `gorm:"default:0" json:"account_quota"`
 	UsedInviteCode bool           `gorm:"default:false" json:"used_invite_code"`
+	Language       string         `gorm:"size:10;not null" json:"language"` // 界面语言，为空时跟随 Telegram 客户端
+	BotBlockedAt   *time.Time     `json:"bot_blocked_at,omitempty"`         // 发现用户屏蔽 Bot 的时间，用户再次互动或消息送达后清除
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
@@ -79,6 +85,11 @@ func (u *User) DisplayName() string {
 	return u.FullName()
 }
 
+// HasBlockedBot 检查用户是否屏蔽了 Bot
+func (u *User) HasBlockedBot() bool {
+	return u.BotBlockedAt != nil
+}
+
 // CanAccess 检查用户是否可以访问系统
 func (u *User) CanAccess() bool {
 	return !u.IsBlocked
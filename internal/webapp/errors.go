@@ -0,0 +1,13 @@
+// Package webapp 领域错误定义
+package webapp
+
+import "errors"
+
+// 领域错误定义
+var (
+	// ErrInvalidInitData initData 缺失、格式错误或签名不匹配
+	ErrInvalidInitData = errors.New("invalid web app init data")
+
+	// ErrInitDataExpired initData 超过有效期
+	ErrInitDataExpired = errors.New("web app init data expired")
+)
@@ -0,0 +1,91 @@
+// Package webapp Telegram Mini App 启动参数校验
+package webapp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InitDataUser initData 中的 Telegram 用户
+type InitDataUser struct {
+	ID           int64  `json:"id"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Username     string `json:"username"`
+	LanguageCode string `json:"language_code"`
+}
+
+// InitData 校验通过的 Mini App 启动参数
+type InitData struct {
+	User     InitDataUser
+	AuthDate time.Time
+	QueryID  string
+}
+
+// ParseInitData 校验并解析 Telegram.WebApp.initData
+// 签名算法: secret = HMAC_SHA256("WebAppData", botToken)，
+// hash = hex(HMAC_SHA256(secret, data_check_string))，其中 data_check_string 为除 hash 外的字段按键名排序后以换行连接的 key=value
+// maxAge 大于 0 时拒绝 auth_date 早于 now-maxAge 的数据
+func ParseInitData(raw, botToken string, maxAge time.Duration, now time.Time) (*InitData, error) {
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInitData, err)
+	}
+
+	hash := values.Get("hash")
+	if hash == "" {
+		return nil, fmt.Errorf("%w: missing hash", ErrInvalidInitData)
+	}
+
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		if key == "hash" {
+			continue
+		}
+		pairs = append(pairs, key+"="+values.Get(key))
+	}
+	sort.Strings(pairs)
+
+	secret := hmacSHA256([]byte("WebAppData"), []byte(botToken))
+	expected := hmacSHA256(secret, []byte(strings.Join(pairs, "\n")))
+
+	got, err := hex.DecodeString(hash)
+	if err != nil || !hmac.Equal(got, expected) {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidInitData)
+	}
+
+	authUnix, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid auth_date", ErrInvalidInitData)
+	}
+	authDate := time.Unix(authUnix, 0)
+	if maxAge > 0 && now.Sub(authDate) > maxAge {
+		return nil, ErrInitDataExpired
+	}
+
+	var u InitDataUser
+	if err := json.Unmarshal([]byte(values.Get("user")), &u); err != nil || u.ID == 0 {
+		return nil, fmt.Errorf("%w: invalid user", ErrInvalidInitData)
+	}
+
+	return &InitData{
+		User:     u,
+		AuthDate: authDate,
+		QueryID:  values.Get("query_id"),
+	}, nil
+}
+
+// hmacSHA256 计算 HMAC-SHA256
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
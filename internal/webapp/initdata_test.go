@@ -0,0 +1,69 @@
+package webapp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 测试向量的 hash 由独立实现按 Telegram 文档的算法计算
+const (
+	testBotToken = "123456:TEST-TOKEN"
+	testAuthDate = 1760000000
+	testUserJSON = `%7B%22id%22%3A279058397%2C%22first_name%22%3A%22Alice%22%2C%22last_name%22%3A%22Liddell%22%2C%22username%22%3A%22alice%22%2C%22language_code%22%3A%22en%22%7D`
+	testHash     = "db9f9639ea9cb48f00241115c3ee979d3c04cb9ad9bb2ff913e164dab10fe580"
+	testInitData = "auth_date=1760000000&query_id=AAHdF6IQAAAAAN0XohDhrOrc&user=" + testUserJSON + "&hash=" + testHash
+)
+
+func TestParseInitData(t *testing.T) {
+	authDate := time.Unix(testAuthDate, 0)
+
+	tests := []struct {
+		name    string
+		raw     string
+		token   string
+		maxAge  time.Duration
+		now     time.Time
+		wantErr error
+	}{
+		{name: "valid init data", raw: testInitData, token: testBotToken, maxAge: time.Hour, now: authDate.Add(time.Minute)},
+		{name: "fields in any order", raw: "hash=" + testHash + "&user=" + testUserJSON + "&query_id=AAHdF6IQAAAAAN0XohDhrOrc&auth_date=1760000000", token: testBotToken, maxAge: time.Hour, now: authDate},
+		{name: "no max age", raw: testInitData, token: testBotToken, now: authDate.Add(365 * 24 * time.Hour)},
+		{name: "exactly max age", raw: testInitData, token: testBotToken, maxAge: time.Hour, now: authDate.Add(time.Hour)},
+		{name: "auth_date older than max age", raw: testInitData, token: testBotToken, maxAge: time.Hour, now: authDate.Add(time.Hour + time.Second), wantErr: ErrInitDataExpired},
+		{name: "secret key from other bot", raw: testInitData, token: "654321:OTHER-TOKEN", maxAge: time.Hour, now: authDate, wantErr: ErrInvalidInitData},
+		{name: "tampered user id", raw: strings.Replace(testInitData, "279058397", "279058398", 1), token: testBotToken, maxAge: time.Hour, now: authDate, wantErr: ErrInvalidInitData},
+		{name: "tampered auth_date", raw: strings.Replace(testInitData, "auth_date=1760000000", "auth_date=1760003600", 1), token: testBotToken, maxAge: time.Hour, now: authDate, wantErr: ErrInvalidInitData},
+		{name: "extra field breaks signature", raw: testInitData + "&start_param=x", token: testBotToken, maxAge: time.Hour, now: authDate, wantErr: ErrInvalidInitData},
+		{name: "tampered hash", raw: strings.Replace(testInitData, "hash=db9f", "hash=db9e", 1), token: testBotToken, maxAge: time.Hour, now: authDate, wantErr: ErrInvalidInitData},
+		{name: "non hex hash", raw: strings.Replace(testInitData, testHash, "zz", 1), token: testBotToken, maxAge: time.Hour, now: authDate, wantErr: ErrInvalidInitData},
+		{name: "hash param removed", raw: strings.TrimSuffix(testInitData, "&hash="+testHash), token: testBotToken, maxAge: time.Hour, now: authDate, wantErr: ErrInvalidInitData},
+		{name: "malformed query", raw: "user=%zz", token: testBotToken, maxAge: time.Hour, now: authDate, wantErr: ErrInvalidInitData},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ParseInitData(tt.raw, tt.token, tt.maxAge, tt.now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseInitData() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseInitData() error = %v", err)
+			}
+			want := InitDataUser{ID: 279058397, FirstName: "Alice", LastName: "Liddell", Username: "alice", LanguageCode: "en"}
+			if data.User != want {
+				t.Errorf("User = %+v, want %+v", data.User, want)
+			}
+			if !data.AuthDate.Equal(authDate) {
+				t.Errorf("AuthDate = %v, want %v", data.AuthDate, authDate)
+			}
+			if data.QueryID != "AAHdF6IQAAAAAN0XohDhrOrc" {
+				t.Errorf("QueryID = %q", data.QueryID)
+			}
+		})
+	}
+}
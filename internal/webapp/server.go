@@ -0,0 +1,385 @@
+// Package webapp Telegram Mini App 账号管理
+// 页面由 Bot 主菜单以 Mini App 方式打开，接口通过 initData 识别 Telegram 用户，
+// 只允许操作用户自己的账号，与 Bot 中的账号归属规则一致
+package webapp
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"emby-telegram/internal/account"
+	"emby-telegram/internal/i18n"
+	"emby-telegram/internal/logger"
+	"emby-telegram/internal/user"
+	"emby-telegram/pkg/timeutil"
+	"emby-telegram/pkg/validator"
+)
+
+//go:embed static
+var staticFS embed.FS
+
+// authScheme Authorization 请求头中携带 initData 的方案名
+const authScheme = "tma "
+
+// maxBodySize 请求体大小上限
+const maxBodySize = 4 << 10
+
+// renewDays 页面提供的续期天数选项，与 Bot 的续期按钮一致
+var renewDays = []int{7, 30, 90, 365}
+
+// Server Mini App HTTP 服务
+type Server struct {
+	accounts *account.Service
+	users    *user.Service
+	catalog  *i18n.Catalog
+	botToken string
+	basePath string
+	maxAge   time.Duration
+}
+
+// NewServer 创建 Mini App 服务
+// basePath 为页面所在路径(以 / 结尾)，maxAge 为 initData 有效期，0 表示不检查
+func NewServer(accounts *account.Service, users *user.Service, catalog *i18n.Catalog, botToken, basePath string, maxAge time.Duration) *Server {
+	if !strings.HasSuffix(basePath, "/") {
+		basePath += "/"
+	}
+	return &Server{
+		accounts: accounts,
+		users:    users,
+		catalog:  catalog,
+		botToken: botToken,
+		basePath: basePath,
+		maxAge:   maxAge,
+	}
+}
+
+// BasePath 返回页面所在路径
+func (s *Server) BasePath() string {
+	return s.basePath
+}
+
+// Handler 返回 Mini App 的路由
+func (s *Server) Handler() http.Handler {
+	static, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		panic("webapp: static files not embedded")
+	}
+	files := http.StripPrefix(s.basePath, http.FileServerFS(static))
+
+	mux := http.NewServeMux()
+	mux.Handle("GET "+s.basePath+"{$}", files)
+	mux.Handle("GET "+s.basePath+"app.js", files)
+	mux.Handle("GET "+s.basePath+"style.css", files)
+
+	mux.Handle("GET "+s.basePath+"api/me", s.api(s.handleMe))
+	mux.Handle("GET "+s.basePath+"api/accounts", s.api(s.handleListAccounts))
+	mux.Handle("GET "+s.basePath+"api/accounts/{id}", s.api(s.handleAccountInfo))
+	mux.Handle("POST "+s.basePath+"api/accounts/{id}/renew", s.api(s.handleRenew))
+	mux.Handle("POST "+s.basePath+"api/accounts/{id}/password", s.api(s.handleChangePassword))
+	mux.Handle("GET "+s.basePath+"api/accounts/{id}/devices", s.api(s.handleDevices))
+
+	return mux
+}
+
+// caller 发起请求的用户
+type caller struct {
+	user *user.User
+	loc  *i18n.Localizer
+}
+
+// apiFunc 接口处理函数，返回值序列化为 JSON
+type apiFunc func(r *http.Request, c *caller) (any, error)
+
+// apiError 接口层的请求错误
+type apiError struct {
+	status int
+	code   string
+	key    string
+}
+
+func (e *apiError) Error() string {
+	return e.code
+}
+
+var (
+	errUnauthorized  = &apiError{http.StatusUnauthorized, "unauthorized", "webapp.error_unauthorized"}
+	errNotRegistered = &apiError{http.StatusForbidden, "not_registered", "webapp.error_not_registered"}
+	errBlocked       = &apiError{http.StatusForbidden, "blocked", "webapp.error_blocked"}
+	errBadRequest    = &apiError{http.StatusBadRequest, "bad_request", "webapp.error_bad_request"}
+)
+
+// api 校验 initData、识别用户后调用处理函数
+func (s *Server) api(fn apiFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+		c, err := s.authenticate(r)
+		if err != nil {
+			loc := s.catalog.Localizer(s.catalog.DefaultLanguage())
+			if c != nil {
+				loc = c.loc
+			}
+			s.writeError(w, loc, err)
+			return
+		}
+
+		result, err := fn(r, c)
+		if err != nil {
+			s.writeError(w, c.loc, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+}
+
+// authenticate 校验 Authorization 请求头中的 initData 并查找对应用户
+func (s *Server) authenticate(r *http.Request) (*caller, error) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, authScheme) {
+		return nil, errUnauthorized
+	}
+
+	data, err := ParseInitData(strings.TrimPrefix(header, authScheme), s.botToken, s.maxAge, time.Now())
+	if err != nil {
+		logger.Warnf("web app request rejected from %s: %v", r.RemoteAddr, err)
+		return nil, errUnauthorized
+	}
+
+	u, err := s.users.GetByTelegramID(r.Context(), data.User.ID)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return nil, errNotRegistered
+		}
+		return nil, err
+	}
+
+	lang := u.Language
+	if lang == "" || !s.catalog.Supports(lang) {
+		lang = s.catalog.Match(data.User.LanguageCode)
+	}
+	c := &caller{user: u, loc: s.catalog.Localizer(lang)}
+
+	if !u.CanAccess() {
+		return c, errBlocked
+	}
+	return c, nil
+}
+
+// accountView 账号信息
+type accountView struct {
+	ID         uint       `json:"id"`
+	Username   string     `json:"username"`
+	Status     string     `json:"status"`
+	ExpireAt   *time.Time `json:"expire_at"`
+	Expired    bool       `json:"expired"`
+	DaysLeft   int        `json:"days_left"`
+	MaxDevices int        `json:"max_devices"`
+	SyncStatus string     `json:"sync_status"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// newAccountView 转换账号信息，不包含密码等敏感字段
+func newAccountView(acc *account.Account) accountView {
+	v := accountView{
+		ID:         acc.ID,
+		Username:   acc.Username,
+		Status:     string(acc.Status),
+		ExpireAt:   acc.ExpireAt,
+		Expired:    timeutil.IsExpired(acc.ExpireAt),
+		MaxDevices: acc.MaxDevices,
+		SyncStatus: acc.SyncStatus,
+		CreatedAt:  acc.CreatedAt,
+	}
+	if acc.ExpireAt != nil {
+		v.DaysLeft = timeutil.DaysUntil(*acc.ExpireAt)
+	}
+	return v
+}
+
+// handleMe 返回当前用户、页面文案和续期选项
+func (s *Server) handleMe(r *http.Request, c *caller) (any, error) {
+	return map[string]any{
+		"telegram_id": c.user.TelegramID,
+		"name":        c.user.FirstName,
+		"language":    c.loc.Language(),
+		"renew_days":  renewDays,
+		"messages":    s.catalog.Prefix(c.loc.Language(), "webapp."),
+	}, nil
+}
+
+// handleListAccounts 列出用户自己的账号
+func (s *Server) handleListAccounts(r *http.Request, c *caller) (any, error) {
+	accs, err := s.accounts.ListByUser(r.Context(), c.user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]accountView, 0, len(accs))
+	for _, acc := range accs {
+		views = append(views, newAccountView(acc))
+	}
+	return views, nil
+}
+
+// handleAccountInfo 获取账号详情
+func (s *Server) handleAccountInfo(r *http.Request, c *caller) (any, error) {
+	acc, err := s.ownedAccount(r, c)
+	if err != nil {
+		return nil, err
+	}
+	return newAccountView(acc), nil
+}
+
+// handleRenew 续期账号
+func (s *Server) handleRenew(r *http.Request, c *caller) (any, error) {
+	acc, err := s.ownedAccount(r, c)
+	if err != nil {
+		return nil, err
+	}
+
+	var req struct {
+		Days int `json:"days"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errBadRequest
+	}
+
+	if err := s.accounts.Renew(r.Context(), acc.ID, req.Days); err != nil {
+		return nil, err
+	}
+	logger.Infof("web app: user %d renewed account %s by %d days", c.user.TelegramID, acc.Username, req.Days)
+
+	acc, err = s.accounts.Get(r.Context(), acc.ID)
+	if err != nil {
+		return nil, err
+	}
+	return newAccountView(acc), nil
+}
+
+// handleChangePassword 修改账号密码
+func (s *Server) handleChangePassword(r *http.Request, c *caller) (any, error) {
+	acc, err := s.ownedAccount(r, c)
+	if err != nil {
+		return nil, err
+	}
+
+	var req struct {
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errBadRequest
+	}
+
+	if err := s.accounts.ChangePassword(r.Context(), acc.ID, req.Password); err != nil {
+		return nil, err
+	}
+	logger.Infof("web app: user %d changed password of account %s", c.user.TelegramID, acc.Username)
+
+	return map[string]bool{"ok": true}, nil
+}
+
+// deviceView 在线设备
+type deviceView struct {
+	DeviceName   string    `json:"device_name"`
+	Client       string    `json:"client"`
+	Version      string    `json:"version"`
+	LastActivity time.Time `json:"last_activity"`
+	NowPlaying   string    `json:"now_playing,omitempty"`
+}
+
+// handleDevices 列出账号的在线设备与设备上限
+func (s *Server) handleDevices(r *http.Request, c *caller) (any, error) {
+	acc, err := s.ownedAccount(r, c)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.accounts.Sessions(r.Context(), acc.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	devices := make([]deviceView, 0, len(sessions))
+	for _, session := range sessions {
+		d := deviceView{
+			DeviceName:   session.DeviceName,
+			Client:       session.Client,
+			Version:      session.ApplicationVersion,
+			LastActivity: session.LastActivityDate,
+		}
+		if item := session.NowPlayingItem; item != nil {
+			d.NowPlaying = item.Name
+			if item.SeriesName != "" {
+				d.NowPlaying = item.SeriesName + " - " + item.Name
+			}
+		}
+		devices = append(devices, d)
+	}
+
+	return map[string]any{
+		"max_devices": acc.MaxDevices,
+		"devices":     devices,
+	}, nil
+}
+
+// ownedAccount 读取路径中的账号 ID 并检查账号归属
+func (s *Server) ownedAccount(r *http.Request, c *caller) (*account.Account, error) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, errBadRequest
+	}
+
+	if err := s.accounts.CheckOwnership(r.Context(), uint(id), c.user.ID); err != nil {
+		return nil, err
+	}
+	return s.accounts.Get(r.Context(), uint(id))
+}
+
+// writeError 将错误转换为状态码和当前语言的提示
+func (s *Server) writeError(w http.ResponseWriter, loc *i18n.Localizer, err error) {
+	status, code, message := http.StatusInternalServerError, "internal", loc.T("webapp.error_internal")
+
+	var aerr *apiError
+	var verr *validator.Error
+	switch {
+	case errors.As(err, &aerr):
+		status, code, message = aerr.status, aerr.code, loc.T(aerr.key)
+	case errors.As(err, &verr):
+		status, code, message = http.StatusBadRequest, "invalid_input", loc.T(verr.Key, verr.Args...)
+	case errors.Is(err, account.ErrInvalidInput):
+		status, code, message = http.StatusBadRequest, "invalid_input", loc.T("webapp.error_bad_request")
+	case errors.Is(err, account.ErrNotFound):
+		status, code, message = http.StatusNotFound, "not_found", loc.T("error.account_not_found")
+	case errors.Is(err, account.ErrUnauthorized):
+		status, code, message = http.StatusForbidden, "forbidden", loc.T("error.unauthorized")
+	case errors.Is(err, account.ErrMaintenance):
+		status, code, message = http.StatusServiceUnavailable, "maintenance", loc.T("maintenance.alert_generic")
+	case errors.Is(err, account.ErrSyncDisabled):
+		status, code, message = http.StatusConflict, "sync_disabled", loc.T("error.sync_disabled")
+	case errors.Is(err, account.ErrNotSynced):
+		status, code, message = http.StatusConflict, "not_synced", loc.T("error.not_synced")
+	case errors.Is(err, context.Canceled):
+		return
+	default:
+		logger.Errorf("web app request failed: %v", err)
+	}
+
+	writeJSON(w, status, map[string]string{"error": code, "message": message})
+}
+
+// writeJSON 输出 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Warnf("failed to write web app response: %v", err)
+	}
+}
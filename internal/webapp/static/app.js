@@ -0,0 +1,227 @@
+// Emby 账号管理 Mini App
+// 文案由 /api/me 按用户语言下发，接口通过 Authorization: tma <initData> 认证
+(function () {
+  'use strict';
+
+  var tg = window.Telegram && window.Telegram.WebApp;
+  var app = document.getElementById('app');
+  var messages = {};
+  var renewDays = [];
+
+  // t 翻译消息，按顺序替换 %s / %d 占位符
+  function t(key) {
+    var args = Array.prototype.slice.call(arguments, 1);
+    var tmpl = messages[key] || key;
+    return tmpl.replace(/%[sd]/g, function (m) {
+      return args.length ? String(args.shift()) : m;
+    });
+  }
+
+  // el 创建元素，文本一律通过 textContent 写入
+  function el(tag, className, text) {
+    var node = document.createElement(tag);
+    if (className) node.className = className;
+    if (text !== undefined) node.textContent = text;
+    return node;
+  }
+
+  function formatTime(value) {
+    var d = new Date(value);
+    if (isNaN(d.getTime())) return '';
+    return d.toLocaleString(undefined, {
+      year: 'numeric', month: '2-digit', day: '2-digit', hour: '2-digit', minute: '2-digit'
+    });
+  }
+
+  function formatDate(value) {
+    return new Date(value).toLocaleDateString(undefined, { year: 'numeric', month: '2-digit', day: '2-digit' });
+  }
+
+  function request(method, path, body) {
+    var options = {
+      method: method,
+      headers: { 'Authorization': 'tma ' + (tg ? tg.initData : '') }
+    };
+    if (body !== undefined) {
+      options.headers['Content-Type'] = 'application/json';
+      options.body = JSON.stringify(body);
+    }
+    return fetch(path, options).then(function (resp) {
+      return resp.json().catch(function () { return {}; }).then(function (data) {
+        if (!resp.ok) {
+          var err = new Error(data.message || t('webapp.error_internal'));
+          err.code = data.error;
+          throw err;
+        }
+        return data;
+      });
+    }, function () {
+      throw new Error(messages['webapp.error_network'] || 'Network error');
+    });
+  }
+
+  function alert(text) {
+    if (tg && tg.showAlert) {
+      tg.showAlert(text);
+    } else {
+      window.alert(text);
+    }
+  }
+
+  function confirm(text, callback) {
+    if (tg && tg.showConfirm) {
+      tg.showConfirm(text, callback);
+    } else {
+      callback(window.confirm(text));
+    }
+  }
+
+  function showError(err) {
+    app.replaceChildren(el('p', 'error', err.message));
+  }
+
+  function statusBadge(acc) {
+    var status = acc.expired ? 'expired' : acc.status;
+    return el('span', 'badge ' + status, t('webapp.status_' + status));
+  }
+
+  function expireText(acc) {
+    if (!acc.expire_at) return t('webapp.expire_permanent');
+    if (acc.expired) return t('webapp.expired_at', formatDate(acc.expire_at));
+    return t('webapp.expire_at', formatDate(acc.expire_at), acc.days_left);
+  }
+
+  function setBackButton(handler) {
+    if (!tg || !tg.BackButton) return;
+    tg.BackButton.offClick(showList);
+    if (handler) {
+      tg.BackButton.onClick(handler);
+      tg.BackButton.show();
+    } else {
+      tg.BackButton.hide();
+    }
+  }
+
+  // showList 账号列表
+  function showList() {
+    setBackButton(null);
+    request('GET', 'api/accounts').then(function (accounts) {
+      var nodes = [el('h1', '', t('webapp.title'))];
+      if (accounts.length === 0) {
+        nodes.push(el('p', 'hint', t('webapp.empty')));
+      }
+      accounts.forEach(function (acc) {
+        var card = el('div', 'card link');
+        var title = el('div', 'title');
+        title.append(el('span', '', acc.username), statusBadge(acc));
+        card.append(title, el('div', 'meta', expireText(acc)));
+        card.addEventListener('click', function () { showAccount(acc.id); });
+        nodes.push(card);
+      });
+      app.replaceChildren.apply(app, nodes);
+    }).catch(showError);
+  }
+
+  // showAccount 账号详情：续期、修改密码和在线设备
+  function showAccount(id) {
+    setBackButton(showList);
+    request('GET', 'api/accounts/' + id).then(function (acc) {
+      var info = el('div', 'card');
+      var title = el('div', 'title');
+      title.append(el('span', '', acc.username), statusBadge(acc));
+      info.append(
+        title,
+        el('div', 'meta', expireText(acc)),
+        el('div', 'meta', t('webapp.max_devices', acc.max_devices)),
+        el('div', 'meta', t('webapp.created_at', formatDate(acc.created_at)))
+      );
+
+      var renew = el('div', 'row');
+      renewDays.forEach(function (days) {
+        var button = el('button', '', t('webapp.renew_option', days));
+        button.addEventListener('click', function () { renewAccount(acc, days); });
+        renew.append(button);
+      });
+
+      var password = el('form', 'card');
+      var input = el('input');
+      input.type = 'password';
+      input.autocomplete = 'new-password';
+      input.placeholder = t('webapp.password_placeholder');
+      var submit = el('button', '', t('webapp.password_submit'));
+      submit.type = 'submit';
+      password.append(input, submit);
+      password.addEventListener('submit', function (event) {
+        event.preventDefault();
+        changePassword(acc, input, submit);
+      });
+
+      var devices = el('div', '');
+      devices.append(el('p', 'hint', '…'));
+
+      app.replaceChildren(
+        info,
+        el('h2', '', t('webapp.renew')), renew,
+        el('h2', '', t('webapp.password')), password,
+        el('h2', '', t('webapp.devices')), devices
+      );
+      loadDevices(acc, devices);
+    }).catch(showError);
+  }
+
+  function renewAccount(acc, days) {
+    confirm(t('webapp.renew_confirm', acc.username, days), function (ok) {
+      if (!ok) return;
+      request('POST', 'api/accounts/' + acc.id + '/renew', { days: days }).then(function () {
+        alert(t('webapp.renew_done', days));
+        showAccount(acc.id);
+      }).catch(function (err) { alert(err.message); });
+    });
+  }
+
+  function changePassword(acc, input, submit) {
+    submit.disabled = true;
+    request('POST', 'api/accounts/' + acc.id + '/password', { password: input.value }).then(function () {
+      input.value = '';
+      alert(t('webapp.password_done'));
+    }).catch(function (err) {
+      alert(err.message);
+    }).then(function () {
+      submit.disabled = false;
+    });
+  }
+
+  function loadDevices(acc, container) {
+    request('GET', 'api/accounts/' + acc.id + '/devices').then(function (data) {
+      var nodes = [el('p', 'hint', t('webapp.devices_count', data.devices.length, data.max_devices))];
+      data.devices.forEach(function (d) {
+        var card = el('div', 'card');
+        card.append(
+          el('div', 'title', d.device_name),
+          el('div', 'meta', d.client + (d.version ? ' ' + d.version : '')),
+          el('div', 'meta', t('webapp.last_active', formatTime(d.last_activity)))
+        );
+        if (d.now_playing) {
+          card.append(el('div', 'meta', t('webapp.now_playing', d.now_playing)));
+        }
+        nodes.push(card);
+      });
+      container.replaceChildren.apply(container, nodes);
+    }).catch(function (err) {
+      container.replaceChildren(el('p', 'hint', err.message));
+    });
+  }
+
+  if (tg) {
+    tg.ready();
+    tg.expand();
+  }
+
+  request('GET', 'api/me').then(function (me) {
+    messages = me.messages || {};
+    renewDays = me.renew_days || [];
+    document.documentElement.lang = me.language;
+    document.title = t('webapp.title');
+    showList();
+  }).catch(showError);
+})();
@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS policy_templates (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    description VARCHAR(200),
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    enable_transcoding BOOLEAN NOT NULL DEFAULT FALSE,
+    enable_downloads BOOLEAN NOT NULL DEFAULT FALSE,
+    enable_remote_access BOOLEAN NOT NULL DEFAULT TRUE,
+    bitrate_limit INT NOT NULL DEFAULT 0,
+    max_parental_rating INT NOT NULL DEFAULT 10,
+    enable_all_folders BOOLEAN NOT NULL DEFAULT TRUE,
+    enabled_folders TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_policy_templates_is_default (is_default)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO policy_templates (name, description, is_default, enable_transcoding, enable_downloads, enable_remote_access, bitrate_limit, max_parental_rating, enable_all_folders, enabled_folders)
+VALUES ('default', '内置默认策略', TRUE, FALSE, FALSE, TRUE, 0, 10, TRUE, '[]');
+
+ALTER TABLE accounts ADD COLUMN policy_template_id BIGINT UNSIGNED NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE accounts DROP COLUMN policy_template_id;
+DROP TABLE IF EXISTS policy_templates;
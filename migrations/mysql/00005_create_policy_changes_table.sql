@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE accounts ADD COLUMN policy_overrides TEXT;
+
+CREATE TABLE IF NOT EXISTS policy_changes (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    account_id BIGINT UNSIGNED NOT NULL,
+    field VARCHAR(100) NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    action VARCHAR(20) NOT NULL,
+    operator_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_policy_changes_account_id (account_id),
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- +goose Down
+DROP TABLE IF EXISTS policy_changes;
+ALTER TABLE accounts DROP COLUMN policy_overrides;
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS roles (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(20) NOT NULL UNIQUE,
+    description VARCHAR(200),
+    permissions TEXT,
+    builtin BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO roles (name, description, permissions, builtin) VALUES
+    ('admin', '超级管理员，拥有全部权限', '[]', TRUE),
+    ('user', '普通用户', '[]', TRUE),
+    ('reseller', '代理商，为自己的客户创建和续期账号', '["customer.manage"]', TRUE),
+    ('support', '客服，查看账号和播放会话', '["account.view","session.view","stats.view"]', TRUE);
+
+ALTER TABLE accounts ADD COLUMN created_by BIGINT UNSIGNED NOT NULL DEFAULT 0;
+ALTER TABLE accounts ADD INDEX idx_accounts_created_by (created_by);
+
+-- +goose Down
+ALTER TABLE accounts DROP INDEX idx_accounts_created_by;
+ALTER TABLE accounts DROP COLUMN created_by;
+DROP TABLE IF EXISTS roles;
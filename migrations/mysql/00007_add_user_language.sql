@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN language VARCHAR(10) NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN language;
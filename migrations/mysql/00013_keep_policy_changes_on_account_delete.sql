@@ -0,0 +1,10 @@
+-- +goose Up
+-- 策略变更是审计记录，删除账号后仍然保留；去掉外键并保存账号用户名
+ALTER TABLE policy_changes DROP FOREIGN KEY policy_changes_ibfk_1;
+ALTER TABLE policy_changes ADD COLUMN account_username VARCHAR(100) NOT NULL DEFAULT '' AFTER account_id;
+UPDATE policy_changes pc JOIN accounts a ON a.id = pc.account_id SET pc.account_username = a.username;
+
+-- +goose Down
+DELETE FROM policy_changes WHERE account_id NOT IN (SELECT id FROM accounts);
+ALTER TABLE policy_changes DROP COLUMN account_username;
+ALTER TABLE policy_changes ADD CONSTRAINT policy_changes_ibfk_1 FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE;
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS policy_templates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    is_default INTEGER NOT NULL DEFAULT 0,
+    enable_transcoding INTEGER NOT NULL DEFAULT 0,
+    enable_downloads INTEGER NOT NULL DEFAULT 0,
+    enable_remote_access INTEGER NOT NULL DEFAULT 1,
+    bitrate_limit INTEGER NOT NULL DEFAULT 0,
+    max_parental_rating INTEGER NOT NULL DEFAULT 10,
+    enable_all_folders INTEGER NOT NULL DEFAULT 1,
+    enabled_folders TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_policy_templates_is_default ON policy_templates(is_default);
+
+INSERT INTO policy_templates (name, description, is_default, enable_transcoding, enable_downloads, enable_remote_access, bitrate_limit, max_parental_rating, enable_all_folders, enabled_folders)
+VALUES ('default', '内置默认策略', 1, 0, 0, 1, 0, 10, 1, '[]');
+
+ALTER TABLE accounts ADD COLUMN policy_template_id INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE accounts DROP COLUMN policy_template_id;
+DROP TABLE IF EXISTS policy_templates;
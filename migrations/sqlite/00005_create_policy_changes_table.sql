@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE accounts ADD COLUMN policy_overrides TEXT;
+
+CREATE TABLE IF NOT EXISTS policy_changes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    account_id INTEGER NOT NULL,
+    field TEXT NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    action TEXT NOT NULL,
+    operator_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_policy_changes_account_id ON policy_changes(account_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS policy_changes;
+ALTER TABLE accounts DROP COLUMN policy_overrides;
@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS roles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    permissions TEXT,
+    builtin INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO roles (name, description, permissions, builtin) VALUES
+    ('admin', '超级管理员，拥有全部权限', '[]', 1),
+    ('user', '普通用户', '[]', 1),
+    ('reseller', '代理商，为自己的客户创建和续期账号', '["customer.manage"]', 1),
+    ('support', '客服，查看账号和播放会话', '["account.view","session.view","stats.view"]', 1);
+
+ALTER TABLE accounts ADD COLUMN created_by INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_accounts_created_by ON accounts(created_by);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_accounts_created_by;
+ALTER TABLE accounts DROP COLUMN created_by;
+DROP TABLE IF EXISTS roles;
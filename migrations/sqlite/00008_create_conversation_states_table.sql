@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS conversation_states (
+    telegram_id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    payload TEXT,
+    expires_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_states_expires_at ON conversation_states(expires_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_conversation_states_expires_at;
+DROP TABLE IF EXISTS conversation_states;
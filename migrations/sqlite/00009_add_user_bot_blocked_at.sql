@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN bot_blocked_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN bot_blocked_at;
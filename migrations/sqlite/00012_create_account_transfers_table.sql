@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS account_transfers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    account_id INTEGER NOT NULL,
+    from_user_id INTEGER NOT NULL,
+    to_user_id INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    operator_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_transfers_account_id ON account_transfers(account_id);
+CREATE INDEX IF NOT EXISTS idx_account_transfers_to_user_id ON account_transfers(to_user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_account_transfers_to_user_id;
+DROP INDEX IF EXISTS idx_account_transfers_account_id;
+DROP TABLE IF EXISTS account_transfers;
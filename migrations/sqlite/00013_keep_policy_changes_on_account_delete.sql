@@ -0,0 +1,44 @@
+-- +goose Up
+-- 策略变更是审计记录，删除账号后仍然保留；去掉外键并保存账号用户名
+CREATE TABLE policy_changes_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    account_id INTEGER NOT NULL,
+    account_username TEXT NOT NULL DEFAULT '',
+    field TEXT NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    action TEXT NOT NULL,
+    operator_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO policy_changes_new (id, account_id, account_username, field, old_value, new_value, action, operator_id, created_at)
+SELECT pc.id, pc.account_id, COALESCE(a.username, ''), pc.field, pc.old_value, pc.new_value, pc.action, pc.operator_id, pc.created_at
+FROM policy_changes pc LEFT JOIN accounts a ON a.id = pc.account_id;
+
+DROP TABLE policy_changes;
+ALTER TABLE policy_changes_new RENAME TO policy_changes;
+
+CREATE INDEX IF NOT EXISTS idx_policy_changes_account_id ON policy_changes(account_id);
+
+-- +goose Down
+CREATE TABLE policy_changes_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    account_id INTEGER NOT NULL,
+    field TEXT NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    action TEXT NOT NULL,
+    operator_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE
+);
+
+INSERT INTO policy_changes_old (id, account_id, field, old_value, new_value, action, operator_id, created_at)
+SELECT id, account_id, field, old_value, new_value, action, operator_id, created_at
+FROM policy_changes WHERE account_id IN (SELECT id FROM accounts);
+
+DROP TABLE policy_changes;
+ALTER TABLE policy_changes_old RENAME TO policy_changes;
+
+CREATE INDEX IF NOT EXISTS idx_policy_changes_account_id ON policy_changes(account_id);
@@ -0,0 +1,204 @@
+// Package cron 提供五段式 cron 表达式解析
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field 单个字段的取值范围
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = [5]field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6}, // 0 为周日，7 也表示周日
+}
+
+// maxSearch 查找下次执行时间的最大范围，超出说明表达式不可能匹配(如 2 月 30 日)
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// Schedule 解析后的 cron 表达式
+// 格式: 分 时 日 月 周，支持 *、数字、a-b 范围、a,b 列表与 /n 步长
+// 日和周都不是 * 时满足任意一个即可，与标准 cron 一致
+type Schedule struct {
+	expr   string
+	sets   [5]uint64
+	anyDom bool
+	anyDow bool
+}
+
+// Parse 解析 cron 表达式
+func Parse(expr string) (*Schedule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("cron expression %q: expected 5 fields, got %d", expr, len(parts))
+	}
+
+	s := &Schedule{
+		expr:   strings.Join(parts, " "),
+		anyDom: parts[2] == "*",
+		anyDow: parts[4] == "*",
+	}
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("cron expression %q: %w", expr, err)
+		}
+		s.sets[i] = set
+	}
+
+	// 周字段的 7 与 0 同为周日
+	if s.sets[4]&(1<<7) != 0 {
+		s.sets[4] |= 1
+	}
+
+	return s, nil
+}
+
+// String 返回规范化的表达式
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next 返回 after 之后(不含)的下一次执行时间，精确到分钟，按 after 所在时区的墙上时间匹配
+// 夏令时跳过的时间不会执行，重复出现的时间只在第一次出现时执行；表达式不可能匹配时返回零值
+func (s *Schedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	// 在没有夏令时的 UTC 中按墙上时间查找，再换算回原时区
+	wall := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute(), 0, 0, time.UTC)
+	limit := wall.Add(maxSearch)
+
+	for wall = s.nextWall(wall, limit); !wall.IsZero(); wall = s.nextWall(wall, limit) {
+		t := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), 0, 0, loc)
+		// 换算后墙上时间变化说明该时间被夏令时跳过
+		if t.Hour() != wall.Hour() || t.Minute() != wall.Minute() {
+			continue
+		}
+		// 重复出现的时间换算为第一次出现，已经过去时跳过
+		if t.After(after) {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// nextWall 在 UTC 表示的墙上时间中查找 wall 之后(不含)的下一个匹配时间，到达 limit 时返回零值
+func (s *Schedule) nextWall(wall, limit time.Time) time.Time {
+	t := wall.Add(time.Minute)
+
+	for t.Before(limit) {
+		if !s.has(3, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.has(1, t.Hour()) {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if !s.has(0, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// has 检查字段是否包含指定值
+func (s *Schedule) has(index, value int) bool {
+	return s.sets[index]&(1<<uint(value)) != 0
+}
+
+// matchDay 检查日期是否满足日和周字段
+func (s *Schedule) matchDay(t time.Time) bool {
+	dom := s.has(2, t.Day())
+	dow := s.has(4, int(t.Weekday()))
+	switch {
+	case s.anyDom && s.anyDow:
+		return true
+	case s.anyDom:
+		return dow
+	case s.anyDow:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// parseField 解析单个字段为位集合
+func parseField(expr string, f field) (uint64, error) {
+	upper := f.max
+	if f.name == "day of week" {
+		upper = 7
+	}
+
+	var set uint64
+	for _, item := range strings.Split(expr, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s", stepPart, f.name)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, upper
+		switch {
+		case rangePart == "*":
+			if !hasStep {
+				hi = f.max
+			}
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = parseValue(a, f.min, upper, f.name); err != nil {
+				return 0, err
+			}
+			if hi, err = parseValue(b, f.min, upper, f.name); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q in %s", rangePart, f.name)
+			}
+		default:
+			v, err := parseValue(rangePart, f.min, upper, f.name)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			hi = v
+			if hasStep {
+				hi = upper
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// parseValue 解析并检查字段取值
+func parseValue(s string, lo, hi int, name string) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < lo || v > hi {
+		return 0, fmt.Errorf("invalid value %q in %s (%d-%d)", s, name, lo, hi)
+	}
+	return v, nil
+}
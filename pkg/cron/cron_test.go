@@ -0,0 +1,132 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    string
+		wantErr bool
+	}{
+		{name: "every minute", expr: "* * * * *", want: "* * * * *"},
+		{name: "extra spaces normalized", expr: "  0   9 * *  1-5 ", want: "0 9 * * 1-5"},
+		{name: "lists ranges and steps", expr: "0,30 */2 1-15/7 1,6 *", want: "0,30 */2 1-15/7 1,6 *"},
+		{name: "sunday as 7", expr: "0 0 * * 7", want: "0 0 * * 7"},
+		{name: "too few fields", expr: "* * * *", wantErr: true},
+		{name: "too many fields", expr: "* * * * * *", wantErr: true},
+		{name: "minute out of range", expr: "60 * * * *", wantErr: true},
+		{name: "hour out of range", expr: "0 24 * * *", wantErr: true},
+		{name: "day of month zero", expr: "0 0 0 * *", wantErr: true},
+		{name: "month out of range", expr: "0 0 1 13 *", wantErr: true},
+		{name: "day of week out of range", expr: "0 0 * * 8", wantErr: true},
+		{name: "reversed range", expr: "0 0 * * 5-1", wantErr: true},
+		{name: "zero step", expr: "*/0 * * * *", wantErr: true},
+		{name: "non numeric", expr: "a * * * *", wantErr: true},
+		{name: "empty list item", expr: "1,,2 * * * *", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %v, want error", tt.expr, s)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+			}
+			if s.String() != tt.want {
+				t.Errorf("String() = %q, want %q", s.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2026-01-01 是周四
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{name: "next minute", expr: "* * * * *", after: at(2026, 1, 1, 10, 0), want: at(2026, 1, 1, 10, 1)},
+		{name: "seconds truncated", expr: "* * * * *", after: at(2026, 1, 1, 10, 0).Add(59 * time.Second), want: at(2026, 1, 1, 10, 1)},
+		{name: "exact match excluded", expr: "0 9 * * *", after: at(2026, 1, 1, 9, 0), want: at(2026, 1, 2, 9, 0)},
+		{name: "later today", expr: "30 18 * * *", after: at(2026, 1, 1, 9, 0), want: at(2026, 1, 1, 18, 30)},
+		{name: "step over hours", expr: "15 */6 * * *", after: at(2026, 1, 1, 6, 16), want: at(2026, 1, 1, 12, 15)},
+		{name: "month rollover", expr: "0 0 1 * *", after: at(2026, 1, 15, 0, 0), want: at(2026, 2, 1, 0, 0)},
+		{name: "year rollover", expr: "0 0 1 1 *", after: at(2026, 1, 1, 0, 0), want: at(2027, 1, 1, 0, 0)},
+		{name: "leap day", expr: "0 0 29 2 *", after: at(2026, 1, 1, 0, 0), want: at(2028, 2, 29, 0, 0)},
+		{name: "day of week only", expr: "0 9 * * 1", after: at(2026, 1, 1, 0, 0), want: at(2026, 1, 5, 9, 0)},
+		{name: "sunday as 7", expr: "0 9 * * 7", after: at(2026, 1, 1, 0, 0), want: at(2026, 1, 4, 9, 0)},
+		{name: "sunday as 0", expr: "0 9 * * 0", after: at(2026, 1, 1, 0, 0), want: at(2026, 1, 4, 9, 0)},
+		{name: "day of month only", expr: "0 9 10 * *", after: at(2026, 1, 1, 0, 0), want: at(2026, 1, 10, 9, 0)},
+		// 日和周都受限时满足任意一个即可: 1 月 10 日之前的第一个周一是 5 日
+		{name: "dom or dow picks weekday", expr: "0 9 10 * 1", after: at(2026, 1, 1, 0, 0), want: at(2026, 1, 5, 9, 0)},
+		{name: "dom or dow picks day", expr: "0 9 2 * 1", after: at(2026, 1, 1, 0, 0), want: at(2026, 1, 2, 9, 0)},
+		{name: "impossible date", expr: "0 0 30 2 *", after: at(2026, 1, 1, 0, 0), want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+			}
+			if got := s.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	// 2026-03-08 02:00 EST 跳到 03:00 EDT，2026-11-01 02:00 EDT 回到 01:00 EST
+	local := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, ny)
+	}
+	est := time.FixedZone("EST", -5*3600)
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{name: "skipped time does not run", expr: "30 2 * * *", after: local(3, 7, 12, 0), want: local(3, 9, 2, 30)},
+		{name: "skipped time from inside the day", expr: "30 2 * * *", after: local(3, 8, 0, 0), want: local(3, 9, 2, 30)},
+		{name: "hourly across the gap", expr: "0 * * * *", after: local(3, 8, 1, 30), want: local(3, 8, 3, 0)},
+		{name: "later time on spring day", expr: "0 9 * * *", after: local(3, 8, 0, 0), want: local(3, 8, 9, 0)},
+		{name: "repeated time runs once", expr: "30 1 * * *", after: local(11, 1, 1, 30), want: local(11, 2, 1, 30)},
+		{name: "repeated time from second occurrence", expr: "30 1 * * *", after: time.Date(2026, 11, 1, 1, 10, 0, 0, est), want: local(11, 2, 1, 30)},
+		{name: "first occurrence of repeated time", expr: "30 1 * * *", after: local(11, 1, 0, 0), want: local(11, 1, 1, 30)},
+		{name: "hourly across the overlap", expr: "0 * * * *", after: local(11, 1, 1, 0), want: local(11, 1, 2, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+			}
+			if got := s.Next(tt.after.In(ny)); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+// Package ratelimit 提供令牌桶限流器
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter 令牌桶限流器
+// rate 为每秒补充的令牌数，burst 为桶容量；nil 表示不限流
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// New 创建令牌桶限流器，初始为满桶
+// rate <= 0 时返回 nil 表示不限流，burst <= 0 时按 1 处理
+func New(rate float64, burst int) *Limiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	return &Limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Allow 令牌足够时消耗一个并返回 true，否则不消耗并返回 false
+func (l *Limiter) Allow() bool {
+	if l == nil {
+		return true
+	}
+	return l.allowAt(time.Now())
+}
+
+// Wait 阻塞直到获得令牌或 ctx 结束，ctx 结束时归还预留的令牌
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	delay := l.reserveAt(time.Now())
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	}
+}
+
+// refill 按经过的时间补充令牌，调用方需持有锁
+func (l *Limiter) refill(now time.Time) {
+	if now.After(l.last) {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		l.last = now
+	}
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+func (l *Limiter) allowAt(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(now)
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// reserveAt 预留一个令牌，返回需要等待的时长；令牌不足时透支，由后续补充抵消
+func (l *Limiter) reserveAt(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(now)
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel 归还一个已预留的令牌
+func (l *Limiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// fullAt 令牌桶在指定时间是否已回满
+func (l *Limiter) fullAt(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(now)
+	return l.tokens >= l.burst
+}
+
+// keyedSweepInterval 按键限流器清理已回满令牌桶的间隔
+const keyedSweepInterval = 10 * time.Minute
+
+// Keyed 按键(如用户 ID)独立限流，每个键一个令牌桶
+// 定期清理已回满的令牌桶，避免长期占用内存；nil 表示不限流
+type Keyed struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     int
+	limiters  map[int64]*Limiter
+	lastSweep time.Time
+}
+
+// NewKeyed 创建按键限流器，参数含义同 New，rate <= 0 时返回 nil 表示不限流
+func NewKeyed(rate float64, burst int) *Keyed {
+	if rate <= 0 {
+		return nil
+	}
+	return &Keyed{
+		rate:      rate,
+		burst:     burst,
+		limiters:  make(map[int64]*Limiter),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow 消耗指定键的一个令牌，令牌不足时返回 false
+func (k *Keyed) Allow(key int64) bool {
+	if k == nil {
+		return true
+	}
+	return k.allowAt(key, time.Now())
+}
+
+func (k *Keyed) allowAt(key int64, now time.Time) bool {
+	return k.limiter(key, now).allowAt(now)
+}
+
+// limiter 获取指定键的令牌桶，不存在时创建
+func (k *Keyed) limiter(key int64, now time.Time) *Limiter {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.sweep(now)
+
+	l, ok := k.limiters[key]
+	if !ok {
+		l = New(k.rate, k.burst)
+		k.limiters[key] = l
+	}
+	return l
+}
+
+// sweep 清理已回满的令牌桶，调用方需持有锁
+func (k *Keyed) sweep(now time.Time) {
+	if now.Sub(k.lastSweep) < keyedSweepInterval {
+		return
+	}
+	k.lastSweep = now
+
+	for key, l := range k.limiters {
+		if l.fullAt(now) {
+			delete(k.limiters, key)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiterReserve(t *testing.T) {
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		at    []time.Duration // 每次预留相对创建时间的偏移
+		want  time.Duration   // 最后一次预留的等待时长
+	}{
+		{name: "within burst", rate: 1, burst: 3, at: []time.Duration{0, 0, 0}, want: 0},
+		{name: "burst exhausted", rate: 1, burst: 3, at: []time.Duration{0, 0, 0, 0}, want: time.Second},
+		{name: "overdraft accumulates", rate: 2, burst: 1, at: []time.Duration{0, 0, 0}, want: time.Second},
+		{name: "refilled after interval", rate: 1, burst: 1, at: []time.Duration{0, time.Second}, want: 0},
+		{name: "partial refill", rate: 1, burst: 1, at: []time.Duration{0, 250 * time.Millisecond}, want: 750 * time.Millisecond},
+		{name: "refill capped at burst", rate: 1, burst: 2, at: []time.Duration{0, 0, time.Hour, time.Hour, time.Hour}, want: time.Second},
+		{name: "zero burst defaults to one", rate: 1, burst: 0, at: []time.Duration{0, 0}, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(tt.rate, tt.burst)
+			start := l.last
+			var got time.Duration
+			for _, offset := range tt.at {
+				got = l.reserveAt(start.Add(offset))
+			}
+			if diff := got - tt.want; diff < -time.Millisecond || diff > time.Millisecond {
+				t.Errorf("last reserve = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		at    []time.Duration
+		want  []bool
+	}{
+		{name: "burst then denied", rate: 1, burst: 2, at: []time.Duration{0, 0, 0}, want: []bool{true, true, false}},
+		{name: "denied call does not overdraw", rate: 1, burst: 1, at: []time.Duration{0, 0, 0, time.Second}, want: []bool{true, false, false, true}},
+		{name: "per minute rate", rate: 1.0 / 60, burst: 1, at: []time.Duration{0, 30 * time.Second, time.Minute}, want: []bool{true, false, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(tt.rate, tt.burst)
+			start := l.last
+			for i, offset := range tt.at {
+				if got := l.allowAt(start.Add(offset)); got != tt.want[i] {
+					t.Errorf("call %d at %v: allow = %v, want %v", i, offset, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	l := New(0, 5)
+	if l != nil {
+		t.Fatalf("New(0, 5) = %v, want nil", l)
+	}
+	if !l.Allow() {
+		t.Error("nil limiter Allow() = false")
+	}
+	if err := l.Wait(context.Background()); err != nil {
+		t.Errorf("nil limiter Wait() = %v", err)
+	}
+	if k := NewKeyed(0, 5); k != nil || !k.Allow(1) {
+		t.Error("NewKeyed(0, 5) should be nil and allow everything")
+	}
+}
+
+func TestLimiterWaitCancelled(t *testing.T) {
+	l := New(0.1, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait() = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); err == nil {
+		t.Fatal("Wait() with cancelled context returned nil")
+	}
+
+	// 取消的等待归还令牌，不会继续透支
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < -0.01 {
+		t.Errorf("tokens = %v after cancel, want about 0", tokens)
+	}
+}
+
+func TestKeyed(t *testing.T) {
+	k := NewKeyed(1, 1)
+	now := k.lastSweep
+
+	if !k.allowAt(1, now) {
+		t.Fatal("first call for key 1 denied")
+	}
+	if k.allowAt(1, now) {
+		t.Error("second call for key 1 allowed")
+	}
+	if !k.allowAt(2, now) {
+		t.Error("key 2 limited by key 1")
+	}
+
+	// 清理间隔后已回满的令牌桶被回收
+	later := now.Add(keyedSweepInterval)
+	k.mu.Lock()
+	k.sweep(later)
+	remaining := len(k.limiters)
+	k.mu.Unlock()
+	if remaining != 0 {
+		t.Errorf("limiters after sweep = %d, want 0", remaining)
+	}
+}
@@ -13,6 +13,8 @@ const (
 	DateFormat = "2006-01-02"
 	// TimeFormat 标准时间格式
 	TimeFormat = "15:04:05"
+	// MinuteFormat 精确到分钟的日期时间格式
+	MinuteFormat = "2006-01-02 15:04"
 )
 
 // FormatDateTime 格式化日期时间
@@ -30,6 +32,11 @@ func FormatTime(t time.Time) string {
 	return t.Format(TimeFormat)
 }
 
+// FormatMinute 按本地时区格式化日期时间，精确到分钟，与 ParseLocalMinute 对应
+func FormatMinute(t time.Time) string {
+	return t.Local().Format(MinuteFormat)
+}
+
 // FormatDuration 格式化时间段为易读格式
 func FormatDuration(d time.Duration) string {
 	days := int(d.Hours() / 24)
@@ -94,6 +101,11 @@ func ParseDate(s string) (time.Time, error) {
 	return time.Parse(DateFormat, s)
 }
 
+// ParseLocalMinute 按本地时区解析精确到分钟的日期时间
+func ParseLocalMinute(s string) (time.Time, error) {
+	return time.ParseInLocation(MinuteFormat, s, time.Local)
+}
+
 // Now 获取当前时间
 func Now() time.Time {
 	return time.Now()
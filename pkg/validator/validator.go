@@ -4,32 +4,52 @@ package validator
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 var (
 	// 用户名正则: 字母、数字、下划线，3-32位
 	usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9_]{3,32}$`)
+	// 模板名正则: 字母、数字、下划线和连字符，1-50位
+	templateNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_\-]{1,50}$`)
 	// 邮箱正则
 	emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
 )
 
+// Error 验证错误
+// Key 为消息 ID，界面层可据此翻译；Error 返回默认的中文描述
+type Error struct {
+	Key     string
+	Args    []any
+	message string
+}
+
+func newError(key, format string, args ...any) *Error {
+	return &Error{Key: key, Args: args, message: fmt.Sprintf(format, args...)}
+}
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	return e.message
+}
+
 // ValidateUsername 验证用户名
 func ValidateUsername(username string) error {
 	if username == "" {
-		return fmt.Errorf("用户名不能为空")
+		return newError("validator.username_required", "用户名不能为空")
 	}
 
 	if len(username) < 3 {
-		return fmt.Errorf("用户名长度不能少于3个字符")
+		return newError("validator.username_too_short", "用户名长度不能少于%d个字符", 3)
 	}
 
 	if len(username) > 32 {
-		return fmt.Errorf("用户名长度不能超过32个字符")
+		return newError("validator.username_too_long", "用户名长度不能超过%d个字符", 32)
 	}
 
 	if !usernameRegex.MatchString(username) {
-		return fmt.Errorf("用户名只能包含字母、数字和下划线")
+		return newError("validator.username_invalid", "用户名只能包含字母、数字和下划线")
 	}
 
 	return nil
@@ -38,15 +58,15 @@ func ValidateUsername(username string) error {
 // ValidatePassword 验证密码
 func ValidatePassword(password string) error {
 	if password == "" {
-		return fmt.Errorf("密码不能为空")
+		return newError("validator.password_required", "密码不能为空")
 	}
 
 	if len(password) < 6 {
-		return fmt.Errorf("密码长度不能少于6个字符")
+		return newError("validator.password_too_short", "密码长度不能少于%d个字符", 6)
 	}
 
 	if len(password) > 64 {
-		return fmt.Errorf("密码长度不能超过64个字符")
+		return newError("validator.password_too_long", "密码长度不能超过%d个字符", 64)
 	}
 
 	return nil
@@ -59,7 +79,7 @@ func ValidateEmail(email string) error {
 	}
 
 	if !emailRegex.MatchString(email) {
-		return fmt.Errorf("邮箱格式不正确")
+		return newError("validator.email_invalid", "邮箱格式不正确")
 	}
 
 	return nil
@@ -68,24 +88,50 @@ func ValidateEmail(email string) error {
 // ValidateDays 验证天数
 func ValidateDays(days int) error {
 	if days <= 0 {
-		return fmt.Errorf("天数必须大于0")
+		return newError("validator.days_not_positive", "天数必须大于0")
 	}
 
 	if days > 3650 { // 最大10年
-		return fmt.Errorf("天数不能超过3650天(10年)")
+		return newError("validator.days_too_many", "天数不能超过%d天(10年)", 3650)
 	}
 
 	return nil
 }
 
+// ParseDays 解析并验证天数输入
+func ParseDays(s string) (int, error) {
+	days, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, newError("validator.days_invalid", "请输入有效的天数")
+	}
+	return days, ValidateDays(days)
+}
+
 // ValidateMaxDevices 验证最大设备数
 func ValidateMaxDevices(maxDevices int) error {
 	if maxDevices <= 0 {
-		return fmt.Errorf("最大设备数必须大于0")
+		return newError("validator.max_devices_not_positive", "最大设备数必须大于0")
 	}
 
 	if maxDevices > 100 {
-		return fmt.Errorf("最大设备数不能超过100")
+		return newError("validator.max_devices_too_many", "最大设备数不能超过%d", 100)
+	}
+
+	return nil
+}
+
+// ValidateTemplateName 验证策略模板名称
+func ValidateTemplateName(name string) error {
+	if name == "" {
+		return newError("validator.template_name_required", "模板名称不能为空")
+	}
+
+	if len(name) > 50 {
+		return newError("validator.template_name_too_long", "模板名称长度不能超过%d个字符", 50)
+	}
+
+	if !templateNameRegex.MatchString(name) {
+		return newError("validator.template_name_invalid", "模板名称只能包含字母、数字、下划线和连字符")
 	}
 
 	return nil